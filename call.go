@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/config"
+	"github.com/phildougherty/go-tailscale-mcp/logging"
+	"github.com/phildougherty/go-tailscale-mcp/server"
+)
+
+const callUsage = `Usage: tailscale-mcp call <tool> [--args '<json>'] [--json] [--timeout 2m] [--config path]
+       tailscale-mcp call --list
+
+Runs a single tool invocation without an MCP client and prints the result.
+`
+
+// runCall implements the call subcommand and returns the process exit code:
+// 0 on success, 1 if the tool reported an error, 2 for usage or setup errors
+func runCall(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("call", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, callUsage)
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+	toolArgs := flags.String("args", "", "tool arguments as a JSON object")
+	asJSON := flags.Bool("json", false, "print the full tool result as JSON")
+	list := flags.Bool("list", false, "list the available tools and exit")
+	timeout := flags.Duration("timeout", 2*time.Minute, "maximum time to wait for the tool")
+	configPath := flags.String("config", "", "config file (default "+config.DefaultPath()+")")
+
+	// Allow the tool name before or after the flags
+	var tool string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		tool, args = args[0], args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if tool == "" && flags.NArg() > 0 {
+		tool = flags.Arg(0)
+	}
+	if tool == "" && !*list {
+		flags.Usage()
+		return 2
+	}
+
+	var arguments json.RawMessage
+	if *toolArgs != "" {
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(*toolArgs), &obj); err != nil {
+			fmt.Fprintf(stderr, "Invalid --args: must be a JSON object: %v\n", err)
+			return 2
+		}
+		arguments = json.RawMessage(*toolArgs)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	logFile, err := logging.Setup(cfg.LogLevel, cfg.LogFormat, cfg.LogFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	defer logFile.Close()
+	// Tokens only live as long as the server, and whoever typed the command
+	// has already decided to run it
+	cfg.ConfirmDestructive = false
+	srv, err := server.NewTailscaleServer(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to create Tailscale MCP server: %v\n", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if *list {
+		tools, err := srv.ListTools(ctx)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error listing tools: %v\n", err)
+			return 2
+		}
+		if *asJSON {
+			return writeJSON(stdout, stderr, tools)
+		}
+		for _, t := range tools {
+			fmt.Fprintf(stdout, "%-40s %s\n", t.Name, firstSentence(t.Description))
+		}
+		return 0
+	}
+
+	result, err := srv.CallTool(ctx, tool, arguments)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error calling %s: %v\n", tool, err)
+		return 2
+	}
+
+	if *asJSON {
+		if code := writeJSON(stdout, stderr, result); code != 0 {
+			return code
+		}
+	} else {
+		for _, content := range result.Content {
+			switch c := content.(type) {
+			case *mcp.TextContent:
+				fmt.Fprint(stdout, c.Text)
+				if !strings.HasSuffix(c.Text, "\n") {
+					fmt.Fprintln(stdout)
+				}
+			default:
+				// Non-text content (images, resources) is only useful as JSON
+				data, _ := json.Marshal(content)
+				fmt.Fprintln(stdout, string(data))
+			}
+		}
+	}
+
+	if result.IsError {
+		return 1
+	}
+	return 0
+}
+
+func writeJSON(stdout, stderr io.Writer, v interface{}) int {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(stderr, "Error encoding result: %v\n", err)
+		return 2
+	}
+	fmt.Fprintln(stdout, string(data))
+	return 0
+}
+
+// firstSentence shortens a tool description for the --list output
+func firstSentence(s string) string {
+	if i := strings.Index(s, ". "); i >= 0 {
+		return s[:i+1]
+	}
+	return s
+}
@@ -0,0 +1,467 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config holds the server configuration loaded from the config file and
+// environment variables
+type Config struct {
+	APIKey            string `json:"api_key,omitempty"`
+	Tailnet           string `json:"tailnet,omitempty"`
+	EnableK8sOperator bool   `json:"enable_k8s_operator,omitempty"`
+	Kubeconfig        string `json:"kubeconfig,omitempty"`
+
+	// OAuthClientID and OAuthClientSecret authenticate API requests with an
+	// OAuth client instead of APIKey. Access tokens are refreshed automatically.
+	OAuthClientID     string `json:"oauth_client_id,omitempty"`
+	OAuthClientSecret string `json:"oauth_client_secret,omitempty"`
+
+	// Tailnets holds credentials for other tailnets, keyed by tailnet name,
+	// which API tools run against when called with that tailnet or after
+	// switch_profile moves this node to it
+	Tailnets map[string]TailnetCredentials `json:"tailnets,omitempty"`
+
+	// TailscaleBin is the tailscale binary to run (default: tailscale on
+	// PATH, then the platform's install locations, e.g. the macOS app bundle)
+	TailscaleBin string `json:"tailscale_bin,omitempty"`
+
+	// Socket is tailscaled's LocalAPI socket, passed to every command as
+	// --socket (default: the platform's, e.g. /var/run/tailscale/tailscaled.sock)
+	Socket string `json:"socket,omitempty"`
+
+	// LoginServer is the control server `tailscale up` logs in to, for
+	// self-hosted control planes such as Headscale (default: Tailscale's)
+	LoginServer string `json:"login_server,omitempty"`
+
+	// APIBaseURL replaces https://api.tailscale.com/api/v2 for API requests,
+	// and APIAuthScheme is how the key or token is sent: "bearer" (the
+	// default) or "basic"
+	APIBaseURL    string `json:"api_base_url,omitempty"`
+	APIAuthScheme string `json:"api_auth_scheme,omitempty"`
+
+	// OutputDir is where file-writing tools save output when the client
+	// does not expose MCP roots
+	OutputDir string `json:"output_dir,omitempty"`
+
+	// StateFile is where workflow state (maintenance windows, access requests,
+	// temporary rules) is persisted between runs
+	StateFile string `json:"state_file,omitempty"`
+
+	// EnableSSHExec allows tools to run commands on tailnet hosts over SSH.
+	// Off by default since it grants remote command execution.
+	EnableSSHExec bool `json:"enable_ssh_exec,omitempty"`
+
+	// MetricsTextfile is a node_exporter textfile collector path that tailnet
+	// metrics are written to every MetricsInterval (e.g., "1m", the default)
+	MetricsTextfile string `json:"metrics_textfile,omitempty"`
+	MetricsInterval string `json:"metrics_interval,omitempty"`
+
+	// WatchInterval is how often the local status is polled for peer, route
+	// and health changes to notify clients about (e.g., "30s", the default)
+	WatchInterval string `json:"watch_interval,omitempty"`
+
+	// Transport is how MCP clients connect: "stdio" (the default) or "http"
+	// for Streamable HTTP and SSE on ListenAddr
+	Transport  string `json:"transport,omitempty"`
+	ListenAddr string `json:"listen_addr,omitempty"`
+
+	// AuthToken (or the contents of AuthTokenFile) is a bearer token HTTP
+	// clients must send. AuthIdentities lets tailnet clients in without a
+	// token when whois resolves them to a listed login name or tag ("*" for any).
+	// Without either, the HTTP transport only starts on a loopback address
+	// unless AllowUnauthenticated is set.
+	AuthToken            string   `json:"auth_token,omitempty"`
+	AuthTokenFile        string   `json:"auth_token_file,omitempty"`
+	AuthIdentities       []string `json:"auth_identities,omitempty"`
+	AllowUnauthenticated bool     `json:"allow_unauthenticated,omitempty"`
+
+	// EnabledTools and DisabledTools filter the registered tools by name or
+	// glob pattern (e.g., "list_*"). When EnabledTools is set only matching
+	// tools are exposed; DisabledTools is applied afterwards.
+	EnabledTools  []string `json:"enabled_tools,omitempty"`
+	DisabledTools []string `json:"disabled_tools,omitempty"`
+
+	// DryRun makes destructive tools that take a dry_run argument (deleting
+	// devices, replacing the ACL or DNS settings, Kubernetes creates and
+	// deletes) only preview their change unless a call passes dry_run=false
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// ConfirmDestructive makes the tools annotated as destructive (deleting
+	// devices, keys and services, editing the ACL or DNS settings, changing
+	// tags, logging out, ...) return a summary and a one-time token first,
+	// and only act when called again with the token
+	ConfirmDestructive bool `json:"confirm_destructive,omitempty"`
+
+	// RevealSecrets lets tool results include full secrets: auth keys, OAuth
+	// client and webhook secrets, disablement secrets and the server's own
+	// credentials. By default they are masked.
+	RevealSecrets bool `json:"reveal_secrets,omitempty"`
+
+	// LogLevel is one of debug, info (the default), warn or error. Logs
+	// are written as LogFormat ("text", the default, or "json") to LogFile,
+	// or to stderr when it is unset.
+	LogLevel  string `json:"log_level,omitempty"`
+	LogFormat string `json:"log_format,omitempty"`
+	LogFile   string `json:"log_file,omitempty"`
+
+	// APITimeout bounds each Tailscale API request (default "30s")
+	APITimeout string `json:"api_timeout,omitempty"`
+
+	// CLITimeout bounds each tailscale command (default "1m", "0s" for no
+	// limit), so a hung tailscaled doesn't block tool calls forever
+	CLITimeout string `json:"cli_timeout,omitempty"`
+
+	// APIRetries is how many times rate-limited or transiently failing API
+	// requests are retried with backoff (default 3, 0 disables retries)
+	APIRetries *int `json:"api_retries,omitempty"`
+
+	// CacheTTL is how long status, device list and DNS lookups are reused
+	// (default "10s", "0s" disables caching)
+	CacheTTL string `json:"cache_ttl,omitempty"`
+
+	// WebhookListenAddr enables a listener for Tailscale webhook deliveries,
+	// which are verified with WebhookSecret (the secret shown when the
+	// endpoint was created) and exposed through the recent_events tool
+	WebhookListenAddr string `json:"webhook_listen_addr,omitempty"`
+	WebhookSecret     string `json:"webhook_secret,omitempty"`
+}
+
+// TailnetCredentials authenticate API requests for one tailnet, with an API
+// key or an OAuth client
+type TailnetCredentials struct {
+	APIKey            string `json:"api_key,omitempty"`
+	OAuthClientID     string `json:"oauth_client_id,omitempty"`
+	OAuthClientSecret string `json:"oauth_client_secret,omitempty"`
+
+	// Profile is the tailscale profile (ID or account) whose tailnet this is,
+	// for profiles whose tailnet name isn't the one the API uses. Switching
+	// to a profile makes the API tools use its tailnet's credentials.
+	Profile string `json:"profile,omitempty"`
+}
+
+// LogLevels are the accepted log_level values
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// LogFormats are the accepted log_format values
+var LogFormats = []string{"text", "json"}
+
+// APIAuthSchemes are the accepted api_auth_scheme values
+var APIAuthSchemes = []string{"bearer", "basic"}
+
+// DefaultPath returns the default config file location
+// (e.g., ~/.config/tailscale-mcp/config.yaml on Linux)
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "tailscale-mcp.yaml"
+	}
+	return filepath.Join(dir, "tailscale-mcp", "config.yaml")
+}
+
+// ResolvePath returns the config file to use: the --config flag value if
+// given, else TAILSCALE_MCP_CONFIG, else the default location
+func ResolvePath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if env := os.Getenv("TAILSCALE_MCP_CONFIG"); env != "" {
+		return env
+	}
+	return DefaultPath()
+}
+
+// DefaultStatePath returns the default state file location, next to the config file
+func DefaultStatePath() string {
+	return filepath.Join(filepath.Dir(DefaultPath()), "state.json")
+}
+
+// Load reads the config file at path. A missing file is not an error and
+// results in an empty configuration.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	// Unknown keys are rejected so typos don't silently fall back to defaults
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Save writes the configuration to path, creating parent directories as needed.
+// The file is written with owner-only permissions since it may contain the API key.
+func Save(path string, cfg *Config) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ApplyEnv overrides config values with any environment variables that are set
+func (c *Config) ApplyEnv() {
+	if apiKey := os.Getenv("TAILSCALE_API_KEY"); apiKey != "" {
+		c.APIKey = apiKey
+	}
+	if tailnet := os.Getenv("TAILSCALE_TAILNET"); tailnet != "" {
+		c.Tailnet = tailnet
+	}
+	if clientID := os.Getenv("TS_OAUTH_CLIENT_ID"); clientID != "" {
+		c.OAuthClientID = clientID
+	}
+	if clientSecret := os.Getenv("TS_OAUTH_CLIENT_SECRET"); clientSecret != "" {
+		c.OAuthClientSecret = clientSecret
+	}
+	if k8sEnv := os.Getenv("ENABLE_K8S_OPERATOR"); k8sEnv != "" {
+		c.EnableK8sOperator = ParseBool(k8sEnv)
+	}
+	if kubeconfig := os.Getenv("KUBECONFIG"); kubeconfig != "" {
+		c.Kubeconfig = kubeconfig
+	}
+	if bin := os.Getenv("TAILSCALE_BIN"); bin != "" {
+		c.TailscaleBin = bin
+	}
+	if socket := os.Getenv("TS_SOCKET"); socket != "" {
+		c.Socket = socket
+	}
+	if loginServer := os.Getenv("TAILSCALE_MCP_LOGIN_SERVER"); loginServer != "" {
+		c.LoginServer = loginServer
+	}
+	if baseURL := os.Getenv("TAILSCALE_MCP_API_BASE_URL"); baseURL != "" {
+		c.APIBaseURL = baseURL
+	}
+	if scheme := os.Getenv("TAILSCALE_MCP_API_AUTH_SCHEME"); scheme != "" {
+		c.APIAuthScheme = scheme
+	}
+	if outputDir := os.Getenv("TAILSCALE_MCP_OUTPUT_DIR"); outputDir != "" {
+		c.OutputDir = outputDir
+	}
+	if stateFile := os.Getenv("TAILSCALE_MCP_STATE_FILE"); stateFile != "" {
+		c.StateFile = stateFile
+	}
+	if sshExec := os.Getenv("TAILSCALE_MCP_ENABLE_SSH_EXEC"); sshExec != "" {
+		c.EnableSSHExec = ParseBool(sshExec)
+	}
+	if textfile := os.Getenv("TAILSCALE_MCP_METRICS_TEXTFILE"); textfile != "" {
+		c.MetricsTextfile = textfile
+	}
+	if interval := os.Getenv("TAILSCALE_MCP_METRICS_INTERVAL"); interval != "" {
+		c.MetricsInterval = interval
+	}
+	if interval := os.Getenv("TAILSCALE_MCP_WATCH_INTERVAL"); interval != "" {
+		c.WatchInterval = interval
+	}
+	if transport := os.Getenv("TAILSCALE_MCP_TRANSPORT"); transport != "" {
+		c.Transport = transport
+	}
+	if listenAddr := os.Getenv("TAILSCALE_MCP_LISTEN_ADDR"); listenAddr != "" {
+		c.ListenAddr = listenAddr
+	}
+	if token := os.Getenv("TAILSCALE_MCP_AUTH_TOKEN"); token != "" {
+		c.AuthToken = token
+	}
+	if tokenFile := os.Getenv("TAILSCALE_MCP_AUTH_TOKEN_FILE"); tokenFile != "" {
+		c.AuthTokenFile = tokenFile
+	}
+	if identities := os.Getenv("TAILSCALE_MCP_AUTH_IDENTITIES"); identities != "" {
+		c.AuthIdentities = splitList(identities)
+	}
+	if allow := os.Getenv("TAILSCALE_MCP_ALLOW_UNAUTHENTICATED"); allow != "" {
+		c.AllowUnauthenticated = ParseBool(allow)
+	}
+	if enabled := os.Getenv("TAILSCALE_MCP_ENABLED_TOOLS"); enabled != "" {
+		c.EnabledTools = splitList(enabled)
+	}
+	if disabled := os.Getenv("TAILSCALE_MCP_DISABLED_TOOLS"); disabled != "" {
+		c.DisabledTools = splitList(disabled)
+	}
+	if dryRun := os.Getenv("TAILSCALE_MCP_DRY_RUN"); dryRun != "" {
+		c.DryRun = ParseBool(dryRun)
+	}
+	if confirm := os.Getenv("TAILSCALE_MCP_CONFIRM_DESTRUCTIVE"); confirm != "" {
+		c.ConfirmDestructive = ParseBool(confirm)
+	}
+	if reveal := os.Getenv("TAILSCALE_MCP_REVEAL_SECRETS"); reveal != "" {
+		c.RevealSecrets = ParseBool(reveal)
+	}
+	if level := os.Getenv("TAILSCALE_MCP_LOG_LEVEL"); level != "" {
+		c.LogLevel = level
+	}
+	if format := os.Getenv("TAILSCALE_MCP_LOG_FORMAT"); format != "" {
+		c.LogFormat = format
+	}
+	if logFile := os.Getenv("TAILSCALE_MCP_LOG_FILE"); logFile != "" {
+		c.LogFile = logFile
+	}
+	if timeout := os.Getenv("TAILSCALE_MCP_API_TIMEOUT"); timeout != "" {
+		c.APITimeout = timeout
+	}
+	if timeout := os.Getenv("TAILSCALE_MCP_CLI_TIMEOUT"); timeout != "" {
+		c.CLITimeout = timeout
+	}
+	if ttl := os.Getenv("TAILSCALE_MCP_CACHE_TTL"); ttl != "" {
+		c.CacheTTL = ttl
+	}
+	if webhookAddr := os.Getenv("TAILSCALE_MCP_WEBHOOK_LISTEN_ADDR"); webhookAddr != "" {
+		c.WebhookListenAddr = webhookAddr
+	}
+	if webhookSecret := os.Getenv("TAILSCALE_MCP_WEBHOOK_SECRET"); webhookSecret != "" {
+		c.WebhookSecret = webhookSecret
+	}
+	if retries := os.Getenv("TAILSCALE_MCP_API_RETRIES"); retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil {
+			// Reported by Validate
+			n = -1
+		}
+		c.APIRetries = &n
+	}
+}
+
+// Validate checks the configuration for invalid values, reporting every
+// problem found rather than just the first
+func (c *Config) Validate() error {
+	var problems []string
+
+	if (c.OAuthClientID == "") != (c.OAuthClientSecret == "") {
+		problems = append(problems, "oauth_client_id and oauth_client_secret must be set together")
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Tailnets)) {
+		creds := c.Tailnets[name]
+		switch {
+		case (creds.OAuthClientID == "") != (creds.OAuthClientSecret == ""):
+			problems = append(problems, fmt.Sprintf("tailnets.%s: oauth_client_id and oauth_client_secret must be set together", name))
+		case creds.APIKey == "" && creds.OAuthClientID == "":
+			problems = append(problems, fmt.Sprintf("tailnets.%s: needs an api_key or an oauth_client_id and oauth_client_secret", name))
+		}
+	}
+
+	switch c.Transport {
+	case "", "stdio", "http":
+	default:
+		problems = append(problems, fmt.Sprintf("transport: %q is not supported (expected stdio or http)", c.Transport))
+	}
+	if c.ListenAddr != "" {
+		if _, _, err := net.SplitHostPort(c.ListenAddr); err != nil {
+			problems = append(problems, fmt.Sprintf("listen_addr: %q must be host:port (e.g., 127.0.0.1:8080)", c.ListenAddr))
+		}
+	}
+	if c.WebhookListenAddr != "" {
+		if _, _, err := net.SplitHostPort(c.WebhookListenAddr); err != nil {
+			problems = append(problems, fmt.Sprintf("webhook_listen_addr: %q must be host:port (e.g., 0.0.0.0:8081)", c.WebhookListenAddr))
+		}
+		if c.WebhookSecret == "" {
+			problems = append(problems, "webhook_secret is required with webhook_listen_addr so deliveries can be verified")
+		}
+	}
+	for _, u := range []struct{ name, value string }{
+		{"login_server", c.LoginServer},
+		{"api_base_url", c.APIBaseURL},
+	} {
+		if u.value == "" {
+			continue
+		}
+		if parsed, err := url.Parse(u.value); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s: %q must be an http(s) URL", u.name, u.value))
+		}
+	}
+	if c.APIAuthScheme != "" && !slices.Contains(APIAuthSchemes, c.APIAuthScheme) {
+		problems = append(problems, fmt.Sprintf("api_auth_scheme: %q is not one of %s", c.APIAuthScheme, strings.Join(APIAuthSchemes, ", ")))
+	}
+	if c.AuthToken != "" && c.AuthTokenFile != "" {
+		problems = append(problems, "auth_token and auth_token_file are mutually exclusive")
+	}
+
+	for _, pattern := range append(append([]string{}, c.EnabledTools...), c.DisabledTools...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("enabled_tools/disabled_tools: invalid pattern %q", pattern))
+		}
+	}
+
+	if c.LogLevel != "" && !slices.Contains(LogLevels, c.LogLevel) {
+		problems = append(problems, fmt.Sprintf("log_level: %q is not one of %s", c.LogLevel, strings.Join(LogLevels, ", ")))
+	}
+	if c.LogFormat != "" && !slices.Contains(LogFormats, c.LogFormat) {
+		problems = append(problems, fmt.Sprintf("log_format: %q is not one of %s", c.LogFormat, strings.Join(LogFormats, ", ")))
+	}
+	for _, d := range []struct{ name, value string }{
+		{"metrics_interval", c.MetricsInterval},
+		{"watch_interval", c.WatchInterval},
+		{"api_timeout", c.APITimeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+		if v, err := time.ParseDuration(d.value); err != nil || v <= 0 {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a positive duration (e.g., 30s, 5m)", d.name, d.value))
+		}
+	}
+	if c.CLITimeout != "" {
+		if timeout, err := time.ParseDuration(c.CLITimeout); err != nil || timeout < 0 {
+			problems = append(problems, fmt.Sprintf("cli_timeout: %q is not a duration (e.g., 1m, or 0s for no limit)", c.CLITimeout))
+		}
+	}
+	if c.CacheTTL != "" {
+		if ttl, err := time.ParseDuration(c.CacheTTL); err != nil || ttl < 0 {
+			problems = append(problems, fmt.Sprintf("cache_ttl: %q is not a duration (e.g., 10s, or 0s to disable)", c.CacheTTL))
+		}
+	}
+	if c.APIRetries != nil && *c.APIRetries < 0 {
+		problems = append(problems, "api_retries: must be a non-negative integer")
+	}
+	if c.MetricsTextfile != "" && !strings.HasSuffix(c.MetricsTextfile, ".prom") {
+		problems = append(problems, "metrics_textfile: must end in .prom to be picked up by node_exporter")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// ParseBool interprets common truthy strings (true, 1, yes, on)
+func ParseBool(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return value == "true" || value == "1" || value == "yes" || value == "on"
+}
+
+// splitList splits a comma-separated environment value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
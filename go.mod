@@ -5,9 +5,11 @@ go 1.23.2
 require (
 	github.com/google/jsonschema-go v0.2.3
 	github.com/modelcontextprotocol/go-sdk v0.5.0
+	github.com/tailscale/hujson v0.0.0-20250226034555-ec1d1c113d33
 	k8s.io/api v0.31.3
 	k8s.io/apimachinery v0.31.3
 	k8s.io/client-go v0.31.3
+	sigs.k8s.io/yaml v1.4.0
 )
 
 require (
@@ -49,5 +51,4 @@ require (
 	k8s.io/utils v0.0.0-20240711033017-18e509b52bc8 // indirect
 	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.4.1 // indirect
-	sigs.k8s.io/yaml v1.4.0 // indirect
 )
@@ -0,0 +1,204 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// exitNodeRoutes are the routes a device advertises to offer itself as an
+// exit node
+var exitNodeRoutes = []string{"0.0.0.0/0", "::/0"}
+
+// ConnectorRoute is a route a Connector is configured to advertise and
+// where it stands in the tailnet
+type ConnectorRoute struct {
+	Route      string `json:"route"`
+	Advertised bool   `json:"advertised"`
+	Approved   bool   `json:"approved"`
+}
+
+// ConnectorReport is a Connector's state in the cluster and in the tailnet.
+// Device and route approval come from the Tailscale API; ApprovalError says
+// why they are missing when they couldn't be checked.
+type ConnectorReport struct {
+	Name          string           `json:"name"`
+	Ready         bool             `json:"ready"`
+	ReadyMessage  string           `json:"ready_message,omitempty"`
+	Hostname      string           `json:"hostname,omitempty"`
+	TailnetIPs    []string         `json:"tailnet_ips,omitempty"`
+	DeviceID      string           `json:"device_id,omitempty"`
+	Online        bool             `json:"online"`
+	ExitNode      bool             `json:"exit_node"`
+	Routes        []ConnectorRoute `json:"routes"`
+	ApprovalError string           `json:"approval_error,omitempty"`
+}
+
+// ConnectorStatusReport reports on a Connector, looking up its device in the
+// tailnet by its Tailscale IPs, or its hostname, to check which of its routes
+// are approved. api may be nil, in which case approval isn't checked.
+func ConnectorStatusReport(ctx context.Context, connector *Connector, api *tailscale.APIClient) *ConnectorReport {
+	report := &ConnectorReport{
+		Name:     connector.Metadata.Name,
+		ExitNode: connector.Spec.ExitNode,
+		Routes:   []ConnectorRoute{},
+	}
+	if status := connector.Status; status != nil {
+		report.Hostname = status.Hostname
+		report.TailnetIPs = status.TailnetIPs
+		for _, condition := range status.Conditions {
+			if condition.Type == "ConnectorReady" {
+				report.Ready = condition.Status == "True"
+				report.ReadyMessage = condition.Message
+			}
+		}
+	}
+
+	var routes []string
+	if connector.Spec.SubnetRouter != nil {
+		routes = append(routes, connector.Spec.SubnetRouter.AdvertiseRoutes...)
+	}
+	if connector.Spec.ExitNode {
+		routes = append(routes, exitNodeRoutes...)
+	}
+	for _, route := range routes {
+		report.Routes = append(report.Routes, ConnectorRoute{Route: route})
+	}
+
+	if api == nil {
+		report.ApprovalError = "no Tailscale API credentials are configured"
+		return report
+	}
+	if len(report.TailnetIPs) == 0 && report.Hostname == "" {
+		report.ApprovalError = "the Connector hasn't reported its device yet"
+		return report
+	}
+	devices, err := api.ListAllDeviceFields(ctx)
+	if err != nil {
+		report.ApprovalError = fmt.Sprintf("could not list the tailnet's devices: %v", err)
+		return report
+	}
+	device := connectorDevice(devices, report)
+	if device == nil {
+		report.ApprovalError = "no device in the tailnet matches the Connector's IPs or hostname"
+		return report
+	}
+
+	report.DeviceID = device.ID
+	report.Online = device.Online
+	for i, route := range report.Routes {
+		report.Routes[i].Advertised = containsRoute(device.AdvertisedRoutes, route.Route)
+		report.Routes[i].Approved = containsRoute(device.EnabledRoutes, route.Route)
+	}
+	return report
+}
+
+// connectorDevice finds the Connector's device by its Tailscale IPs, falling
+// back to its MagicDNS name
+func connectorDevice(devices []tailscale.Device, report *ConnectorReport) *tailscale.Device {
+	for i, device := range devices {
+		for _, address := range device.Addresses {
+			for _, ip := range report.TailnetIPs {
+				if address == ip {
+					return &devices[i]
+				}
+			}
+		}
+	}
+	if report.Hostname == "" {
+		return nil
+	}
+	hostname := strings.TrimSuffix(report.Hostname, ".")
+	for i, device := range devices {
+		name := strings.TrimSuffix(device.Name, ".")
+		if strings.EqualFold(name, hostname) || strings.EqualFold(strings.SplitN(name, ".", 2)[0], hostname) {
+			return &devices[i]
+		}
+	}
+	return nil
+}
+
+// containsRoute reports whether routes has route, comparing prefixes in
+// their canonical form
+func containsRoute(routes []string, route string) bool {
+	want := canonicalRoute(route)
+	for _, r := range routes {
+		if canonicalRoute(r) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func canonicalRoute(route string) string {
+	if prefix, err := netip.ParsePrefix(strings.TrimSpace(route)); err == nil {
+		return prefix.Masked().String()
+	}
+	return route
+}
+
+// FormatConnectorReport renders the report, with what to do about routes
+// that aren't advertised or approved yet
+func FormatConnectorReport(report *ConnectorReport) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Connector '%s':\n\n", report.Name))
+
+	ready := "✓ Ready"
+	if !report.Ready {
+		ready = "✗ Not ready"
+	}
+	if report.ReadyMessage != "" {
+		ready += fmt.Sprintf(" (%s)", report.ReadyMessage)
+	}
+	result.WriteString(ready + "\n")
+
+	hostname := report.Hostname
+	if hostname == "" {
+		hostname = "not assigned yet"
+	}
+	result.WriteString(fmt.Sprintf("Hostname: %s\n", hostname))
+	ips := "not assigned yet"
+	if len(report.TailnetIPs) > 0 {
+		ips = strings.Join(report.TailnetIPs, ", ")
+	}
+	result.WriteString(fmt.Sprintf("Tailscale IPs: %s\n", ips))
+	if report.DeviceID != "" {
+		online := "offline"
+		if report.Online {
+			online = "online"
+		}
+		result.WriteString(fmt.Sprintf("Device: %s (%s)\n", report.DeviceID, online))
+	}
+
+	if len(report.Routes) == 0 {
+		result.WriteString("\nNo routes configured\n")
+		return result.String()
+	}
+	result.WriteString("\nRoutes:\n")
+	pending := 0
+	for _, route := range report.Routes {
+		state := "approval not checked"
+		if report.ApprovalError == "" {
+			switch {
+			case route.Approved:
+				state = "✓ approved"
+			case route.Advertised:
+				state = "⚠ advertised, not approved"
+				pending++
+			default:
+				state = "⚠ not advertised yet"
+			}
+		}
+		result.WriteString(fmt.Sprintf("  %s: %s\n", route.Route, state))
+	}
+
+	if report.ApprovalError != "" {
+		result.WriteString(fmt.Sprintf("\nℹ Route approval wasn't checked: %s.\n", report.ApprovalError))
+	} else if pending > 0 {
+		result.WriteString(fmt.Sprintf("\n→ Approve the routes with enable_routes (device_id %s), or add autoApprovers for the Connector's tags to the policy.\n", report.DeviceID))
+	}
+	return result.String()
+}
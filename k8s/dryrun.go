@@ -0,0 +1,90 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DryRunTools take a dry_run argument, which sends the create or delete to
+// the API server as a server-side dry run: admission and validation run as
+// usual, but nothing is persisted. The server's dry_run setting turns it on
+// for calls that don't pass one.
+var DryRunTools = []string{
+	"mcp__tailscale__k8s_proxy_class_create",
+	"mcp__tailscale__k8s_proxy_class_delete",
+	"mcp__tailscale__k8s_proxy_group_create",
+	"mcp__tailscale__k8s_proxy_group_delete",
+	"mcp__tailscale__k8s_ingress_create",
+	"mcp__tailscale__k8s_egress_create",
+	"mcp__tailscale__k8s_connector_create",
+	"mcp__tailscale__k8s_connector_delete",
+	"mcp__tailscale__k8s_dns_config_create",
+}
+
+// dryRunProperty is the dry_run argument of the tools in DryRunTools
+var dryRunProperty = &jsonschema.Schema{
+	Type:        "boolean",
+	Description: "Validate with a server-side dry run and show the manifest without changing anything (default: false, or the server's dry_run setting)",
+}
+
+func (rm *ResourceManager) createOptions() metav1.CreateOptions {
+	if rm.DryRun {
+		return metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}
+	}
+	return metav1.CreateOptions{}
+}
+
+func (rm *ResourceManager) deleteOptions() metav1.DeleteOptions {
+	if rm.DryRun {
+		return metav1.DeleteOptions{DryRun: []string{metav1.DryRunAll}}
+	}
+	return metav1.DeleteOptions{}
+}
+
+// GetResourceYAML renders an existing resource the way update diffs show it
+func (rm *ResourceManager) GetResourceYAML(ctx context.Context, gvr schema.GroupVersionResource, kind, name string) (string, error) {
+	obj, err := rm.dynamicClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", NewResourceNotFoundError(kind, name, err)
+		}
+		return "", NewConnectivityError(fmt.Sprintf("failed to get %s", kind), err)
+	}
+	manifest, err := resourceYAML(obj)
+	if err != nil {
+		return "", NewK8sError(ErrorTypeResourceInvalid, fmt.Sprintf("failed to render %s", kind), err)
+	}
+	return manifest, nil
+}
+
+// dryRunCreateResult reports a create that the API server accepted as a dry
+// run, with the manifest that would have been created
+func dryRunCreateResult(kind, name string, obj interface{}) (*mcp.CallToolResult, error) {
+	unstructuredObj, err := toUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(unstructuredObj.Object, "metadata", "creationTimestamp")
+	manifest, err := resourceYAML(unstructuredObj)
+	if err != nil {
+		return nil, err
+	}
+	return dryRunResult(fmt.Sprintf("Dry run: %s '%s' would be created (accepted by the API server; nothing was changed)", kind, name), manifest), nil
+}
+
+// dryRunResult reports a dry run with the manifest it concerns
+func dryRunResult(summary, manifest string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: summary + ":\n\n" + manifest},
+		},
+		StructuredContent: map[string]interface{}{"dry_run": true, "manifest": manifest},
+	}
+}
@@ -2,6 +2,8 @@ package k8s
 
 import (
 	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // K8sError represents a Kubernetes-specific error
@@ -134,4 +136,74 @@ func (e *K8sError) GetTroubleshootingHint() string {
 // FormatErrorWithHint formats the error with troubleshooting hints
 func (e *K8sError) FormatErrorWithHint() string {
 	return fmt.Sprintf("%s\n\n%s", e.Error(), e.GetTroubleshootingHint())
-}
\ No newline at end of file
+}
+
+// Category groups the error type the same way the Tailscale tools group
+// their error codes
+func (e *K8sError) Category() string {
+	switch e.Type {
+	case ErrorTypeKubeConfig:
+		return "config"
+	case ErrorTypePermission:
+		return "auth"
+	case ErrorTypeConnectivity:
+		return "unavailable"
+	case ErrorTypeResourceNotFound:
+		return "not_found"
+	case ErrorTypeResourceConflict:
+		return "conflict"
+	case ErrorTypeResourceInvalid:
+		return "input"
+	case ErrorTypeOperatorNotFound:
+		return "precondition"
+	default:
+		return "internal"
+	}
+}
+
+// Retryable reports whether the same call may succeed later unchanged
+func (e *K8sError) Retryable() bool {
+	return e.Type == ErrorTypeConnectivity
+}
+
+// toolError is the structured payload of a failed tool call, in the same
+// shape as the Tailscale tools' {"error": {...}}
+type toolError struct {
+	Code      string `json:"code"`
+	Category  string `json:"category"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+	Hint      string `json:"hint,omitempty"`
+}
+
+// errorResult reports a failed tool call with IsError set. A K8sError gives
+// the code (its type) and the troubleshooting hint; anything else is unknown.
+func errorResult(err error) *mcp.CallToolResult {
+	te := toolError{Code: string(ErrorTypeUnknown), Category: "internal", Message: err.Error()}
+	text := te.Message
+	if k8sErr, ok := err.(*K8sError); ok {
+		te.Code = string(k8sErr.Type)
+		te.Category = k8sErr.Category()
+		te.Retryable = k8sErr.Retryable()
+		te.Hint = k8sErr.GetTroubleshootingHint()
+		text = k8sErr.FormatErrorWithHint()
+	}
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+		StructuredContent: map[string]interface{}{"error": te},
+	}
+}
+
+// invalidParamsResult reports arguments that couldn't be parsed or validated
+func invalidParamsResult(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: message},
+		},
+		StructuredContent: map[string]interface{}{"error": toolError{Code: "invalid_params", Category: "input", Message: message}},
+	}
+}
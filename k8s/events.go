@@ -0,0 +1,169 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultEventNamespaces are the namespaces the operator and its proxies usually run in
+var DefaultEventNamespaces = []string{TailscaleSystemNamespace, "tailscale-system"}
+
+// ObjectEvents groups the warning events reported for one object
+type ObjectEvents struct {
+	Namespace string
+	Kind      string
+	Name      string
+	Events    []corev1.Event
+	LastSeen  time.Time
+}
+
+// RecentWarningEvents returns Warning events newer than since in the given
+// namespaces, grouped by the object they are about, most recent first.
+// Namespaces that don't exist are skipped.
+func (c *Client) RecentWarningEvents(ctx context.Context, namespaces []string, since time.Duration) ([]ObjectEvents, error) {
+	cutoff := time.Now().Add(-since)
+	groups := map[string]*ObjectEvents{}
+
+	for _, ns := range namespaces {
+		list, err := c.clientset.CoreV1().Events(ns).List(ctx, metav1.ListOptions{
+			FieldSelector: "type=" + corev1.EventTypeWarning,
+		})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, NewConnectivityError(fmt.Sprintf("failed to list events in namespace %s", ns), err)
+		}
+
+		for _, event := range list.Items {
+			seen := eventTime(event)
+			if seen.Before(cutoff) {
+				continue
+			}
+
+			obj := event.InvolvedObject
+			key := obj.Namespace + "/" + obj.Kind + "/" + obj.Name
+			group, ok := groups[key]
+			if !ok {
+				group = &ObjectEvents{Namespace: obj.Namespace, Kind: obj.Kind, Name: obj.Name}
+				groups[key] = group
+			}
+			group.Events = append(group.Events, event)
+			if seen.After(group.LastSeen) {
+				group.LastSeen = seen
+			}
+		}
+	}
+
+	result := make([]ObjectEvents, 0, len(groups))
+	for _, group := range groups {
+		sort.Slice(group.Events, func(i, j int) bool {
+			return eventTime(group.Events[i]).After(eventTime(group.Events[j]))
+		})
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastSeen.After(result[j].LastSeen)
+	})
+
+	return result, nil
+}
+
+// eventTime returns when an event was last observed, falling back through the
+// fields set by the different event APIs
+func eventTime(event corev1.Event) time.Time {
+	if event.Series != nil && !event.Series.LastObservedTime.IsZero() {
+		return event.Series.LastObservedTime.Time
+	}
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// eventCount returns how many times an event was observed
+func eventCount(event corev1.Event) int32 {
+	count := event.Count
+	if event.Series != nil && event.Series.Count > count {
+		count = event.Series.Count
+	}
+	return count
+}
+
+// ObjectEventsInfo is the structured form of ObjectEvents
+type ObjectEventsInfo struct {
+	Namespace string      `json:"namespace"`
+	Kind      string      `json:"kind"`
+	Name      string      `json:"name"`
+	LastSeen  time.Time   `json:"last_seen"`
+	Events    []EventInfo `json:"events"`
+}
+
+// EventInfo is one Warning event about an object
+type EventInfo struct {
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// ObjectEventsInfos converts grouped events to their structured form
+func ObjectEventsInfos(groups []ObjectEvents) []ObjectEventsInfo {
+	infos := make([]ObjectEventsInfo, 0, len(groups))
+	for _, g := range groups {
+		info := ObjectEventsInfo{Namespace: g.Namespace, Kind: g.Kind, Name: g.Name, LastSeen: g.LastSeen, Events: []EventInfo{}}
+		for _, event := range g.Events {
+			info.Events = append(info.Events, EventInfo{
+				Reason:   event.Reason,
+				Message:  strings.TrimSpace(event.Message),
+				Count:    eventCount(event),
+				LastSeen: eventTime(event),
+			})
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// FormatObjectEvents renders grouped events as a readable report
+func FormatObjectEvents(groups []ObjectEvents, namespaces []string, since time.Duration) string {
+	var result strings.Builder
+
+	if len(groups) == 0 {
+		result.WriteString(fmt.Sprintf("✓ No Warning events in %s in the last %s\n", strings.Join(namespaces, ", "), since))
+		return result.String()
+	}
+
+	total := 0
+	for _, g := range groups {
+		total += len(g.Events)
+	}
+	result.WriteString(fmt.Sprintf("Warning events in %s in the last %s: %d across %d object(s)\n\n",
+		strings.Join(namespaces, ", "), since, total, len(groups)))
+
+	for _, g := range groups {
+		result.WriteString(fmt.Sprintf("⚠ %s %s/%s\n", g.Kind, g.Namespace, g.Name))
+		for _, event := range g.Events {
+			count := eventCount(event)
+			age := time.Since(eventTime(event)).Round(time.Second)
+			line := fmt.Sprintf("  %s ago  %s: %s", age, event.Reason, strings.TrimSpace(event.Message))
+			if count > 1 {
+				line += fmt.Sprintf(" (x%d)", count)
+			}
+			result.WriteString(line + "\n")
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
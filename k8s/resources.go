@@ -23,9 +23,9 @@ type ProxyClass struct {
 }
 
 type ProxyClassSpec struct {
-	ProxyImage     string            `json:"proxyImage,omitempty"`
-	StatefulSet    *StatefulSetSpec  `json:"statefulSet,omitempty"`
-	TailscaleConfig map[string]string `json:"tailscaleConfig,omitempty"`
+	StatefulSet *StatefulSetSpec `json:"statefulSet,omitempty"`
+	Metrics     *MetricsSpec     `json:"metrics,omitempty"`
+	Tailscale   *TailscaleConfig `json:"tailscale,omitempty"`
 }
 
 type StatefulSetSpec struct {
@@ -35,14 +35,44 @@ type StatefulSetSpec struct {
 }
 
 type PodSpec struct {
-	Labels        map[string]string `json:"labels,omitempty"`
-	Annotations   map[string]string `json:"annotations,omitempty"`
-	TailscaleContainer *TailscaleContainer `json:"tailscaleContainer,omitempty"`
+	Labels             map[string]string          `json:"labels,omitempty"`
+	Annotations        map[string]string          `json:"annotations,omitempty"`
+	Affinity           *corev1.Affinity           `json:"affinity,omitempty"`
+	NodeSelector       map[string]string          `json:"nodeSelector,omitempty"`
+	Tolerations        []corev1.Toleration        `json:"tolerations,omitempty"`
+	SecurityContext    *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+	PriorityClassName  string                     `json:"priorityClassName,omitempty"`
+	TailscaleContainer *TailscaleContainer        `json:"tailscaleContainer,omitempty"`
 }
 
 type TailscaleContainer struct {
-	Env       []corev1.EnvVar           `json:"env,omitempty"`
-	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	Image           string                       `json:"image,omitempty"`
+	ImagePullPolicy corev1.PullPolicy            `json:"imagePullPolicy,omitempty"`
+	Env             []corev1.EnvVar              `json:"env,omitempty"`
+	Resources       *corev1.ResourceRequirements `json:"resources,omitempty"`
+	SecurityContext *corev1.SecurityContext      `json:"securityContext,omitempty"`
+	Debug           *DebugSpec                   `json:"debug,omitempty"`
+}
+
+// DebugSpec enables the proxy's debug endpoints (pprof and friends) on port
+// 9001 of the tailscale container
+type DebugSpec struct {
+	Enable bool `json:"enable"`
+}
+
+// MetricsSpec enables the proxy's Prometheus metrics on port 9002, and a
+// ServiceMonitor for them when the Prometheus operator is installed
+type MetricsSpec struct {
+	Enable         bool                `json:"enable"`
+	ServiceMonitor *ServiceMonitorSpec `json:"serviceMonitor,omitempty"`
+}
+
+type ServiceMonitorSpec struct {
+	Enable bool `json:"enable"`
+}
+
+type TailscaleConfig struct {
+	AcceptRoutes bool `json:"acceptRoutes,omitempty"`
 }
 
 type ProxyClassStatus struct {
@@ -70,6 +100,14 @@ type ProxyGroupStatus struct {
 	Conditions     []metav1.Condition `json:"conditions,omitempty"`
 	Replicas       int32              `json:"replicas"`
 	ReadyReplicas  int32              `json:"readyReplicas"`
+	Devices        []TailnetDevice    `json:"devices,omitempty"`
+}
+
+// TailnetDevice is a proxy's device in the tailnet, as the operator reports
+// it in a ProxyGroup's status
+type TailnetDevice struct {
+	Hostname   string   `json:"hostname"`
+	TailnetIPs []string `json:"tailnetIPs,omitempty"`
 }
 
 // Connector represents a Tailscale Connector resource
@@ -96,7 +134,11 @@ type SubnetRouterSpec struct {
 type ConnectorStatus struct {
 	Conditions      []metav1.Condition `json:"conditions,omitempty"`
 	Hostname        string             `json:"hostname,omitempty"`
-	TailscaleIPs    []string           `json:"tailscaleIPs,omitempty"`
+	TailnetIPs      []string           `json:"tailnetIPs,omitempty"`
+	// SubnetRoutes is the comma-separated list of routes the operator
+	// configured the Connector to advertise
+	SubnetRoutes string `json:"subnetRoutes,omitempty"`
+	IsExitNode   bool   `json:"isExitNode,omitempty"`
 }
 
 // DNSConfig represents a Tailscale DNSConfig resource
@@ -158,6 +200,8 @@ var (
 type ResourceManager struct {
 	client       *Client
 	dynamicClient dynamic.Interface
+	// DryRun sends creates and deletes as server-side dry runs
+	DryRun bool
 }
 
 // NewResourceManager creates a new resource manager
@@ -183,7 +227,7 @@ func (rm *ResourceManager) CreateProxyClass(ctx context.Context, proxyClass *Pro
 		return NewK8sError(ErrorTypeResourceInvalid, "failed to convert ProxyClass to unstructured", err)
 	}
 
-	_, err = rm.dynamicClient.Resource(ProxyClassGVR).Create(ctx, unstructuredObj, metav1.CreateOptions{})
+	_, err = rm.dynamicClient.Resource(ProxyClassGVR).Create(ctx, unstructuredObj, rm.createOptions())
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			return NewResourceConflictError("ProxyClass", proxyClass.Metadata.Name, err)
@@ -217,9 +261,65 @@ func (rm *ResourceManager) ListProxyClasses(ctx context.Context, namespace strin
 	return proxyClasses, nil
 }
 
+// GetProxyClass gets a ProxyClass resource. ProxyClasses are cluster-scoped.
+func (rm *ResourceManager) GetProxyClass(ctx context.Context, name string) (*ProxyClass, error) {
+	unstructuredObj, err := rm.dynamicClient.Resource(ProxyClassGVR).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, NewResourceNotFoundError("ProxyClass", name, err)
+		}
+		return nil, NewConnectivityError("failed to get ProxyClass", err)
+	}
+
+	var proxyClass ProxyClass
+	if err := fromUnstructured(unstructuredObj, &proxyClass); err != nil {
+		return nil, NewK8sError(ErrorTypeResourceInvalid, "failed to parse ProxyClass", err)
+	}
+
+	return &proxyClass, nil
+}
+
+// ProxyClassUpdate is a change to a ProxyClass's proxy pods. Labels,
+// annotations and resources are merged into the existing ones, with a nil
+// value removing a key; an empty image leaves the image as it is.
+type ProxyClassUpdate struct {
+	Labels      map[string]*string
+	Annotations map[string]*string
+	Image       string
+	// Resources holds "requests" and "limits", each mapping a resource
+	// name (cpu, memory) to a quantity
+	Resources map[string]map[string]*string
+}
+
+// PlanProxyClassUpdate prepares an update of a ProxyClass's pod labels,
+// annotations, proxy image and resources
+func (rm *ResourceManager) PlanProxyClassUpdate(ctx context.Context, name string, update ProxyClassUpdate) (*UpdatePlan, error) {
+	pod := []string{"spec", "statefulSet", "pod"}
+	container := []string{"spec", "statefulSet", "pod", "tailscaleContainer"}
+	return rm.PlanUpdate(ctx, ProxyClassGVR, "ProxyClass", name, func(obj *unstructured.Unstructured) error {
+		if err := mergeStringMap(obj, update.Labels, append(pod, "labels")...); err != nil {
+			return err
+		}
+		if err := mergeStringMap(obj, update.Annotations, append(pod, "annotations")...); err != nil {
+			return err
+		}
+		if update.Image != "" {
+			if err := unstructured.SetNestedField(obj.Object, update.Image, append(container, "image")...); err != nil {
+				return err
+			}
+		}
+		for kind, quantities := range update.Resources {
+			if err := mergeStringMap(obj, quantities, append(container, "resources", kind)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // DeleteProxyClass deletes a ProxyClass resource
 func (rm *ResourceManager) DeleteProxyClass(ctx context.Context, namespace, name string) error {
-	err := rm.dynamicClient.Resource(ProxyClassGVR).Delete(ctx, name, metav1.DeleteOptions{})
+	err := rm.dynamicClient.Resource(ProxyClassGVR).Delete(ctx, name, rm.deleteOptions())
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return NewResourceNotFoundError("ProxyClass", name, err)
@@ -240,7 +340,7 @@ func (rm *ResourceManager) CreateProxyGroup(ctx context.Context, proxyGroup *Pro
 		return NewK8sError(ErrorTypeResourceInvalid, "failed to convert ProxyGroup to unstructured", err)
 	}
 
-	_, err = rm.dynamicClient.Resource(ProxyGroupGVR).Create(ctx, unstructuredObj, metav1.CreateOptions{})
+	_, err = rm.dynamicClient.Resource(ProxyGroupGVR).Create(ctx, unstructuredObj, rm.createOptions())
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			return NewResourceConflictError("ProxyGroup", proxyGroup.Metadata.Name, err)
@@ -251,8 +351,28 @@ func (rm *ResourceManager) CreateProxyGroup(ctx context.Context, proxyGroup *Pro
 	return nil
 }
 
-// GetProxyGroupStatus gets the status of a ProxyGroup resource
-func (rm *ResourceManager) GetProxyGroupStatus(ctx context.Context, namespace, name string) (*ProxyGroupStatus, error) {
+// ListProxyGroups lists all ProxyGroup resources. ProxyGroups are
+// cluster-scoped.
+func (rm *ResourceManager) ListProxyGroups(ctx context.Context) ([]ProxyGroup, error) {
+	unstructuredList, err := rm.dynamicClient.Resource(ProxyGroupGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, NewConnectivityError("failed to list ProxyGroups", err)
+	}
+
+	var proxyGroups []ProxyGroup
+	for _, item := range unstructuredList.Items {
+		var pg ProxyGroup
+		if err := fromUnstructured(&item, &pg); err != nil {
+			continue // Skip invalid items
+		}
+		proxyGroups = append(proxyGroups, pg)
+	}
+
+	return proxyGroups, nil
+}
+
+// GetProxyGroup gets a ProxyGroup resource
+func (rm *ResourceManager) GetProxyGroup(ctx context.Context, name string) (*ProxyGroup, error) {
 	unstructuredObj, err := rm.dynamicClient.Resource(ProxyGroupGVR).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -266,27 +386,61 @@ func (rm *ResourceManager) GetProxyGroupStatus(ctx context.Context, namespace, n
 		return nil, NewK8sError(ErrorTypeResourceInvalid, "failed to parse ProxyGroup", err)
 	}
 
-	return proxyGroup.Status, nil
+	return &proxyGroup, nil
 }
 
-// ScaleProxyGroup scales a ProxyGroup resource
-func (rm *ResourceManager) ScaleProxyGroup(ctx context.Context, namespace, name string, replicas int32) error {
-	unstructuredObj, err := rm.dynamicClient.Resource(ProxyGroupGVR).Get(ctx, name, metav1.GetOptions{})
+// GetProxyGroupStatus gets the status of a ProxyGroup resource
+func (rm *ResourceManager) GetProxyGroupStatus(ctx context.Context, namespace, name string) (*ProxyGroupStatus, error) {
+	proxyGroup, err := rm.GetProxyGroup(ctx, name)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			return NewResourceNotFoundError("ProxyGroup", name, err)
-		}
-		return NewConnectivityError("failed to get ProxyGroup", err)
+		return nil, err
 	}
+	return proxyGroup.Status, nil
+}
 
-	// Update replicas in spec
-	if err := unstructured.SetNestedField(unstructuredObj.Object, int64(replicas), "spec", "replicas"); err != nil {
-		return NewK8sError(ErrorTypeResourceInvalid, "failed to set replicas", err)
-	}
+// PlanProxyGroupScale prepares an update of a ProxyGroup's replica count
+func (rm *ResourceManager) PlanProxyGroupScale(ctx context.Context, namespace, name string, replicas int32) (*UpdatePlan, error) {
+	return rm.PlanUpdate(ctx, ProxyGroupGVR, "ProxyGroup", name, func(obj *unstructured.Unstructured) error {
+		return unstructured.SetNestedField(obj.Object, int64(replicas), "spec", "replicas")
+	})
+}
+
+// ProxyGroupUpdate is a change to a ProxyGroup. A nil field is left as it
+// is; an empty one is removed, so the operator's default applies (tag:k8s,
+// no ProxyClass, the group's name as hostname prefix).
+type ProxyGroupUpdate struct {
+	Tags           []string
+	ProxyClass     *string
+	HostnamePrefix *string
+}
+
+// PlanProxyGroupUpdate prepares an update of a ProxyGroup's tags, ProxyClass
+// and hostname prefix
+func (rm *ResourceManager) PlanProxyGroupUpdate(ctx context.Context, name string, update ProxyGroupUpdate) (*UpdatePlan, error) {
+	return rm.PlanUpdate(ctx, ProxyGroupGVR, "ProxyGroup", name, func(obj *unstructured.Unstructured) error {
+		if update.Tags != nil {
+			if len(update.Tags) == 0 {
+				unstructured.RemoveNestedField(obj.Object, "spec", "tags")
+			} else if err := unstructured.SetNestedStringSlice(obj.Object, update.Tags, "spec", "tags"); err != nil {
+				return err
+			}
+		}
+		if err := setOptionalString(obj, update.ProxyClass, "spec", "proxyClass"); err != nil {
+			return err
+		}
+		return setOptionalString(obj, update.HostnamePrefix, "spec", "hostnamePrefix")
+	})
+}
 
-	_, err = rm.dynamicClient.Resource(ProxyGroupGVR).Update(ctx, unstructuredObj, metav1.UpdateOptions{})
+// DeleteProxyGroup deletes a ProxyGroup resource, and with it its proxies
+// and their devices in the tailnet
+func (rm *ResourceManager) DeleteProxyGroup(ctx context.Context, name string) error {
+	err := rm.dynamicClient.Resource(ProxyGroupGVR).Delete(ctx, name, rm.deleteOptions())
 	if err != nil {
-		return NewK8sError(ErrorTypeUnknown, "failed to scale ProxyGroup", err)
+		if errors.IsNotFound(err) {
+			return NewResourceNotFoundError("ProxyGroup", name, err)
+		}
+		return NewK8sError(ErrorTypeUnknown, "failed to delete ProxyGroup", err)
 	}
 
 	return nil
@@ -302,7 +456,7 @@ func (rm *ResourceManager) CreateConnector(ctx context.Context, connector *Conne
 		return NewK8sError(ErrorTypeResourceInvalid, "failed to convert Connector to unstructured", err)
 	}
 
-	_, err = rm.dynamicClient.Resource(ConnectorGVR).Create(ctx, unstructuredObj, metav1.CreateOptions{})
+	_, err = rm.dynamicClient.Resource(ConnectorGVR).Create(ctx, unstructuredObj, rm.createOptions())
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			return NewResourceConflictError("Connector", connector.Metadata.Name, err)
@@ -313,6 +467,103 @@ func (rm *ResourceManager) CreateConnector(ctx context.Context, connector *Conne
 	return nil
 }
 
+// ListConnectors lists all Connector resources. Connectors are
+// cluster-scoped.
+func (rm *ResourceManager) ListConnectors(ctx context.Context) ([]Connector, error) {
+	unstructuredList, err := rm.dynamicClient.Resource(ConnectorGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, NewConnectivityError("failed to list Connectors", err)
+	}
+
+	var connectors []Connector
+	for _, item := range unstructuredList.Items {
+		var connector Connector
+		if err := fromUnstructured(&item, &connector); err != nil {
+			continue // Skip invalid items
+		}
+		connectors = append(connectors, connector)
+	}
+
+	return connectors, nil
+}
+
+// GetConnector gets a Connector resource
+func (rm *ResourceManager) GetConnector(ctx context.Context, name string) (*Connector, error) {
+	unstructuredObj, err := rm.dynamicClient.Resource(ConnectorGVR).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, NewResourceNotFoundError("Connector", name, err)
+		}
+		return nil, NewConnectivityError("failed to get Connector", err)
+	}
+
+	var connector Connector
+	if err := fromUnstructured(unstructuredObj, &connector); err != nil {
+		return nil, NewK8sError(ErrorTypeResourceInvalid, "failed to parse Connector", err)
+	}
+
+	return &connector, nil
+}
+
+// ConnectorUpdate is a change to a Connector. A nil field is left as it is;
+// an empty hostname, ProxyClass, tag list or route list is removed.
+type ConnectorUpdate struct {
+	Hostname     *string
+	ProxyClass   *string
+	Tags         []string
+	SubnetRoutes []string
+	ExitNode     *bool
+}
+
+// PlanConnectorUpdate prepares an update of a Connector's hostname,
+// ProxyClass, tags, subnet routes and exit node role
+func (rm *ResourceManager) PlanConnectorUpdate(ctx context.Context, name string, update ConnectorUpdate) (*UpdatePlan, error) {
+	return rm.PlanUpdate(ctx, ConnectorGVR, "Connector", name, func(obj *unstructured.Unstructured) error {
+		if err := setOptionalString(obj, update.Hostname, "spec", "hostname"); err != nil {
+			return err
+		}
+		if err := setOptionalString(obj, update.ProxyClass, "spec", "proxyClass"); err != nil {
+			return err
+		}
+		if update.Tags != nil {
+			if len(update.Tags) == 0 {
+				unstructured.RemoveNestedField(obj.Object, "spec", "tags")
+			} else if err := unstructured.SetNestedStringSlice(obj.Object, update.Tags, "spec", "tags"); err != nil {
+				return err
+			}
+		}
+		if update.SubnetRoutes != nil {
+			if len(update.SubnetRoutes) == 0 {
+				unstructured.RemoveNestedField(obj.Object, "spec", "subnetRouter")
+			} else if err := unstructured.SetNestedStringSlice(obj.Object, update.SubnetRoutes, "spec", "subnetRouter", "advertiseRoutes"); err != nil {
+				return err
+			}
+		}
+		if update.ExitNode != nil {
+			if !*update.ExitNode {
+				unstructured.RemoveNestedField(obj.Object, "spec", "exitNode")
+			} else if err := unstructured.SetNestedField(obj.Object, true, "spec", "exitNode"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteConnector deletes a Connector resource, and with it its proxy and
+// its device in the tailnet
+func (rm *ResourceManager) DeleteConnector(ctx context.Context, name string) error {
+	err := rm.dynamicClient.Resource(ConnectorGVR).Delete(ctx, name, rm.deleteOptions())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return NewResourceNotFoundError("Connector", name, err)
+		}
+		return NewK8sError(ErrorTypeUnknown, "failed to delete Connector", err)
+	}
+
+	return nil
+}
+
 // CreateDNSConfig creates a DNSConfig resource
 func (rm *ResourceManager) CreateDNSConfig(ctx context.Context, dnsConfig *DNSConfig) error {
 	dnsConfig.APIVersion = "tailscale.com/v1alpha1"
@@ -323,7 +574,7 @@ func (rm *ResourceManager) CreateDNSConfig(ctx context.Context, dnsConfig *DNSCo
 		return NewK8sError(ErrorTypeResourceInvalid, "failed to convert DNSConfig to unstructured", err)
 	}
 
-	_, err = rm.dynamicClient.Resource(DNSConfigGVR).Create(ctx, unstructuredObj, metav1.CreateOptions{})
+	_, err = rm.dynamicClient.Resource(DNSConfigGVR).Create(ctx, unstructuredObj, rm.createOptions())
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
 			return NewResourceConflictError("DNSConfig", dnsConfig.Metadata.Name, err)
@@ -336,9 +587,25 @@ func (rm *ResourceManager) CreateDNSConfig(ctx context.Context, dnsConfig *DNSCo
 
 // CreateTailscaleIngress creates a Tailscale ingress using a standard Kubernetes Ingress with Tailscale annotations
 func (rm *ResourceManager) CreateTailscaleIngress(ctx context.Context, namespace, name, hostname, serviceName string, servicePort int32) error {
+	ingress := TailscaleIngress(namespace, name, hostname, serviceName, servicePort)
+
+	_, err := rm.client.clientset.NetworkingV1().Ingresses(namespace).Create(ctx, ingress, rm.createOptions())
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return NewResourceConflictError("Ingress", name, err)
+		}
+		return NewK8sError(ErrorTypeResourceInvalid, "failed to create Tailscale ingress", err)
+	}
+
+	return nil
+}
+
+// TailscaleIngress builds the Ingress that CreateTailscaleIngress creates
+func TailscaleIngress(namespace, name, hostname, serviceName string, servicePort int32) *networkingv1.Ingress {
 	pathType := networkingv1.PathTypePrefix
 
-	ingress := &networkingv1.Ingress{
+	return &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
@@ -373,21 +640,27 @@ func (rm *ResourceManager) CreateTailscaleIngress(ctx context.Context, namespace
 			},
 		},
 	}
+}
 
-	_, err := rm.client.clientset.NetworkingV1().Ingresses(namespace).Create(ctx, ingress, metav1.CreateOptions{})
+// CreateEgressService creates an egress service for Tailscale
+func (rm *ResourceManager) CreateEgressService(ctx context.Context, namespace, name, externalHostname string, port int32) error {
+	service := EgressService(namespace, name, externalHostname, port)
+
+	_, err := rm.client.clientset.CoreV1().Services(namespace).Create(ctx, service, rm.createOptions())
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
-			return NewResourceConflictError("Ingress", name, err)
+			return NewResourceConflictError("Service", name, err)
 		}
-		return NewK8sError(ErrorTypeResourceInvalid, "failed to create Tailscale ingress", err)
+		return NewK8sError(ErrorTypeResourceInvalid, "failed to create egress service", err)
 	}
 
 	return nil
 }
 
-// CreateEgressService creates an egress service for Tailscale
-func (rm *ResourceManager) CreateEgressService(ctx context.Context, namespace, name, externalHostname string, port int32) error {
-	service := &corev1.Service{
+// EgressService builds the Service that CreateEgressService creates
+func EgressService(namespace, name, externalHostname string, port int32) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
@@ -406,16 +679,6 @@ func (rm *ResourceManager) CreateEgressService(ctx context.Context, namespace, n
 			},
 		},
 	}
-
-	_, err := rm.client.clientset.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
-	if err != nil {
-		if errors.IsAlreadyExists(err) {
-			return NewResourceConflictError("Service", name, err)
-		}
-		return NewK8sError(ErrorTypeResourceInvalid, "failed to create egress service", err)
-	}
-
-	return nil
 }
 
 // Helper functions for converting between structured and unstructured objects
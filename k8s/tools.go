@@ -4,19 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 )
 
-// RegisterK8sOperatorTools registers all Kubernetes operator tools with the MCP server
-func RegisterK8sOperatorTools(server *mcp.Server) error {
+// RegisterK8sOperatorTools registers all Kubernetes operator tools with the MCP server.
+// api is used to check Connectors' devices in the tailnet and may be nil.
+func RegisterK8sOperatorTools(server *mcp.Server, api *tailscale.APIClient) error {
 	// ACL preparation tool
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_prepare_acl",
 			Description: "Prepare Tailscale ACL configuration for Kubernetes operator (shows required configuration)",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type:       "object",
 				Properties: map[string]*jsonschema.Schema{},
@@ -35,6 +44,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_operator_status",
 			Description: "Get the status of the Tailscale Kubernetes operator",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type:       "object",
 				Properties: map[string]*jsonschema.Schema{},
@@ -43,18 +53,72 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 		mcp.ToolHandler(handleOperatorStatus),
 	)
 
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_recent_events",
+			Description: "List recent Warning events in the Tailscale namespaces, grouped by object. The quickest signal when proxies fail to come up.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"minutes": {Type: "integer", Description: "How far back to look, in minutes (default: 30)"},
+					"namespaces": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "Namespaces to check (default: tailscale, tailscale-system)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(handleRecentEvents),
+	)
+
 	// ProxyClass management
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_proxy_class_create",
-			Description: "Create a ProxyClass resource for customizing proxy configurations",
+			Description: "Create a ProxyClass resource for customizing proxy configurations: the proxy pods' labels, annotations, scheduling and security context, and the proxy container's image, resources, metrics and debug endpoints",
+			Annotations: updateTool(false),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
-					"name":        {Type: "string", Description: "Name of the ProxyClass"},
-					"namespace":   {Type: "string", Description: "Namespace for the ProxyClass"},
-					"labels":      {Type: "object", Description: "Labels to apply to proxy pods"},
-					"annotations": {Type: "object", Description: "Annotations to apply to proxy pods"},
+					"name":              {Type: "string", Description: "Name of the ProxyClass"},
+					"namespace":         {Type: "string", Description: "Namespace for the ProxyClass"},
+					"labels":            {Type: "object", Description: "Labels to apply to proxy pods"},
+					"annotations":       {Type: "object", Description: "Annotations to apply to proxy pods"},
+					"image":             {Type: "string", Description: "Proxy container image (e.g., tailscale/tailscale:v1.76.1; default: the operator's)"},
+					"image_pull_policy": {Type: "string", Enum: []any{"Always", "IfNotPresent", "Never"}, Description: "Pull policy for the proxy image"},
+					"resources": {
+						Type:        "object",
+						Description: `Proxy container resources, e.g. {"requests": {"cpu": "100m", "memory": "128Mi"}, "limits": {"memory": "256Mi"}}`,
+						Properties: map[string]*jsonschema.Schema{
+							"requests": {Type: "object", Description: "Resource requests by name (cpu, memory)"},
+							"limits":   {Type: "object", Description: "Resource limits by name (cpu, memory)"},
+						},
+					},
+					"node_selector": {Type: "object", Description: "Node labels the proxy pods must be scheduled on (e.g., {\"kubernetes.io/os\": \"linux\"})"},
+					"tolerations": {
+						Type:        "array",
+						Description: "Tolerations for the proxy pods, as in a pod spec",
+						Items: &jsonschema.Schema{
+							Type: "object",
+							Properties: map[string]*jsonschema.Schema{
+								"key":               {Type: "string", Description: "Taint key to tolerate (empty with operator Exists matches all taints)"},
+								"operator":          {Type: "string", Enum: []any{"Equal", "Exists"}, Description: "How the value is matched (default: Equal)"},
+								"value":             {Type: "string", Description: "Taint value to match, with operator Equal"},
+								"effect":            {Type: "string", Enum: []any{"NoSchedule", "PreferNoSchedule", "NoExecute"}, Description: "Taint effect to tolerate (empty matches all)"},
+								"tolerationSeconds": {Type: "integer", Description: "How long a NoExecute taint is tolerated before eviction"},
+							},
+						},
+					},
+					"affinity":             {Type: "object", Description: "Affinity for the proxy pods, as in a pod spec (nodeAffinity, podAffinity, podAntiAffinity)"},
+					"pod_security_context": {Type: "object", Description: "Security context of the proxy pods, as in a pod spec (e.g., {\"runAsNonRoot\": true, \"fsGroup\": 1000})"},
+					"security_context":     {Type: "object", Description: "Security context of the proxy container, as in a container spec (e.g., {\"privileged\": false, \"capabilities\": {\"add\": [\"NET_ADMIN\"]}})"},
+					"priority_class_name":  {Type: "string", Description: "PriorityClass of the proxy pods"},
+					"metrics":              {Type: "boolean", Description: "Expose the proxies' Prometheus metrics on port 9002 (default: false)"},
+					"service_monitor":      {Type: "boolean", Description: "Create a Prometheus ServiceMonitor for the metrics; requires metrics and the Prometheus operator (default: false)"},
+					"debug":                {Type: "boolean", Description: "Expose the proxies' debug endpoints (pprof, /debug/metrics) on port 9001 (default: false)"},
+					"dry_run":              dryRunProperty,
 				},
 				Required: []string{"name", "namespace"},
 			},
@@ -66,6 +130,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_proxy_class_list",
 			Description: "List ProxyClass resources in a namespace",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -76,15 +141,61 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 		mcp.ToolHandler(handleProxyClassList),
 	)
 
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_proxy_class_get",
+			Description: "Get a ProxyClass: its spec as YAML and its status conditions",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {Type: "string", Description: "Name of the ProxyClass (ProxyClasses are cluster-scoped)"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(handleProxyClassGet),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_proxy_class_update",
+			Description: "Update a ProxyClass's proxy pods: labels and annotations are merged into the existing ones (a null value removes a key), resources are merged per request and limit, and image replaces the proxy image. Returns a YAML diff of the change; image changes, which restart every proxy using the class, are only applied with confirm: true",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name":        {Type: "string", Description: "Name of the ProxyClass"},
+					"labels":      {Type: "object", Description: "Labels to set on proxy pods (null removes one)"},
+					"annotations": {Type: "object", Description: "Annotations to set on proxy pods (null removes one)"},
+					"image":       {Type: "string", Description: "Proxy container image (e.g., tailscale/tailscale:v1.76.1)"},
+					"resources": {
+						Type:        "object",
+						Description: `Proxy container resources, e.g. {"requests": {"cpu": "100m", "memory": "128Mi"}, "limits": {"memory": null}} (null removes one)`,
+						Properties: map[string]*jsonschema.Schema{
+							"requests": {Type: "object", Description: "Resource requests by name (cpu, memory)"},
+							"limits":   {Type: "object", Description: "Resource limits by name (cpu, memory)"},
+						},
+					},
+					"confirm": {Type: "boolean", Description: "Apply an image change (default: false, only shows the diff)"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(handleProxyClassUpdate),
+	)
+
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_proxy_class_delete",
 			Description: "Delete a ProxyClass resource",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
 					"name":      {Type: "string", Description: "Name of the ProxyClass to delete"},
 					"namespace": {Type: "string", Description: "Namespace of the ProxyClass"},
+					"dry_run":   dryRunProperty,
 				},
 				Required: []string{"name", "namespace"},
 			},
@@ -97,6 +208,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_proxy_group_create",
 			Description: "Create a ProxyGroup for high availability configurations",
+			Annotations: updateTool(false),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -110,6 +222,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 						Items:       &jsonschema.Schema{Type: "string"},
 						Description: "Tags to apply to the proxy devices",
 					},
+					"dry_run": dryRunProperty,
 				},
 				Required: []string{"name", "namespace", "type"},
 			},
@@ -121,6 +234,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_proxy_group_status",
 			Description: "Get the status of a ProxyGroup",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -136,13 +250,15 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_proxy_group_scale",
-			Description: "Scale a ProxyGroup to a different number of replicas",
+			Description: "Scale a ProxyGroup to a different number of replicas. Returns a YAML diff of the change; replica changes are only applied with confirm: true",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
 					"name":      {Type: "string", Description: "Name of the ProxyGroup"},
 					"namespace": {Type: "string", Description: "Namespace of the ProxyGroup"},
 					"replicas":  {Type: "integer", Description: "New number of replicas"},
+					"confirm":   {Type: "boolean", Description: "Apply the change (default: false, only shows the diff)"},
 				},
 				Required: []string{"name", "namespace", "replicas"},
 			},
@@ -150,11 +266,79 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 		mcp.ToolHandler(handleProxyGroupScale),
 	)
 
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_proxy_group_list",
+			Description: "List ProxyGroup resources (ProxyGroups are cluster-scoped)",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(handleProxyGroupList),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_proxy_group_get",
+			Description: "Get a ProxyGroup: its spec as YAML, its status conditions, and the tailnet devices of its proxies",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {Type: "string", Description: "Name of the ProxyGroup (ProxyGroups are cluster-scoped)"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(handleProxyGroupGet),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_proxy_group_update",
+			Description: "Update a ProxyGroup's tags, ProxyClass or hostname prefix. Returns a YAML diff of the change; tag and ProxyClass changes, which re-tag or restart every proxy in the group, are only applied with confirm: true",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {Type: "string", Description: "Name of the ProxyGroup"},
+					"tags": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "Tags for the proxy devices, replacing the current ones (an empty list reverts to the operator's default, tag:k8s)",
+					},
+					"proxy_class":     {Type: "string", Description: "ProxyClass for the proxies (empty string removes it)"},
+					"hostname_prefix": {Type: "string", Description: "Prefix of the proxies' hostnames, which get -0, -1, ... appended (empty string reverts to the group's name)"},
+					"confirm":         {Type: "boolean", Description: "Apply a tag or ProxyClass change (default: false, only shows the diff)"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(handleProxyGroupUpdate),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_proxy_group_delete",
+			Description: "Delete a ProxyGroup; the operator removes its proxies and their devices from the tailnet",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name":    {Type: "string", Description: "Name of the ProxyGroup"},
+					"dry_run": dryRunProperty,
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(handleProxyGroupDelete),
+	)
+
 	// Ingress and Egress
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_ingress_create",
 			Description: "Create a Tailscale ingress to expose a cluster service to the tailnet",
+			Annotations: updateTool(false),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -163,6 +347,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 					"hostname":     {Type: "string", Description: "Hostname for the ingress"},
 					"service_name": {Type: "string", Description: "Name of the service to expose"},
 					"service_port": {Type: "integer", Description: "Port of the service to expose"},
+					"dry_run":      dryRunProperty,
 				},
 				Required: []string{"name", "namespace", "hostname", "service_name", "service_port"},
 			},
@@ -174,6 +359,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_egress_create",
 			Description: "Create an egress service to expose a tailnet service to the cluster",
+			Annotations: updateTool(false),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -181,6 +367,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 					"namespace":         {Type: "string", Description: "Namespace for the egress service"},
 					"external_hostname": {Type: "string", Description: "External hostname to connect to"},
 					"port":              {Type: "integer", Description: "Port to connect to"},
+					"dry_run":           dryRunProperty,
 				},
 				Required: []string{"name", "namespace", "external_hostname", "port"},
 			},
@@ -193,6 +380,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_connector_create",
 			Description: "Create a Connector for subnet routing or exit node functionality",
+			Annotations: updateTool(false),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -211,6 +399,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 						Items:       &jsonschema.Schema{Type: "string"},
 						Description: "Tags to apply to the Connector",
 					},
+					"dry_run": dryRunProperty,
 				},
 				Required: []string{"name", "namespace"},
 			},
@@ -218,10 +407,100 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 		mcp.ToolHandler(handleConnectorCreate),
 	)
 
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_connector_list",
+			Description: "List Connector resources (Connectors are cluster-scoped)",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(handleConnectorList),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_connector_get",
+			Description: "Get a Connector: its spec as YAML and its status conditions",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {Type: "string", Description: "Name of the Connector (Connectors are cluster-scoped)"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(handleConnectorGet),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_connector_status",
+			Description: "Show whether a Connector is ready, its hostname and Tailscale IPs, and, when the server has API access, whether each of its subnet and exit node routes is advertised and approved in the tailnet",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {Type: "string", Description: "Name of the Connector"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		connectorStatusHandler(api),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_connector_update",
+			Description: "Update a Connector's hostname, ProxyClass, tags, subnet routes or exit node role. Returns a YAML diff of the change; changes to tags, routes, the ProxyClass or the exit node role are only applied with confirm: true",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name":        {Type: "string", Description: "Name of the Connector"},
+					"hostname":    {Type: "string", Description: "Hostname of the Connector's device (empty string reverts to the default)"},
+					"proxy_class": {Type: "string", Description: "ProxyClass to use (empty string removes it)"},
+					"tags": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "Tags for the Connector's device, replacing the current ones (an empty list reverts to the operator's default, tag:k8s)",
+					},
+					"subnet_routes": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "Subnet routes to advertise, replacing the current ones (an empty list stops subnet routing)",
+					},
+					"exit_node": {Type: "boolean", Description: "Whether the Connector acts as an exit node"},
+					"confirm":   {Type: "boolean", Description: "Apply a tag, route, ProxyClass or exit node change (default: false, only shows the diff)"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(handleConnectorUpdate),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "mcp__tailscale__k8s_connector_delete",
+			Description: "Delete a Connector; the operator removes its proxy and its device from the tailnet, and its routes stop working",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name":    {Type: "string", Description: "Name of the Connector"},
+					"dry_run": dryRunProperty,
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(handleConnectorDelete),
+	)
+
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "mcp__tailscale__k8s_dns_config_create",
 			Description: "Create a DNSConfig for MagicDNS configuration",
+			Annotations: updateTool(false),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -233,6 +512,7 @@ func RegisterK8sOperatorTools(server *mcp.Server) error {
 						Items:       &jsonschema.Schema{Type: "string"},
 						Description: "List of nameserver IPs",
 					},
+					"dry_run": dryRunProperty,
 				},
 				Required: []string{"name", "namespace", "magic_dns"},
 			},
@@ -287,6 +567,9 @@ Copy this into your Tailscale ACL editor at https://login.tailscale.com/admin/ac
 
 === OAUTH CLIENT CONFIGURATION ===
 
+To create the client without the admin console, call create_oauth_client
+with preset "k8s-operator" (requires API access). Otherwise:
+
 When creating the OAuth client at https://login.tailscale.com/admin/settings/oauth
 
 1. Click "Generate OAuth client"
@@ -312,6 +595,15 @@ The OAuth client will look like:
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: instructions + sampleACL},
 		},
+		StructuredContent: map[string]interface{}{
+			"tag_owners": map[string][]string{
+				"tag:k8s-operator": {},
+				"tag:k8s":          {"tag:k8s-operator"},
+			},
+			"oauth_client_tags":     []string{"tag:k8s-operator"},
+			"oauth_scopes":          []string{"devices:write", "auth_keys:write"},
+			"optional_oauth_scopes": []string{"routes:write", "dns:write"},
+		},
 	}, nil
 }
 
@@ -320,26 +612,12 @@ The OAuth client will look like:
 func handleOperatorStatus(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	client, err := NewClient()
 	if err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
-		}
-		return nil, err
+		return errorResult(err), nil
 	}
 
 	status, err := client.GetOperatorStatus(ctx)
 	if err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
-		}
-		return nil, err
+		return errorResult(err), nil
 	}
 
 	statusJSON, err := json.MarshalIndent(status, "", "  ")
@@ -351,6 +629,48 @@ func handleOperatorStatus(ctx context.Context, req *mcp.CallToolRequest) (*mcp.C
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: fmt.Sprintf("Operator Status:\n%s", string(statusJSON))},
 		},
+		StructuredContent: json.RawMessage(statusJSON),
+	}, nil
+}
+
+func handleRecentEvents(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Minutes    int      `json:"minutes"`
+		Namespaces []string `json:"namespaces"`
+	}
+	if len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+			return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+		}
+	}
+
+	if params.Minutes <= 0 {
+		params.Minutes = 30
+	}
+	if len(params.Namespaces) == 0 {
+		params.Namespaces = DefaultEventNamespaces
+	}
+	since := time.Duration(params.Minutes) * time.Minute
+
+	client, err := NewClient()
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	groups, err := client.RecentWarningEvents(ctx, params.Namespaces, since)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: FormatObjectEvents(groups, params.Namespaces, since)},
+		},
+		StructuredContent: map[string]interface{}{
+			"namespaces": params.Namespaces,
+			"minutes":    params.Minutes,
+			"objects":    ObjectEventsInfos(groups),
+		},
 	}, nil
 }
 
@@ -358,27 +678,58 @@ func handleOperatorStatus(ctx context.Context, req *mcp.CallToolRequest) (*mcp.C
 
 func handleProxyClassCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		Name        string                 `json:"name"`
-		Namespace   string                 `json:"namespace"`
-		Labels      map[string]interface{} `json:"labels,omitempty"`
-		Annotations map[string]interface{} `json:"annotations,omitempty"`
+		Name               string                            `json:"name"`
+		Namespace          string                            `json:"namespace"`
+		Labels             map[string]interface{}            `json:"labels,omitempty"`
+		Annotations        map[string]interface{}            `json:"annotations,omitempty"`
+		Image              string                            `json:"image,omitempty"`
+		ImagePullPolicy    corev1.PullPolicy                 `json:"image_pull_policy,omitempty"`
+		Resources          map[string]map[string]interface{} `json:"resources,omitempty"`
+		NodeSelector       map[string]interface{}            `json:"node_selector,omitempty"`
+		Tolerations        []corev1.Toleration               `json:"tolerations,omitempty"`
+		Affinity           *corev1.Affinity                  `json:"affinity,omitempty"`
+		PodSecurityContext *corev1.PodSecurityContext        `json:"pod_security_context,omitempty"`
+		SecurityContext    *corev1.SecurityContext           `json:"security_context,omitempty"`
+		PriorityClassName  string                            `json:"priority_class_name,omitempty"`
+		Metrics            bool                              `json:"metrics,omitempty"`
+		ServiceMonitor     bool                              `json:"service_monitor,omitempty"`
+		Debug              bool                              `json:"debug,omitempty"`
+		DryRun             bool                              `json:"dry_run,omitempty"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-			},
-		}, nil
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+	if params.ServiceMonitor && !params.Metrics {
+		return invalidParamsResult("service_monitor requires metrics: true"), nil
 	}
 
-	client, err := NewClient()
-	if err != nil {
-		return nil, err
+	container := TailscaleContainer{
+		Image:           params.Image,
+		ImagePullPolicy: params.ImagePullPolicy,
+		SecurityContext: params.SecurityContext,
+	}
+	if len(params.Resources) > 0 {
+		resources, err := resourceRequirements(params.Resources)
+		if err != nil {
+			return invalidParamsResult(fmt.Sprintf("Invalid resources: %v", err)), nil
+		}
+		container.Resources = resources
+	}
+	if params.Debug {
+		container.Debug = &DebugSpec{Enable: true}
 	}
 
-	rm, err := NewResourceManager(client)
-	if err != nil {
-		return nil, err
+	pod := PodSpec{
+		Labels:            stringMap(params.Labels),
+		Annotations:       stringMap(params.Annotations),
+		Affinity:          params.Affinity,
+		NodeSelector:      stringMap(params.NodeSelector),
+		Tolerations:       params.Tolerations,
+		SecurityContext:   params.PodSecurityContext,
+		PriorityClassName: params.PriorityClassName,
+	}
+	if !reflect.DeepEqual(container, TailscaleContainer{}) {
+		pod.TailscaleContainer = &container
 	}
 
 	proxyClass := &ProxyClass{
@@ -388,48 +739,35 @@ func handleProxyClassCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp
 		},
 		Spec: ProxyClassSpec{},
 	}
-
-	// Add labels if provided
-	if params.Labels != nil {
-		labelsStr := make(map[string]string)
-		for k, v := range params.Labels {
-			labelsStr[k] = fmt.Sprintf("%v", v)
-		}
-		if proxyClass.Spec.StatefulSet == nil {
-			proxyClass.Spec.StatefulSet = &StatefulSetSpec{}
-		}
-		if proxyClass.Spec.StatefulSet.Pod == nil {
-			proxyClass.Spec.StatefulSet.Pod = &PodSpec{}
+	if !reflect.DeepEqual(pod, PodSpec{}) {
+		proxyClass.Spec.StatefulSet = &StatefulSetSpec{Pod: &pod}
+	}
+	if params.Metrics {
+		proxyClass.Spec.Metrics = &MetricsSpec{Enable: true}
+		if params.ServiceMonitor {
+			proxyClass.Spec.Metrics.ServiceMonitor = &ServiceMonitorSpec{Enable: true}
 		}
-		proxyClass.Spec.StatefulSet.Pod.Labels = labelsStr
 	}
 
-	// Add annotations if provided
-	if params.Annotations != nil {
-		annotationsStr := make(map[string]string)
-		for k, v := range params.Annotations {
-			annotationsStr[k] = fmt.Sprintf("%v", v)
-		}
-		if proxyClass.Spec.StatefulSet == nil {
-			proxyClass.Spec.StatefulSet = &StatefulSetSpec{}
-		}
-		if proxyClass.Spec.StatefulSet.Pod == nil {
-			proxyClass.Spec.StatefulSet.Pod = &PodSpec{}
-		}
-		proxyClass.Spec.StatefulSet.Pod.Annotations = annotationsStr
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
 	}
 
-	if err := rm.CreateProxyClass(ctx, proxyClass); err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
-		}
+	rm, err := NewResourceManager(client)
+	if err != nil {
 		return nil, err
 	}
 
+	rm.DryRun = params.DryRun
+	if err := rm.CreateProxyClass(ctx, proxyClass); err != nil {
+		return errorResult(err), nil
+	}
+
+	if params.DryRun {
+		return dryRunCreateResult("ProxyClass", proxyClass.Metadata.Name, proxyClass)
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: fmt.Sprintf("ProxyClass '%s' created successfully in namespace '%s'",
@@ -443,11 +781,7 @@ func handleProxyClassList(ctx context.Context, req *mcp.CallToolRequest) (*mcp.C
 		Namespace string `json:"namespace,omitempty"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-			},
-		}, nil
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
 	}
 
 	client, err := NewClient()
@@ -462,14 +796,7 @@ func handleProxyClassList(ctx context.Context, req *mcp.CallToolRequest) (*mcp.C
 
 	proxyClasses, err := rm.ListProxyClasses(ctx, params.Namespace)
 	if err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
-		}
-		return nil, err
+		return errorResult(err), nil
 	}
 
 	listJSON, err := json.MarshalIndent(proxyClasses, "", "  ")
@@ -481,20 +808,16 @@ func handleProxyClassList(ctx context.Context, req *mcp.CallToolRequest) (*mcp.C
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: fmt.Sprintf("ProxyClasses:\n%s", string(listJSON))},
 		},
+		StructuredContent: map[string]interface{}{"proxy_classes": proxyClasses},
 	}, nil
 }
 
-func handleProxyClassDelete(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func handleProxyClassGet(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		Name      string `json:"name"`
-		Namespace string `json:"namespace"`
+		Name string `json:"name"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-			},
-		}, nil
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
 	}
 
 	client, err := NewClient()
@@ -507,39 +830,78 @@ func handleProxyClassDelete(ctx context.Context, req *mcp.CallToolRequest) (*mcp
 		return nil, err
 	}
 
-	if err := rm.DeleteProxyClass(ctx, params.Namespace, params.Name); err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
-		}
+	proxyClass, err := rm.GetProxyClass(ctx, params.Name)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	spec, err := yaml.Marshal(proxyClass.Spec)
+	if err != nil {
 		return nil, err
 	}
 
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("ProxyClass '%s':\n\n", params.Name))
+	result.WriteString(string(spec))
+	result.WriteString("\nStatus:\n")
+	if proxyClass.Status == nil || len(proxyClass.Status.Conditions) == 0 {
+		result.WriteString("  No conditions reported yet\n")
+	} else {
+		for _, condition := range proxyClass.Status.Conditions {
+			result.WriteString(fmt.Sprintf("  %s: %s", condition.Type, condition.Status))
+			if condition.Message != "" {
+				result.WriteString(fmt.Sprintf(" (%s: %s)", condition.Reason, condition.Message))
+			}
+			result.WriteString("\n")
+		}
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("ProxyClass '%s' deleted from namespace '%s'", params.Name, params.Namespace)},
+			&mcp.TextContent{Text: result.String()},
 		},
+		StructuredContent: proxyClass,
 	}, nil
 }
 
-func handleProxyGroupCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func handleProxyClassUpdate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		Name       string   `json:"name"`
-		Namespace  string   `json:"namespace"`
-		Type       string   `json:"type"`
-		Replicas   int32    `json:"replicas,omitempty"`
-		ProxyClass string   `json:"proxy_class,omitempty"`
-		Tags       []string `json:"tags,omitempty"`
+		Name        string                            `json:"name"`
+		Labels      map[string]interface{}            `json:"labels,omitempty"`
+		Annotations map[string]interface{}            `json:"annotations,omitempty"`
+		Image       string                            `json:"image,omitempty"`
+		Resources   map[string]map[string]interface{} `json:"resources,omitempty"`
+		Confirm     bool                              `json:"confirm"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-			},
-		}, nil
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+	if len(params.Labels) == 0 && len(params.Annotations) == 0 && params.Image == "" && len(params.Resources) == 0 {
+		return invalidParamsResult("Nothing to update: pass labels, annotations, image or resources"), nil
+	}
+
+	update := ProxyClassUpdate{
+		Labels:      stringValues(params.Labels),
+		Annotations: stringValues(params.Annotations),
+		Image:       params.Image,
+	}
+	for kind, quantities := range params.Resources {
+		if kind != "requests" && kind != "limits" {
+			return invalidParamsResult(fmt.Sprintf("Invalid resources: %q is not requests or limits", kind)), nil
+		}
+		values := stringValues(quantities)
+		for name, value := range values {
+			if value == nil {
+				continue
+			}
+			if _, err := resource.ParseQuantity(*value); err != nil {
+				return invalidParamsResult(fmt.Sprintf("Invalid resources: %s %s %q: %v", kind, name, *value, err)), nil
+			}
+		}
+		if update.Resources == nil {
+			update.Resources = map[string]map[string]*string{}
+		}
+		update.Resources[kind] = values
 	}
 
 	client, err := NewClient()
@@ -552,34 +914,179 @@ func handleProxyGroupCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp
 		return nil, err
 	}
 
-	replicas := params.Replicas
-	if replicas == 0 {
-		replicas = 2 // Default
+	plan, err := rm.PlanProxyClassUpdate(ctx, params.Name, update)
+	if err != nil {
+		return errorResult(err), nil
 	}
 
-	proxyGroup := &ProxyGroup{
-		Metadata: metav1.ObjectMeta{
-			Name:      params.Name,
-			Namespace: params.Namespace,
-		},
-		Spec: ProxyGroupSpec{
-			Type:       params.Type,
-			Replicas:   &replicas,
-			ProxyClass: params.ProxyClass,
-			Tags:       params.Tags,
-		},
+	if !plan.Changed() || (plan.NeedsConfirmation() && !params.Confirm) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: FormatUpdatePlan(plan, false)},
+			},
+		}, nil
 	}
 
-	if err := rm.CreateProxyGroup(ctx, proxyGroup); err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
+	if err := rm.ApplyUpdate(ctx, plan); err != nil {
+		return errorResult(err), nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: FormatUpdatePlan(plan, true)},
+		},
+	}, nil
+}
+
+// stringMap converts tool arguments to string values
+func stringMap(values map[string]interface{}) map[string]string {
+	if values == nil {
+		return nil
+	}
+	converted := make(map[string]string, len(values))
+	for k, v := range values {
+		converted[k] = fmt.Sprintf("%v", v)
+	}
+	return converted
+}
+
+// resourceRequirements parses the requests and limits of a resources
+// argument
+func resourceRequirements(resources map[string]map[string]interface{}) (*corev1.ResourceRequirements, error) {
+	requirements := &corev1.ResourceRequirements{}
+	for kind, quantities := range resources {
+		list := corev1.ResourceList{}
+		for name, value := range stringMap(quantities) {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s %q: %v", kind, name, value, err)
+			}
+			list[corev1.ResourceName(name)] = quantity
+		}
+		switch kind {
+		case "requests":
+			requirements.Requests = list
+		case "limits":
+			requirements.Limits = list
+		default:
+			return nil, fmt.Errorf("%q is not requests or limits", kind)
 		}
+	}
+	return requirements, nil
+}
+
+// stringValues converts tool arguments to string values, keeping null values
+// (keys to remove) as nil
+func stringValues(values map[string]interface{}) map[string]*string {
+	if values == nil {
+		return nil
+	}
+	converted := make(map[string]*string, len(values))
+	for k, v := range values {
+		if v == nil {
+			converted[k] = nil
+			continue
+		}
+		value := fmt.Sprintf("%v", v)
+		converted[k] = &value
+	}
+	return converted
+}
+
+func handleProxyClassDelete(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+		DryRun    bool   `json:"dry_run,omitempty"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := NewResourceManager(client)
+	if err != nil {
 		return nil, err
 	}
+	rm.DryRun = params.DryRun
+
+	var manifest string
+	if params.DryRun {
+		manifest, err = rm.GetResourceYAML(ctx, ProxyClassGVR, "ProxyClass", params.Name)
+		if err != nil {
+			return errorResult(err), nil
+		}
+	}
+
+	if err := rm.DeleteProxyClass(ctx, params.Namespace, params.Name); err != nil {
+		return errorResult(err), nil
+	}
+
+	if params.DryRun {
+		return dryRunResult(fmt.Sprintf("Dry run: ProxyClass '%s' would be deleted (accepted by the API server; nothing was changed)", params.Name), manifest), nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("ProxyClass '%s' deleted from namespace '%s'", params.Name, params.Namespace)},
+		},
+	}, nil
+}
+
+func handleProxyGroupCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Name       string   `json:"name"`
+		Namespace  string   `json:"namespace"`
+		Type       string   `json:"type"`
+		Replicas   int32    `json:"replicas,omitempty"`
+		ProxyClass string   `json:"proxy_class,omitempty"`
+		Tags       []string `json:"tags,omitempty"`
+		DryRun     bool     `json:"dry_run,omitempty"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := NewResourceManager(client)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := params.Replicas
+	if replicas == 0 {
+		replicas = 2 // Default
+	}
+
+	proxyGroup := &ProxyGroup{
+		Metadata: metav1.ObjectMeta{
+			Name:      params.Name,
+			Namespace: params.Namespace,
+		},
+		Spec: ProxyGroupSpec{
+			Type:       params.Type,
+			Replicas:   &replicas,
+			ProxyClass: params.ProxyClass,
+			Tags:       params.Tags,
+		},
+	}
+
+	rm.DryRun = params.DryRun
+	if err := rm.CreateProxyGroup(ctx, proxyGroup); err != nil {
+		return errorResult(err), nil
+	}
+
+	if params.DryRun {
+		return dryRunCreateResult("ProxyGroup", proxyGroup.Metadata.Name, proxyGroup)
+	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -595,11 +1102,7 @@ func handleProxyGroupStatus(ctx context.Context, req *mcp.CallToolRequest) (*mcp
 		Namespace string `json:"namespace"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-			},
-		}, nil
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
 	}
 
 	client, err := NewClient()
@@ -614,14 +1117,7 @@ func handleProxyGroupStatus(ctx context.Context, req *mcp.CallToolRequest) (*mcp
 
 	status, err := rm.GetProxyGroupStatus(ctx, params.Namespace, params.Name)
 	if err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
-		}
-		return nil, err
+		return errorResult(err), nil
 	}
 
 	statusJSON, err := json.MarshalIndent(status, "", "  ")
@@ -633,6 +1129,7 @@ func handleProxyGroupStatus(ctx context.Context, req *mcp.CallToolRequest) (*mcp
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: fmt.Sprintf("ProxyGroup Status:\n%s", string(statusJSON))},
 		},
+		StructuredContent: json.RawMessage(statusJSON),
 	}, nil
 }
 
@@ -641,15 +1138,47 @@ func handleProxyGroupScale(ctx context.Context, req *mcp.CallToolRequest) (*mcp.
 		Name      string `json:"name"`
 		Namespace string `json:"namespace"`
 		Replicas  int32  `json:"replicas"`
+		Confirm   bool   `json:"confirm"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := NewResourceManager(client)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := rm.PlanProxyGroupScale(ctx, params.Namespace, params.Name, params.Replicas)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	if !plan.Changed() || (plan.NeedsConfirmation() && !params.Confirm) {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
+				&mcp.TextContent{Text: FormatUpdatePlan(plan, false)},
 			},
 		}, nil
 	}
 
+	if err := rm.ApplyUpdate(ctx, plan); err != nil {
+		return errorResult(err), nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: FormatUpdatePlan(plan, true)},
+		},
+	}, nil
+}
+
+func handleProxyGroupList(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	client, err := NewClient()
 	if err != nil {
 		return nil, err
@@ -660,20 +1189,184 @@ func handleProxyGroupScale(ctx context.Context, req *mcp.CallToolRequest) (*mcp.
 		return nil, err
 	}
 
-	if err := rm.ScaleProxyGroup(ctx, params.Namespace, params.Name, params.Replicas); err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
+	proxyGroups, err := rm.ListProxyGroups(ctx)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	listJSON, err := json.MarshalIndent(proxyGroups, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("ProxyGroups:\n%s", string(listJSON))},
+		},
+		StructuredContent: map[string]interface{}{"proxy_groups": proxyGroups},
+	}, nil
+}
+
+func handleProxyGroupGet(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := NewResourceManager(client)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyGroup, err := rm.GetProxyGroup(ctx, params.Name)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	spec, err := yaml.Marshal(proxyGroup.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("ProxyGroup '%s':\n\n", params.Name))
+	result.WriteString(string(spec))
+	result.WriteString("\nStatus:\n")
+	if proxyGroup.Status == nil || len(proxyGroup.Status.Conditions) == 0 {
+		result.WriteString("  No conditions reported yet\n")
+	} else {
+		for _, condition := range proxyGroup.Status.Conditions {
+			result.WriteString(fmt.Sprintf("  %s: %s", condition.Type, condition.Status))
+			if condition.Message != "" {
+				result.WriteString(fmt.Sprintf(" (%s: %s)", condition.Reason, condition.Message))
+			}
+			result.WriteString("\n")
+		}
+	}
+	if proxyGroup.Status != nil && len(proxyGroup.Status.Devices) > 0 {
+		result.WriteString("\nDevices:\n")
+		for _, device := range proxyGroup.Status.Devices {
+			result.WriteString(fmt.Sprintf("  %s", device.Hostname))
+			if len(device.TailnetIPs) > 0 {
+				result.WriteString(fmt.Sprintf(" (%s)", strings.Join(device.TailnetIPs, ", ")))
+			}
+			result.WriteString("\n")
 		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result.String()},
+		},
+		StructuredContent: proxyGroup,
+	}, nil
+}
+
+func handleProxyGroupUpdate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Name           string   `json:"name"`
+		Tags           []string `json:"tags"`
+		ProxyClass     *string  `json:"proxy_class"`
+		HostnamePrefix *string  `json:"hostname_prefix"`
+		Confirm        bool     `json:"confirm"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+	if params.Tags == nil && params.ProxyClass == nil && params.HostnamePrefix == nil {
+		return invalidParamsResult("Nothing to update: pass tags, proxy_class or hostname_prefix"), nil
+	}
+	for i, tag := range params.Tags {
+		params.Tags[i] = tailscale.NormalizeTag(tag)
+		if params.Tags[i] == "" {
+			return invalidParamsResult("Invalid tags: tags can't be empty"), nil
+		}
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := NewResourceManager(client)
+	if err != nil {
 		return nil, err
 	}
 
+	plan, err := rm.PlanProxyGroupUpdate(ctx, params.Name, ProxyGroupUpdate{
+		Tags:           params.Tags,
+		ProxyClass:     params.ProxyClass,
+		HostnamePrefix: params.HostnamePrefix,
+	})
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	if !plan.Changed() || (plan.NeedsConfirmation() && !params.Confirm) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: FormatUpdatePlan(plan, false)},
+			},
+		}, nil
+	}
+
+	if err := rm.ApplyUpdate(ctx, plan); err != nil {
+		return errorResult(err), nil
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("ProxyGroup '%s' scaled to %d replicas", params.Name, params.Replicas)},
+			&mcp.TextContent{Text: FormatUpdatePlan(plan, true)},
+		},
+	}, nil
+}
+
+func handleProxyGroupDelete(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Name   string `json:"name"`
+		DryRun bool   `json:"dry_run,omitempty"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := NewResourceManager(client)
+	if err != nil {
+		return nil, err
+	}
+	rm.DryRun = params.DryRun
+
+	var manifest string
+	if params.DryRun {
+		manifest, err = rm.GetResourceYAML(ctx, ProxyGroupGVR, "ProxyGroup", params.Name)
+		if err != nil {
+			return errorResult(err), nil
+		}
+	}
+
+	if err := rm.DeleteProxyGroup(ctx, params.Name); err != nil {
+		return errorResult(err), nil
+	}
+
+	if params.DryRun {
+		return dryRunResult(fmt.Sprintf("Dry run: ProxyGroup '%s' would be deleted (accepted by the API server; nothing was changed)", params.Name), manifest), nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("ProxyGroup '%s' deleted; the operator removes its proxies and their tailnet devices", params.Name)},
 		},
 	}, nil
 }
@@ -685,13 +1378,10 @@ func handleIngressCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.Ca
 		Hostname    string `json:"hostname"`
 		ServiceName string `json:"service_name"`
 		ServicePort int32  `json:"service_port"`
+		DryRun      bool   `json:"dry_run,omitempty"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-			},
-		}, nil
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
 	}
 
 	client, err := NewClient()
@@ -704,15 +1394,13 @@ func handleIngressCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.Ca
 		return nil, err
 	}
 
+	rm.DryRun = params.DryRun
 	if err := rm.CreateTailscaleIngress(ctx, params.Namespace, params.Name, params.Hostname, params.ServiceName, params.ServicePort); err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
-		}
-		return nil, err
+		return errorResult(err), nil
+	}
+
+	if params.DryRun {
+		return dryRunCreateResult("Ingress", params.Name, TailscaleIngress(params.Namespace, params.Name, params.Hostname, params.ServiceName, params.ServicePort))
 	}
 
 	return &mcp.CallToolResult{
@@ -729,13 +1417,10 @@ func handleEgressCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.Cal
 		Namespace        string `json:"namespace"`
 		ExternalHostname string `json:"external_hostname"`
 		Port             int32  `json:"port"`
+		DryRun           bool   `json:"dry_run,omitempty"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-			},
-		}, nil
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
 	}
 
 	client, err := NewClient()
@@ -748,15 +1433,13 @@ func handleEgressCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.Cal
 		return nil, err
 	}
 
+	rm.DryRun = params.DryRun
 	if err := rm.CreateEgressService(ctx, params.Namespace, params.Name, params.ExternalHostname, params.Port); err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
-		}
-		return nil, err
+		return errorResult(err), nil
+	}
+
+	if params.DryRun {
+		return dryRunCreateResult("Service", params.Name, EgressService(params.Namespace, params.Name, params.ExternalHostname, params.Port))
 	}
 
 	return &mcp.CallToolResult{
@@ -776,13 +1459,16 @@ func handleConnectorCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.
 		SubnetRoutes []string `json:"subnet_routes,omitempty"`
 		ExitNode     bool     `json:"exit_node,omitempty"`
 		Tags         []string `json:"tags,omitempty"`
+		DryRun       bool     `json:"dry_run,omitempty"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-			},
-		}, nil
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+
+	if len(params.SubnetRoutes) > 0 {
+		if _, err := tailscale.ValidateRoutes(params.SubnetRoutes); err != nil {
+			return invalidParamsResult(fmt.Sprintf("Invalid subnet_routes: %v", err)), nil
+		}
 	}
 
 	client, err := NewClient()
@@ -815,15 +1501,13 @@ func handleConnectorCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.
 		}
 	}
 
+	rm.DryRun = params.DryRun
 	if err := rm.CreateConnector(ctx, connector); err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
-		}
-		return nil, err
+		return errorResult(err), nil
+	}
+
+	if params.DryRun {
+		return dryRunCreateResult("Connector", connector.Metadata.Name, connector)
 	}
 
 	return &mcp.CallToolResult{
@@ -834,19 +1518,244 @@ func handleConnectorCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.
 	}, nil
 }
 
+func handleConnectorList(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := NewResourceManager(client)
+	if err != nil {
+		return nil, err
+	}
+
+	connectors, err := rm.ListConnectors(ctx)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	listJSON, err := json.MarshalIndent(connectors, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Connectors:\n%s", string(listJSON))},
+		},
+		StructuredContent: map[string]interface{}{"connectors": connectors},
+	}, nil
+}
+
+func handleConnectorGet(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := NewResourceManager(client)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := rm.GetConnector(ctx, params.Name)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	spec, err := yaml.Marshal(connector.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Connector '%s':\n\n", params.Name))
+	result.WriteString(string(spec))
+	result.WriteString("\nStatus:\n")
+	if connector.Status == nil || len(connector.Status.Conditions) == 0 {
+		result.WriteString("  No conditions reported yet\n")
+	} else {
+		for _, condition := range connector.Status.Conditions {
+			result.WriteString(fmt.Sprintf("  %s: %s", condition.Type, condition.Status))
+			if condition.Message != "" {
+				result.WriteString(fmt.Sprintf(" (%s: %s)", condition.Reason, condition.Message))
+			}
+			result.WriteString("\n")
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result.String()},
+		},
+		StructuredContent: connector,
+	}, nil
+}
+
+// connectorStatusHandler reports on a Connector, checking its routes against
+// its device in the tailnet when api is set
+func connectorStatusHandler(api *tailscale.APIClient) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var params struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+			return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+		}
+
+		client, err := NewClient()
+		if err != nil {
+			return nil, err
+		}
+
+		rm, err := NewResourceManager(client)
+		if err != nil {
+			return nil, err
+		}
+
+		connector, err := rm.GetConnector(ctx, params.Name)
+		if err != nil {
+			return errorResult(err), nil
+		}
+
+		report := ConnectorStatusReport(ctx, connector, api)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: FormatConnectorReport(report)},
+			},
+			StructuredContent: report,
+		}, nil
+	}
+}
+
+func handleConnectorUpdate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Name         string   `json:"name"`
+		Hostname     *string  `json:"hostname"`
+		ProxyClass   *string  `json:"proxy_class"`
+		Tags         []string `json:"tags"`
+		SubnetRoutes []string `json:"subnet_routes"`
+		ExitNode     *bool    `json:"exit_node"`
+		Confirm      bool     `json:"confirm"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+	if params.Hostname == nil && params.ProxyClass == nil && params.Tags == nil && params.SubnetRoutes == nil && params.ExitNode == nil {
+		return invalidParamsResult("Nothing to update: pass hostname, proxy_class, tags, subnet_routes or exit_node"), nil
+	}
+	for i, tag := range params.Tags {
+		params.Tags[i] = tailscale.NormalizeTag(tag)
+		if params.Tags[i] == "" {
+			return invalidParamsResult("Invalid tags: tags can't be empty"), nil
+		}
+	}
+	if len(params.SubnetRoutes) > 0 {
+		if _, err := tailscale.ValidateRoutes(params.SubnetRoutes); err != nil {
+			return invalidParamsResult(fmt.Sprintf("Invalid subnet_routes: %v", err)), nil
+		}
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := NewResourceManager(client)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := rm.PlanConnectorUpdate(ctx, params.Name, ConnectorUpdate{
+		Hostname:     params.Hostname,
+		ProxyClass:   params.ProxyClass,
+		Tags:         params.Tags,
+		SubnetRoutes: params.SubnetRoutes,
+		ExitNode:     params.ExitNode,
+	})
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	if !plan.Changed() || (plan.NeedsConfirmation() && !params.Confirm) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: FormatUpdatePlan(plan, false)},
+			},
+		}, nil
+	}
+
+	if err := rm.ApplyUpdate(ctx, plan); err != nil {
+		return errorResult(err), nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: FormatUpdatePlan(plan, true)},
+		},
+	}, nil
+}
+
+func handleConnectorDelete(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Name   string `json:"name"`
+		DryRun bool   `json:"dry_run,omitempty"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := NewResourceManager(client)
+	if err != nil {
+		return nil, err
+	}
+	rm.DryRun = params.DryRun
+
+	var manifest string
+	if params.DryRun {
+		manifest, err = rm.GetResourceYAML(ctx, ConnectorGVR, "Connector", params.Name)
+		if err != nil {
+			return errorResult(err), nil
+		}
+	}
+
+	if err := rm.DeleteConnector(ctx, params.Name); err != nil {
+		return errorResult(err), nil
+	}
+
+	if params.DryRun {
+		return dryRunResult(fmt.Sprintf("Dry run: Connector '%s' would be deleted (accepted by the API server; nothing was changed)", params.Name), manifest), nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Connector '%s' deleted; the operator removes its proxy and its tailnet device", params.Name)},
+		},
+	}, nil
+}
+
 func handleDNSConfigCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
 		Name        string   `json:"name"`
 		Namespace   string   `json:"namespace"`
 		MagicDNS    bool     `json:"magic_dns"`
 		Nameservers []string `json:"nameservers,omitempty"`
+		DryRun      bool     `json:"dry_run,omitempty"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-			},
-		}, nil
+		return invalidParamsResult(fmt.Sprintf("Invalid parameters: %v", err)), nil
 	}
 
 	client, err := NewClient()
@@ -871,15 +1780,13 @@ func handleDNSConfigCreate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.
 		},
 	}
 
+	rm.DryRun = params.DryRun
 	if err := rm.CreateDNSConfig(ctx, dnsConfig); err != nil {
-		if k8sErr, ok := err.(*K8sError); ok {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: k8sErr.FormatErrorWithHint()},
-				},
-			}, nil
-		}
-		return nil, err
+		return errorResult(err), nil
+	}
+
+	if params.DryRun {
+		return dryRunCreateResult("DNSConfig", dnsConfig.Metadata.Name, dnsConfig)
 	}
 
 	return &mcp.CallToolResult{
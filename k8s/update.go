@@ -0,0 +1,208 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/phildougherty/go-tailscale-mcp/diff"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// sensitiveField is a spec field whose changes need explicit confirmation
+// because they affect capacity, what the proxies run, or what they expose to
+// the tailnet
+type sensitiveField struct {
+	label string
+	path  []string
+}
+
+var sensitiveFields = []sensitiveField{
+	{label: "replicas", path: []string{"spec", "replicas"}},
+	{label: "tags", path: []string{"spec", "tags"}},
+	{label: "routes", path: []string{"spec", "subnetRouter", "advertiseRoutes"}},
+	{label: "routes", path: []string{"spec", "appConnector", "routes"}},
+	{label: "proxy image", path: []string{"spec", "statefulSet", "pod", "tailscaleContainer", "image"}},
+	{label: "proxy class", path: []string{"spec", "proxyClass"}},
+	{label: "exit node", path: []string{"spec", "exitNode"}},
+}
+
+// UpdatePlan describes a proposed change to an existing resource. Update tools
+// build a plan first so the diff can be shown before anything is written.
+type UpdatePlan struct {
+	GVR      schema.GroupVersionResource
+	Kind     string
+	Name     string
+	Current  *unstructured.Unstructured
+	Proposed *unstructured.Unstructured
+	// Diff is a unified YAML diff of current vs proposed (empty if unchanged)
+	Diff string
+	// Sensitive lists the sensitive fields (replicas, tags, routes, proxy
+	// image, proxy class, exit node) that change
+	Sensitive []string
+}
+
+// Changed reports whether the plan modifies the resource
+func (p *UpdatePlan) Changed() bool {
+	return p.Diff != ""
+}
+
+// NeedsConfirmation reports whether the plan touches fields that require confirm
+func (p *UpdatePlan) NeedsConfirmation() bool {
+	return len(p.Sensitive) > 0
+}
+
+// PlanUpdate fetches a resource and applies mutate to a copy of it, returning
+// the resulting plan without writing anything
+func (rm *ResourceManager) PlanUpdate(ctx context.Context, gvr schema.GroupVersionResource, kind, name string, mutate func(obj *unstructured.Unstructured) error) (*UpdatePlan, error) {
+	current, err := rm.dynamicClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, NewResourceNotFoundError(kind, name, err)
+		}
+		return nil, NewConnectivityError(fmt.Sprintf("failed to get %s", kind), err)
+	}
+
+	proposed := current.DeepCopy()
+	if err := mutate(proposed); err != nil {
+		return nil, NewK8sError(ErrorTypeResourceInvalid, fmt.Sprintf("failed to update %s", kind), err)
+	}
+
+	currentYAML, err := resourceYAML(current)
+	if err != nil {
+		return nil, NewK8sError(ErrorTypeResourceInvalid, fmt.Sprintf("failed to render %s", kind), err)
+	}
+	proposedYAML, err := resourceYAML(proposed)
+	if err != nil {
+		return nil, NewK8sError(ErrorTypeResourceInvalid, fmt.Sprintf("failed to render %s", kind), err)
+	}
+
+	plan := &UpdatePlan{
+		GVR:      gvr,
+		Kind:     kind,
+		Name:     name,
+		Current:  current,
+		Proposed: proposed,
+		Diff: diff.Unified(
+			fmt.Sprintf("%s/%s (current)", kind, name),
+			fmt.Sprintf("%s/%s (proposed)", kind, name),
+			currentYAML, proposedYAML),
+	}
+
+	for _, field := range sensitiveFields {
+		before, _, _ := unstructured.NestedFieldNoCopy(current.Object, field.path...)
+		after, _, _ := unstructured.NestedFieldNoCopy(proposed.Object, field.path...)
+		if !reflect.DeepEqual(before, after) && !containsLabel(plan.Sensitive, field.label) {
+			plan.Sensitive = append(plan.Sensitive, field.label)
+		}
+	}
+
+	return plan, nil
+}
+
+// ApplyUpdate writes the proposed resource. The update carries the resource
+// version that was diffed, so it fails if the resource changed in between.
+func (rm *ResourceManager) ApplyUpdate(ctx context.Context, plan *UpdatePlan) error {
+	_, err := rm.dynamicClient.Resource(plan.GVR).Update(ctx, plan.Proposed, metav1.UpdateOptions{})
+	if err != nil {
+		if errors.IsConflict(err) {
+			return NewResourceConflictError(plan.Kind, plan.Name, err)
+		}
+		return NewK8sError(ErrorTypeUnknown, fmt.Sprintf("failed to update %s", plan.Kind), err)
+	}
+	return nil
+}
+
+// FormatUpdatePlan renders the plan's diff along with what happened to it.
+// applied is false when the plan was only previewed.
+func FormatUpdatePlan(plan *UpdatePlan, applied bool) string {
+	var result strings.Builder
+
+	if !plan.Changed() {
+		result.WriteString(fmt.Sprintf("%s '%s' already matches the requested configuration, nothing to update\n", plan.Kind, plan.Name))
+		return result.String()
+	}
+
+	if applied {
+		result.WriteString(fmt.Sprintf("✓ %s '%s' updated\n\n", plan.Kind, plan.Name))
+	} else {
+		result.WriteString(fmt.Sprintf("Proposed changes to %s '%s' (not applied):\n\n", plan.Kind, plan.Name))
+	}
+	result.WriteString(plan.Diff)
+
+	if !applied && plan.NeedsConfirmation() {
+		result.WriteString(fmt.Sprintf("\n⚠ This change affects %s. Re-run with confirm: true to apply it.\n", strings.Join(plan.Sensitive, ", ")))
+	}
+
+	return result.String()
+}
+
+// resourceYAML renders a resource for diffing, leaving out server-managed
+// fields that would only add noise
+func resourceYAML(obj *unstructured.Unstructured) (string, error) {
+	clean := obj.DeepCopy()
+	unstructured.RemoveNestedField(clean.Object, "status")
+	unstructured.RemoveNestedField(clean.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(clean.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(clean.Object, "metadata", "generation")
+
+	data, err := yaml.Marshal(clean.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// mergeStringMap merges values into the string map at path, removing the keys
+// whose value is nil, and the map itself if that leaves it empty
+func mergeStringMap(obj *unstructured.Unstructured, values map[string]*string, path ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	merged, _, err := unstructured.NestedStringMap(obj.Object, path...)
+	if err != nil {
+		return err
+	}
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	for key, value := range values {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = *value
+		}
+	}
+	if len(merged) == 0 {
+		unstructured.RemoveNestedField(obj.Object, path...)
+		return nil
+	}
+	return unstructured.SetNestedStringMap(obj.Object, merged, path...)
+}
+
+// setOptionalString sets the string field at path, removing it when value is
+// empty; a nil value leaves the field as it is
+func setOptionalString(obj *unstructured.Unstructured, value *string, path ...string) error {
+	if value == nil {
+		return nil
+	}
+	if *value == "" {
+		unstructured.RemoveNestedField(obj.Object, path...)
+		return nil
+	}
+	return unstructured.SetNestedField(obj.Object, *value, path...)
+}
+
+func containsLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/config"
+	"github.com/phildougherty/go-tailscale-mcp/logging"
 	"github.com/phildougherty/go-tailscale-mcp/server"
+	"github.com/phildougherty/go-tailscale-mcp/tools"
 )
 
 func main() {
@@ -18,27 +24,97 @@ func main() {
 		return
 	}
 
-	ctx := context.Background()
-
-	// Check environment variable for Kubernetes operator support
-	enableK8sOperator := false
-	if k8sEnv := os.Getenv("ENABLE_K8S_OPERATOR"); k8sEnv != "" {
-		k8sEnv = strings.ToLower(k8sEnv)
-		if k8sEnv == "true" || k8sEnv == "1" || k8sEnv == "yes" || k8sEnv == "on" {
-			enableK8sOperator = true
+	// Interactive setup wizard
+	if len(os.Args) > 1 && os.Args[1] == "setup" {
+		flags := flag.NewFlagSet("setup", flag.ExitOnError)
+		configPath := flags.String("config", "", "config file to write (default "+config.DefaultPath()+")")
+		flags.Parse(os.Args[2:])
+		if err := runSetup(config.ResolvePath(*configPath)); err != nil {
+			log.Fatalf("Setup failed: %v", err)
 		}
+		return
+	}
+
+	// Run a single tool invocation without an MCP client
+	if len(os.Args) > 1 && os.Args[1] == "call" {
+		os.Exit(runCall(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	configPath := flag.String("config", "", "config file (default "+config.DefaultPath()+")")
+	transport := flag.String("transport", "", "MCP transport: stdio (default) or http")
+	listen := flag.String("listen", "", "listen address for the http transport (default "+server.DefaultListenAddr+")")
+	selftest := flag.Bool("selftest", false, "check the setup (tailscale binary, tailscaled, API credentials, Kubernetes), print a report and exit non-zero if a check fails")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *transport != "" {
+		cfg.Transport = *transport
+	}
+	if *listen != "" {
+		cfg.ListenAddr = *listen
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
 	}
+	logFile, err := logging.Setup(cfg.LogLevel, cfg.LogFormat, cfg.LogFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer logFile.Close()
 
 	// Create and configure the MCP server
-	srv, err := server.NewTailscaleServer(enableK8sOperator)
+	srv, err := server.NewTailscaleServer(cfg)
 	if err != nil {
 		log.Fatalf("Failed to create Tailscale MCP server: %v", err)
 	}
 
-	// Run the server with stdio transport
-	transport := &mcp.StdioTransport{}
-	if err := srv.Run(ctx, transport); err != nil {
+	if *selftest {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		checks := srv.Doctor(ctx)
+		cancel()
+		fmt.Print(tools.FormatDoctor(checks))
+		if tools.DoctorFailed(checks) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch cfg.Transport {
+	case "", "stdio":
+		err = srv.Run(ctx, &mcp.StdioTransport{})
+	case "http":
+		err = srv.RunHTTP(ctx, cfg.ListenAddr)
+	}
+	if err != nil {
 		log.Fatalf("Server error: %v", err)
-		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
+
+// loadConfig reads the config file (if present) and applies environment
+// overrides. A config file named explicitly must exist.
+func loadConfig(flagValue string) (*config.Config, error) {
+	path := config.ResolvePath(flagValue)
+	if path != config.DefaultPath() {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg.ApplyEnv()
+
+	// The k8s client reads KUBECONFIG directly
+	if cfg.Kubeconfig != "" && os.Getenv("KUBECONFIG") == "" {
+		os.Setenv("KUBECONFIG", cfg.Kubeconfig)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,92 @@
+// Package redact masks secrets in tool output: Tailscale auth keys, API
+// access tokens, OAuth client and webhook secrets (all tskey-...), tailnet
+// lock disablement secrets, private keys, and any other known values such
+// as the server's own credentials.
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Mask replaces the secret part of a redacted value
+const Mask = "[redacted]"
+
+var (
+	// tskey-<type>-<id>-<secret>, or tskey-<id>-<secret> for older keys. The
+	// ID is kept so the key can still be told apart from others.
+	tskeyPattern = regexp.MustCompile(`\btskey-(?:[a-z]+-)?[A-Za-z0-9]+(?:-[A-Za-z0-9]+)?`)
+
+	prefixedPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\bdisablement-secret:[0-9A-Za-z]+`),
+		regexp.MustCompile(`\bprivkey:[0-9a-f]+`),
+		regexp.MustCompile(`\bnlpriv:[0-9a-f]+`),
+	}
+)
+
+// Redactor masks secrets in strings. A nil Redactor returns its input
+// unchanged, which is how full secrets are let through when configured.
+type Redactor struct {
+	literals []string
+}
+
+// New returns a Redactor that also masks each of literals wherever it
+// appears verbatim. Empty values are ignored.
+func New(literals ...string) *Redactor {
+	r := &Redactor{}
+	for _, literal := range literals {
+		if literal = strings.TrimSpace(literal); literal != "" {
+			r.literals = append(r.literals, literal)
+		}
+	}
+	return r
+}
+
+// String returns s with its secrets masked and how many were masked
+func (r *Redactor) String(s string) (string, int) {
+	if r == nil {
+		return s, 0
+	}
+
+	count := 0
+	for _, literal := range r.literals {
+		if n := strings.Count(s, literal); n > 0 {
+			s = strings.ReplaceAll(s, literal, Mask)
+			count += n
+		}
+	}
+
+	s = tskeyPattern.ReplaceAllStringFunc(s, func(key string) string {
+		count++
+		return maskTSKey(key)
+	})
+	for _, pattern := range prefixedPatterns {
+		s = pattern.ReplaceAllStringFunc(s, func(secret string) string {
+			count++
+			return secret[:strings.Index(secret, ":")+1] + Mask
+		})
+	}
+	return s, count
+}
+
+// maskTSKey keeps a key's type and ID and masks the rest. Keys without a
+// separate ID segment (e.g., webhook secrets) are masked after the type.
+func maskTSKey(key string) string {
+	parts := strings.Split(key, "-")
+	if len(parts) == 2 {
+		return "tskey-" + Mask
+	}
+	if len(parts) == 4 || (len(parts) == 3 && !isLower(parts[1])) {
+		return strings.Join(parts[:len(parts)-1], "-") + "-" + Mask
+	}
+	return strings.Join(parts[:2], "-") + "-" + Mask
+}
+
+func isLower(s string) bool {
+	for _, c := range s {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return s != ""
+}
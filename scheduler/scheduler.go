@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Scheduler runs periodic background jobs (expiry sweeps, reminders) for the
+// lifetime of the server
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []job
+	ctx     context.Context
+	started bool
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	fn       func(ctx context.Context)
+}
+
+// New creates an empty scheduler
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every registers fn to run every interval. Jobs registered after Start begin immediately.
+func (s *Scheduler) Every(name string, interval time.Duration, fn func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j := job{name: name, interval: interval, fn: fn}
+	s.jobs = append(s.jobs, j)
+	if s.started {
+		go s.run(s.ctx, j)
+	}
+}
+
+// Start runs all registered jobs until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+	s.ctx = ctx
+
+	for _, j := range s.jobs {
+		go s.run(ctx, j)
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, j job) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, j)
+		}
+	}
+}
+
+// runOnce runs a job, keeping a panicking job from taking down the server
+func (s *Scheduler) runOnce(ctx context.Context, j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Background job panicked", "job", j.name, "panic", r)
+		}
+	}()
+	j.fn(ctx)
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/go-tailscale-mcp/config"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// whoisCacheTTL is how long a whois result is reused; streamable HTTP sends
+// every message as its own request, and each one would otherwise hit tailscaled
+const whoisCacheTTL = time.Minute
+
+// whoisCacheSize caps the cached remote addresses, so clients connecting
+// from many addresses can't grow the cache without limit
+const whoisCacheSize = 1024
+
+// authenticator checks HTTP requests against a static bearer token and/or the
+// Tailscale identity of the connecting node
+type authenticator struct {
+	token      string
+	identities []string
+	cli        *tailscale.CLI
+
+	mu    sync.Mutex
+	cache map[string]whoisEntry
+}
+
+type whoisEntry struct {
+	identity string
+	allowed  bool
+	expires  time.Time
+}
+
+// newAuthenticator builds an authenticator from the config. It returns nil
+// when neither a token nor identities are configured.
+func newAuthenticator(cfg *config.Config, cli *tailscale.CLI) (*authenticator, error) {
+	token := cfg.AuthToken
+	if cfg.AuthTokenFile != "" {
+		data, err := os.ReadFile(cfg.AuthTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read auth token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+		if token == "" {
+			return nil, fmt.Errorf("auth token file %s is empty", cfg.AuthTokenFile)
+		}
+	}
+
+	if token == "" && len(cfg.AuthIdentities) == 0 {
+		return nil, nil
+	}
+	return &authenticator{
+		token:      token,
+		identities: cfg.AuthIdentities,
+		cli:        cli,
+		cache:      map[string]whoisEntry{},
+	}, nil
+}
+
+// wrap rejects requests that carry neither a valid token nor an allowed identity
+func (a *authenticator) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.validToken(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(a.identities) > 0 {
+			identity, allowed := a.checkIdentity(r.Context(), r.RemoteAddr)
+			if allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if identity != "" {
+				slog.Warn("Rejected MCP request: identity not allowed", "identity", identity, "remote_addr", r.RemoteAddr)
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+		}
+
+		if a.token != "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tailscale-mcp"`)
+		}
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+func (a *authenticator) validToken(r *http.Request) bool {
+	if a.token == "" {
+		return false
+	}
+	provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(provided)), []byte(a.token)) == 1
+}
+
+// checkIdentity resolves the remote address with whois and reports the
+// identity (a login name, or the node's tags) and whether it is allowed.
+// Clients that aren't on the tailnet have no identity.
+func (a *authenticator) checkIdentity(ctx context.Context, remoteAddr string) (string, bool) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	a.mu.Lock()
+	entry, ok := a.cache[host]
+	a.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.identity, entry.allowed
+	}
+
+	entry = whoisEntry{expires: time.Now().Add(whoisCacheTTL)}
+	if who, err := a.cli.WhoIs(ctx, host); err == nil {
+		entry.identity, entry.allowed = a.matchIdentity(who)
+	} else if ctx.Err() != nil {
+		// The client went away; don't remember it as unknown
+		return "", false
+	}
+
+	a.remember(host, entry)
+	return entry.identity, entry.allowed
+}
+
+// remember caches a whois result. When the cache is full, expired entries
+// are dropped first, then the one closest to expiring.
+func (a *authenticator) remember(host string, entry whoisEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.cache[host]; !ok && len(a.cache) >= whoisCacheSize {
+		now := time.Now()
+		oldest := ""
+		for cached, e := range a.cache {
+			if !now.Before(e.expires) {
+				delete(a.cache, cached)
+			} else if oldest == "" || e.expires.Before(a.cache[oldest].expires) {
+				oldest = cached
+			}
+		}
+		if len(a.cache) >= whoisCacheSize {
+			delete(a.cache, oldest)
+		}
+	}
+	a.cache[host] = entry
+}
+
+// matchIdentity checks a tagged node's tags, or otherwise the owning user's
+// login name, against the allowed identities
+func (a *authenticator) matchIdentity(who *tailscale.WhoIs) (string, bool) {
+	candidates := []string{who.UserProfile.LoginName}
+	if who.Tagged() {
+		candidates = who.Node.Tags
+	}
+
+	for _, allowed := range a.identities {
+		for _, candidate := range candidates {
+			if allowed == "*" || strings.EqualFold(allowed, candidate) {
+				return candidate, true
+			}
+		}
+	}
+	return strings.Join(candidates, ","), false
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tools"
+)
+
+// connectInProcess opens an in-process client session to the server, so tools
+// can be invoked without an external MCP client. Background jobs are not
+// started. The returned function closes the session and waits for the server
+// side to shut down.
+func (s *TailscaleServer) connectInProcess(ctx context.Context) (*mcp.ClientSession, func(), error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.Server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start server session: %w", err)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "tailscale-mcp-call", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		serverSession.Close()
+		return nil, nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+
+	closeSession := func() {
+		session.Close()
+		serverSession.Wait()
+	}
+	return session, closeSession, nil
+}
+
+// CallTool runs a single tool invocation through an in-process session
+func (s *TailscaleServer) CallTool(ctx context.Context, name string, args json.RawMessage) (*mcp.CallToolResult, error) {
+	session, closeSession, err := s.connectInProcess(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSession()
+
+	params := &mcp.CallToolParams{Name: name}
+	if len(args) > 0 {
+		params.Arguments = args
+	}
+	return session.CallTool(ctx, params)
+}
+
+// ListTools returns the tools registered with the current configuration
+func (s *TailscaleServer) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
+	session, closeSession, err := s.connectInProcess(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSession()
+
+	var listed []*mcp.Tool
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, err
+		}
+		listed = append(listed, tool)
+	}
+	return listed, nil
+}
+
+// Doctor runs the doctor checks directly rather than through the tool, so
+// they work whatever tools are enabled
+func (s *TailscaleServer) Doctor(ctx context.Context) []tools.DoctorCheck {
+	return tools.RunDoctor(ctx, s.cli, s.api, s.enableK8sOperator)
+}
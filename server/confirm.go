@@ -0,0 +1,333 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// confirmationTTL is how long a confirmation token can be redeemed
+const confirmationTTL = 5 * time.Minute
+
+// confirmationTokenProperty is added to the schema of the tools that need
+// confirmation when confirm_destructive is on
+var confirmationTokenProperty = &jsonschema.Schema{
+	Type:        "string",
+	Description: "One-time token from the confirmation request returned by a previous call with the same arguments",
+}
+
+// confirmations holds the tokens handed out for destructive calls. A token
+// is bound to the tool, its arguments and the session that asked for it, and
+// is used up by the call it confirms.
+type confirmations struct {
+	mu      sync.Mutex
+	pending map[string]pendingConfirmation
+}
+
+type pendingConfirmation struct {
+	tool    string
+	args    string
+	session string
+	expires time.Time
+}
+
+func newConfirmations() *confirmations {
+	return &confirmations{pending: make(map[string]pendingConfirmation)}
+}
+
+// issue returns a new token for the call and when it expires
+func (c *confirmations) issue(tool, args, session string) (string, time.Time, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(buf)
+	expires := time.Now().Add(confirmationTTL)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for t, p := range c.pending {
+		if time.Now().After(p.expires) {
+			delete(c.pending, t)
+		}
+	}
+	c.pending[token] = pendingConfirmation{tool: tool, args: args, session: session, expires: expires}
+	return token, expires, nil
+}
+
+// redeem uses up token, reporting whether it was issued for this exact call
+// and hasn't expired. A token that doesn't match is left for its own call.
+func (c *confirmations) redeem(token, tool, args, session string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[token]
+	if !ok || p.tool != tool || p.args != args || p.session != session {
+		return false
+	}
+	delete(c.pending, token)
+	return time.Now().Before(p.expires)
+}
+
+// previewSwitch is the argument that makes a destructive tool only preview
+// its change: dry_run, or a confirm, apply or advertise flag that must be
+// true for the tool to act
+type previewSwitch struct {
+	name string
+	// act is the value that makes the call act, and preview its opposite
+	act     bool
+	preview bool
+	// defaultPreview is whether a call without the argument previews
+	defaultPreview bool
+}
+
+// previews reports whether the call's arguments make it a preview. Values
+// that aren't booleans don't, so a malformed switch can't skip the token.
+func (p *previewSwitch) previews(args map[string]json.RawMessage) bool {
+	raw, set := args[p.name]
+	if !set || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return p.defaultPreview
+	}
+	var value bool
+	return json.Unmarshal(raw, &value) == nil && value == p.preview
+}
+
+// confirmTools finds the tools that need a confirmation token: every tool
+// annotated as destructive, with the argument that makes it preview, if it
+// has one (nil otherwise)
+func (s *TailscaleServer) confirmTools(ctx context.Context) (map[string]*previewSwitch, error) {
+	listed, err := s.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gates := map[string]*previewSwitch{}
+	for _, tool := range listed {
+		if tool.Annotations == nil || tool.Annotations.DestructiveHint == nil || !*tool.Annotations.DestructiveHint {
+			continue
+		}
+		var properties map[string]*jsonschema.Schema
+		if tool.InputSchema != nil {
+			properties = tool.InputSchema.Properties
+		}
+		var gate *previewSwitch
+		switch {
+		case properties["dry_run"] != nil:
+			// The DryRunTools act by default, unless the server's dry_run
+			// setting is on; the other tools with dry_run preview by default
+			defaultPreview := true
+			if isDryRunTool(tool.Name) {
+				defaultPreview = s.dryRun
+			}
+			gate = &previewSwitch{name: "dry_run", act: false, preview: true, defaultPreview: defaultPreview}
+		case properties["confirm"] != nil:
+			gate = &previewSwitch{name: "confirm", act: true, preview: false, defaultPreview: true}
+		case properties["apply"] != nil:
+			gate = &previewSwitch{name: "apply", act: true, preview: false, defaultPreview: true}
+		case properties["advertise"] != nil:
+			gate = &previewSwitch{name: "advertise", act: true, preview: false, defaultPreview: true}
+		}
+		gates[tool.Name] = gate
+	}
+	return gates, nil
+}
+
+// requireConfirmation implements the confirm_destructive setting. A call to
+// a destructive tool that would act doesn't run without a
+// confirmation_token: tools that can preview run as a preview, and the others
+// only describe the call, and either way the result carries a token. Calling
+// again with the same arguments and the token runs the tool for real. Calls
+// that only preview run as usual.
+func (s *TailscaleServer) requireConfirmation(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if list, ok := req.(*mcp.ListToolsRequest); ok && s.confirmations != nil {
+			result, err := next(ctx, method, list)
+			if tools, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+				s.addConfirmationTokens(tools)
+			}
+			return result, err
+		}
+
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok || s.confirmations == nil {
+			return next(ctx, method, req)
+		}
+		gate, ok := s.confirmGates[call.Params.Name]
+		if !ok {
+			return next(ctx, method, req)
+		}
+
+		args := map[string]json.RawMessage{}
+		if raw := bytes.TrimSpace(call.Params.Arguments); len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return confirmationErrorResult("The arguments must be a JSON object. " + call.Params.Name + " needs a confirmation token, which can't be checked against arguments that don't parse."), nil
+			}
+		}
+		if gate != nil && gate.previews(args) {
+			return next(ctx, method, req)
+		}
+
+		var token string
+		if raw, ok := args["confirmation_token"]; ok {
+			if err := json.Unmarshal(raw, &token); err != nil {
+				return confirmationErrorResult("confirmation_token must be a string"), nil
+			}
+			delete(args, "confirmation_token")
+		}
+		if gate != nil {
+			delete(args, gate.name)
+		}
+		canonical, err := canonicalArguments(args)
+		if err != nil {
+			return nil, err
+		}
+		session := ""
+		if call.Session != nil {
+			session = call.Session.ID()
+		}
+
+		if token != "" {
+			if !s.confirmations.redeem(token, call.Params.Name, canonical, session) {
+				return confirmationErrorResult("The confirmation token is unknown, expired, already used, or was issued for different arguments. Call the tool again without confirmation_token to get a new one."), nil
+			}
+			if gate != nil {
+				// Keep the tool's or the server's default from turning this
+				// into a preview
+				args[gate.name] = boolArgument(gate.act)
+			}
+			if call.Params.Arguments, err = json.Marshal(args); err != nil {
+				return nil, err
+			}
+			return next(ctx, method, req)
+		}
+
+		var result *mcp.CallToolResult
+		if gate != nil {
+			args[gate.name] = boolArgument(gate.preview)
+			if call.Params.Arguments, err = json.Marshal(args); err != nil {
+				return nil, err
+			}
+			preview, err := next(ctx, method, req)
+			if err != nil {
+				return preview, err
+			}
+			result, _ = preview.(*mcp.CallToolResult)
+			if result == nil || result.IsError {
+				return preview, nil
+			}
+		} else {
+			result = &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("%s was not run yet. Arguments:\n%s", call.Params.Name, indentedArguments(args))},
+				},
+			}
+		}
+
+		token, expires, err := s.confirmations.issue(call.Params.Name, canonical, session)
+		if err != nil {
+			return nil, err
+		}
+		return withConfirmationRequest(result, call.Params.Name, token, expires), nil
+	}
+}
+
+func boolArgument(value bool) json.RawMessage {
+	if value {
+		return json.RawMessage("true")
+	}
+	return json.RawMessage("false")
+}
+
+// canonicalArguments renders arguments with sorted keys and no whitespace, so
+// the confirming call matches however the client formats it
+func canonicalArguments(args map[string]json.RawMessage) (string, error) {
+	values := make(map[string]interface{}, len(args))
+	for key, raw := range args {
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return "", err
+		}
+		values[key] = value
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func indentedArguments(args map[string]json.RawMessage) string {
+	if len(args) == 0 {
+		return "  (none)"
+	}
+	data, err := json.MarshalIndent(args, "  ", "  ")
+	if err != nil {
+		return "  (unprintable)"
+	}
+	return "  " + string(data)
+}
+
+// withConfirmationRequest appends the confirmation instructions to a
+// preview, and adds the token to its structured content when that is an
+// object (or makes it one)
+func withConfirmationRequest(result *mcp.CallToolResult, tool, token string, expires time.Time) *mcp.CallToolResult {
+	request := map[string]interface{}{
+		"tool":       tool,
+		"token":      token,
+		"expires_at": expires.UTC().Format(time.RFC3339),
+	}
+	result.Content = append(result.Content, &mcp.TextContent{Text: fmt.Sprintf(
+		"⚠ Confirmation required: nothing has been changed. To go ahead, call %s again with the same arguments and confirmation_token: %q within %d minutes. The token works once.",
+		tool, token, int(confirmationTTL.Minutes()))})
+
+	structured := map[string]interface{}{}
+	if result.StructuredContent != nil {
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil || json.Unmarshal(data, &structured) != nil {
+			structured = map[string]interface{}{"preview": result.StructuredContent}
+		}
+	}
+	structured["confirmation_required"] = request
+	result.StructuredContent = structured
+	return result
+}
+
+// addConfirmationTokens advertises confirmation_token on the listed tools
+// that need it. The tools are copied, since the server owns the originals.
+func (s *TailscaleServer) addConfirmationTokens(result *mcp.ListToolsResult) {
+	for i, tool := range result.Tools {
+		if _, ok := s.confirmGates[tool.Name]; !ok || tool.InputSchema == nil {
+			continue
+		}
+		copied := *tool
+		copied.InputSchema = tool.InputSchema.CloneSchemas()
+		if copied.InputSchema.Properties == nil {
+			copied.InputSchema.Properties = map[string]*jsonschema.Schema{}
+		}
+		copied.InputSchema.Properties["confirmation_token"] = confirmationTokenProperty
+		result.Tools[i] = &copied
+	}
+}
+
+// confirmationErrorResult reports a confirmation token that can't be used,
+// in the same {"error": {...}} shape as the tools' errors
+func confirmationErrorResult(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: message},
+		},
+		StructuredContent: map[string]interface{}{"error": map[string]interface{}{
+			"code":      "confirmation_invalid",
+			"category":  "precondition",
+			"message":   message,
+			"retryable": false,
+		}},
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/k8s"
+	"github.com/phildougherty/go-tailscale-mcp/tools"
+)
+
+// applyDryRunDefault implements the dry_run setting: calls to tools that take
+// a dry_run argument get dry_run=true unless they pass one themselves
+func (s *TailscaleServer) applyDryRunDefault(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok || !s.dryRun || !isDryRunTool(call.Params.Name) {
+			return next(ctx, method, req)
+		}
+
+		args := map[string]json.RawMessage{}
+		if raw := bytes.TrimSpace(call.Params.Arguments); len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+			// Leave arguments that aren't an object for the tool to reject
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return next(ctx, method, req)
+			}
+		}
+		if _, set := args["dry_run"]; !set {
+			args["dry_run"] = json.RawMessage("true")
+			data, err := json.Marshal(args)
+			if err != nil {
+				return nil, err
+			}
+			call.Params.Arguments = data
+		}
+		return next(ctx, method, req)
+	}
+}
+
+func isDryRunTool(name string) bool {
+	return slices.Contains(tools.DryRunTools, name) || slices.Contains(k8s.DryRunTools, name)
+}
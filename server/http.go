@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultListenAddr is where the HTTP transport listens when no address is configured
+const DefaultListenAddr = "127.0.0.1:8080"
+
+// shutdownTimeout bounds how long in-flight requests get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// RunHTTP starts background jobs and serves MCP over HTTP until ctx is
+// cancelled. Streamable HTTP is served at /mcp and the older SSE transport at
+// /sse, so any number of clients can connect to one server.
+func (s *TailscaleServer) RunHTTP(ctx context.Context, addr string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if addr == "" {
+		addr = DefaultListenAddr
+	}
+
+	getServer := func(*http.Request) *mcp.Server { return s.Server }
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(getServer, nil))
+	mux.Handle("/sse", mcp.NewSSEHandler(getServer))
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	var handler http.Handler = mux
+	if s.auth != nil {
+		handler = s.auth.wrap(mux)
+	} else if !isLoopback(listener.Addr()) {
+		if !s.allowUnauthenticated {
+			listener.Close()
+			return fmt.Errorf("refusing to serve MCP over HTTP on %s without authentication: set auth_token or auth_identities, or allow_unauthenticated to accept any client that can reach the listener", listener.Addr())
+		}
+		slog.Warn("MCP HTTP transport is listening without authentication; restrict access with tailnet ACLs", "addr", listener.Addr().String())
+	}
+	slog.Info("Serving MCP over HTTP", "url", fmt.Sprintf("http://%s/mcp", listener.Addr()), "sse", "/sse")
+
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	if err := s.serveWebhooks(ctx); err != nil {
+		listener.Close()
+		return err
+	}
+	s.scheduler.Start(ctx)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errc:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		// SSE and streaming connections stay open, so don't wait on them forever
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelShutdown()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return httpServer.Close()
+		}
+		return nil
+	}
+}
+
+func isLoopback(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	return ok && tcpAddr.IP.IsLoopback()
+}
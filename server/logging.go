@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// logToolCalls logs every tool call with its duration and outcome: "ok",
+// "error" when the tool reported a failure (with its error code), or
+// "failed" when the call didn't produce a result at all
+func logToolCalls(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok {
+			return next(ctx, method, req)
+		}
+
+		start := time.Now()
+		result, err := next(ctx, method, req)
+		attrs := []any{
+			"tool", call.Params.Name,
+			"duration_ms", time.Since(start).Milliseconds(),
+		}
+		if call.Session != nil && call.Session.ID() != "" {
+			attrs = append(attrs, "session", call.Session.ID())
+		}
+
+		toolResult, _ := result.(*mcp.CallToolResult)
+		switch {
+		case err != nil:
+			slog.WarnContext(ctx, "Tool call", append(attrs, "outcome", "failed", "error", err)...)
+		case toolResult != nil && toolResult.IsError:
+			attrs = append(attrs, "outcome", "error")
+			if code := errorCode(toolResult); code != "" {
+				attrs = append(attrs, "code", code)
+			}
+			slog.InfoContext(ctx, "Tool call", attrs...)
+		default:
+			slog.InfoContext(ctx, "Tool call", append(attrs, "outcome", "ok")...)
+		}
+		return result, err
+	}
+}
+
+// errorCode returns the code from a failed result's {"error": {...}} payload
+func errorCode(result *mcp.CallToolResult) string {
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return ""
+	}
+	var payload struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &payload) != nil {
+		return ""
+	}
+	return payload.Error.Code
+}
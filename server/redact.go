@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// redactSecrets masks secrets in tool results, in the text and in the
+// structured content, unless reveal_secrets is set. When anything was
+// masked a note says so, so agents don't mistake a masked key for a
+// working one.
+func (s *TailscaleServer) redactSecrets(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+		toolResult, ok := result.(*mcp.CallToolResult)
+		if !ok || toolResult == nil || s.redactor == nil {
+			return result, err
+		}
+
+		redacted := 0
+		for _, content := range toolResult.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				var n int
+				text.Text, n = s.redactor.String(text.Text)
+				redacted += n
+			}
+		}
+		if toolResult.StructuredContent != nil {
+			if data, err := json.Marshal(toolResult.StructuredContent); err == nil {
+				if masked, n := s.redactor.String(string(data)); n > 0 {
+					toolResult.StructuredContent = json.RawMessage(masked)
+					redacted += n
+				}
+			}
+		}
+
+		if redacted > 0 {
+			toolResult.Content = append(toolResult.Content, &mcp.TextContent{Text: fmt.Sprintf(
+				"ℹ %d secret(s) in this result were redacted. Tools that create secrets can save them with secrets_file; set reveal_secrets in the server config to return them in full.", redacted)})
+		}
+		return result, err
+	}
+}
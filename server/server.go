@@ -1,11 +1,22 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/config"
 	"github.com/phildougherty/go-tailscale-mcp/k8s"
+	"github.com/phildougherty/go-tailscale-mcp/redact"
+	"github.com/phildougherty/go-tailscale-mcp/scheduler"
+	"github.com/phildougherty/go-tailscale-mcp/store"
 	"github.com/phildougherty/go-tailscale-mcp/tailscale"
 	"github.com/phildougherty/go-tailscale-mcp/tools"
 )
@@ -14,10 +25,32 @@ type TailscaleServer struct {
 	*mcp.Server
 	cli              *tailscale.CLI
 	api              *tailscale.APIClient
+	output           *tools.OutputWriter
+	store            *store.Store
+	scheduler        *scheduler.Scheduler
 	enableK8sOperator bool
+	enableSSHExec    bool
+	metricsTextfile  string
+	metricsInterval  time.Duration
+	watchInterval    time.Duration
+	cache            *tools.ResponseCache
+	auth             *authenticator
+	allowUnauthenticated bool
+	webhookAddr      string
+	webhooks         *tools.WebhookReceiver
+	dryRun           bool
+	confirmations    *confirmations
+	confirmGates     map[string]*previewSwitch
+	redactor         *redact.Redactor
+	tailnetTools     map[string]bool
+	pinnedTools      map[string]bool
+	profileTailnets  map[string]string
+
+	tailnetMu     sync.Mutex
+	activeTailnet string
 }
 
-func NewTailscaleServer(enableK8sOperator bool) (*TailscaleServer, error) {
+func NewTailscaleServer(cfg *config.Config) (*TailscaleServer, error) {
 	// Initialize the MCP server
 	server := mcp.NewServer(
 		&mcp.Implementation{
@@ -25,72 +58,388 @@ func NewTailscaleServer(enableK8sOperator bool) (*TailscaleServer, error) {
 			Version: "1.0.0",
 		},
 		&mcp.ServerOptions{
-			HasTools: true,
+			HasTools:           true,
+			// The SDK tracks subscriptions itself; any resource can be watched
+			SubscribeHandler:   func(context.Context, *mcp.SubscribeRequest) error { return nil },
+			UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { return nil },
 		},
 	)
 
 	// Create Tailscale CLI wrapper
 	cli := tailscale.NewCLI()
+	if cfg.TailscaleBin != "" {
+		if err := cli.SetBinaryPath(cfg.TailscaleBin); err != nil {
+			// The API tools still work; CLI tools report the missing binary
+			slog.Warn("Configured tailscale binary not found", "tailscale_bin", cfg.TailscaleBin, "error", err)
+		}
+	} else if _, err := cli.BinaryPath(); err != nil {
+		slog.Warn("tailscale binary not found, CLI tools will fail", "error", err,
+			"hint", "Install Tailscale, or set TAILSCALE_BIN to the tailscale binary")
+	}
+	if cfg.Socket != "" {
+		cli.SetSocket(cfg.Socket)
+	}
+	if cfg.LoginServer != "" {
+		cli.SetLoginServer(cfg.LoginServer)
+	}
+	if cfg.CLITimeout != "" {
+		if timeout, err := time.ParseDuration(cfg.CLITimeout); err == nil && timeout >= 0 {
+			cli.SetTimeout(timeout)
+		}
+	}
 
-	// Create API client if API key is provided
-	var apiClient *tailscale.APIClient
-	if apiKey := os.Getenv("TAILSCALE_API_KEY"); apiKey != "" {
-		var err error
+	// Create API client if OAuth client credentials or an API key are provided
+	apiClient, err := NewAPIClient(cfg)
+	if err != nil {
+		// Log error but continue without API
+		slog.Warn("Failed to initialize Tailscale API client", "error", err,
+			"hint", "Check TAILSCALE_API_KEY (or the OAuth client), or run 'tailscale-mcp setup' to create a config file interactively")
+	} else if apiClient != nil {
+		slog.Info("Tailscale API client initialized", "tailnet", cfg.Tailnet)
+	}
+	if apiClient != nil && apiClient.Tailnet() == "-" {
+		discoverTailnet(cli, apiClient)
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Tailnets)) {
+		if apiClient == nil {
+			slog.Warn("Ignoring tailnets: no API credentials for the default tailnet", "hint", "Set api_key (or an OAuth client), or set tailnet to one of the tailnets entries")
+			break
+		}
+		if name == apiClient.Tailnet() {
+			continue
+		}
+		creds := cfg.Tailnets[name]
+		client, err := newAPIClient(cfg, creds.APIKey, creds.OAuthClientID, creds.OAuthClientSecret, name)
+		if err != nil {
+			slog.Warn("Failed to initialize Tailscale API client", "tailnet", name, "error", err)
+			continue
+		}
+		apiClient.AddTailnet(name, client)
+	}
+	profileTailnets := map[string]string{}
+	for name, creds := range cfg.Tailnets {
+		if creds.Profile != "" {
+			profileTailnets[strings.ToLower(creds.Profile)] = name
+		}
+	}
 
-		// Check if tailnet is explicitly provided
-		if tailnet := os.Getenv("TAILSCALE_TAILNET"); tailnet != "" {
-			apiClient, err = tailscale.NewAPIClientWithTailnet(apiKey, tailnet)
+	statePath := cfg.StateFile
+	if statePath == "" {
+		statePath = config.DefaultStatePath()
+	}
+
+	metricsInterval := time.Minute
+	if cfg.MetricsInterval != "" {
+		interval, err := time.ParseDuration(cfg.MetricsInterval)
+		if err != nil || interval <= 0 {
+			slog.Warn("Invalid metrics_interval, using the default", "metrics_interval", cfg.MetricsInterval, "default", metricsInterval)
 		} else {
-			apiClient, err = tailscale.NewAPIClient(apiKey)
+			metricsInterval = interval
 		}
+	}
 
-		if err != nil {
-			// Log error but continue without API
-			fmt.Fprintf(os.Stderr, "Warning: Failed to initialize Tailscale API client: %v\n", err)
-			fmt.Fprintf(os.Stderr, "Hint: Set TAILSCALE_TAILNET environment variable to your tailnet domain (e.g., your-email@example.com)\n")
-		} else {
-			fmt.Fprintf(os.Stderr, "Tailscale API client initialized successfully\n")
+	watchInterval := 30 * time.Second
+	if cfg.WatchInterval != "" {
+		if interval, err := time.ParseDuration(cfg.WatchInterval); err == nil && interval > 0 {
+			watchInterval = interval
+		}
+	}
+
+	cacheTTL := 10 * time.Second
+	if cfg.CacheTTL != "" {
+		if ttl, err := time.ParseDuration(cfg.CacheTTL); err == nil && ttl >= 0 {
+			cacheTTL = ttl
 		}
 	}
 
+	auth, err := newAuthenticator(cfg, cli)
+	if err != nil {
+		return nil, err
+	}
+
 	ts := &TailscaleServer{
 		Server:           server,
 		cli:              cli,
 		api:              apiClient,
-		enableK8sOperator: enableK8sOperator,
+		output:           tools.NewOutputWriter(cfg.OutputDir),
+		store:            store.Open(statePath),
+		scheduler:        scheduler.New(),
+		enableK8sOperator: cfg.EnableK8sOperator,
+		enableSSHExec:    cfg.EnableSSHExec,
+		metricsTextfile:  cfg.MetricsTextfile,
+		metricsInterval:  metricsInterval,
+		watchInterval:    watchInterval,
+		cache:            tools.NewResponseCache(cacheTTL),
+		auth:             auth,
+		allowUnauthenticated: cfg.AllowUnauthenticated,
+		dryRun:           cfg.DryRun,
+		profileTailnets:  profileTailnets,
+	}
+	if cfg.ConfirmDestructive {
+		ts.confirmations = newConfirmations()
+	}
+	if !cfg.RevealSecrets {
+		literals := []string{cfg.APIKey, cfg.OAuthClientSecret, cfg.WebhookSecret}
+		for _, creds := range cfg.Tailnets {
+			literals = append(literals, creds.APIKey, creds.OAuthClientSecret)
+		}
+		if auth != nil {
+			literals = append(literals, auth.token)
+		}
+		ts.redactor = redact.New(literals...)
+	}
+	if cfg.WebhookListenAddr != "" {
+		ts.webhookAddr = cfg.WebhookListenAddr
+		ts.webhooks = tools.NewWebhookReceiver(server, cfg.WebhookSecret)
 	}
 
+	ts.AddReceivingMiddleware(ts.invalidateCache, logToolCalls, ts.redactSecrets, ts.requireConfirmation, ts.applyDryRunDefault, ts.applyTailnet)
+
 	// Register all tools
 	if err := ts.registerTools(); err != nil {
 		return nil, fmt.Errorf("failed to register tools: %w", err)
 	}
 
+	if len(cfg.EnabledTools) > 0 || len(cfg.DisabledTools) > 0 {
+		if err := ts.filterTools(cfg.EnabledTools, cfg.DisabledTools); err != nil {
+			return nil, fmt.Errorf("failed to filter tools: %w", err)
+		}
+	}
+	if ts.confirmations != nil {
+		gates, err := ts.confirmTools(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the tools that need confirmation: %w", err)
+		}
+		ts.confirmGates = gates
+	}
+
 	return ts, nil
 }
 
 func (s *TailscaleServer) registerTools() error {
 	// Register all Tailscale tool categories
 	tools.RegisterProfileTools(s.Server, s.cli)
-	tools.RegisterDeviceToolsWithAPI(s.Server, s.cli, s.api)
-	tools.RegisterNetworkTools(s.Server, s.cli)
+	tools.RegisterDeviceToolsWithAPI(s.Server, s.cli, s.api, s.cache)
+	tools.RegisterNetworkTools(s.Server, s.cli, s.cache)
 	tools.RegisterRoutingToolsWithAPI(s.Server, s.cli, s.api)
 	tools.RegisterSystemTools(s.Server, s.cli)
 	tools.RegisterDiagnosticTools(s.Server, s.cli)
+	tools.RegisterDoctorTools(s.Server, s.cli, s.api, s.enableK8sOperator)
+	tools.RegisterServeTools(s.Server, s.cli)
+	tools.RegisterTaildropTools(s.Server, s.cli, s.output)
+	tools.RegisterTailnetLockTools(s.Server, s.cli, s.output, s.redactor == nil)
+	tools.RegisterMetricsTools(s.Server, s.cli, s.api, s.output, s.metricsTextfile)
+	tools.RegisterIPv6Tools(s.Server, s.cli, s.api)
+	tools.RegisterConnectivityTools(s.Server, s.cli)
+	tools.RegisterDERPTools(s.Server, s.cli, s.store)
+	tools.RegisterTopologyTools(s.Server, s.cli)
+	tools.RegisterOverviewTools(s.Server, s.cli, s.api)
+	tools.RegisterControlPlaneTools(s.Server, s.cli)
+	tools.RegisterMeteredTools(s.Server, s.cli, s.store, s.scheduler)
+	tools.RegisterStatusResources(s.Server, s.cli, s.scheduler, s.watchInterval)
+	tools.RegisterPrompts(s.Server)
+	if s.webhooks != nil {
+		tools.RegisterWebhookEventTools(s.Server, s.webhooks)
+	}
+	if s.metricsTextfile != "" {
+		tools.ScheduleMetricsExport(s.cli, s.api, s.scheduler, s.metricsTextfile, s.metricsInterval)
+	}
+
+	// Register tools that run commands on devices if SSH execution is enabled
+	if s.enableSSHExec {
+		tools.RegisterFleetTools(s.Server, s.cli)
+		tools.RegisterSSHExecTools(s.Server, s.cli)
+	}
 
 	// Register API-specific tools if API is available
 	if s.api != nil && s.api.IsAvailable() {
-		tools.RegisterACLTools(s.Server, s.api)
-		tools.RegisterAuthKeyTools(s.Server, s.api)
-		tools.RegisterDNSAPITools(s.Server, s.api)
+		// These only use the API, so they take a tailnet argument
+		var err error
+		s.tailnetTools, err = s.registeredTools(func() {
+			tools.RegisterACLTools(s.Server, s.api, s.output)
+			tools.RegisterAuthKeyTools(s.Server, s.api, s.output)
+			tools.RegisterOAuthClientTools(s.Server, s.api, s.output)
+			tools.RegisterWebhookTools(s.Server, s.api, s.output)
+			tools.RegisterTailnetSettingsTools(s.Server, s.api)
+			tools.RegisterUserTools(s.Server, s.api)
+			tools.RegisterFlowLogTools(s.Server, s.api)
+			tools.RegisterBulkDeviceTools(s.Server, s.api)
+			tools.RegisterKeyExpiryTools(s.Server, s.api, s.output)
+			tools.RegisterStaleDeviceTools(s.Server, s.api)
+			tools.RegisterExportTools(s.Server, s.api, s.output)
+			tools.RegisterRouteTableTools(s.Server, s.api)
+			tools.RegisterServiceTools(s.Server, s.api)
+			tools.RegisterDNSAPITools(s.Server, s.api, s.cache)
+			tools.RegisterPostureTools(s.Server, s.api, s.output)
+			tools.RegisterGrantMigrationTools(s.Server, s.api)
+			tools.RegisterACLDiffTools(s.Server, s.api)
+			tools.RegisterACLEditTools(s.Server, s.api)
+			tools.RegisterACLPreviewTools(s.Server, s.api)
+			tools.RegisterACLTestTools(s.Server, s.api)
+			tools.RegisterACLLintTools(s.Server, s.api)
+			tools.RegisterACLSyncTools(s.Server, s.api, s.output)
+			tools.RegisterACLGroupTools(s.Server, s.api)
+			tools.RegisterACLTagOwnerTools(s.Server, s.api)
+			tools.RegisterACLHostTools(s.Server, s.api)
+			tools.RegisterACLNodeAttrTools(s.Server, s.api)
+			tools.RegisterACLGrantTools(s.Server, s.api)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register API tools: %w", err)
+		}
+		// These keep state for the configured tailnet, so they always use it
+		s.pinnedTools, err = s.registeredTools(func() {
+			tools.RegisterMaintenanceTools(s.Server, s.api, s.store, s.scheduler)
+			tools.RegisterAccessRequestTools(s.Server, s.api, s.store)
+			tools.RegisterTemporaryRuleTools(s.Server, s.api, s.store)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register API tools: %w", err)
+		}
+		tools.RegisterACLCanaryTools(s.Server, s.cli, s.api, s.enableSSHExec)
+		tools.RegisterACLSSHTools(s.Server, s.cli, s.api)
+		tools.ScheduleTemporaryRuleExpiry(s.Server, s.api, s.store, s.scheduler)
 	}
 
 	// Register Kubernetes operator tools if enabled
 	if s.enableK8sOperator {
-		if err := k8s.RegisterK8sOperatorTools(s.Server); err != nil {
+		if err := k8s.RegisterK8sOperatorTools(s.Server, s.api); err != nil {
 			return fmt.Errorf("failed to register Kubernetes operator tools: %w", err)
 		}
-		fmt.Fprintf(os.Stderr, "Kubernetes operator tools enabled\n")
+		slog.Info("Kubernetes operator tools enabled")
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// Run starts background jobs and serves MCP requests over the transport until
+// the client disconnects or ctx is cancelled
+func (s *TailscaleServer) Run(ctx context.Context, transport mcp.Transport) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if err := s.serveWebhooks(ctx); err != nil {
+		return err
+	}
+	s.scheduler.Start(ctx)
+	return s.Server.Run(ctx, transport)
+}
+
+// invalidateCache clears the response cache after calls to tools it doesn't
+// serve, since they may have changed the network (switched profiles, edited
+// DNS, removed a device, ...)
+func (s *TailscaleServer) invalidateCache(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+		if call, ok := req.(*mcp.CallToolRequest); ok && !slices.Contains(tools.CachedTools, call.Params.Name) {
+			s.cache.Clear()
+		}
+		return result, err
+	}
+}
+
+// filterTools removes registered tools that don't match enabled (when set)
+// or that match disabled. Patterns are tool names or path.Match globs.
+func (s *TailscaleServer) filterTools(enabled, disabled []string) error {
+	registered, err := s.ListTools(context.Background())
+	if err != nil {
+		return err
+	}
+
+	var removed []string
+	for _, tool := range registered {
+		if (len(enabled) > 0 && !matchesAny(enabled, tool.Name)) || matchesAny(disabled, tool.Name) {
+			removed = append(removed, tool.Name)
+		}
+	}
+	s.Server.RemoveTools(removed...)
+
+	if len(removed) > 0 {
+		slog.Info("Tool filter applied", "enabled", len(registered)-len(removed), "registered", len(registered))
+	}
+	return nil
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverTailnet names the API credentials' own tailnet when none is
+// configured. Requests work with "-" either way, but the name is what
+// profiles and the tailnets config are matched against.
+func discoverTailnet(cli *tailscale.CLI, api *tailscale.APIClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	status, err := cli.Status(ctx)
+	if err == nil {
+		var tailnet string
+		if tailnet, err = api.DiscoverTailnet(ctx, status); err == nil {
+			api.SetTailnet(tailnet)
+			slog.Info("Discovered the API credentials' tailnet", "tailnet", tailnet)
+			return
+		}
+	}
+	slog.Info("Using the API credentials' own tailnet without its name", "reason", err,
+		"hint", "Set TAILSCALE_TAILNET to name it")
+}
+
+// NewAPIClient creates the API client for cfg's default tailnet, from the
+// top-level OAuth client or API key, or else the credentials of cfg's
+// tailnet in the tailnets config. It returns a nil client when neither is
+// configured.
+func NewAPIClient(cfg *config.Config) (*tailscale.APIClient, error) {
+	var client *tailscale.APIClient
+	var err error
+	if cfg.OAuthClientID != "" || cfg.APIKey != "" {
+		client, err = newAPIClient(cfg, cfg.APIKey, cfg.OAuthClientID, cfg.OAuthClientSecret, cfg.Tailnet)
+	}
+	if creds, ok := cfg.Tailnets[cfg.Tailnet]; ok && client == nil {
+		// Without top-level credentials the configured tailnet's are the default
+		client, err = newAPIClient(cfg, creds.APIKey, creds.OAuthClientID, creds.OAuthClientSecret, cfg.Tailnet)
+	}
+	return client, err
+}
+
+// newAPIClient creates a client for the API at cfg's base URL with an OAuth
+// client or an API key, and cfg's API auth scheme, timeout and retries
+func newAPIClient(cfg *config.Config, apiKey, clientID, clientSecret, tailnet string) (*tailscale.APIClient, error) {
+	baseURL := tailscale.DefaultAPIBaseURL
+	if cfg.APIBaseURL != "" {
+		baseURL = cfg.APIBaseURL
+	}
+
+	var client *tailscale.APIClient
+	var err error
+	// OAuth clients don't expire, so prefer them over an API key
+	if clientID != "" {
+		client, err = tailscale.NewAPIClientWithOAuthAt(baseURL, clientID, clientSecret, tailnet)
+	} else {
+		// Without a tailnet, requests are for the key's own ("-")
+		client, err = tailscale.NewAPIClientWithTailnet(apiKey, tailnet)
+		if err == nil {
+			client.SetBaseURL(baseURL)
+		}
+	}
+	if err == nil {
+		err = client.SetAuthScheme(cfg.APIAuthScheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.APITimeout != "" {
+		if timeout, err := time.ParseDuration(cfg.APITimeout); err == nil && timeout > 0 {
+			client.SetTimeout(timeout)
+		}
+	}
+	if cfg.APIRetries != nil {
+		client.SetMaxRetries(*cfg.APIRetries)
+	}
+	return client, nil
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+var tailnetProperty = &jsonschema.Schema{
+	Type:        "string",
+	Description: "Tailnet to operate on instead of the current one (e.g., example.com), using its credentials from the tailnets config when it has them (optional)",
+}
+
+// registeredTools runs register and returns the names of the tools it
+// registered
+func (s *TailscaleServer) registeredTools(register func()) (map[string]bool, error) {
+	before, err := s.ListTools(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	for _, tool := range before {
+		existing[tool.Name] = true
+	}
+
+	register()
+
+	after, err := s.ListTools(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	added := map[string]bool{}
+	for _, tool := range after {
+		if !existing[tool.Name] {
+			added[tool.Name] = true
+		}
+	}
+	return added, nil
+}
+
+// applyTailnet picks the tailnet API requests are made for. Calls run
+// against the tailnet of the active profile (see followProfile), except for
+// the tailnetTools called with a tailnet argument, which is advertised on
+// the listed tools and removed before the tool sees it, and the
+// pinnedTools, whose stored state belongs to the configured tailnet.
+func (s *TailscaleServer) applyTailnet(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if list, ok := req.(*mcp.ListToolsRequest); ok && len(s.tailnetTools) > 0 {
+			result, err := next(ctx, method, list)
+			if tools, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+				s.addTailnetProperty(tools)
+			}
+			return result, err
+		}
+
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok || s.api == nil || s.pinnedTools[call.Params.Name] {
+			return next(ctx, method, req)
+		}
+		if call.Params.Name == "switch_profile" {
+			result, err := next(ctx, method, req)
+			if switched, ok := result.(*mcp.CallToolResult); ok && err == nil && switched != nil && !switched.IsError {
+				s.followProfile(ctx, switched)
+			}
+			return result, err
+		}
+
+		tailnet := s.currentTailnet()
+		if s.tailnetTools[call.Params.Name] {
+			args := map[string]json.RawMessage{}
+			if raw := bytes.TrimSpace(call.Params.Arguments); len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+				// Leave arguments that aren't an object for the tool to reject
+				if err := json.Unmarshal(raw, &args); err != nil {
+					return next(ctx, method, req)
+				}
+			}
+			if raw, set := args["tailnet"]; set {
+				var requested string
+				if err := json.Unmarshal(raw, &requested); err != nil {
+					return tailnetErrorResult("tailnet must be a string"), nil
+				}
+				if requested != "" {
+					tailnet = requested
+				}
+				delete(args, "tailnet")
+				data, err := json.Marshal(args)
+				if err != nil {
+					return nil, err
+				}
+				call.Params.Arguments = data
+			}
+		}
+
+		if tailnet != s.api.Tailnet() {
+			ctx = tailscale.WithTailnet(ctx, tailnet)
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// currentTailnet returns the tailnet API tools manage by default: the
+// configured one, or the one followProfile switched to
+func (s *TailscaleServer) currentTailnet() string {
+	s.tailnetMu.Lock()
+	defer s.tailnetMu.Unlock()
+	if s.activeTailnet != "" {
+		return s.activeTailnet
+	}
+	return s.api.Tailnet()
+}
+
+// followProfile points the API tools at the tailnet of the profile
+// switch_profile made active, when there are credentials for it. When
+// there aren't, the result and the log warn that the API tools still manage
+// the previous tailnet, so changes don't land in a tailnet the agent
+// believes it left.
+func (s *TailscaleServer) followProfile(ctx context.Context, result *mcp.CallToolResult) {
+	profiles, err := s.cli.ListProfiles(ctx)
+	if err != nil {
+		slog.Warn("Could not check the active profile after switching", "error", err)
+		return
+	}
+	var active *tailscale.Profile
+	for i := range profiles {
+		if profiles[i].Active {
+			active = &profiles[i]
+		}
+	}
+	if active == nil {
+		return
+	}
+
+	previous := s.currentTailnet()
+	tailnet, ok := s.profileTailnet(*active)
+	if !ok {
+		slog.Warn("Active profile has no API credentials, API tools still manage the previous tailnet",
+			"profile", active.ID, "profile_tailnet", active.Tailnet, "api_tailnet", previous,
+			"hint", "Add credentials for the tailnet under tailnets in the config file")
+		result.Content = append(result.Content, &mcp.TextContent{Text: fmt.Sprintf(
+			"⚠ No API credentials are configured for tailnet %s, so the API tools still manage %s. Add them under tailnets in the server config, or switch back before using API tools.", active.Tailnet, previous)})
+		return
+	}
+
+	s.tailnetMu.Lock()
+	s.activeTailnet = tailnet
+	s.tailnetMu.Unlock()
+	if tailnet != previous {
+		slog.Info("API tools switched tailnet with the profile", "profile", active.ID, "tailnet", tailnet)
+		result.Content = append(result.Content, &mcp.TextContent{Text: fmt.Sprintf("ℹ The API tools now manage tailnet %s.", tailnet)})
+	}
+}
+
+// profileTailnet returns the tailnet with API credentials that profile is
+// in: the one whose tailnets entry names the profile, or the one with the
+// profile's tailnet name
+func (s *TailscaleServer) profileTailnet(profile tailscale.Profile) (string, bool) {
+	for _, key := range []string{profile.ID, profile.Account} {
+		if tailnet, ok := s.profileTailnets[strings.ToLower(key)]; ok {
+			return tailnet, true
+		}
+	}
+	for _, tailnet := range append([]string{s.api.Tailnet()}, s.api.Tailnets()...) {
+		if strings.EqualFold(tailnet, profile.Tailnet) {
+			return tailnet, true
+		}
+	}
+	return "", false
+}
+
+// addTailnetProperty advertises tailnet on the listed tools that take it.
+// The tools are copied, since the server owns the originals.
+func (s *TailscaleServer) addTailnetProperty(result *mcp.ListToolsResult) {
+	for i, tool := range result.Tools {
+		if !s.tailnetTools[tool.Name] || tool.InputSchema == nil {
+			continue
+		}
+		copied := *tool
+		copied.InputSchema = tool.InputSchema.CloneSchemas()
+		if copied.InputSchema.Properties == nil {
+			copied.InputSchema.Properties = map[string]*jsonschema.Schema{}
+		}
+		copied.InputSchema.Properties["tailnet"] = tailnetProperty
+		result.Tools[i] = &copied
+	}
+}
+
+// tailnetErrorResult reports a tailnet argument that can't be used, in the
+// same {"error": {...}} shape as the tools' errors
+func tailnetErrorResult(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: message},
+		},
+		StructuredContent: map[string]interface{}{"error": map[string]interface{}{
+			"code":      "invalid_params",
+			"category":  "input",
+			"message":   message,
+			"retryable": false,
+		}},
+	}
+}
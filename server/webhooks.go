@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// serveWebhooks starts the webhook receiver's listener, if one is configured,
+// and stops it when ctx is cancelled. It only returns an error when the
+// listener can't be opened, so a bad address fails startup.
+func (s *TailscaleServer) serveWebhooks(ctx context.Context) error {
+	if s.webhooks == nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", s.webhookAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for webhooks on %s: %w", s.webhookAddr, err)
+	}
+	slog.Info("Receiving Tailscale webhooks", "url", fmt.Sprintf("http://%s/", listener.Addr()))
+
+	httpServer := &http.Server{
+		Handler:           s.webhooks,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("Webhook receiver stopped", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+	return nil
+}
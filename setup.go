@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/go-tailscale-mcp/config"
+	"github.com/phildougherty/go-tailscale-mcp/k8s"
+	"github.com/phildougherty/go-tailscale-mcp/server"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// runSetup walks the user through creating a config file interactively
+func runSetup(configPath string) error {
+	return setupWizard(os.Stdin, os.Stdout, configPath)
+}
+
+func setupWizard(in io.Reader, out io.Writer, configPath string) error {
+	reader := bufio.NewReader(in)
+
+	fmt.Fprintln(out, "=== Tailscale MCP Setup ===")
+	fmt.Fprintln(out)
+
+	// Start from the existing config so re-running setup keeps previous answers as defaults
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+
+	// Step 1: detect the local tailscale install
+	fmt.Fprintln(out, "Step 1: Checking local Tailscale installation...")
+	if path, err := tailscale.FindBinary(cfg.TailscaleBin); err != nil {
+		fmt.Fprintf(out, "  ⚠ %v\n", err)
+		fmt.Fprintln(out, "    Install Tailscale from https://tailscale.com/download before using the CLI tools, or set tailscale_bin to its path")
+	} else {
+		cli := tailscale.NewCLI()
+		cli.SetBinaryPath(path)
+		if cfg.Socket != "" {
+			cli.SetSocket(cfg.Socket)
+		}
+		ctx := context.Background()
+		version, err := cli.Version(ctx)
+		if err != nil {
+			fmt.Fprintf(out, "  ⚠ Found %s but could not get version: %v\n", path, err)
+		} else {
+			firstLine := strings.SplitN(version, "\n", 2)[0]
+			fmt.Fprintf(out, "  ✓ Found %s (version %s)\n", path, firstLine)
+		}
+
+		if status, err := cli.Status(ctx); err == nil {
+			fmt.Fprintf(out, "  ✓ tailscaled backend state: %s\n", status.BackendState)
+			if cfg.Tailnet == "" && status.CurrentTailnet != nil {
+				cfg.Tailnet = status.CurrentTailnet.Name
+			}
+		} else {
+			fmt.Fprintf(out, "  ⚠ Could not query tailscaled: %v\n", err)
+		}
+	}
+	fmt.Fprintln(out)
+
+	// Step 2: API credentials
+	fmt.Fprintln(out, "Step 2: Tailscale API credentials")
+	fmt.Fprintln(out, "  Create an API key at https://login.tailscale.com/admin/settings/keys")
+	fmt.Fprintln(out, "  Leave empty to run with CLI tools only.")
+	fmt.Fprintln(out, "  (For OAuth clients, set oauth_client_id and oauth_client_secret in the config file or TS_OAUTH_CLIENT_ID/TS_OAUTH_CLIENT_SECRET instead.)")
+
+	apiKey, err := prompt(reader, out, "  API key", maskSecret(cfg.APIKey))
+	if err != nil {
+		return err
+	}
+	if apiKey != maskSecret(cfg.APIKey) {
+		cfg.APIKey = apiKey
+	}
+
+	hasOAuth := cfg.OAuthClientID != ""
+	if hasOAuth {
+		fmt.Fprintf(out, "  ✓ Using OAuth client %s from the config file or environment\n", cfg.OAuthClientID)
+	}
+
+	if cfg.APIKey != "" || hasOAuth {
+		tailnet, err := prompt(reader, out, "  Tailnet (e.g., your-email@example.com or example.com; empty for the credentials' own tailnet)", cfg.Tailnet)
+		if err != nil {
+			return err
+		}
+		cfg.Tailnet = tailnet
+
+		// Step 3: test the credentials
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Step 3: Testing API credentials...")
+		if err := testAPICredentials(cfg); err != nil {
+			fmt.Fprintf(out, "  ✗ API test failed: %v\n", err)
+			keep, err := confirm(reader, out, "  Save these credentials anyway?", false)
+			if err != nil {
+				return err
+			}
+			if !keep {
+				return fmt.Errorf("aborted: API credentials could not be verified")
+			}
+		} else {
+			fmt.Fprintln(out, "  ✓ API credentials are valid")
+		}
+	}
+	fmt.Fprintln(out)
+
+	// Step 4: optional Kubernetes operator support
+	fmt.Fprintln(out, "Step 4: Kubernetes operator support (optional)")
+	enableK8s, err := confirm(reader, out, "  Enable Kubernetes operator tools?", cfg.EnableK8sOperator)
+	if err != nil {
+		return err
+	}
+	cfg.EnableK8sOperator = enableK8s
+
+	if enableK8s {
+		kubeconfig, err := prompt(reader, out, "  Kubeconfig path (empty for default)", cfg.Kubeconfig)
+		if err != nil {
+			return err
+		}
+		cfg.Kubeconfig = kubeconfig
+		if kubeconfig != "" {
+			os.Setenv("KUBECONFIG", kubeconfig)
+		}
+
+		if _, err := k8s.NewClient(); err != nil {
+			fmt.Fprintf(out, "  ⚠ Could not connect to the cluster: %v\n", err)
+			fmt.Fprintln(out, "    Kubernetes tools will be enabled but may fail until cluster access works")
+		} else {
+			fmt.Fprintln(out, "  ✓ Connected to Kubernetes cluster")
+		}
+	}
+	fmt.Fprintln(out)
+
+	// Step 5: write the config file
+	if err := config.Save(configPath, cfg); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "✓ Configuration written to %s\n", configPath)
+	fmt.Fprintln(out, "  Environment variables (TAILSCALE_API_KEY, TAILSCALE_TAILNET, ENABLE_K8S_OPERATOR) still override file values.")
+	return nil
+}
+
+// testAPICredentials verifies cfg's API credentials by listing devices
+// through the same client the server builds, so the base URL, auth scheme
+// and OAuth client are tested too
+func testAPICredentials(cfg *config.Config) error {
+	client, err := server.NewAPIClient(cfg)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return fmt.Errorf("no API credentials configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err = client.ListDevices(ctx)
+	return err
+}
+
+// prompt asks a question and returns the answer, or the default if the answer is empty
+func prompt(reader *bufio.Reader, out io.Writer, question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(out, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(out, "%s: ", question)
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return defaultValue, nil
+	}
+	return line, nil
+}
+
+// confirm asks a yes/no question
+func confirm(reader *bufio.Reader, out io.Writer, question string, defaultValue bool) (bool, error) {
+	hint := "y/N"
+	if defaultValue {
+		hint = "Y/n"
+	}
+
+	answer, err := prompt(reader, out, fmt.Sprintf("%s (%s)", question, hint), "")
+	if err != nil {
+		return false, err
+	}
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return config.ParseBool(answer) || strings.EqualFold(answer, "y"), nil
+}
+
+// maskSecret shortens a secret for display as a prompt default
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 12 {
+		return "****"
+	}
+	return secret[:12] + "..."
+}
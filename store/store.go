@@ -0,0 +1,126 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists small amounts of workflow state (maintenance windows, access
+// requests, temporary rules) in a single JSON file. Each workflow keeps its
+// records in a named bucket.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns a store backed by the file at path. The file is created on first save.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the location of the backing file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load decodes the bucket into v. A missing file or bucket leaves v unchanged.
+func (s *Store) Load(bucket string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets, err := s.read()
+	if err != nil {
+		return err
+	}
+	return decodeBucket(buckets, bucket, v)
+}
+
+// Save replaces the contents of the bucket with v
+func (s *Store) Save(bucket string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets, err := s.read()
+	if err != nil {
+		return err
+	}
+	return s.writeBucket(buckets, bucket, v)
+}
+
+// Update loads the bucket into v, calls fn to modify it, and saves the result.
+// The store is locked for the duration so concurrent updates don't race.
+// If fn returns an error nothing is saved.
+func (s *Store) Update(bucket string, v interface{}, fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets, err := s.read()
+	if err != nil {
+		return err
+	}
+	if err := decodeBucket(buckets, bucket, v); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return s.writeBucket(buckets, bucket, v)
+}
+
+func (s *Store) read() (map[string]json.RawMessage, error) {
+	buckets := make(map[string]json.RawMessage)
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return buckets, nil
+		}
+		return nil, fmt.Errorf("failed to read state file %s: %w", s.path, err)
+	}
+
+	if err := json.Unmarshal(data, &buckets); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", s.path, err)
+	}
+	return buckets, nil
+}
+
+func (s *Store) writeBucket(buckets map[string]json.RawMessage, bucket string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s state: %w", bucket, err)
+	}
+	buckets[bucket] = data
+
+	out, err := json.MarshalIndent(buckets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	// Write to a temp file and rename so a crash can't leave a truncated file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, out, 0600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+func decodeBucket(buckets map[string]json.RawMessage, bucket string, v interface{}) error {
+	data, ok := buckets[bucket]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s state: %w", bucket, err)
+	}
+	return nil
+}
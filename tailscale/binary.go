@@ -0,0 +1,97 @@
+package tailscale
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// BinaryNotFoundError is returned when the tailscale binary can't be found.
+// It matches exec.ErrNotFound with errors.Is, like a missing binary on PATH.
+type BinaryNotFoundError struct {
+	// Path is the configured binary, if there was one
+	Path string
+	// Searched lists the locations that were tried
+	Searched []string
+}
+
+func (e *BinaryNotFoundError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("tailscale binary %q not found", e.Path)
+	}
+	return fmt.Sprintf("tailscale binary not found on PATH or in %s", strings.Join(e.Searched, ", "))
+}
+
+func (e *BinaryNotFoundError) Unwrap() error {
+	return exec.ErrNotFound
+}
+
+// FindBinary returns the tailscale binary to run. A configured path (a file,
+// or a command name looked up on PATH) must exist. Otherwise tailscale is
+// looked up on PATH and then in the platform's install locations, since
+// MCP clients often start servers with a minimal PATH and the macOS app
+// doesn't put its CLI on PATH at all.
+func FindBinary(path string) (string, error) {
+	if path != "" {
+		if strings.ContainsRune(path, os.PathSeparator) || strings.ContainsRune(path, '/') {
+			if isExecutable(path) {
+				return path, nil
+			}
+		} else if found, err := exec.LookPath(path); err == nil {
+			return found, nil
+		}
+		return "", &BinaryNotFoundError{Path: path}
+	}
+
+	if found, err := exec.LookPath("tailscale"); err == nil {
+		return found, nil
+	}
+	candidates := binaryCandidates()
+	for _, candidate := range candidates {
+		if isExecutable(candidate) {
+			return candidate, nil
+		}
+	}
+	return "", &BinaryNotFoundError{Searched: candidates}
+}
+
+// binaryCandidates returns where Tailscale installs its CLI on this platform
+func binaryCandidates() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{
+			// The App Store and standalone apps run as the CLI when invoked this way
+			"/Applications/Tailscale.app/Contents/MacOS/Tailscale",
+			"/opt/homebrew/bin/tailscale",
+			"/usr/local/bin/tailscale",
+		}
+	case "windows":
+		var candidates []string
+		for _, env := range []string{"ProgramFiles", "ProgramW6432", "ProgramFiles(x86)"} {
+			if dir := os.Getenv(env); dir != "" {
+				candidate := filepath.Join(dir, "Tailscale", "tailscale.exe")
+				if !slices.Contains(candidates, candidate) {
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+		if len(candidates) == 0 {
+			candidates = append(candidates, `C:\Program Files\Tailscale\tailscale.exe`)
+		}
+		return candidates
+	default:
+		return []string{"/usr/bin/tailscale", "/usr/local/bin/tailscale", "/usr/sbin/tailscale"}
+	}
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
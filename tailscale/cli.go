@@ -2,41 +2,173 @@ package tailscale
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os/exec"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 )
 
-// CLI wraps the Tailscale CLI commands
+// DefaultCommandTimeout bounds each tailscale command unless SetTimeout or
+// WithCommandTimeout says otherwise. Commands are answered by tailscaled, so
+// one that runs this long is almost certainly hung.
+const DefaultCommandTimeout = time.Minute
+
+// updateTimeout bounds installing an update, which downloads a package
+const updateTimeout = 10 * time.Minute
+
+// killWaitDelay is how long a killed command's output is still read
+const killWaitDelay = 2 * time.Second
+
+// CLI wraps the Tailscale CLI commands. Read-only queries go through the
+// LocalAPI when its socket is available, falling back to the CLI otherwise.
 type CLI struct {
-	binaryPath string
+	binaryPath  string
+	binaryErr   error
+	timeout     time.Duration
+	socket      string
+	local       *LocalClient
+	loginServer string
 }
 
-// NewCLI creates a new Tailscale CLI wrapper
+// NewCLI creates a new Tailscale CLI wrapper for the tailscale binary found
+// by FindBinary. If there is none, commands fail with a BinaryNotFoundError.
 func NewCLI() *CLI {
-	return &CLI{
-		binaryPath: "tailscale",
+	c := &CLI{timeout: DefaultCommandTimeout, local: NewLocalClient(DefaultSocketPath)}
+	c.binaryPath, c.binaryErr = FindBinary("")
+	return c
+}
+
+// SetBinaryPath makes commands run path (a file, or a command name looked up
+// on PATH) instead of the detected binary. If it doesn't exist the error is
+// returned, and commands fail with it too.
+func (c *CLI) SetBinaryPath(path string) error {
+	c.binaryPath, c.binaryErr = FindBinary(path)
+	return c.binaryErr
+}
+
+// SetSocket makes commands and LocalAPI requests use tailscaled's socket at
+// path instead of the default, e.g. for a userspace-networking tailscaled or
+// one in a container
+func (c *CLI) SetSocket(path string) {
+	c.socket = path
+	c.local = NewLocalClient(path)
+}
+
+// Socket returns the socket set with SetSocket, if any
+func (c *CLI) Socket() string {
+	return c.socket
+}
+
+// BinaryPath returns the tailscale binary commands run, and the error
+// commands fail with when it wasn't found
+func (c *CLI) BinaryPath() (string, error) {
+	return c.binaryPath, c.binaryErr
+}
+
+// SetTimeout sets how long each command may run (DefaultCommandTimeout
+// unless set); 0 or less means no limit
+func (c *CLI) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+type commandTimeoutKey struct{}
+
+// WithCommandTimeout returns a context whose tailscale commands may run for
+// timeout instead of the CLI's default, for calls known to be slow (or
+// quick); 0 or less means no limit
+func WithCommandTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, commandTimeoutKey{}, timeout)
+}
+
+// allowAtLeast raises the command timeout to timeout for a command known to
+// be slow, unless the call set its own timeout or there is no limit
+func (c *CLI) allowAtLeast(ctx context.Context, timeout time.Duration) context.Context {
+	if _, ok := ctx.Value(commandTimeoutKey{}).(time.Duration); ok || c.timeout <= 0 || c.timeout >= timeout {
+		return ctx
 	}
+	return WithCommandTimeout(ctx, timeout)
+}
+
+// command is a tailscale command that is killed when the caller's context is
+// done or its timeout expires
+type command struct {
+	*exec.Cmd
+	args    []string
+	ctx     context.Context
+	timeout time.Duration
+	timer   context.Context
+	stop    context.CancelFunc
+}
+
+// command prepares a tailscale command, failing without running anything
+// when the binary is missing. Call stop once it has finished.
+func (c *CLI) command(ctx context.Context, args []string) (*command, error) {
+	if c.binaryErr != nil {
+		return nil, &CommandError{Args: args, Err: c.binaryErr}
+	}
+	timeout, ok := ctx.Value(commandTimeoutKey{}).(time.Duration)
+	if !ok {
+		timeout = c.timeout
+	}
+	cmd := &command{args: args, ctx: ctx, timeout: timeout}
+	if timeout > 0 {
+		cmd.timer, cmd.stop = context.WithTimeout(ctx, timeout)
+	} else {
+		cmd.timer, cmd.stop = context.WithCancel(ctx)
+	}
+	if c.socket != "" {
+		// --socket is a global flag, so it goes before the subcommand
+		args = append([]string{"--socket=" + c.socket}, args...)
+	}
+	cmd.Cmd = exec.CommandContext(cmd.timer, c.binaryPath, args...)
+	// Don't wait on output pipes held open by anything the command started
+	cmd.WaitDelay = killWaitDelay
+	return cmd, nil
+}
+
+// interrupted returns why a failed command was killed: the caller's context
+// was canceled or expired, or the command timed out. It returns nil when the
+// command failed on its own.
+func (cmd *command) interrupted() error {
+	if ctxErr := cmd.ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("command canceled: %w", ctxErr)
+	}
+	if cmd.timer.Err() != nil {
+		return &CommandTimeoutError{Args: cmd.args, Timeout: cmd.timeout}
+	}
+	return nil
 }
 
 // Execute runs a Tailscale CLI command and returns the output
-func (c *CLI) Execute(args ...string) (string, error) {
-	cmd := exec.Command(c.binaryPath, args...)
+func (c *CLI) Execute(ctx context.Context, args ...string) (string, error) {
+	cmd, err := c.command(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	defer cmd.stop()
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err = cmd.Run()
 	if err != nil {
-		return "", fmt.Errorf("command failed: %v, stderr: %s", err, stderr.String())
+		// Say why a killed command stopped rather than reporting the signal
+		if interrupted := cmd.interrupted(); interrupted != nil {
+			return "", interrupted
+		}
+		return "", &CommandError{Args: args, Stderr: stderr.String(), Err: err}
 	}
 
 	return strings.TrimSpace(stdout.String()), nil
 }
 
 // ExecuteJSON runs a Tailscale CLI command and parses JSON output
-func (c *CLI) ExecuteJSON(v interface{}, args ...string) error {
+func (c *CLI) ExecuteJSON(ctx context.Context, v interface{}, args ...string) error {
 	// Add --json flag if not present
 	hasJSON := false
 	for _, arg := range args {
@@ -49,7 +181,7 @@ func (c *CLI) ExecuteJSON(v interface{}, args ...string) error {
 		args = append(args, "--json")
 	}
 
-	output, err := c.Execute(args...)
+	output, err := c.Execute(ctx, args...)
 	if err != nil {
 		return err
 	}
@@ -62,151 +194,477 @@ func (c *CLI) ExecuteJSON(v interface{}, args ...string) error {
 }
 
 // Status returns the current Tailscale status
-func (c *CLI) Status() (*Status, error) {
+func (c *CLI) Status(ctx context.Context) (*Status, error) {
+	if c.local.Available() {
+		if status, err := c.local.Status(ctx); err == nil {
+			return status, nil
+		}
+	}
+
 	var status Status
-	err := c.ExecuteJSON(&status, "status")
+	err := c.ExecuteJSON(ctx, &status, "status")
 	return &status, err
 }
 
-// Login connects to Tailscale
-func (c *CLI) Login(authKey string, options map[string]string) error {
+// SetLoginServer makes Login use a self-hosted control server (e.g.,
+// Headscale) unless the options name one
+func (c *CLI) SetLoginServer(url string) {
+	c.loginServer = url
+}
+
+// LoginServer returns the control server set with SetLoginServer, if any
+func (c *CLI) LoginServer() string {
+	return c.loginServer
+}
+
+// Login connects to Tailscale. Options are passed as --name=value, so
+// boolean flags can be turned off.
+func (c *CLI) Login(ctx context.Context, authKey string, options map[string]string) error {
 	args := []string{"up"}
 
 	if authKey != "" {
 		args = append(args, "--authkey", authKey)
 	}
+	if _, ok := options["login-server"]; !ok && c.loginServer != "" {
+		args = append(args, "--login-server="+c.loginServer)
+	}
 
 	for key, value := range options {
-		args = append(args, fmt.Sprintf("--%s", key), value)
+		args = append(args, fmt.Sprintf("--%s=%s", key, value))
 	}
 
-	_, err := c.Execute(args...)
+	_, err := c.Execute(ctx, args...)
 	return err
 }
 
 // Logout disconnects from Tailscale
-func (c *CLI) Logout() error {
-	_, err := c.Execute("logout")
+func (c *CLI) Logout(ctx context.Context) error {
+	_, err := c.Execute(ctx, "logout")
 	return err
 }
 
 // Down disconnects from the network but stays logged in
-func (c *CLI) Down() error {
-	_, err := c.Execute("down")
+func (c *CLI) Down(ctx context.Context) error {
+	_, err := c.Execute(ctx, "down")
 	return err
 }
 
 // SwitchProfile switches to a different Tailscale profile
-func (c *CLI) SwitchProfile(profile string) error {
-	_, err := c.Execute("switch", profile)
+func (c *CLI) SwitchProfile(ctx context.Context, profile string) error {
+	_, err := c.Execute(ctx, "switch", profile)
 	return err
 }
 
-// ListProfiles lists all available profiles
-func (c *CLI) ListProfiles() ([]Profile, error) {
-	output, err := c.Execute("switch", "--list")
+// ListProfiles lists all available profiles, from the LocalAPI or `tailscale
+// switch --list --json`. Clients too old for --json fall back to parsing the
+// table output.
+func (c *CLI) ListProfiles(ctx context.Context) ([]Profile, error) {
+	if c.local.Available() {
+		if profiles, err := c.local.Profiles(ctx); err == nil {
+			return profiles, nil
+		}
+	}
+
+	output, err := c.Execute(ctx, "switch", "--list", "--json")
+	if err == nil {
+		if profiles, err := parseProfilesJSON(output); err == nil {
+			return profiles, nil
+		}
+	} else if !isUnknownFlag(err) {
+		return nil, err
+	}
+
+	output, err = c.Execute(ctx, "switch", "--list")
 	if err != nil {
 		return nil, err
 	}
+	return parseProfileTable(output), nil
+}
 
-	// Parse the table output
-	// Format: ID    Tailnet                   Account
-	//         826b  phil.dougherty@gmail.com  phil.dougherty@gmail.com*
+// cliProfile is a profile from `tailscale switch --list --json`. Both the
+// CLI's own field names and the LocalAPI LoginProfile shape are accepted.
+type cliProfile struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Tailnet        string `json:"tailnet"`
+	Account        string `json:"account"`
+	Selected       bool   `json:"selected"`
+	Active         bool   `json:"active"`
+	NetworkProfile struct {
+		DomainName string `json:"DomainName"`
+	} `json:"NetworkProfile"`
+	UserProfile struct {
+		LoginName string `json:"LoginName"`
+	} `json:"UserProfile"`
+}
+
+func parseProfilesJSON(output string) ([]Profile, error) {
+	var listed []cliProfile
+	if err := json.Unmarshal([]byte(output), &listed); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles: %w", err)
+	}
+
+	profiles := make([]Profile, 0, len(listed))
+	for _, p := range listed {
+		if p.ID == "" {
+			return nil, fmt.Errorf("failed to parse profiles: profile without an ID")
+		}
+		profile := Profile{ID: p.ID, Tailnet: p.Tailnet, Account: p.Account, Active: p.Selected || p.Active}
+		if profile.Tailnet == "" {
+			profile.Tailnet = p.NetworkProfile.DomainName
+		}
+		if profile.Tailnet == "" {
+			profile.Tailnet = p.Name
+		}
+		if profile.Account == "" {
+			profile.Account = p.UserProfile.LoginName
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// parseProfileTable parses `tailscale switch --list` output:
+//
+//	ID    Tailnet                   Account
+//	826b  phil.dougherty@gmail.com  phil.dougherty@gmail.com*
+//
+// Columns are cut at the header's offsets, so values containing spaces stay
+// whole; the active profile's account is marked with *.
+func parseProfileTable(output string) []Profile {
 	profiles := []Profile{}
 	lines := strings.Split(output, "\n")
+	if len(lines) == 0 {
+		return profiles
+	}
+	// Offsets are in runes, since that is how the table is aligned
+	header := []rune(lines[0])
+	tailnetCol := runeIndex(header, "Tailnet")
+	accountCol := runeIndex(header, "Account")
 
-	for i, line := range lines {
-		// Skip header line and empty lines
-		if i == 0 || strings.TrimSpace(line) == "" {
+	for _, text := range lines[1:] {
+		if strings.TrimSpace(text) == "" {
 			continue
 		}
 
-		// Split by whitespace and reconstruct fields
-		fields := strings.Fields(line)
-		if len(fields) < 3 {
-			continue
+		line := []rune(text)
+		var id, tailnet, account string
+		if tailnetCol > 0 && accountCol > tailnetCol && len(line) > accountCol {
+			id = strings.TrimSpace(string(line[:tailnetCol]))
+			tailnet = strings.TrimSpace(string(line[tailnetCol:accountCol]))
+			account = strings.TrimSpace(string(line[accountCol:]))
+		} else {
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				continue
+			}
+			id, tailnet, account = fields[0], fields[1], strings.Join(fields[2:], " ")
 		}
-
-		// Extract ID and tailnet
-		id := fields[0]
-		tailnet := fields[1]
-
-		// Extract account and check if it's active (marked with *)
-		account := fields[2]
-		active := false
-		if strings.HasSuffix(account, "*") {
-			active = true
-			account = strings.TrimSuffix(account, "*")
+		if id == "" {
+			continue
 		}
 
+		active := strings.HasSuffix(account, "*")
 		profiles = append(profiles, Profile{
 			ID:      id,
 			Tailnet: tailnet,
-			Account: account,
+			Account: strings.TrimSuffix(account, "*"),
 			Active:  active,
 		})
 	}
 
-	return profiles, nil
+	return profiles
+}
+
+// runeIndex returns the rune offset of substr in s, or -1
+func runeIndex(s []rune, substr string) int {
+	i := strings.Index(string(s), substr)
+	if i < 0 {
+		return -1
+	}
+	return len([]rune(string(s)[:i]))
 }
 
 // Ping pings a peer device
-func (c *CLI) Ping(target string, count int) (string, error) {
+func (c *CLI) Ping(ctx context.Context, target string, count int) (string, error) {
+	if output, ok := c.localPing(ctx, target, count); ok {
+		return output, nil
+	}
+
 	args := []string{"ping", target}
 	if count > 0 {
 		args = append(args, "-c", fmt.Sprintf("%d", count))
 	}
-	return c.Execute(args...)
+	return c.Execute(ctx, args...)
+}
+
+// localPing pings target through the LocalAPI, mirroring `tailscale ping`:
+// without a count it stops at the first direct pong (at most 10 pings). It
+// reports false if the LocalAPI is unavailable or target isn't a known peer.
+func (c *CLI) localPing(ctx context.Context, target string, count int) (string, bool) {
+	results, ok := c.localPingResults(ctx, target, count)
+	if !ok {
+		return "", false
+	}
+	lines := make([]string, len(results))
+	for i, result := range results {
+		lines[i] = result.String()
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// findPeer returns the peer whose hostname or MagicDNS name (short or full) is name
+func findPeer(status *Status, name string) *PeerStatus {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	for _, peer := range status.Peer {
+		if peer == nil {
+			continue
+		}
+		dnsName := strings.TrimSuffix(strings.ToLower(peer.DNSName), ".")
+		short, _, _ := strings.Cut(dnsName, ".")
+		if strings.ToLower(peer.HostName) == name || dnsName == name || short == name {
+			return peer
+		}
+	}
+	return nil
+}
+
+// Prefs returns the node's current preferences
+func (c *CLI) Prefs(ctx context.Context) (*Prefs, error) {
+	if c.local.Available() {
+		if prefs, err := c.local.Prefs(ctx); err == nil {
+			return prefs, nil
+		}
+	}
+
+	output, err := c.Execute(ctx, "debug", "prefs")
+	if err != nil {
+		return nil, err
+	}
+	var prefs Prefs
+	if err := json.Unmarshal([]byte(output), &prefs); err != nil {
+		return nil, fmt.Errorf("failed to parse prefs: %w", err)
+	}
+	return &prefs, nil
 }
 
 // Version returns Tailscale version information
-func (c *CLI) Version() (string, error) {
-	return c.Execute("version")
+func (c *CLI) Version(ctx context.Context) (string, error) {
+	return c.Execute(ctx, "version")
+}
+
+// Update runs `tailscale update` non-interactively. With dryRun it only
+// reports what would be installed. track (stable or unstable) and version
+// are optional. The command prints its progress to both stdout and stderr,
+// so the combined output is returned.
+func (c *CLI) Update(ctx context.Context, dryRun bool, track, version string) (string, error) {
+	args := []string{"update", "--yes"}
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	if track != "" {
+		args = append(args, "--track="+track)
+	}
+	if version != "" {
+		args = append(args, "--version="+version)
+	}
+
+	if !dryRun {
+		ctx = c.allowAtLeast(ctx, updateTimeout)
+	}
+	cmd, err := c.command(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	defer cmd.stop()
+	output, err := cmd.CombinedOutput()
+	out := strings.TrimSpace(string(output))
+	if err != nil {
+		if interrupted := cmd.interrupted(); interrupted != nil {
+			return out, interrupted
+		}
+		return out, fmt.Errorf("command failed: %v, output: %s", err, out)
+	}
+	return out, nil
 }
 
 // IP returns the Tailscale IP addresses
-func (c *CLI) IP(device string) (string, error) {
+func (c *CLI) IP(ctx context.Context, device string) (string, error) {
 	args := []string{"ip"}
 	if device != "" {
 		args = append(args, device)
 	}
-	return c.Execute(args...)
+	return c.Execute(ctx, args...)
 }
 
 // SetExitNode sets the exit node
-func (c *CLI) SetExitNode(node string) error {
-	_, err := c.Execute("set", "--exit-node", node)
+func (c *CLI) SetExitNode(ctx context.Context, node string) error {
+	_, err := c.Execute(ctx, "set", "--exit-node", node)
 	return err
 }
 
+// ExitNodeList lists exit nodes with `tailscale exit-node list`. Without a
+// filter, Mullvad exit nodes are shown as the best node per city; with a
+// country filter, every node in that country is listed.
+func (c *CLI) ExitNodeList(ctx context.Context, country string) ([]ExitNodeListEntry, error) {
+	args := []string{"exit-node", "list"}
+	if country != "" {
+		args = append(args, "--filter="+country)
+	}
+	output, err := c.Execute(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseExitNodeList(output), nil
+}
+
+// exitNodeColumnSep separates the columns of `tailscale exit-node list`
+var exitNodeColumnSep = regexp.MustCompile(`\s{2,}`)
+
+// parseExitNodeList parses the exit-node list table. Columns are separated by
+// runs of spaces; "-" marks an empty cell and "#" lines are usage hints.
+func parseExitNodeList(output string) []ExitNodeListEntry {
+	var entries []ExitNodeListEntry
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "IP ") {
+			continue
+		}
+		fields := exitNodeColumnSep.Split(line, -1)
+		if len(fields) < 5 {
+			continue
+		}
+		for i, field := range fields {
+			if field == "-" {
+				fields[i] = ""
+			}
+		}
+		entries = append(entries, ExitNodeListEntry{
+			IP:       fields[0],
+			Hostname: fields[1],
+			Country:  fields[2],
+			City:     fields[3],
+			Status:   fields[4],
+		})
+	}
+	return entries
+}
+
 // ClearExitNode clears the exit node
-func (c *CLI) ClearExitNode() error {
-	_, err := c.Execute("set", "--exit-node=")
+func (c *CLI) ClearExitNode(ctx context.Context) error {
+	_, err := c.Execute(ctx, "set", "--exit-node=")
+	return err
+}
+
+// ServeTCP forwards raw TCP connections to port on the tailnet to target
+// (tcp://host:port) in the background. With tlsTerminated, TLS is terminated
+// with the node's certificate before forwarding.
+func (c *CLI) ServeTCP(ctx context.Context, port int, target string, tlsTerminated bool) error {
+	flag := "--tcp"
+	if tlsTerminated {
+		flag = "--tls-terminated-tcp"
+	}
+	_, err := c.Execute(ctx, "serve", "--bg", fmt.Sprintf("%s=%d", flag, port), target)
+	return err
+}
+
+// ServeTCPOff removes the TCP forwarder on port
+func (c *CLI) ServeTCPOff(ctx context.Context, port int, tlsTerminated bool) error {
+	flag := "--tcp"
+	if tlsTerminated {
+		flag = "--tls-terminated-tcp"
+	}
+	_, err := c.Execute(ctx, "serve", fmt.Sprintf("%s=%d", flag, port), "off")
+	return err
+}
+
+// WaitingFiles lists received Taildrop files. The CLI can only save them, not
+// list them, so this needs the LocalAPI.
+func (c *CLI) WaitingFiles(ctx context.Context) ([]WaitingFile, error) {
+	if !c.local.Available() {
+		return nil, fmt.Errorf("listing received files needs the tailscaled LocalAPI socket, which isn't available")
+	}
+	return c.local.WaitingFiles(ctx)
+}
+
+// SaveWaitingFile saves one received Taildrop file to w through the LocalAPI
+func (c *CLI) SaveWaitingFile(ctx context.Context, name string, w io.Writer) error {
+	if !c.local.Available() {
+		return fmt.Errorf("saving a single file needs the tailscaled LocalAPI socket, which isn't available")
+	}
+	return c.local.SaveFile(ctx, name, w)
+}
+
+// FileGet moves all received Taildrop files into dir with `tailscale file
+// get`. conflict is what to do when a file exists: skip, overwrite or rename.
+func (c *CLI) FileGet(ctx context.Context, dir, conflict string) (string, error) {
+	return c.Execute(ctx, "file", "get", "--conflict="+conflict, dir)
+}
+
+// Set changes preferences with `tailscale set`. flags maps flag names
+// (without dashes) to values, which are passed as --name=value so boolean
+// flags can be turned off.
+func (c *CLI) Set(ctx context.Context, flags map[string]string) error {
+	if len(flags) == 0 {
+		return fmt.Errorf("no preferences specified")
+	}
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := []string{"set"}
+	for _, name := range names {
+		args = append(args, fmt.Sprintf("--%s=%s", name, flags[name]))
+	}
+	_, err := c.Execute(ctx, args...)
+	return err
+}
+
+// SetAdvertiseTags changes the tags this node advertises. `tailscale set`
+// has no flag for them and `tailscale up` needs every other non-default flag
+// restated, so this edits the preferences through the LocalAPI.
+func (c *CLI) SetAdvertiseTags(ctx context.Context, tags []string) error {
+	if !c.local.Available() {
+		return fmt.Errorf("changing advertised tags needs the tailscaled LocalAPI socket, which isn't available")
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	_, err := c.local.EditPrefs(ctx, map[string]interface{}{
+		"AdvertiseTags":    tags,
+		"AdvertiseTagsSet": true,
+	})
 	return err
 }
 
 // AdvertiseRoutes advertises routes
-func (c *CLI) AdvertiseRoutes(routes []string) error {
+func (c *CLI) AdvertiseRoutes(ctx context.Context, routes []string) error {
 	if len(routes) == 0 {
 		return fmt.Errorf("no routes specified")
 	}
-	_, err := c.Execute("set", "--advertise-routes", strings.Join(routes, ","))
+	_, err := c.Execute(ctx, "set", "--advertise-routes", strings.Join(routes, ","))
 	return err
 }
 
 // AcceptRoutes enables accepting routes from peers
-func (c *CLI) AcceptRoutes(accept bool) error {
+func (c *CLI) AcceptRoutes(ctx context.Context, accept bool) error {
 	value := "false"
 	if accept {
 		value = "true"
 	}
-	_, err := c.Execute("set", "--accept-routes", value)
+	_, err := c.Execute(ctx, "set", "--accept-routes", value)
 	return err
 }
 
 // LoginNewProfile logs in with a new profile
-func (c *CLI) LoginNewProfile() (string, error) {
+func (c *CLI) LoginNewProfile(ctx context.Context) (string, error) {
 	// This will start the login process and return the auth URL
-	output, err := c.Execute("login")
+	args := []string{"login"}
+	if c.loginServer != "" {
+		args = append(args, "--login-server="+c.loginServer)
+	}
+	output, err := c.Execute(ctx, args...)
 	return output, err
 }
\ No newline at end of file
@@ -0,0 +1,75 @@
+package tailscale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIError is returned when the Tailscale API answers with an error status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Body)
+}
+
+// CommandError is returned when a tailscale CLI command fails. Err is the
+// exec error (an exit status, or one matching exec.ErrNotFound when the
+// binary is missing).
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("command failed: %v", e.Err)
+	}
+	return fmt.Sprintf("command failed: %v, stderr: %s", e.Err, e.Stderr)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Command returns the command line that failed, e.g. "tailscale set --exit-node=foo"
+func (e *CommandError) Command() string {
+	return commandLine(e.Args)
+}
+
+// CommandTimeoutError is returned when a tailscale command was killed for
+// running longer than its timeout. It matches context.DeadlineExceeded.
+type CommandTimeoutError struct {
+	Args    []string
+	Timeout time.Duration
+}
+
+func (e *CommandTimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.Command(), e.Timeout)
+}
+
+func (e *CommandTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// Command returns the command line that timed out
+func (e *CommandTimeoutError) Command() string {
+	return commandLine(e.Args)
+}
+
+func commandLine(args []string) string {
+	return strings.TrimSpace("tailscale " + strings.Join(args, " "))
+}
+
+// isUnknownFlag reports whether a command failed because this tailscale
+// version doesn't have one of its flags
+func isUnknownFlag(err error) bool {
+	var cmdErr *CommandError
+	return errors.As(err, &cmdErr) && strings.Contains(cmdErr.Stderr, "flag provided but not defined")
+}
@@ -0,0 +1,369 @@
+package tailscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+// PolicyGrant is a rule in the policy file's "grants" section. A grant gives
+// network access (IP) and/or application capabilities (App) to Src on Dst.
+type PolicyGrant struct {
+	Src []string `json:"src"`
+	Dst []string `json:"dst"`
+	// IP lists the allowed ports and protocols: "*", "443", "8000-8099",
+	// "tcp:443" or "icmp:*"
+	IP []string `json:"ip,omitempty"`
+	// App maps capability names ("example.com/cap/admin") to the parameters
+	// passed to the destination application
+	App        map[string][]json.RawMessage `json:"app,omitempty"`
+	SrcPosture []string                     `json:"srcPosture,omitempty"`
+	Via        []string                     `json:"via,omitempty"`
+}
+
+// Validate checks the grant's structure and ip syntax, catching mistakes
+// with a clearer message than the control plane gives
+func (g PolicyGrant) Validate() error {
+	if len(g.Src) == 0 {
+		return fmt.Errorf("src is required")
+	}
+	if len(g.Dst) == 0 {
+		return fmt.Errorf("dst is required")
+	}
+	if len(g.IP) == 0 && len(g.App) == 0 {
+		return fmt.Errorf("a grant needs ip or app (or both)")
+	}
+	for _, ip := range g.IP {
+		if err := validateGrantIP(ip); err != nil {
+			return err
+		}
+	}
+	for capability, params := range g.App {
+		if !strings.Contains(capability, "/") {
+			return fmt.Errorf("app capability %q must be a domain-qualified name like example.com/cap/name", capability)
+		}
+		for _, param := range params {
+			if !strings.HasPrefix(strings.TrimSpace(string(param)), "{") {
+				return fmt.Errorf("app capability %q parameters must be objects", capability)
+			}
+		}
+	}
+	return nil
+}
+
+// validateGrantIP checks an ip entry: "*", or a port or port range, optionally
+// prefixed with a protocol name or number
+func validateGrantIP(ip string) error {
+	ports := ip
+	if proto, rest, ok := strings.Cut(ip, ":"); ok {
+		if proto == "" || (!grantProtocols[proto] && !isNumber(proto)) {
+			return fmt.Errorf("ip %q has an unknown protocol %q", ip, proto)
+		}
+		ports = rest
+	}
+	if ports == "*" {
+		return nil
+	}
+	low, high, isRange := strings.Cut(ports, "-")
+	if !isPort(low) || (isRange && !isPort(high)) {
+		return fmt.Errorf("ip %q must be *, a port, or a port range like 8000-8099", ip)
+	}
+	return nil
+}
+
+// grantProtocols are the protocol names accepted in grant ip entries
+var grantProtocols = map[string]bool{"tcp": true, "udp": true, "icmp": true, "sctp": true, "igmp": true, "ipv6-icmp": true, "gre": true, "esp": true, "ah": true}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isPort(s string) bool {
+	if !isNumber(s) || len(s) > 5 {
+		return false
+	}
+	n := 0
+	for _, r := range s {
+		n = n*10 + int(r-'0')
+	}
+	return n <= 65535
+}
+
+// Grants returns the entries of the "grants" section
+func (p *Policy) Grants() ([]PolicyGrant, error) {
+	var policy struct {
+		Grants []PolicyGrant `json:"grants"`
+	}
+	if err := p.Decode(&policy); err != nil {
+		return nil, err
+	}
+	return policy.Grants, nil
+}
+
+// GrantProblems validates every grant, returning one message per invalid grant
+func (p *Policy) GrantProblems() ([]string, error) {
+	grants, err := p.Grants()
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for i, grant := range grants {
+		if err := grant.Validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("grants[%d]: %v", i, err))
+		}
+	}
+	return problems, nil
+}
+
+// AddGrant appends grant to the "grants" section, creating it if needed. A
+// non-empty comment is written on the line above the new grant.
+func (p *Policy) AddGrant(grant PolicyGrant, comment string) error {
+	if err := grant.Validate(); err != nil {
+		return err
+	}
+	return p.appendEntry("grants", grant, comment)
+}
+
+// RemoveGrant deletes grants[i] along with its leading comment
+func (p *Policy) RemoveGrant(i int) error {
+	found := p.value.Find("/grants")
+	if found == nil {
+		return fmt.Errorf("the policy has no grants")
+	}
+	grants, ok := found.Value.(*hujson.Array)
+	if !ok {
+		return fmt.Errorf("grants is not a list")
+	}
+	if i < 0 || i >= len(grants.Elements) {
+		return fmt.Errorf("no grant at index %d (the policy has %d)", i, len(grants.Elements))
+	}
+	removeElement(grants, i)
+	p.value.Format()
+	return nil
+}
+
+// GrantMigration summarizes a conversion of "acls" rules into "grants"
+type GrantMigration struct {
+	// Converted is the number of acls rules that were replaced by grants
+	Converted int
+	// Grants is the number of grants written (a rule whose destinations use
+	// different ports becomes one grant per port set)
+	Grants int
+	// Manual lists rules left in "acls" because they can't be converted mechanically
+	Manual []string
+}
+
+// aclRuleFields are the rule keys the converter understands
+var aclRuleFields = map[string]bool{
+	"action":     true,
+	"src":        true,
+	"dst":        true,
+	"proto":      true,
+	"srcPosture": true,
+}
+
+// MigrateACLsToGrants replaces each "acls" rule with equivalent "grants"
+// entries, carrying the rule's comments over. Rules that can't be converted
+// mechanically stay in "acls" and are reported in the result. The "acls"
+// section is removed once it is empty.
+func (p *Policy) MigrateACLsToGrants() (*GrantMigration, error) {
+	migration := &GrantMigration{}
+
+	found := p.value.Find("/acls")
+	if found == nil {
+		return migration, nil
+	}
+	acls, ok := found.Value.(*hujson.Array)
+	if !ok {
+		return nil, fmt.Errorf("acls must be an array")
+	}
+
+	type conversion struct {
+		index   int
+		grants  []PolicyGrant
+		comment hujson.Extra
+	}
+	var conversions []conversion
+
+	for i, elem := range acls.Elements {
+		grants, problem := convertACLRule(elem)
+		if problem != "" {
+			migration.Manual = append(migration.Manual, fmt.Sprintf("acls[%d]: %s", i, problem))
+			continue
+		}
+		// Comments on the lines above the rule move with it; a comment on the
+		// same line as the previous element stays where it is
+		var comment hujson.Extra
+		if j := strings.Index(string(elem.BeforeExtra), "\n"); j >= 0 {
+			comment = append(hujson.Extra(nil), elem.BeforeExtra[j:]...)
+		}
+		// A comment trailing the rule on its own line is stored before the
+		// next element (or the closing bracket); move it above the grant
+		next := &acls.AfterExtra
+		if i+1 < len(acls.Elements) {
+			next = &acls.Elements[i+1].BeforeExtra
+		}
+		if j := strings.Index(string(*next), "\n"); j >= 0 {
+			if trailing := strings.TrimSpace(string((*next)[:j])); trailing != "" {
+				*next = append(hujson.Extra(nil), (*next)[j:]...)
+				if len(comment) == 0 {
+					comment = hujson.Extra("\n")
+				}
+				comment = append(comment, trailing+"\n"...)
+			}
+		}
+		conversions = append(conversions, conversion{index: i, grants: grants, comment: comment})
+	}
+
+	if len(conversions) == 0 {
+		return migration, nil
+	}
+
+	var newGrants []hujson.Value
+	for _, c := range conversions {
+		for j, grant := range c.grants {
+			data, err := json.Marshal(grant)
+			if err != nil {
+				return nil, err
+			}
+			value, err := hujson.Parse(data)
+			if err != nil {
+				return nil, err
+			}
+			if j == 0 {
+				value.BeforeExtra = c.comment
+			}
+			newGrants = append(newGrants, value)
+		}
+	}
+
+	// Remove converted rules from the end so indices stay valid
+	for k := len(conversions) - 1; k >= 0; k-- {
+		removeElement(acls, conversions[k].index)
+	}
+
+	if p.value.Find("/grants") == nil {
+		if err := p.Patch([]PatchOp{{Op: "add", Path: "/grants", Value: []interface{}{}}}); err != nil {
+			return nil, err
+		}
+	}
+	grants, ok := p.value.Find("/grants").Value.(*hujson.Array)
+	if !ok {
+		return nil, fmt.Errorf("grants must be an array")
+	}
+	grants.Elements = append(grants.Elements, newGrants...)
+
+	if acls, ok := p.value.Find("/acls").Value.(*hujson.Array); ok && len(acls.Elements) == 0 {
+		if err := p.Patch([]PatchOp{{Op: "remove", Path: "/acls"}}); err != nil {
+			return nil, err
+		}
+	}
+	p.value.Format()
+
+	migration.Converted = len(conversions)
+	migration.Grants = len(newGrants)
+	return migration, nil
+}
+
+// convertACLRule converts one acls rule into grants, or explains why it can't be
+func convertACLRule(elem hujson.Value) ([]PolicyGrant, string) {
+	value := elem.Clone()
+	value.Standardize()
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(value.Pack(), &fields); err != nil {
+		return nil, "rule is not an object"
+	}
+	for key := range fields {
+		if !aclRuleFields[key] {
+			return nil, fmt.Sprintf("uses %q, which has no direct grants equivalent (legacy or unknown syntax)", key)
+		}
+	}
+
+	var rule struct {
+		Action     string   `json:"action"`
+		Src        []string `json:"src"`
+		Dst        []string `json:"dst"`
+		Proto      string   `json:"proto"`
+		SrcPosture []string `json:"srcPosture"`
+	}
+	if err := json.Unmarshal(value.Pack(), &rule); err != nil {
+		return nil, fmt.Sprintf("could not be decoded: %v", err)
+	}
+	if rule.Action != "accept" {
+		return nil, fmt.Sprintf("action %q is not supported by grants (only accept)", rule.Action)
+	}
+	if len(rule.Src) == 0 || len(rule.Dst) == 0 {
+		return nil, "src and dst are required"
+	}
+
+	// Grants apply their ip list to every destination, so destinations are
+	// grouped by port list, in order of first appearance
+	var order []string
+	hostsByPorts := map[string][]string{}
+	for _, dst := range rule.Dst {
+		host, ports, ok := splitACLDestination(dst)
+		if !ok {
+			return nil, fmt.Sprintf("destination %q is not in host:port form", dst)
+		}
+		if _, seen := hostsByPorts[ports]; !seen {
+			order = append(order, ports)
+		}
+		hostsByPorts[ports] = append(hostsByPorts[ports], host)
+	}
+
+	grants := make([]PolicyGrant, 0, len(order))
+	for _, ports := range order {
+		grants = append(grants, PolicyGrant{
+			Src:        rule.Src,
+			Dst:        hostsByPorts[ports],
+			IP:         grantIPs(ports, rule.Proto),
+			SrcPosture: rule.SrcPosture,
+		})
+	}
+	return grants, ""
+}
+
+// splitACLDestination splits an acls destination ("tag:web:80,443",
+// "10.0.0.0/8:*", "[fd7a::1]:22") into its host and port list
+func splitACLDestination(dst string) (string, string, bool) {
+	if strings.HasPrefix(dst, "[") {
+		if end := strings.Index(dst, "]:"); end > 0 {
+			return dst[1:end], dst[end+2:], true
+		}
+		return "", "", false
+	}
+
+	i := strings.LastIndex(dst, ":")
+	if i <= 0 || i == len(dst)-1 {
+		return "", "", false
+	}
+	return dst[:i], dst[i+1:], true
+}
+
+// grantIPs converts an acls port list and protocol into a grant's ip entries
+func grantIPs(ports, proto string) []string {
+	var ips []string
+	for _, port := range strings.Split(ports, ",") {
+		port = strings.TrimSpace(port)
+		switch {
+		case proto == "" && port == "*":
+			ips = append(ips, "*")
+		case proto == "":
+			ips = append(ips, port)
+		default:
+			ips = append(ips, proto+":"+port)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,302 @@
+package tailscale
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultSocketPath is tailscaled's LocalAPI socket on Linux and other Unix systems
+const DefaultSocketPath = "/var/run/tailscale/tailscaled.sock"
+
+// localAPITimeout bounds LocalAPI requests; they are answered locally, so a
+// slow response means tailscaled is stuck and the CLI fallback won't help either
+const localAPITimeout = 30 * time.Second
+
+// LocalClient talks to tailscaled's LocalAPI over its Unix socket, which is
+// faster and more robust than running the CLI and parsing its output. The
+// macOS GUI app and Windows don't expose the socket, so callers fall back to
+// the CLI when it isn't available.
+type LocalClient struct {
+	socketPath string
+	httpClient *http.Client
+}
+
+// NewLocalClient creates a LocalAPI client for the socket at socketPath
+func NewLocalClient(socketPath string) *LocalClient {
+	if socketPath == "" {
+		socketPath = DefaultSocketPath
+	}
+	dialer := &net.Dialer{}
+	return &LocalClient{
+		socketPath: socketPath,
+		httpClient: &http.Client{
+			Timeout: localAPITimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// Available reports whether the LocalAPI socket exists
+func (l *LocalClient) Available() bool {
+	if l == nil || runtime.GOOS == "windows" {
+		return false
+	}
+	info, err := os.Stat(l.socketPath)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
+
+// do sends a LocalAPI request and decodes the JSON response into v (if non-nil)
+func (l *LocalClient) do(ctx context.Context, method, path string, v interface{}) error {
+	return l.send(ctx, method, path, nil, v)
+}
+
+// send is do with a JSON request body (if non-nil)
+func (l *LocalClient) send(ctx context.Context, method, path string, body, v interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	// The host is ignored by the dialer but tailscaled checks it to reject
+	// requests forged by browsers
+	req, err := http.NewRequestWithContext(ctx, method, "http://local-tailscaled.sock/localapi/v0/"+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Sec-Tailscale", "localapi")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("LocalAPI request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read LocalAPI response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("LocalAPI %s returned %d: %s", path, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if v == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, v); err != nil {
+		return fmt.Errorf("failed to parse LocalAPI %s response: %w", path, err)
+	}
+	return nil
+}
+
+// Status returns the same status as `tailscale status --json`
+func (l *LocalClient) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	if err := l.do(ctx, "GET", "status", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Prefs returns the node's current preferences
+func (l *LocalClient) Prefs(ctx context.Context) (*Prefs, error) {
+	var prefs Prefs
+	if err := l.do(ctx, "GET", "prefs", &prefs); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// EditPrefs changes the preferences named in masked, which uses tailscaled's
+// MaskedPrefs form: each changed field X is accompanied by "XSet": true.
+// It returns the preferences after the change.
+func (l *LocalClient) EditPrefs(ctx context.Context, masked map[string]interface{}) (*Prefs, error) {
+	var prefs Prefs
+	if err := l.send(ctx, "PATCH", "prefs", masked, &prefs); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// WhoIs looks up the node and user that own addr (an IP, or IP:port)
+func (l *LocalClient) WhoIs(ctx context.Context, addr string) (*WhoIs, error) {
+	var who WhoIs
+	if err := l.do(ctx, "GET", "whois?addr="+url.QueryEscape(addr), &who); err != nil {
+		return nil, err
+	}
+	return &who, nil
+}
+
+// Ping sends a single disco ping to a peer's Tailscale IP
+func (l *LocalClient) Ping(ctx context.Context, ip string) (*PingResult, error) {
+	var result PingResult
+	query := url.Values{"ip": {ip}, "type": {"disco"}}
+	if err := l.do(ctx, "POST", "ping?"+query.Encode(), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DERPMap returns the DERP relay regions the node currently uses
+func (l *LocalClient) DERPMap(ctx context.Context) (*DERPMap, error) {
+	var derpMap DERPMap
+	if err := l.do(ctx, "GET", "derpmap", &derpMap); err != nil {
+		return nil, err
+	}
+	return &derpMap, nil
+}
+
+// Profiles returns the login profiles, marking the current one active
+func (l *LocalClient) Profiles(ctx context.Context) ([]Profile, error) {
+	var profiles []loginProfile
+	if err := l.do(ctx, "GET", "profiles/", &profiles); err != nil {
+		return nil, err
+	}
+	var current loginProfile
+	if err := l.do(ctx, "GET", "profiles/current", &current); err != nil {
+		return nil, err
+	}
+
+	result := make([]Profile, 0, len(profiles))
+	for _, p := range profiles {
+		tailnet := p.NetworkProfile.DomainName
+		if tailnet == "" {
+			tailnet = p.Name
+		}
+		result = append(result, Profile{
+			ID:      p.ID,
+			Tailnet: tailnet,
+			Account: p.UserProfile.LoginName,
+			Active:  p.ID == current.ID,
+		})
+	}
+	return result, nil
+}
+
+// WaitingFiles lists the Taildrop files received and not yet saved
+func (l *LocalClient) WaitingFiles(ctx context.Context) ([]WaitingFile, error) {
+	var files []WaitingFile
+	if err := l.do(ctx, "GET", "files/", &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// SaveFile copies a received Taildrop file to w and removes it from the inbox
+func (l *LocalClient) SaveFile(ctx context.Context, name string, w io.Writer) error {
+	path := "files/" + url.PathEscape(name)
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://local-tailscaled.sock/localapi/v0/"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Sec-Tailscale", "localapi")
+
+	// Files can be large, so don't apply the usual request timeout
+	client := *l.httpClient
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("LocalAPI request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("LocalAPI %s returned %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return l.do(ctx, "DELETE", path, nil)
+}
+
+// WaitingFile is a received Taildrop file waiting in the inbox
+type WaitingFile struct {
+	Name string `json:"Name"`
+	Size int64  `json:"Size"`
+}
+
+// loginProfile is the LocalAPI representation of a profile
+type loginProfile struct {
+	ID             string `json:"ID"`
+	Name           string `json:"Name"`
+	NetworkProfile struct {
+		DomainName string `json:"DomainName"`
+	} `json:"NetworkProfile"`
+	UserProfile struct {
+		LoginName string `json:"LoginName"`
+	} `json:"UserProfile"`
+}
+
+// Prefs holds the node preferences used by the tools
+type Prefs struct {
+	ControlURL      string   `json:"ControlURL"`
+	RouteAll        bool     `json:"RouteAll"`
+	ExitNodeID      string   `json:"ExitNodeID"`
+	ExitNodeIP      string   `json:"ExitNodeIP"`
+	CorpDNS         bool     `json:"CorpDNS"`
+	RunSSH          bool     `json:"RunSSH"`
+	WantRunning     bool     `json:"WantRunning"`
+	ShieldsUp       bool     `json:"ShieldsUp"`
+	AdvertiseTags   []string `json:"AdvertiseTags"`
+	AdvertiseRoutes []string `json:"AdvertiseRoutes"`
+	Hostname        string   `json:"Hostname"`
+	OperatorUser    string   `json:"OperatorUser"`
+	// NetfilterMode is 0 (off), 1 (nodivert) or 2 (on); Linux only
+	NetfilterMode int `json:"NetfilterMode"`
+	AutoUpdate    struct {
+		Check bool  `json:"Check"`
+		Apply *bool `json:"Apply"`
+	} `json:"AutoUpdate"`
+}
+
+// PingResult is the outcome of a single LocalAPI ping
+type PingResult struct {
+	IP             string  `json:"IP"`
+	NodeIP         string  `json:"NodeIP"`
+	NodeName       string  `json:"NodeName"`
+	Err            string  `json:"Err,omitempty"`
+	LatencySeconds float64 `json:"LatencySeconds"`
+	// Endpoint is the ip:port the pong came from when the path is direct
+	Endpoint       string `json:"Endpoint,omitempty"`
+	DERPRegionID   int    `json:"DERPRegionID,omitempty"`
+	DERPRegionCode string `json:"DERPRegionCode,omitempty"`
+}
+
+// Direct reports whether the pong arrived over a direct path rather than DERP
+func (r *PingResult) Direct() bool {
+	return r.Endpoint != ""
+}
+
+// String formats the result like `tailscale ping` does
+func (r *PingResult) String() string {
+	if r.Err != "" {
+		return r.Err
+	}
+	via := r.Endpoint
+	if !r.Direct() {
+		via = fmt.Sprintf("DERP(%s)", r.DERPRegionCode)
+	}
+	latency := time.Duration(r.LatencySeconds * float64(time.Second)).Round(time.Millisecond)
+	return fmt.Sprintf("pong from %s (%s) via %s in %s", strings.TrimSuffix(r.NodeName, "."), r.NodeIP, via, latency)
+}
@@ -0,0 +1,153 @@
+package tailscale
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LockStatus is the output of `tailscale lock status --json`
+type LockStatus struct {
+	Enabled bool `json:"Enabled"`
+	// PublicKey is this node's tailnet lock key (tlpub:...)
+	PublicKey     string    `json:"PublicKey"`
+	NodeKey       string    `json:"NodeKey"`
+	NodeKeySigned bool      `json:"NodeKeySigned"`
+	TrustedKeys   []LockKey `json:"TrustedKeys"`
+	// FilteredPeers are peers hidden from this node because their node key
+	// isn't signed by a trusted key
+	FilteredPeers []LockPeer `json:"FilteredPeers"`
+}
+
+// LockKey is a trusted tailnet lock signing key
+type LockKey struct {
+	Key      string            `json:"Key"`
+	Metadata map[string]string `json:"Metadata,omitempty"`
+	Votes    int               `json:"Votes"`
+}
+
+// LockPeer is a peer as seen by tailnet lock
+type LockPeer struct {
+	Name         string   `json:"Name"`
+	StableID     string   `json:"StableID"`
+	TailscaleIPs []string `json:"TailscaleIPs"`
+	NodeKey      string   `json:"NodeKey"`
+}
+
+// LockLogEntry is an update in the tailnet key authority log
+type LockLogEntry struct {
+	Hash string `json:"hash"`
+	// Kind is the change made, e.g. checkpoint, add-key or remove-key
+	Kind    string   `json:"kind"`
+	Details []string `json:"details,omitempty"`
+}
+
+var (
+	lockKeyPattern           = regexp.MustCompile(`^tlpub:[0-9a-f]{64}$`)
+	nodeKeyPattern           = regexp.MustCompile(`^nodekey:[0-9a-f]{64}$`)
+	disablementSecretPattern = regexp.MustCompile(`disablement-secret:[0-9A-Fa-f]+`)
+	lockUpdatePattern        = regexp.MustCompile(`^update ([0-9a-fA-F]+) \(([^)]+)\)`)
+	ansiEscapePattern        = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// ValidLockKey reports whether key looks like a tailnet lock public key
+func ValidLockKey(key string) bool {
+	return lockKeyPattern.MatchString(key)
+}
+
+// ValidNodeKey reports whether key looks like a node public key
+func ValidNodeKey(key string) bool {
+	return nodeKeyPattern.MatchString(key)
+}
+
+// ParseDisablementSecrets returns the disablement secrets in s, such as the
+// output of `tailscale lock init`
+func ParseDisablementSecrets(s string) []string {
+	return disablementSecretPattern.FindAllString(s, -1)
+}
+
+// LockStatus returns the tailnet lock state as seen by this node
+func (c *CLI) LockStatus(ctx context.Context) (*LockStatus, error) {
+	var status LockStatus
+	if err := c.ExecuteJSON(ctx, &status, "lock", "status"); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// LockInit enables tailnet lock with keys as the trusted signing keys,
+// generating disablement secrets. The secrets are only ever shown in the
+// returned output. With supportDisablement, an extra secret is generated
+// and shared with Tailscale support so they can disable lock if all other
+// secrets are lost.
+func (c *CLI) LockInit(ctx context.Context, keys []string, disablements int, supportDisablement bool) (string, error) {
+	args := []string{"lock", "init", "--confirm", "--gen-disablements=" + strconv.Itoa(disablements)}
+	if supportDisablement {
+		args = append(args, "--gen-disablement-for-support")
+	}
+	args = append(args, keys...)
+	return c.Execute(ctx, args...)
+}
+
+// LockAdd adds trusted signing keys. This node's key must already be trusted.
+func (c *CLI) LockAdd(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("no keys specified")
+	}
+	_, err := c.Execute(ctx, append([]string{"lock", "add"}, keys...)...)
+	return err
+}
+
+// LockRemove removes trusted signing keys. Unless reSign is false, nodes
+// signed by a removed key are re-signed with this node's key so they stay
+// in the tailnet.
+func (c *CLI) LockRemove(ctx context.Context, keys []string, reSign bool) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("no keys specified")
+	}
+	args := []string{"lock", "remove", "--re-sign=" + strconv.FormatBool(reSign)}
+	_, err := c.Execute(ctx, append(args, keys...)...)
+	return err
+}
+
+// LockSign signs a node key with this node's tailnet lock key
+func (c *CLI) LockSign(ctx context.Context, nodeKey string) error {
+	_, err := c.Execute(ctx, "lock", "sign", nodeKey)
+	return err
+}
+
+// LockDisable permanently disables tailnet lock for the whole tailnet
+func (c *CLI) LockDisable(ctx context.Context, secret string) error {
+	_, err := c.Execute(ctx, "lock", "disable", secret)
+	return err
+}
+
+// LockLog returns the most recent limit updates to the tailnet key
+// authority, newest first
+func (c *CLI) LockLog(ctx context.Context, limit int) ([]LockLogEntry, error) {
+	output, err := c.Execute(ctx, "lock", "log", "--limit="+strconv.Itoa(limit))
+	if err != nil {
+		return nil, err
+	}
+	return parseLockLog(output), nil
+}
+
+// parseLockLog splits `tailscale lock log` output into its update stanzas,
+// each starting with "update <hash> (<kind>)"
+func parseLockLog(output string) []LockLogEntry {
+	var entries []LockLogEntry
+	for _, line := range strings.Split(ansiEscapePattern.ReplaceAllString(output, ""), "\n") {
+		line = strings.TrimSpace(line)
+		if m := lockUpdatePattern.FindStringSubmatch(line); m != nil {
+			entries = append(entries, LockLogEntry{Hash: m[1], Kind: m[2]})
+			continue
+		}
+		if line != "" && len(entries) > 0 {
+			last := &entries[len(entries)-1]
+			last.Details = append(last.Details, line)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,96 @@
+package tailscale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// NetcheckReport holds the fields of `tailscale netcheck --format=json` used
+// by the tools. The optional booleans are null when netcheck couldn't tell.
+type NetcheckReport struct {
+	UDP         bool   `json:"UDP"`
+	IPv4        bool   `json:"IPv4"`
+	IPv6        bool   `json:"IPv6"`
+	IPv6CanSend bool   `json:"IPv6CanSend"`
+	OSHasIPv6   bool   `json:"OSHasIPv6"`
+	GlobalV4    string `json:"GlobalV4"`
+	GlobalV6    string `json:"GlobalV6"`
+	// MappingVariesByDestIP is true behind a hard NAT, which gives each
+	// destination a different public port
+	MappingVariesByDestIP *bool `json:"MappingVariesByDestIP"`
+	HairPinning           *bool `json:"HairPinning"`
+	UPnP                  *bool `json:"UPnP"`
+	PMP                   *bool `json:"PMP"`
+	PCP                   *bool `json:"PCP"`
+	PreferredDERP         int   `json:"PreferredDERP"`
+	// RegionLatency is the measured latency to each DERP region by region ID
+	RegionLatency map[int]time.Duration `json:"RegionLatency"`
+	CaptivePortal *bool                 `json:"CaptivePortal"`
+}
+
+// DERPMap is the set of DERP relay regions, keyed by region ID
+type DERPMap struct {
+	Regions map[int]*DERPRegion `json:"Regions"`
+}
+
+// DERPRegion is a DERP relay region
+type DERPRegion struct {
+	RegionID   int    `json:"RegionID"`
+	RegionCode string `json:"RegionCode"`
+	RegionName string `json:"RegionName"`
+	// Avoid is set for regions clients only use when told to
+	Avoid bool `json:"Avoid,omitempty"`
+}
+
+// HardNAT reports whether this node is behind a NAT that maps each
+// destination to a different public port, which defeats NAT traversal
+// unless the other side is easy to reach
+func (r *NetcheckReport) HardNAT() bool {
+	return r.MappingVariesByDestIP != nil && *r.MappingVariesByDestIP
+}
+
+// PortMapping reports whether a UPnP, NAT-PMP or PCP port mapping service
+// was found on the local network
+func (r *NetcheckReport) PortMapping() bool {
+	for _, b := range []*bool{r.UPnP, r.PMP, r.PCP} {
+		if b != nil && *b {
+			return true
+		}
+	}
+	return false
+}
+
+// Netcheck probes the local network's NAT, UDP and DERP connectivity
+func (c *CLI) Netcheck(ctx context.Context) (*NetcheckReport, error) {
+	// netcheck takes --format rather than the --json flag ExecuteJSON adds
+	output, err := c.Execute(ctx, "netcheck", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+	var report NetcheckReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		return nil, fmt.Errorf("failed to parse netcheck output: %w", err)
+	}
+	return &report, nil
+}
+
+// DERPMap returns the DERP relay regions the node currently uses
+func (c *CLI) DERPMap(ctx context.Context) (*DERPMap, error) {
+	if c.local.Available() {
+		if derpMap, err := c.local.DERPMap(ctx); err == nil {
+			return derpMap, nil
+		}
+	}
+
+	output, err := c.Execute(ctx, "debug", "derp-map")
+	if err != nil {
+		return nil, err
+	}
+	var derpMap DERPMap
+	if err := json.Unmarshal([]byte(output), &derpMap); err != nil {
+		return nil, fmt.Errorf("failed to parse DERP map: %w", err)
+	}
+	return &derpMap, nil
+}
@@ -0,0 +1,171 @@
+package tailscale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oauthTokenPath is the OAuth client credentials token endpoint, relative to the API base URL
+const oauthTokenPath = "/oauth/token"
+
+// tokenRefreshMargin refreshes access tokens this long before they expire so
+// requests in flight don't race the expiry. Short-lived tokens use a quarter
+// of their lifetime instead, so they're still reused.
+const tokenRefreshMargin = 5 * time.Minute
+
+// defaultTokenLifetime is assumed when a token response has no expires_in;
+// Tailscale access tokens last an hour
+const defaultTokenLifetime = time.Hour
+
+// oauthTokenSource exchanges OAuth client credentials for short-lived API
+// access tokens, caching each token until shortly before it expires
+type oauthTokenSource struct {
+	clientID     string
+	clientSecret string
+	tokenURL     string
+	httpClient   *http.Client
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+	scopes    []string
+}
+
+// Token returns a valid access token, requesting a new one if needed
+func (s *oauthTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Before(s.refreshAt) {
+		return s.token, nil
+	}
+
+	form := url.Values{
+		"client_id":     {s.clientID},
+		"client_secret": {s.clientSecret},
+		"grant_type":    {"client_credentials"},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("OAuth token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read OAuth token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OAuth token request failed with %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Scope       string `json:"scope"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("failed to parse OAuth token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("OAuth token response did not include an access token")
+	}
+
+	s.token = token.AccessToken
+	lifetime := time.Duration(token.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = defaultTokenLifetime
+	}
+	margin := tokenRefreshMargin
+	if margin > lifetime/4 {
+		margin = lifetime / 4
+	}
+	s.refreshAt = time.Now().Add(lifetime - margin)
+	s.scopes = strings.Fields(token.Scope)
+	return s.token, nil
+}
+
+// Scopes returns the scopes granted with the last access token
+func (s *oauthTokenSource) Scopes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scopes
+}
+
+// invalidate drops the cached token, e.g. after the API rejects it
+func (s *oauthTokenSource) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+// NewAPIClientWithOAuth creates a Tailscale API client that authenticates with
+// an OAuth client. Access tokens are exchanged and refreshed automatically,
+// so unlike API keys the credentials don't expire.
+func NewAPIClientWithOAuth(clientID, clientSecret, tailnet string) (*APIClient, error) {
+	return NewAPIClientWithOAuthAt(DefaultAPIBaseURL, clientID, clientSecret, tailnet)
+}
+
+// NewAPIClientWithOAuthAt is NewAPIClientWithOAuth for the API at baseURL.
+// The first token is exchanged here, so use this rather than SetBaseURL.
+func NewAPIClientWithOAuthAt(baseURL, clientID, clientSecret, tailnet string) (*APIClient, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, fmt.Errorf("OAuth client ID and secret are required")
+	}
+	if tailnet == "" {
+		tailnet = "-"
+	}
+
+	client := &APIClient{
+		tailnet: tailnet,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		maxRetries: DefaultMaxRetries,
+	}
+	client.oauth = &oauthTokenSource{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		tokenURL:     client.baseURL + oauthTokenPath,
+		httpClient:   client.httpClient,
+	}
+
+	// Exchange a token up front so bad credentials are reported at startup
+	if _, err := client.oauth.Token(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// OAuthScopes returns the scopes granted to the OAuth client the API client
+// authenticates with, and false when it uses an API key instead
+func (c *APIClient) OAuthScopes() ([]string, bool) {
+	if c.oauth == nil {
+		return nil, false
+	}
+	return c.oauth.Scopes(), true
+}
+
+// OAuthClientID returns the ID of the OAuth client the API client
+// authenticates with, or "" when it uses an API key
+func (c *APIClient) OAuthClientID() string {
+	if c.oauth == nil {
+		return ""
+	}
+	return c.oauth.clientID
+}
@@ -0,0 +1,186 @@
+package tailscale
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// pingReplyTimeout is how long `tailscale ping` waits for each reply, and
+// defaultPingCount how many pings it sends without -c
+const (
+	pingReplyTimeout = 5 * time.Second
+	defaultPingCount = 10
+)
+
+// pongPattern matches a reply line of `tailscale ping`, e.g.
+// "pong from host (100.64.0.1) via 203.0.113.7:41641 in 23ms" or
+// "pong from host (100.64.0.1) via DERP(nyc) in 48ms"
+var pongPattern = regexp.MustCompile(`^pong from (\S+) \(([^),]+)[^)]*\) via (\S+) in (\S+)`)
+
+// derpViaPattern matches the DERP(<region>) form of a relayed reply
+var derpViaPattern = regexp.MustCompile(`^DERP\(([^)]*)\)$`)
+
+// PingSummary aggregates a run of pings to one peer
+type PingSummary struct {
+	Sent     int `json:"sent"`
+	Received int `json:"received"`
+	// Path is direct, derp, or none when no reply came back
+	Path           string  `json:"path"`
+	Endpoint       string  `json:"endpoint,omitempty"`
+	DERPRegionCode string  `json:"derp_region,omitempty"`
+	MinLatencyMs   float64 `json:"min_latency_ms,omitempty"`
+	AvgLatencyMs   float64 `json:"avg_latency_ms,omitempty"`
+	MaxLatencyMs   float64 `json:"max_latency_ms,omitempty"`
+	// BecameDirect is set when early replies were relayed and later ones direct
+	BecameDirect bool `json:"became_direct,omitempty"`
+}
+
+// SummarizePings computes the latency and path of a run of pings. The path
+// is that of the last reply, since the first pings often go over DERP while
+// a direct connection is set up.
+func SummarizePings(results []PingResult) PingSummary {
+	summary := PingSummary{Sent: len(results), Path: "none"}
+	var total float64
+	relayed := false
+	for _, r := range results {
+		if r.Err != "" {
+			continue
+		}
+		ms := r.LatencySeconds * 1000
+		if summary.Received == 0 || ms < summary.MinLatencyMs {
+			summary.MinLatencyMs = ms
+		}
+		if ms > summary.MaxLatencyMs {
+			summary.MaxLatencyMs = ms
+		}
+		total += ms
+		summary.Received++
+
+		if r.Direct() {
+			summary.Path = "direct"
+			summary.Endpoint = r.Endpoint
+			summary.DERPRegionCode = ""
+			summary.BecameDirect = relayed
+		} else {
+			summary.Path = "derp"
+			summary.Endpoint = ""
+			summary.DERPRegionCode = r.DERPRegionCode
+			relayed = true
+		}
+	}
+	if summary.Received > 0 {
+		summary.AvgLatencyMs = total / float64(summary.Received)
+	}
+	return summary
+}
+
+// ParsePingOutput parses the replies in `tailscale ping` output. Lines that
+// report a timeout or other failure become results with Err set; the
+// trailing summary lines are ignored.
+func ParsePingOutput(output string) []PingResult {
+	var results []PingResult
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if m := pongPattern.FindStringSubmatch(line); m != nil {
+			result := PingResult{NodeName: m[1], NodeIP: m[2], IP: m[2]}
+			if latency, err := time.ParseDuration(m[4]); err == nil {
+				result.LatencySeconds = latency.Seconds()
+			}
+			if derp := derpViaPattern.FindStringSubmatch(m[3]); derp != nil {
+				result.DERPRegionCode = derp[1]
+			} else {
+				result.Endpoint = m[3]
+			}
+			results = append(results, result)
+			continue
+		}
+		if strings.HasPrefix(line, "timeout") || strings.HasPrefix(line, "ping ") && strings.Contains(line, "failed") {
+			results = append(results, PingResult{Err: line})
+		}
+	}
+	return results
+}
+
+// PingResults pings a peer count times and returns each reply. Without a
+// count it stops at the first direct reply, like `tailscale ping`.
+func (c *CLI) PingResults(ctx context.Context, target string, count int) ([]PingResult, error) {
+	if results, ok := c.localPingResults(ctx, target, count); ok {
+		return results, nil
+	}
+
+	args := []string{"ping", target}
+	if count > 0 {
+		args = append(args, "-c", fmt.Sprintf("%d", count))
+	}
+	// tailscale ping exits non-zero when no direct path was established, but
+	// the replies it printed are still wanted
+	// Each ping that gets no reply waits out tailscale ping's own timeout
+	pings := count
+	if pings <= 0 {
+		pings = defaultPingCount
+	}
+	cmd, err := c.command(c.allowAtLeast(ctx, time.Duration(pings)*pingReplyTimeout+10*time.Second), args)
+	if err != nil {
+		return nil, err
+	}
+	defer cmd.stop()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	results := ParsePingOutput(stdout.String())
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return results, fmt.Errorf("ping canceled: %w", ctxErr)
+	}
+	if err != nil && len(results) == 0 {
+		if interrupted := cmd.interrupted(); interrupted != nil {
+			return nil, interrupted
+		}
+	}
+	if err != nil && len(results) == 0 {
+		return nil, &CommandError{Args: args, Stderr: stderr.String(), Err: err}
+	}
+	return results, nil
+}
+
+// localPingResults pings target through the LocalAPI. It reports false if
+// the LocalAPI is unavailable or target isn't a known peer.
+func (c *CLI) localPingResults(ctx context.Context, target string, count int) ([]PingResult, bool) {
+	if !c.local.Available() {
+		return nil, false
+	}
+	ip := target
+	if net.ParseIP(target) == nil {
+		status, err := c.local.Status(ctx)
+		if err != nil {
+			return nil, false
+		}
+		peer := findPeer(status, target)
+		if peer == nil || len(peer.TailscaleIPs) == 0 {
+			return nil, false
+		}
+		ip = peer.TailscaleIPs[0]
+	}
+
+	untilDirect := count <= 0
+	if untilDirect {
+		count = 10
+	}
+	var results []PingResult
+	for i := 0; i < count; i++ {
+		result, err := c.local.Ping(ctx, ip)
+		if err != nil {
+			return nil, false
+		}
+		results = append(results, *result)
+		if untilDirect && result.Err == "" && result.Direct() {
+			break
+		}
+	}
+	return results, true
+}
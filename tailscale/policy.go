@@ -0,0 +1,781 @@
+package tailscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+// Policy is a parsed HuJSON policy file. Edits operate on the syntax tree so
+// comments and formatting in the original document are preserved.
+type Policy struct {
+	value hujson.Value
+	etag  string
+}
+
+// ParsePolicy parses a raw HuJSON policy document
+func ParsePolicy(raw string) (*Policy, error) {
+	value, err := hujson.Parse([]byte(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if _, ok := value.Value.(*hujson.Object); !ok {
+		return nil, fmt.Errorf("failed to parse policy: top-level value must be an object")
+	}
+	return &Policy{value: value}, nil
+}
+
+// ParseACL parses a policy fetched with GetACL, keeping its ETag so saving
+// the edited policy fails rather than overwriting changes made in between
+func ParseACL(acl *ACL) (*Policy, error) {
+	policy, err := ParsePolicy(acl.RawPolicy)
+	if err != nil {
+		return nil, err
+	}
+	policy.etag = acl.ETag
+	return policy, nil
+}
+
+// SetETag sets the policy version that updates are conditional on, e.g.
+// the ETag returned by SetACL when saving several edits in a row
+func (p *Policy) SetETag(etag string) {
+	p.etag = etag
+}
+
+// Clone returns a deep copy of the policy
+func (p *Policy) Clone() *Policy {
+	// Re-parse rather than using hujson's Value.Clone, which drops the empty
+	// (non-nil) extras that mark trailing commas
+	value, err := hujson.Parse(p.value.Pack())
+	if err != nil {
+		return &Policy{value: p.value.Clone(), etag: p.etag}
+	}
+	return &Policy{value: value, etag: p.etag}
+}
+
+// String returns the policy as HuJSON
+func (p *Policy) String() string {
+	return p.value.String()
+}
+
+// ACL returns the policy wrapped for the ACL API methods
+func (p *Policy) ACL() *ACL {
+	return &ACL{RawPolicy: p.String(), ETag: p.etag}
+}
+
+// Decode unmarshals the policy (with comments stripped) into v
+func (p *Policy) Decode(v interface{}) error {
+	value := p.Clone().value
+	value.Standardize()
+	if err := json.Unmarshal(value.Pack(), v); err != nil {
+		return fmt.Errorf("failed to decode policy: %w", err)
+	}
+	return nil
+}
+
+// Patch applies an RFC 6902 JSON Patch to the policy, preserving comments.
+// The result is reformatted in standard HuJSON style (as the admin console
+// does) since patched values are otherwise inserted on a single line.
+func (p *Policy) Patch(ops []PatchOp) error {
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+	if err := p.value.Patch(patch); err != nil {
+		return fmt.Errorf("failed to patch policy: %w", err)
+	}
+	p.value.Format()
+	return nil
+}
+
+// PatchOp is a single JSON Patch operation
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// PointerEscape escapes a key for use in a JSON Pointer path
+func PointerEscape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// HasTagOwner reports whether the policy declares owners for tag
+func (p *Policy) HasTagOwner(tag string) bool {
+	return p.value.Find("/tagOwners/"+PointerEscape(tag)) != nil
+}
+
+// AddTagOwner declares tag in tagOwners with the given owners, creating the
+// tagOwners section if needed. Existing declarations are left untouched.
+func (p *Policy) AddTagOwner(tag string, owners []string) error {
+	if p.HasTagOwner(tag) {
+		return nil
+	}
+
+	var ops []PatchOp
+	if p.value.Find("/tagOwners") == nil {
+		ops = append(ops, PatchOp{Op: "add", Path: "/tagOwners", Value: map[string]interface{}{}})
+	}
+	ops = append(ops, PatchOp{Op: "add", Path: "/tagOwners/" + PointerEscape(tag), Value: owners})
+	return p.Patch(ops)
+}
+
+// AddTagOwners adds owners to tag's entry in tagOwners, declaring the tag if
+// needed. It returns the number of owners that weren't already listed.
+func (p *Policy) AddTagOwners(tag string, owners []string) (int, error) {
+	return p.appendToList("tagOwners", tag, owners)
+}
+
+// RemoveTagOwners removes owners from tag's entry in tagOwners, keeping the
+// tag declared. It returns the number of owners removed.
+func (p *Policy) RemoveTagOwners(tag string, owners []string) (int, error) {
+	return p.removeFromList("tagOwners", tag, owners)
+}
+
+// RemoveTagOwner deletes tag's entry from tagOwners, so the tag can no longer
+// be applied. It reports whether the tag was declared.
+func (p *Policy) RemoveTagOwner(tag string) (bool, error) {
+	if !p.HasTagOwner(tag) {
+		return false, nil
+	}
+	return true, p.Patch([]PatchOp{{Op: "remove", Path: "/tagOwners/" + PointerEscape(tag)}})
+}
+
+// AddGroupMembers adds members to group in the "groups" section, creating the
+// group if needed. It returns the number of members that weren't already listed.
+func (p *Policy) AddGroupMembers(group string, members []string) (int, error) {
+	return p.appendToList("groups", group, members)
+}
+
+// RemoveGroupMembers removes members from group in the "groups" section,
+// keeping the group itself. It returns the number of members removed.
+func (p *Policy) RemoveGroupMembers(group string, members []string) (int, error) {
+	return p.removeFromList("groups", group, members)
+}
+
+// SetHost points the host alias name at address in the "hosts" section,
+// creating the section if needed and replacing any existing alias
+func (p *Policy) SetHost(name, address string) error {
+	var ops []PatchOp
+	if p.value.Find("/hosts") == nil {
+		ops = append(ops, PatchOp{Op: "add", Path: "/hosts", Value: map[string]interface{}{}})
+	}
+	ops = append(ops, PatchOp{Op: "add", Path: "/hosts/" + PointerEscape(name), Value: address})
+	return p.Patch(ops)
+}
+
+// RemoveHost deletes the host alias name. It reports whether it existed.
+func (p *Policy) RemoveHost(name string) (bool, error) {
+	if p.value.Find("/hosts/"+PointerEscape(name)) == nil {
+		return false, nil
+	}
+	return true, p.Patch([]PatchOp{{Op: "remove", Path: "/hosts/" + PointerEscape(name)}})
+}
+
+// appendToList appends the values missing from the list at section/key,
+// creating the section and list as needed
+func (p *Policy) appendToList(section, key string, values []string) (int, error) {
+	path := "/" + section + "/" + PointerEscape(key)
+
+	var ops []PatchOp
+	var existing []string
+	if found := p.value.Find(path); found != nil {
+		value := found.Clone()
+		value.Standardize()
+		if err := json.Unmarshal(value.Pack(), &existing); err != nil {
+			return 0, fmt.Errorf("%s entry %s is not a list of strings", section, key)
+		}
+	} else {
+		if p.value.Find("/"+section) == nil {
+			ops = append(ops, PatchOp{Op: "add", Path: "/" + section, Value: map[string]interface{}{}})
+		}
+		ops = append(ops, PatchOp{Op: "add", Path: path, Value: []string{}})
+	}
+
+	added := 0
+	for _, v := range values {
+		if !slices.Contains(existing, v) {
+			ops = append(ops, PatchOp{Op: "add", Path: path + "/-", Value: v})
+			existing = append(existing, v)
+			added++
+		}
+	}
+	if len(ops) == 0 {
+		return 0, nil
+	}
+	return added, p.Patch(ops)
+}
+
+// removeFromList deletes the given values, and the comments above them, from
+// the list at section/key
+func (p *Policy) removeFromList(section, key string, values []string) (int, error) {
+	found := p.value.Find("/" + section + "/" + PointerEscape(key))
+	if found == nil {
+		return 0, fmt.Errorf("%s has no entry %s", section, key)
+	}
+	arr, ok := found.Value.(*hujson.Array)
+	if !ok {
+		return 0, fmt.Errorf("%s entry %s is not a list of strings", section, key)
+	}
+	removed := removeStrings(arr, values)
+	if removed > 0 {
+		p.value.Format()
+	}
+	return removed, nil
+}
+
+// removeStrings deletes the string elements of arr found in values
+func removeStrings(arr *hujson.Array, values []string) int {
+	removed := 0
+	for i := len(arr.Elements) - 1; i >= 0; i-- {
+		if slices.Contains(values, literalString(arr.Elements[i].Value)) {
+			removeElement(arr, i)
+			removed++
+		}
+	}
+	return removed
+}
+
+// PolicyNodeAttr is an entry in the policy file's "nodeAttrs" section, which
+// gives node attributes such as funnel or mullvad to the targeted nodes
+type PolicyNodeAttr struct {
+	Target []string               `json:"target"`
+	Attr   []string               `json:"attr,omitempty"`
+	App    map[string]interface{} `json:"app,omitempty"`
+}
+
+// NodeAttrs returns the entries of the "nodeAttrs" section
+func (p *Policy) NodeAttrs() ([]PolicyNodeAttr, error) {
+	var policy struct {
+		NodeAttrs []PolicyNodeAttr `json:"nodeAttrs"`
+	}
+	if err := p.Decode(&policy); err != nil {
+		return nil, err
+	}
+	return policy.NodeAttrs, nil
+}
+
+// AddNodeAttrs gives attrs to target. They are added to the nodeAttrs entry
+// with exactly that target list if there is one, or to a new entry otherwise.
+// It returns the number of attributes that weren't already there.
+func (p *Policy) AddNodeAttrs(target, attrs []string, comment string) (int, error) {
+	entries, err := p.NodeAttrs()
+	if err != nil {
+		return 0, err
+	}
+
+	i := slices.IndexFunc(entries, func(e PolicyNodeAttr) bool { return equalStrings(e.Target, target) })
+	if i < 0 {
+		var unique []string
+		for _, attr := range attrs {
+			if !slices.Contains(unique, attr) {
+				unique = append(unique, attr)
+			}
+		}
+		return len(unique), p.appendEntry("nodeAttrs", PolicyNodeAttr{Target: target, Attr: unique}, comment)
+	}
+
+	path := fmt.Sprintf("/nodeAttrs/%d/attr", i)
+	var ops []PatchOp
+	existing := entries[i].Attr
+	if p.value.Find(path) == nil {
+		ops = append(ops, PatchOp{Op: "add", Path: path, Value: []string{}})
+	}
+	added := 0
+	for _, attr := range attrs {
+		if !slices.Contains(existing, attr) {
+			ops = append(ops, PatchOp{Op: "add", Path: path + "/-", Value: attr})
+			existing = append(existing, attr)
+			added++
+		}
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, p.Patch(ops)
+}
+
+// RemoveNodeAttrs takes attrs away from every nodeAttrs entry whose target
+// list is exactly target, dropping entries left with nothing to grant. It
+// returns the number of attributes removed.
+func (p *Policy) RemoveNodeAttrs(target, attrs []string) (int, error) {
+	entries, err := p.NodeAttrs()
+	if err != nil {
+		return 0, err
+	}
+	found := p.value.Find("/nodeAttrs")
+	if found == nil {
+		return 0, nil
+	}
+	arr, ok := found.Value.(*hujson.Array)
+	if !ok || len(arr.Elements) != len(entries) {
+		return 0, fmt.Errorf("nodeAttrs is not a list of entries")
+	}
+
+	removed := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !equalStrings(entries[i].Target, target) {
+			continue
+		}
+		attrValue := p.value.Find(fmt.Sprintf("/nodeAttrs/%d/attr", i))
+		if attrValue == nil {
+			continue
+		}
+		attrArr, ok := attrValue.Value.(*hujson.Array)
+		if !ok {
+			continue
+		}
+		n := removeStrings(attrArr, attrs)
+		removed += n
+		if n > 0 && len(attrArr.Elements) == 0 && len(entries[i].App) == 0 {
+			removeElement(arr, i)
+		}
+	}
+	if removed > 0 {
+		p.value.Format()
+	}
+	return removed, nil
+}
+
+// PolicyRule is an ACL rule as written in the policy file's "acls" section
+type PolicyRule struct {
+	Action string   `json:"action"`
+	Src    []string `json:"src"`
+	Dst    []string `json:"dst"`
+}
+
+// Equal reports whether two rules have the same action, sources and destinations
+func (r PolicyRule) Equal(other PolicyRule) bool {
+	return r.Action == other.Action && equalStrings(r.Src, other.Src) && equalStrings(r.Dst, other.Dst)
+}
+
+// PolicySSHRule is a rule in the policy file's "ssh" section
+type PolicySSHRule struct {
+	Action      string   `json:"action"` // accept or check
+	Src         []string `json:"src"`
+	Dst         []string `json:"dst"`
+	Users       []string `json:"users"`
+	CheckPeriod string   `json:"checkPeriod,omitempty"`
+}
+
+// AddACLRule appends a rule to the "acls" section. A non-empty comment is
+// written above the rule so it is recognizable when reading the policy.
+func (p *Policy) AddACLRule(rule PolicyRule, comment string) error {
+	return p.appendEntry("acls", rule, comment)
+}
+
+// AddSSHRule appends rule to the "ssh" section, creating it if needed. A
+// non-empty comment is written on the line above the new rule.
+func (p *Policy) AddSSHRule(rule PolicySSHRule, comment string) error {
+	return p.appendEntry("ssh", rule, comment)
+}
+
+// appendEntry appends value to the array section, with comment above it
+func (p *Policy) appendEntry(section string, value interface{}, comment string) error {
+	var ops []PatchOp
+	if p.value.Find("/"+section) == nil {
+		ops = append(ops, PatchOp{Op: "add", Path: "/" + section, Value: []interface{}{}})
+	}
+	ops = append(ops, PatchOp{Op: "add", Path: "/" + section + "/-", Value: value})
+	if err := p.Patch(ops); err != nil {
+		return err
+	}
+
+	if comment != "" {
+		arr, ok := p.value.Find("/" + section).Value.(*hujson.Array)
+		if !ok {
+			return fmt.Errorf("%s is not a list", section)
+		}
+		last := &arr.Elements[len(arr.Elements)-1]
+		// Keep any comment trailing the previous element on its own line
+		extra := string(last.BeforeExtra)
+		sameLine, rest := extra, "\n"
+		if j := strings.Index(extra, "\n"); j >= 0 {
+			sameLine, rest = extra[:j], extra[j:]
+		}
+		last.BeforeExtra = hujson.Extra(sameLine + "\n// " + comment + rest)
+		p.value.Format()
+	}
+	return nil
+}
+
+// RemoveACLRule removes the first rule in "acls" equal to rule, along with its
+// leading comment. It reports whether a matching rule was found.
+func (p *Policy) RemoveACLRule(rule PolicyRule) (bool, error) {
+	return p.RemoveCommentedACLRule(rule, "")
+}
+
+// RemoveCommentedACLRule removes the first rule in "acls" equal to rule whose
+// leading comment contains comment, so a rule added with a marker comment is
+// removed without touching an identical rule added by someone else. It
+// reports whether a matching rule was found.
+func (p *Policy) RemoveCommentedACLRule(rule PolicyRule, comment string) (bool, error) {
+	acls, i := p.findACLRule(rule, comment)
+	if i < 0 {
+		return false, nil
+	}
+	removeElement(acls, i)
+	p.value.Format()
+	return true, nil
+}
+
+// HasACLRule reports whether "acls" contains a rule equal to rule
+func (p *Policy) HasACLRule(rule PolicyRule) bool {
+	_, i := p.findACLRule(rule, "")
+	return i >= 0
+}
+
+// findACLRule returns the "acls" array and the index of the first rule equal
+// to rule whose leading comment contains comment (any rule when comment is
+// empty), or -1 if there is none
+func (p *Policy) findACLRule(rule PolicyRule, comment string) (*hujson.Array, int) {
+	found := p.value.Find("/acls")
+	if found == nil {
+		return nil, -1
+	}
+	acls, ok := found.Value.(*hujson.Array)
+	if !ok {
+		return nil, -1
+	}
+
+	for i, elem := range acls.Elements {
+		if comment != "" && !strings.Contains(string(elem.BeforeExtra), comment) {
+			continue
+		}
+		value := elem.Clone()
+		value.Standardize()
+
+		var existing PolicyRule
+		if err := json.Unmarshal(value.Pack(), &existing); err != nil {
+			continue
+		}
+		if existing.Equal(rule) {
+			return acls, i
+		}
+	}
+	return acls, -1
+}
+
+// removeElement deletes arr.Elements[i] together with the comments on the lines
+// above it. A comment trailing the previous element on the same line is kept.
+func removeElement(arr *hujson.Array, i int) {
+	removed := arr.Elements[i]
+
+	var sameLine hujson.Extra
+	if j := strings.Index(string(removed.BeforeExtra), "\n"); j >= 0 {
+		sameLine = append(hujson.Extra(nil), removed.BeforeExtra[:j]...)
+	}
+
+	arr.Elements = append(arr.Elements[:i], arr.Elements[i+1:]...)
+	if i < len(arr.Elements) {
+		arr.Elements[i].BeforeExtra = append(sameLine, arr.Elements[i].BeforeExtra...)
+		return
+	}
+
+	// The last element was removed: keep the trailing comma state and move any
+	// same-line comment before the closing bracket
+	if i > 0 {
+		arr.Elements[i-1].AfterExtra = removed.AfterExtra
+	}
+	arr.AfterExtra = append(sameLine, arr.AfterExtra...)
+}
+
+// PolicyTest is an entry in the policy file's "tests" section, checked by the
+// control plane whenever the policy is validated or saved
+type PolicyTest struct {
+	Src    string   `json:"src"`
+	Accept []string `json:"accept,omitempty"`
+	Deny   []string `json:"deny,omitempty"`
+}
+
+// AddTests appends entries to the "tests" section, creating it if needed
+func (p *Policy) AddTests(tests []PolicyTest) error {
+	if len(tests) == 0 {
+		return nil
+	}
+
+	var ops []PatchOp
+	if p.value.Find("/tests") == nil {
+		ops = append(ops, PatchOp{Op: "add", Path: "/tests", Value: []interface{}{}})
+	}
+	for _, test := range tests {
+		ops = append(ops, PatchOp{Op: "add", Path: "/tests/-", Value: test})
+	}
+	return p.Patch(ops)
+}
+
+// Tests returns the entries of the "tests" section
+func (p *Policy) Tests() ([]PolicyTest, error) {
+	var policy struct {
+		Tests []PolicyTest `json:"tests"`
+	}
+	if err := p.Decode(&policy); err != nil {
+		return nil, err
+	}
+	return policy.Tests, nil
+}
+
+// SetTests replaces the "tests" section with tests
+func (p *Policy) SetTests(tests []PolicyTest) error {
+	if p.value.Find("/tests") != nil {
+		if err := p.Patch([]PatchOp{{Op: "remove", Path: "/tests"}}); err != nil {
+			return err
+		}
+	}
+	return p.AddTests(tests)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeGroup adds the "group:" prefix if it is missing
+func NormalizeGroup(group string) string {
+	group = strings.TrimSpace(group)
+	if group != "" && !strings.HasPrefix(group, "group:") {
+		group = "group:" + group
+	}
+	return group
+}
+
+// NormalizeTag adds the "tag:" prefix if it is missing
+func NormalizeTag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if tag != "" && !strings.HasPrefix(tag, "tag:") {
+		tag = "tag:" + tag
+	}
+	return tag
+}
+
+// RenameTag replaces every reference to oldTag with newTag, including object
+// keys (tagOwners) and host:port destinations ("tag:web:443"). References that
+// would duplicate an existing newTag entry are dropped. It returns the number
+// of references changed in each top-level section.
+func (p *Policy) RenameTag(oldTag, newTag string) (map[string]int, error) {
+	// Both keys may already exist after an interrupted rename (see AliasTag). That's
+	// fine when they are identical, but differing values would need a manual merge.
+	for v := range p.value.All() {
+		obj, ok := v.Value.(*hujson.Object)
+		if !ok {
+			continue
+		}
+		oldValue, hasOld := memberValue(obj, oldTag)
+		newValue, hasNew := memberValue(obj, newTag)
+		if hasOld && hasNew && !equalValues(oldValue, newValue, oldTag, newTag) {
+			return nil, fmt.Errorf("policy defines both %s and %s with different values; merge them first", oldTag, newTag)
+		}
+	}
+	return p.rewriteTag(oldTag, newTag, false), nil
+}
+
+// AliasTag adds newTag next to every list entry and object key that references
+// oldTag, leaving the original references in place. This produces a transitional
+// policy where devices carrying either tag keep the same access.
+func (p *Policy) AliasTag(oldTag, newTag string) map[string]int {
+	return p.rewriteTag(oldTag, newTag, true)
+}
+
+func (p *Policy) rewriteTag(oldTag, newTag string, keepOld bool) map[string]int {
+	counts := make(map[string]int)
+	root := p.value.Value.(*hujson.Object)
+	for i := range root.Members {
+		name := literalString(root.Members[i].Name.Value)
+		if n := rewriteTagValue(&root.Members[i].Value, oldTag, newTag, keepOld); n > 0 {
+			counts[name] += n
+		}
+	}
+	return counts
+}
+
+// rewriteTagValue walks v and rewrites tag references, returning the number changed
+func rewriteTagValue(v *hujson.Value, oldTag, newTag string, keepOld bool) int {
+	changed := 0
+
+	switch t := v.Value.(type) {
+	case hujson.Literal:
+		// Bare string values (e.g., "src" in tests) can't hold both tags, so they
+		// are only rewritten when the old tag is being removed
+		if renamed, ok := renameTagRef(literalString(t), oldTag, newTag); ok && !keepOld {
+			v.Value = hujson.String(renamed)
+			changed++
+		}
+
+	case *hujson.Object:
+		var members []hujson.ObjectMember
+		for i := range t.Members {
+			member := &t.Members[i]
+			changed += rewriteTagValue(&member.Value, oldTag, newTag, keepOld)
+
+			renamed, ok := renameTagRef(literalString(member.Name.Value), oldTag, newTag)
+			if !ok {
+				members = append(members, *member)
+				continue
+			}
+			changed++
+			if hasMember(t, renamed) {
+				if keepOld {
+					changed--
+					members = append(members, *member)
+				} else if i+1 < len(t.Members) {
+					inheritExtra(&t.Members[i+1].Name.BeforeExtra, member.Name.BeforeExtra)
+				}
+				continue
+			}
+			if !keepOld {
+				member.Name.Value = hujson.String(renamed)
+				members = append(members, *member)
+				continue
+			}
+
+			alias := hujson.ObjectMember{Name: member.Name.Clone(), Value: member.Value.Clone()}
+			alias.Name.BeforeExtra = trailingWhitespace(member.Name.BeforeExtra)
+			alias.Name.Value = hujson.String(renamed)
+			alias.Value.AfterExtra = nil
+			if i == len(t.Members)-1 {
+				alias.Value.AfterExtra = member.Value.AfterExtra
+				member.Value.AfterExtra = nil
+			}
+			members = append(members, *member, alias)
+		}
+		if n := len(members); n > 0 && n < len(t.Members) && members[n-1].Value.AfterExtra == nil {
+			members[n-1].Value.AfterExtra = t.Members[len(t.Members)-1].Value.AfterExtra
+		}
+		t.Members = members
+
+	case *hujson.Array:
+		var elements []hujson.ArrayElement
+		for i := range t.Elements {
+			elem := &t.Elements[i]
+			lit, isLiteral := elem.Value.(hujson.Literal)
+			if !isLiteral {
+				changed += rewriteTagValue(elem, oldTag, newTag, keepOld)
+				elements = append(elements, *elem)
+				continue
+			}
+
+			renamed, ok := renameTagRef(literalString(lit), oldTag, newTag)
+			if !ok {
+				elements = append(elements, *elem)
+				continue
+			}
+			changed++
+			if hasElement(t, renamed) {
+				if keepOld {
+					changed--
+					elements = append(elements, *elem)
+				} else if i+1 < len(t.Elements) {
+					inheritExtra(&t.Elements[i+1].BeforeExtra, elem.BeforeExtra)
+				}
+				continue
+			}
+			if !keepOld {
+				elem.Value = hujson.String(renamed)
+				elements = append(elements, *elem)
+				continue
+			}
+
+			alias := hujson.ArrayElement{
+				BeforeExtra: trailingWhitespace(elem.BeforeExtra),
+				Value:       hujson.String(renamed),
+			}
+			if i == len(t.Elements)-1 {
+				alias.AfterExtra = elem.AfterExtra
+				elem.AfterExtra = nil
+			}
+			elements = append(elements, *elem, alias)
+		}
+		if n := len(elements); n > 0 && n < len(t.Elements) && elements[n-1].AfterExtra == nil {
+			elements[n-1].AfterExtra = t.Elements[len(t.Elements)-1].AfterExtra
+		}
+		t.Elements = elements
+	}
+
+	return changed
+}
+
+// renameTagRef rewrites s if it is oldTag or an oldTag host:port reference
+func renameTagRef(s, oldTag, newTag string) (string, bool) {
+	if s == oldTag {
+		return newTag, true
+	}
+	if strings.HasPrefix(s, oldTag+":") {
+		return newTag + s[len(oldTag):], true
+	}
+	return "", false
+}
+
+// literalString returns the string value of a JSON string literal, or "" otherwise
+func literalString(v hujson.ValueTrimmed) string {
+	lit, ok := v.(hujson.Literal)
+	if !ok || lit.Kind() != '"' {
+		return ""
+	}
+	return lit.String()
+}
+
+func memberValue(obj *hujson.Object, name string) (hujson.Value, bool) {
+	for _, m := range obj.Members {
+		if literalString(m.Name.Value) == name {
+			return m.Value, true
+		}
+	}
+	return hujson.Value{}, false
+}
+
+// equalValues compares two values ignoring comments and whitespace, treating
+// references to oldTag inside a as newTag
+func equalValues(a, b hujson.Value, oldTag, newTag string) bool {
+	a, b = a.Clone(), b.Clone()
+	rewriteTagValue(&a, oldTag, newTag, false)
+	rewriteTagValue(&b, oldTag, newTag, false)
+	a.Minimize()
+	b.Minimize()
+	return a.String() == b.String()
+}
+
+func hasMember(obj *hujson.Object, name string) bool {
+	for _, m := range obj.Members {
+		if literalString(m.Name.Value) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasElement(arr *hujson.Array, s string) bool {
+	for _, e := range arr.Elements {
+		if literalString(e.Value) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// inheritExtra gives the value after a dropped one the dropped value's leading
+// whitespace, unless it has comments of its own
+func inheritExtra(next *hujson.Extra, dropped hujson.Extra) {
+	if strings.TrimSpace(string(*next)) == "" {
+		*next = append(hujson.Extra(nil), dropped...)
+	}
+}
+
+// trailingWhitespace returns the indentation after the last newline in extra,
+// so inserted values line up without duplicating comments
+func trailingWhitespace(extra hujson.Extra) hujson.Extra {
+	s := string(extra)
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		return hujson.Extra(s[i:])
+	}
+	return hujson.Extra(" ")
+}
@@ -0,0 +1,129 @@
+package tailscale
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxRetries is how many times a failed API request is retried by default
+const DefaultMaxRetries = 3
+
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+	// maxRetryAfter caps how long a Retry-After header can make us wait, so a
+	// long rate-limit window fails the tool call instead of hanging it
+	maxRetryAfter = time.Minute
+)
+
+// SetMaxRetries sets how many times transient failures are retried (0 disables retries)
+func (c *APIClient) SetMaxRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	c.maxRetries = n
+}
+
+// send authorizes and sends req, retrying rate limits, transient server
+// errors and network failures with jittered exponential backoff. Network
+// errors and gateway failures are only retried for idempotent methods, since
+// the request may already have been applied.
+func (c *APIClient) send(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		if err := c.authorize(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		retry := attempt < c.maxRetries && (req.Body == nil || req.GetBody != nil)
+		if err != nil {
+			if !retry || !idempotent(req.Method) {
+				return nil, err
+			}
+			if err := sleep(req.Context(), backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !retry || !retryableStatus(req.Method, resp.StatusCode) {
+			return resp, nil
+		}
+		delay := backoff(attempt)
+		if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if after > maxRetryAfter {
+				return resp, nil
+			}
+			delay = after
+		}
+		resp.Body.Close()
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for d, returning early with the context's error if it is cancelled
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryableStatus reports whether a response status is worth retrying.
+// 429 and 503 mean the request wasn't processed, so any method can be retried.
+func retryableStatus(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(method)
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// backoff returns a random delay up to base*2^attempt ("full jitter"), capped at retryMaxDelay
+func backoff(attempt int) time.Duration {
+	limit := retryBaseDelay << attempt
+	if limit <= 0 || limit > retryMaxDelay {
+		limit = retryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(limit))) + time.Millisecond
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,149 @@
+package tailscale
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+var (
+	// cgnatRange is the IPv4 range Tailscale assigns device addresses from
+	cgnatRange = netip.MustParsePrefix("100.64.0.0/10")
+	// tailscaleULARange is the IPv6 range Tailscale assigns device addresses from
+	tailscaleULARange = netip.MustParsePrefix("fd7a:115c:a1e0::/48")
+)
+
+const (
+	// minIPv4RouteBits and minIPv6RouteBits reject routes broader than these
+	// prefix lengths, which almost always indicate a typo
+	minIPv4RouteBits = 8
+	minIPv6RouteBits = 32
+)
+
+// ValidateRoutes checks subnet routes before they are advertised: CIDR syntax,
+// host bits, overlap with Tailscale's own address ranges, and overly broad prefixes.
+// All problems are reported together.
+func ValidateRoutes(routes []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	var problems []string
+
+	for _, route := range routes {
+		route = strings.TrimSpace(route)
+		prefix, err := netip.ParsePrefix(route)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%q is not a valid CIDR (e.g., 192.168.1.0/24)", route))
+			continue
+		}
+
+		if masked := prefix.Masked(); masked != prefix {
+			problems = append(problems, fmt.Sprintf("%s has host bits set; did you mean %s?", route, masked))
+			continue
+		}
+
+		if prefix.Bits() == 0 {
+			problems = append(problems, fmt.Sprintf("%s is a default route; advertise an exit node instead", route))
+			continue
+		}
+
+		if prefix.Overlaps(cgnatRange) {
+			problems = append(problems, fmt.Sprintf("%s overlaps the Tailscale CGNAT range %s", route, cgnatRange))
+			continue
+		}
+		if prefix.Overlaps(tailscaleULARange) {
+			problems = append(problems, fmt.Sprintf("%s overlaps the Tailscale IPv6 range %s", route, tailscaleULARange))
+			continue
+		}
+
+		if prefix.Addr().Is4() && prefix.Bits() < minIPv4RouteBits {
+			problems = append(problems, fmt.Sprintf("%s is too broad (IPv4 routes must be /%d or longer)", route, minIPv4RouteBits))
+			continue
+		}
+		if prefix.Addr().Is6() && prefix.Bits() < minIPv6RouteBits {
+			problems = append(problems, fmt.Sprintf("%s is too broad (IPv6 routes must be /%d or longer)", route, minIPv6RouteBits))
+			continue
+		}
+
+		prefixes = append(prefixes, prefix)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid routes:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return prefixes, nil
+}
+
+// SummarizeRoutes aggregates IP addresses and CIDRs into the smallest set of
+// CIDRs covering exactly the same addresses. Bare IPs are treated as /32 or /128.
+func SummarizeRoutes(routes []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, route := range routes {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+
+		if !strings.Contains(route, "/") {
+			addr, err := netip.ParseAddr(route)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a valid IP address or CIDR", route)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(route)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid IP address or CIDR", route)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	// Sort IPv4 before IPv6, then by address, with broader prefixes first
+	sort.Slice(prefixes, func(i, j int) bool {
+		a, b := prefixes[i], prefixes[j]
+		if a.Addr().Is4() != b.Addr().Is4() {
+			return a.Addr().Is4()
+		}
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c < 0
+		}
+		return a.Bits() < b.Bits()
+	})
+
+	var result []netip.Prefix
+	for _, prefix := range prefixes {
+		// Skip prefixes already covered by the last kept one. Kept prefixes are
+		// disjoint and sorted, so only the last can contain this one.
+		if n := len(result); n > 0 {
+			last := result[n-1]
+			if last.Addr().Is4() == prefix.Addr().Is4() && last.Bits() <= prefix.Bits() && last.Contains(prefix.Addr()) {
+				continue
+			}
+		}
+
+		// Merge with the previous prefix while the two are halves of the same parent
+		for len(result) > 0 && prefix.Bits() > 0 {
+			last := result[len(result)-1]
+			parent := netip.PrefixFrom(prefix.Addr(), prefix.Bits()-1).Masked()
+			if last.Bits() != prefix.Bits() || last.Addr() != parent.Addr() || prefix.Addr() == parent.Addr() {
+				break
+			}
+			result = result[:len(result)-1]
+			prefix = parent
+		}
+
+		result = append(result, prefix)
+	}
+
+	return result, nil
+}
+
+// PrefixStrings converts prefixes to their string form
+func PrefixStrings(prefixes []netip.Prefix) []string {
+	result := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		result[i] = prefix.String()
+	}
+	return result
+}
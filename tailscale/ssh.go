@@ -0,0 +1,93 @@
+package tailscale
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SSHResult is the outcome of a command run over SSH
+type SSHResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// sshConnectionFailed is the exit status ssh uses for its own errors
+const sshConnectionFailed = 255
+
+// ValidateSSHTarget checks that host and user can't be read as ssh options
+// or change the target: neither may start with "-" or contain "@" or
+// whitespace. user may be empty for ssh's default.
+func ValidateSSHTarget(user, host string) error {
+	if host == "" || strings.HasPrefix(host, "-") || strings.Contains(host, "@") || strings.IndexFunc(host, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("host must be a device name or address")
+	}
+	if strings.HasPrefix(user, "-") || strings.Contains(user, "@") || strings.IndexFunc(user, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("ssh user %q is not a valid user name", user)
+	}
+	return nil
+}
+
+// SSHExec runs command on a tailnet host using `tailscale ssh`, which resolves
+// MagicDNS names and verifies host keys against the tailnet. The command runs
+// non-interactively, so hosts requiring Tailscale SSH check mode will fail.
+// A non-zero exit from the remote command is reported in the result, not as an error.
+func (c *CLI) SSHExec(ctx context.Context, user, host, command string, timeout time.Duration) (*SSHResult, error) {
+	if err := ValidateSSHTarget(user, host); err != nil {
+		return nil, err
+	}
+
+	target := host
+	if user != "" {
+		target = user + "@" + host
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	// The session is bounded by timeout, not the default command timeout
+	ctx = WithCommandTimeout(ctx, 0)
+
+	// "--" ends ssh's options, so a command starting with "-" isn't parsed as one
+	args := []string{"ssh", target, "-o", "BatchMode=yes", "-o", "ConnectTimeout=10", "--", command}
+	cmd, err := c.command(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	defer cmd.stop()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	result := &SSHResult{
+		Stdout: strings.TrimSpace(stdout.String()),
+		Stderr: strings.TrimSpace(stderr.String()),
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return result, fmt.Errorf("ssh to %s timed out after %s", host, timeout)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		if result.ExitCode == sshConnectionFailed {
+			return result, fmt.Errorf("ssh to %s failed: %s", host, result.Stderr)
+		}
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("ssh to %s failed: %v", host, err)
+	}
+
+	return result, nil
+}
@@ -7,6 +7,8 @@ import (
 
 // Status represents the Tailscale status
 type Status struct {
+	// Version is the tailscaled version (e.g., 1.76.1-t...)
+	Version       string              `json:"Version,omitempty"`
 	BackendState  string              `json:"BackendState"`
 	AuthURL       string              `json:"AuthURL,omitempty"`
 	Self          *PeerStatus         `json:"Self"`
@@ -28,6 +30,8 @@ type PeerStatus struct {
 	AllowedIPs       []string  `json:"AllowedIPs"`
 	Addrs            []string  `json:"Addrs"`
 	CurAddr          string    `json:"CurAddr"`
+	// Relay is the code of the peer's home DERP region
+	Relay            string    `json:"Relay,omitempty"`
 	RxBytes          int64     `json:"RxBytes"`
 	TxBytes          int64     `json:"TxBytes"`
 	Created          time.Time `json:"Created"`
@@ -44,6 +48,30 @@ type PeerStatus struct {
 	PrimaryRoutes    []string  `json:"PrimaryRoutes,omitempty"`
 	Expired          bool      `json:"Expired"`
 	KeyExpiry        time.Time `json:"KeyExpiry"`
+	// SSHHostKeys is set when the node runs the Tailscale SSH server
+	SSHHostKeys []string `json:"sshHostKeys,omitempty"`
+	// Location is set for Mullvad exit nodes
+	Location *Location `json:"Location,omitempty"`
+}
+
+// Location is where an exit node is, as reported for Mullvad exit nodes.
+// Higher Priority nodes are preferred within a location.
+type Location struct {
+	Country     string `json:"Country,omitempty"`
+	CountryCode string `json:"CountryCode,omitempty"`
+	City        string `json:"City,omitempty"`
+	CityCode    string `json:"CityCode,omitempty"`
+	Priority    int    `json:"Priority,omitempty"`
+}
+
+// ExitNodeListEntry is a row of `tailscale exit-node list`. Country and City
+// are empty for the tailnet's own exit nodes.
+type ExitNodeListEntry struct {
+	IP       string `json:"ip"`
+	Hostname string `json:"hostname"`
+	Country  string `json:"country,omitempty"`
+	City     string `json:"city,omitempty"`
+	Status   string `json:"status,omitempty"`
 }
 
 // TailnetStatus represents the current tailnet status
@@ -61,6 +89,40 @@ type User struct {
 	ProfilePicURL string        `json:"ProfilePicURL"`
 }
 
+// TailnetUser is a user of the tailnet as returned by the API
+type TailnetUser struct {
+	ID          string `json:"id"`
+	LoginName   string `json:"loginName"`
+	DisplayName string `json:"displayName"`
+	Role        string `json:"role"`
+	Status      string `json:"status"`
+	Type        string `json:"type"` // member or shared
+}
+
+// TailnetSettings are the tailnet-wide settings from the admin console
+type TailnetSettings struct {
+	DevicesApprovalOn                      bool   `json:"devicesApprovalOn"`
+	DevicesAutoUpdatesOn                   bool   `json:"devicesAutoUpdatesOn"`
+	DevicesKeyDurationDays                 int    `json:"devicesKeyDurationDays"`
+	UsersApprovalOn                        bool   `json:"usersApprovalOn"`
+	UsersRoleAllowedToJoinExternalTailnets string `json:"usersRoleAllowedToJoinExternalTailnets"`
+	NetworkFlowLoggingOn                   bool   `json:"networkFlowLoggingOn"`
+	RegionalRoutingOn                      bool   `json:"regionalRoutingOn"`
+	PostureIdentityCollectionOn            bool   `json:"postureIdentityCollectionOn"`
+}
+
+// TailnetSettingsUpdate holds the settings to change; nil fields are left as they are
+type TailnetSettingsUpdate struct {
+	DevicesApprovalOn                      *bool   `json:"devicesApprovalOn,omitempty"`
+	DevicesAutoUpdatesOn                   *bool   `json:"devicesAutoUpdatesOn,omitempty"`
+	DevicesKeyDurationDays                 *int    `json:"devicesKeyDurationDays,omitempty"`
+	UsersApprovalOn                        *bool   `json:"usersApprovalOn,omitempty"`
+	UsersRoleAllowedToJoinExternalTailnets *string `json:"usersRoleAllowedToJoinExternalTailnets,omitempty"`
+	NetworkFlowLoggingOn                   *bool   `json:"networkFlowLoggingOn,omitempty"`
+	RegionalRoutingOn                      *bool   `json:"regionalRoutingOn,omitempty"`
+	PostureIdentityCollectionOn            *bool   `json:"postureIdentityCollectionOn,omitempty"`
+}
+
 // Profile represents a Tailscale profile
 type Profile struct {
 	ID       string `json:"id"`       // Profile ID (e.g., "826b")
@@ -72,6 +134,7 @@ type Profile struct {
 // Device represents a device in the network
 type Device struct {
 	ID            string    `json:"id"`
+	NodeID        string    `json:"nodeId,omitempty"`
 	Name          string    `json:"name"`
 	Hostname      string    `json:"hostname"`
 	OS            string    `json:"os"`
@@ -79,11 +142,21 @@ type Device struct {
 	User          string    `json:"user"`
 	Tags          []string  `json:"tags"`
 	Authorized    bool      `json:"authorized"`
-	KeyExpiry     time.Time `json:"keyExpiry"`
+	Created       time.Time `json:"created"`
+	// KeyExpiry is when the node key expires; zero when expiry is disabled
+	KeyExpiry         time.Time `json:"expires"`
+	KeyExpiryDisabled bool      `json:"keyExpiryDisabled"`
 	LastSeen      time.Time `json:"lastSeen"`
 	Online        bool      `json:"online"`
 	ExitNode      bool      `json:"exitNode"`
 	PrimaryRoutes []string  `json:"primaryRoutes,omitempty"`
+	MachineKey      string `json:"machineKey,omitempty"`
+	NodeKey         string `json:"nodeKey,omitempty"`
+	ClientVersion   string `json:"clientVersion,omitempty"`
+	UpdateAvailable bool   `json:"updateAvailable"`
+	// AdvertisedRoutes and EnabledRoutes are only returned by ListAllDeviceFields
+	AdvertisedRoutes []string `json:"advertisedRoutes,omitempty"`
+	EnabledRoutes    []string `json:"enabledRoutes,omitempty"`
 }
 
 // ACL represents Access Control List configuration
@@ -94,14 +167,22 @@ type ACL struct {
 	ACLs       []ACLRule           `json:"acls"`
 	Tests      []ACLTest           `json:"tests,omitempty"`
 	AutoApprovers map[string][]string `json:"autoApprovers,omitempty"`
+	Grants     []PolicyGrant       `json:"grants,omitempty"`
+	SSH        []PolicySSHRule     `json:"ssh,omitempty"`
+	NodeAttrs  []PolicyNodeAttr    `json:"nodeAttrs,omitempty"`
 	RawPolicy  string              `json:"-"` // Raw HuJSON policy from API
+	ETag       string              `json:"-"` // Version of the policy from API, sent as If-Match on updates
 }
 
-// ACLRule represents a single ACL rule
+// ACLRule represents a single ACL rule. Src and Dst are the current field
+// names; Users and Ports are the legacy ones older policies still use
 type ACLRule struct {
 	Action string   `json:"action"`
-	Users  []string `json:"users"`
-	Ports  []string `json:"ports"`
+	Src    []string `json:"src,omitempty"`
+	Dst    []string `json:"dst,omitempty"`
+	Proto  string   `json:"proto,omitempty"`
+	Users  []string `json:"users,omitempty"`
+	Ports  []string `json:"ports,omitempty"`
 }
 
 // ACLTest represents an ACL test case
@@ -111,16 +192,196 @@ type ACLTest struct {
 	Deny  []string `json:"deny,omitempty"`
 }
 
-// AuthKey represents an authentication key
+// ACLTestReport is the validate endpoint's verdict on a policy. Message is
+// empty when the policy is valid and all of its tests pass
+type ACLTestReport struct {
+	Message string           `json:"message"`
+	Data    []ACLTestFailure `json:"data,omitempty"`
+}
+
+// ACLTestFailure lists the failed assertions for one test source
+type ACLTestFailure struct {
+	User     string   `json:"user"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ACLPreview is the result of previewing a policy for a user or ip:port
+type ACLPreview struct {
+	Type       string            `json:"type"`
+	PreviewFor string            `json:"previewFor"`
+	Matches    []ACLPreviewMatch `json:"matches"`
+}
+
+// ACLPreviewMatch is a policy rule that applies to the previewed user or ip:port
+type ACLPreviewMatch struct {
+	Users      []string `json:"users"`
+	Ports      []string `json:"ports"`
+	LineNumber int      `json:"lineNumber"`
+}
+
+// AuthKey represents an authentication key. The API reports the key's
+// options under capabilities; they are copied to the flat fields on decode.
+
 type AuthKey struct {
-	ID          string    `json:"id"`
-	Key         string    `json:"key"`
-	Created     time.Time `json:"created"`
-	Expires     time.Time `json:"expires"`
-	Reusable    bool      `json:"reusable"`
-	Ephemeral   bool      `json:"ephemeral"`
-	Preauthorized bool    `json:"preauthorized"`
-	Tags        []string  `json:"tags,omitempty"`
+	ID            string               `json:"id"`
+	Key           string               `json:"key"`
+	Description   string               `json:"description,omitempty"`
+	Created       time.Time            `json:"created"`
+	Expires       time.Time            `json:"expires"`
+	Revoked       *time.Time           `json:"revoked,omitempty"`
+	Invalid       bool                 `json:"invalid,omitempty"`
+	UserID        string               `json:"userId,omitempty"`
+	KeyType       string               `json:"keyType,omitempty"`
+	Scopes        []string             `json:"scopes,omitempty"`
+	Reusable      bool                 `json:"reusable"`
+	Ephemeral     bool                 `json:"ephemeral"`
+	Preauthorized bool                 `json:"preauthorized"`
+	Tags          []string             `json:"tags,omitempty"`
+	Capabilities  *AuthKeyCapabilities `json:"capabilities,omitempty"`
+}
+
+// AuthKeyCapabilities are what devices joining with an auth key get
+type AuthKeyCapabilities struct {
+	Devices struct {
+		Create struct {
+			Reusable      bool     `json:"reusable"`
+			Ephemeral     bool     `json:"ephemeral"`
+			Preauthorized bool     `json:"preauthorized"`
+			Tags          []string `json:"tags,omitempty"`
+		} `json:"create"`
+	} `json:"devices"`
+}
+
+// applyCapabilities copies the key's capabilities to its flat fields
+func (k *AuthKey) applyCapabilities() {
+	if k.Capabilities == nil {
+		return
+	}
+	create := k.Capabilities.Devices.Create
+	k.Reusable = create.Reusable
+	k.Ephemeral = create.Ephemeral
+	k.Preauthorized = create.Preauthorized
+	if len(k.Tags) == 0 {
+		k.Tags = create.Tags
+	}
+}
+
+// Webhook is a webhook endpoint that the control plane sends tailnet events to
+type Webhook struct {
+	EndpointID       string    `json:"endpointId"`
+	EndpointURL      string    `json:"endpointUrl"`
+	ProviderType     string    `json:"providerType"`
+	CreatorLoginName string    `json:"creatorLoginName"`
+	Created          time.Time `json:"created"`
+	LastModified     time.Time `json:"lastModified"`
+	Subscriptions    []string  `json:"subscriptions"`
+	// Secret signs deliveries; the API only returns it on creation
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookEvents are the event types a webhook endpoint can subscribe to
+var WebhookEvents = []string{
+	"nodeCreated",
+	"nodeNeedsApproval",
+	"nodeApproved",
+	"nodeKeyExpiringInOneDay",
+	"nodeKeyExpired",
+	"nodeDeleted",
+	"nodeSigned",
+	"nodeNeedsSignature",
+	"policyUpdate",
+	"userCreated",
+	"userNeedsApproval",
+	"userSuspended",
+	"userRestored",
+	"userDeleted",
+	"userApproved",
+	"userRoleUpdated",
+	"subnetIPForwardingNotEnabled",
+	"exitNodeIPForwardingNotEnabled",
+}
+
+// ConfigurationAuditLog is one change to the tailnet configuration, such as
+// a device being authorized or the policy file being edited
+type ConfigurationAuditLog struct {
+	EventGroupID string          `json:"eventGroupID"`
+	EventTime    time.Time       `json:"eventTime"`
+	Origin       string          `json:"origin"`
+	Type         string          `json:"type"`
+	Action       string          `json:"action"`
+	Actor        AuditLogActor   `json:"actor"`
+	Target       AuditLogTarget  `json:"target"`
+	Old          json.RawMessage `json:"old,omitempty"`
+	New          json.RawMessage `json:"new,omitempty"`
+	Details      string          `json:"actionDetails,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// AuditLogActor is who made a configuration change
+type AuditLogActor struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	LoginName   string `json:"loginName,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// AuditLogTarget is what a configuration change applied to
+type AuditLogTarget struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type"`
+	Property string `json:"property,omitempty"`
+}
+
+// NetworkFlowLog is the traffic one node logged over a period of time
+type NetworkFlowLog struct {
+	Logged          time.Time        `json:"logged"`
+	NodeID          string           `json:"nodeId"`
+	Start           time.Time        `json:"start"`
+	End             time.Time        `json:"end"`
+	VirtualTraffic  []FlowConnection `json:"virtualTraffic,omitempty"`
+	SubnetTraffic   []FlowConnection `json:"subnetTraffic,omitempty"`
+	ExitTraffic     []FlowConnection `json:"exitTraffic,omitempty"`
+	PhysicalTraffic []FlowConnection `json:"physicalTraffic,omitempty"`
+}
+
+// FlowConnection is the traffic counted for one connection. Src and Dst are
+// ip:port; Proto is the IP protocol number (6 for TCP, 17 for UDP).
+type FlowConnection struct {
+	Proto   int    `json:"proto,omitempty"`
+	Src     string `json:"src,omitempty"`
+	Dst     string `json:"dst,omitempty"`
+	TxPkts  uint64 `json:"txPkts,omitempty"`
+	TxBytes uint64 `json:"txBytes,omitempty"`
+	RxPkts  uint64 `json:"rxPkts,omitempty"`
+	RxBytes uint64 `json:"rxBytes,omitempty"`
+}
+
+// DeviceRoutes is a device's subnet routes. Advertised routes are the ones
+// the device offers; enabled routes are the ones approved for use.
+type DeviceRoutes struct {
+	AdvertisedRoutes []string `json:"advertisedRoutes"`
+	EnabledRoutes    []string `json:"enabledRoutes"`
+}
+
+// Service is a Tailscale Service: a name and virtual IPs that clients reach,
+// backed by whichever approved hosts advertise it
+type Service struct {
+	Name        string            `json:"name"`
+	Addrs       []string          `json:"addrs,omitempty"`
+	Comment     string            `json:"comment,omitempty"`
+	Ports       []string          `json:"ports,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ServiceHost is a device advertising a Tailscale Service
+type ServiceHost struct {
+	DeviceID   string `json:"deviceId"`
+	Hostname   string `json:"hostname,omitempty"`
+	Approved   bool   `json:"approved"`
+	Configured bool   `json:"configured,omitempty"`
 }
 
 // DNSConfig represents DNS configuration
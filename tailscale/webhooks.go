@@ -0,0 +1,68 @@
+package tailscale
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSignatureHeader carries the signature of a webhook delivery
+const WebhookSignatureHeader = "Tailscale-Webhook-Signature"
+
+// webhookMaxAge is how old a delivery's timestamp may be before it is
+// rejected as a possible replay
+const webhookMaxAge = 5 * time.Minute
+
+// WebhookEvent is one event in a webhook delivery
+type WebhookEvent struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Version   int                    `json:"version"`
+	Type      string                 `json:"type"`
+	Tailnet   string                 `json:"tailnet"`
+	Message   string                 `json:"message"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// VerifyWebhookSignature checks a delivery's signature header, of the form
+// "t=<unix time>,v1=<hex HMAC-SHA256>", against body and the endpoint secret.
+// The HMAC covers "<unix time>.<body>"; deliveries older than five minutes
+// are rejected so captured requests can't be replayed.
+func VerifyWebhookSignature(header string, body []byte, secret string, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("malformed %s header", WebhookSignatureHeader)
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed timestamp %q", timestamp)
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > webhookMaxAge || age < -webhookMaxAge {
+		return fmt.Errorf("timestamp is %s off, outside the allowed %s", age.Round(time.Second), webhookMaxAge)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if got, err := hex.DecodeString(signature); err == nil && hmac.Equal(got, expected) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature does not match")
+}
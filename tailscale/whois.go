@@ -0,0 +1,46 @@
+package tailscale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// WhoIs identifies the node and user behind a tailnet address
+type WhoIs struct {
+	Node struct {
+		ID   json.Number `json:"ID"`
+		Name string      `json:"Name"`
+		Tags []string    `json:"Tags,omitempty"`
+	} `json:"Node"`
+	UserProfile struct {
+		LoginName   string `json:"LoginName"`
+		DisplayName string `json:"DisplayName"`
+	} `json:"UserProfile"`
+}
+
+// Tagged reports whether the node is owned by tags rather than a user
+func (w *WhoIs) Tagged() bool {
+	return len(w.Node.Tags) > 0
+}
+
+// WhoIs looks up the node and user that own addr (an IP, or IP:port)
+func (c *CLI) WhoIs(ctx context.Context, addr string) (*WhoIs, error) {
+	if c.local.Available() {
+		if who, err := c.local.WhoIs(ctx, addr); err == nil {
+			return who, nil
+		}
+	}
+
+	// whois takes flags before the address, so ExecuteJSON's trailing --json won't do
+	output, err := c.Execute(ctx, "whois", "--json", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var who WhoIs
+	if err := json.Unmarshal([]byte(output), &who); err != nil {
+		return nil, fmt.Errorf("failed to parse whois output: %w", err)
+	}
+	return &who, nil
+}
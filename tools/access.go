@@ -0,0 +1,403 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/store"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const (
+	accessRequestsBucket  = "access_requests"
+	defaultAccessDuration = time.Hour
+	maxAccessDuration     = 7 * 24 * time.Hour
+)
+
+// Access request statuses
+const (
+	AccessPending  = "pending"
+	AccessApproved = "approved"
+	AccessDenied   = "denied"
+	// accessApproving marks a request whose rule is being applied, so a
+	// concurrent approval sees it as decided
+	accessApproving = "approving"
+)
+
+// AccessRequest is a request for temporary access from a source to a destination
+type AccessRequest struct {
+	ID        string    `json:"id"`
+	Requester string    `json:"requester,omitempty"`
+	Src       string    `json:"src"`
+	Dst       string    `json:"dst"`
+	Duration  string    `json:"duration"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+	Created   time.Time `json:"created"`
+	DecidedBy string    `json:"decided_by,omitempty"`
+	DecidedAt time.Time `json:"decided_at,omitempty"`
+	RuleID    string    `json:"rule_id,omitempty"`
+	Expires   time.Time `json:"expires,omitempty"`
+}
+
+// RegisterAccessRequestTools registers the access request and approval workflow tools
+func RegisterAccessRequestTools(server *mcp.Server, api *tailscale.APIClient, st *store.Store) {
+	// Request access tool
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "request_access",
+			Description: "Request temporary access from a source (group, user or tag) to a destination host:port. The request is stored until an approver runs approve_access.",
+			Annotations: updateTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"src": {
+						Type:        "string",
+						Description: "Who needs access (e.g., group:eng, alice@example.com, tag:ci)",
+					},
+					"dst": {
+						Type:        "string",
+						Description: "Target and ports (e.g., tag:db:5432, 100.101.102.103:22, prod-host:80,443)",
+					},
+					"duration": {
+						Type:        "string",
+						Description: "How long access is needed (e.g., 30m, 4h) (default: 1h, max: 168h)",
+					},
+					"reason": {
+						Type:        "string",
+						Description: "Why access is needed",
+					},
+					"requester": {
+						Type:        "string",
+						Description: "Name or email of the person requesting access (optional)",
+					},
+				},
+				Required: []string{"src", "dst", "reason"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Src       string `json:"src"`
+				Dst       string `json:"dst"`
+				Duration  string `json:"duration"`
+				Reason    string `json:"reason"`
+				Requester string `json:"requester"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			if err := validateAccessTarget(params.Src, params.Dst); err != nil {
+				return errorResult("Invalid access request", err), nil
+			}
+			if strings.TrimSpace(params.Reason) == "" {
+				return invalidInputResult("Invalid access request: a reason is required"), nil
+			}
+
+			duration, err := parseAccessDuration(params.Duration)
+			if err != nil {
+				return errorResult("Invalid access request", err), nil
+			}
+
+			request := &AccessRequest{
+				ID:        newRecordID(),
+				Requester: params.Requester,
+				Src:       strings.TrimSpace(params.Src),
+				Dst:       strings.TrimSpace(params.Dst),
+				Duration:  duration.String(),
+				Reason:    params.Reason,
+				Status:    AccessPending,
+				Created:   time.Now(),
+			}
+
+			var requests map[string]*AccessRequest
+			err = st.Update(accessRequestsBucket, &requests, func() error {
+				if requests == nil {
+					requests = make(map[string]*AccessRequest)
+				}
+				requests[request.ID] = request
+				return nil
+			})
+			if err != nil {
+				return errorResult("Error saving access request", err), nil
+			}
+
+			notifySessions(ctx, server, "notice", fmt.Sprintf("New access request %s: %s → %s for %s (%s)",
+				request.ID, request.Src, request.Dst, request.Duration, request.Reason))
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Access request %s submitted: %s → %s for %s\n\nAn approver can grant it with approve_access (request_id=%s).",
+						request.ID, request.Src, request.Dst, request.Duration, request.ID)},
+				},
+			}, nil
+		}),
+	)
+
+	// Approve access tool
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "approve_access",
+			Description: "Approve or deny a pending access request. Approval adds a temporary ACL rule (validated before applying) that is removed automatically when the requested duration ends. Approving needs an approver other than the requester.",
+			Annotations: destructiveTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"request_id": {
+						Type:        "string",
+						Description: "ID of the access request",
+					},
+					"approve": {
+						Type:        "boolean",
+						Description: "true to approve, false to deny (default: true)",
+					},
+					"approver": {
+						Type:        "string",
+						Description: "Name or email of the approver, recorded with the decision (required to approve; must differ from the requester)",
+					},
+				},
+				Required: []string{"request_id"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				RequestID string `json:"request_id"`
+				Approve   *bool  `json:"approve"`
+				Approver  string `json:"approver"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			approve := params.Approve == nil || *params.Approve
+			approver := strings.TrimSpace(params.Approver)
+			if approve && approver == "" {
+				return invalidInputResult("approver is required to approve an access request"), nil
+			}
+
+			// Claim the request in one store update, so two concurrent
+			// decisions can't both act on it
+			var request AccessRequest
+			var refused *mcp.CallToolResult
+			var requests map[string]*AccessRequest
+			err := st.Update(accessRequestsBucket, &requests, func() error {
+				pending, ok := requests[params.RequestID]
+				switch {
+				case !ok:
+					refused = notFoundResult(fmt.Sprintf("Access request %s not found", params.RequestID))
+				case pending.Status != AccessPending:
+					refused = refusedResult(fmt.Sprintf("Access request %s was already %s", pending.ID, pending.Status))
+				case approve && pending.Requester != "" && strings.EqualFold(strings.TrimSpace(pending.Requester), approver):
+					refused = refusedResult(fmt.Sprintf("%s requested access request %s and can't approve it", approver, pending.ID))
+				}
+				if refused != nil {
+					return fmt.Errorf("access request %s can't be decided", params.RequestID)
+				}
+
+				pending.DecidedBy = approver
+				pending.DecidedAt = time.Now()
+				pending.Status = AccessDenied
+				if approve {
+					pending.Status = accessApproving
+				}
+				request = *pending
+				return nil
+			})
+			if refused != nil {
+				return refused, nil
+			}
+			if err != nil {
+				return errorResult("Error recording the decision", err), nil
+			}
+
+			if !approve {
+				return textResult(fmt.Sprintf("Access request %s denied.\n", request.ID)), nil
+			}
+
+			var result strings.Builder
+			duration, err := time.ParseDuration(request.Duration)
+			if err != nil {
+				duration = defaultAccessDuration
+			}
+
+			rule := tailscale.PolicyRule{
+				Action: "accept",
+				Src:    []string{request.Src},
+				Dst:    []string{request.Dst},
+			}
+			temp, policyDiff, err := addTemporaryRule(ctx, api, st, rule, duration, request.Reason, "access_request:"+request.ID)
+			if temp == nil {
+				// Nothing was granted, so the request can be decided again
+				st.Update(accessRequestsBucket, &requests, func() error {
+					if r, ok := requests[request.ID]; ok && r.Status == accessApproving {
+						r.Status = AccessPending
+						r.DecidedBy = ""
+						r.DecidedAt = time.Time{}
+					}
+					return nil
+				})
+				return errorResult(fmt.Sprintf("Error granting access request %s", request.ID), err), nil
+			}
+			if err != nil {
+				result.WriteString(fmt.Sprintf("⚠ %v\n\n", err))
+			}
+
+			result.WriteString(fmt.Sprintf("✓ Access request %s approved by %s: %s → %s until %s\n", request.ID, approver, request.Src, request.Dst, temp.Expires.Format(time.RFC3339)))
+			result.WriteString(fmt.Sprintf("  Temporary rule %s will be removed automatically when it expires.\n", temp.ID))
+			if policyDiff != "" {
+				result.WriteString("\nApplied ACL change:\n")
+				result.WriteString(policyDiff)
+			}
+
+			err = st.Update(accessRequestsBucket, &requests, func() error {
+				if r, ok := requests[request.ID]; ok {
+					r.Status = AccessApproved
+					r.RuleID = temp.ID
+					r.Expires = temp.Expires
+				}
+				return nil
+			})
+			if err != nil {
+				result.WriteString(fmt.Sprintf("\n⚠ Could not record the decision: %v\n", err))
+			}
+
+			return textResult(result.String()), nil
+		}),
+	)
+
+	// List access requests tool
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "list_access_requests",
+			Description: "List access requests and their status",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"status": {
+						Type:        "string",
+						Description: "Only show requests with this status: pending, approved, denied (optional)",
+						Enum:        []any{AccessPending, AccessApproved, AccessDenied},
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Status string `json:"status"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			var requests map[string]*AccessRequest
+			if err := st.Load(accessRequestsBucket, &requests); err != nil {
+				return errorResult("Error loading access requests", err), nil
+			}
+
+			var list []*AccessRequest
+			for _, request := range requests {
+				if params.Status == "" || request.Status == params.Status {
+					list = append(list, request)
+				}
+			}
+			sort.Slice(list, func(i, j int) bool { return list[i].Created.After(list[j].Created) })
+
+			if len(list) == 0 {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: "No access requests found."},
+					},
+				}, nil
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Access Requests (%d):\n\n", len(list)))
+			now := time.Now()
+			for _, request := range list {
+				status := request.Status
+				if status == AccessApproved && now.After(request.Expires) {
+					status = "expired"
+				}
+				result.WriteString(fmt.Sprintf("%s [%s] %s → %s for %s\n", request.ID, status, request.Src, request.Dst, request.Duration))
+				if request.Requester != "" {
+					result.WriteString(fmt.Sprintf("  Requested by: %s\n", request.Requester))
+				}
+				result.WriteString(fmt.Sprintf("  Reason: %s\n", request.Reason))
+				result.WriteString(fmt.Sprintf("  Created: %s\n", request.Created.Format(time.RFC3339)))
+				if request.DecidedBy != "" {
+					result.WriteString(fmt.Sprintf("  Decided by: %s\n", request.DecidedBy))
+				}
+				if request.Status == AccessApproved {
+					result.WriteString(fmt.Sprintf("  Expires: %s (rule %s)\n", request.Expires.Format(time.RFC3339), request.RuleID))
+				}
+				result.WriteString("\n")
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"requests": list}), nil
+		}),
+	)
+}
+
+// validateAccessTarget checks the source and host:port destination of a rule
+func validateAccessTarget(src, dst string) error {
+	src = strings.TrimSpace(src)
+	dst = strings.TrimSpace(dst)
+	if src == "" || strings.ContainsAny(src, " ,") {
+		return fmt.Errorf("src must be a single group, user, or tag (e.g., group:eng)")
+	}
+
+	i := strings.LastIndex(dst, ":")
+	if i <= 0 || i == len(dst)-1 {
+		return fmt.Errorf("dst must be in host:port form (e.g., tag:db:5432)")
+	}
+	for _, port := range strings.Split(dst[i+1:], ",") {
+		if port == "*" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(port, "-")
+		if !isNumeric(lo) || (isRange && !isNumeric(hi)) {
+			return fmt.Errorf("invalid port %q in dst (use e.g. 22, 80,443, 8000-8100 or *)", port)
+		}
+	}
+	return nil
+}
+
+// parseAccessDuration parses a requested duration, applying the default and maximum
+func parseAccessDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultAccessDuration, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %q: use a positive Go duration such as 30m or 4h", value)
+	}
+	if duration > maxAccessDuration {
+		return 0, fmt.Errorf("duration %s exceeds the maximum of %s", duration, maxAccessDuration)
+	}
+	return duration, nil
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -4,43 +4,79 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/diff"
 	"github.com/phildougherty/go-tailscale-mcp/tailscale"
 )
 
 // RegisterACLTools registers ACL management tools
-func RegisterACLTools(server *mcp.Server, api *tailscale.APIClient) {
+func RegisterACLTools(server *mcp.Server, api *tailscale.APIClient, output *OutputWriter) {
 	// Get ACL tool
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "get_acl",
-			Description: "Get the current ACL (Access Control List) policy",
-			InputSchema: &jsonschema.Schema{Type: "object"},
+			Description: "Get the current ACL (Access Control List) policy, optionally saving it to a file",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"output_file": {
+						Type:        "string",
+						Description: "Save the policy to this file (relative to the client's roots or the configured output directory) (optional)",
+					},
+				},
+			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				OutputFile string `json:"output_file"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
 			}
 
-			acl, err := api.GetACL()
+			acl, err := api.GetACL(ctx)
 			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+
+			// Export the policy to a file if requested
+			if params.OutputFile != "" {
+				link, err := output.Write(ctx, req.Session, params.OutputFile, []byte(acl.RawPolicy), "application/hujson")
+				if err != nil {
+					return errorResult("Error saving ACL", err), nil
+				}
+
 				return &mcp.CallToolResult{
 					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting ACL: %v", err)},
+						&mcp.TextContent{Text: fmt.Sprintf("ACL policy saved to %s (%d bytes)", link.URI, *link.Size)},
+						link,
 					},
 				}, nil
 			}
 
-			// Return the raw HuJSON policy
+			// Return the raw HuJSON policy, with the decoded policy as structured content
+			text := fmt.Sprintf("Current ACL Policy (HuJSON format):\n\n%s", acl.RawPolicy)
+			if acl.ETag != "" {
+				text = fmt.Sprintf("ETag: %s (pass as etag to update_acl to avoid overwriting concurrent edits)\n\n%s", acl.ETag, text)
+			}
+			var decoded map[string]interface{}
+			if policy, err := tailscale.ParsePolicy(acl.RawPolicy); err == nil && policy.Decode(&decoded) == nil {
+				return structuredResult(text, decoded), nil
+			}
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Current ACL Policy (HuJSON format):\n\n%s", acl.RawPolicy)},
+					&mcp.TextContent{Text: text},
 				},
 			}, nil
 		}),
@@ -51,60 +87,74 @@ func RegisterACLTools(server *mcp.Server, api *tailscale.APIClient) {
 		&mcp.Tool{
 			Name:        "update_acl",
 			Description: "Update the ACL (Access Control List) policy",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
 					"acl": {
 						Type:        "string",
-						Description: "ACL policy in JSON format",
+						Description: "ACL policy in HuJSON or JSON format",
 					},
+					"etag": {
+						Type:        "string",
+						Description: "ETag reported by get_acl. When set, the update fails instead of overwriting changes made since (optional)",
+					},
+					"dry_run": dryRunProperty("validate the policy and show the diff against the current one"),
 				},
 				Required: []string{"acl"},
 			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
 			}
 
 			var params struct {
-				ACL string `json:"acl"`
+				ACL    string `json:"acl"`
+				ETag   string `json:"etag"`
+				DryRun bool   `json:"dry_run"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
-			// Try to parse as JSON first, otherwise treat as HuJSON
-			var acl tailscale.ACL
-			if err := json.Unmarshal([]byte(params.ACL), &acl); err != nil {
-				// Not valid JSON, treat as HuJSON
-				acl.RawPolicy = params.ACL
+			// Send the policy as written: JSON is valid HuJSON, and decoding
+			// into the ACL struct would drop sections it doesn't model
+			acl, problem := parsePolicyInput(params.ACL)
+			if problem != "" {
+				return invalidInputResult(problem), nil
 			}
+			acl.ETag = params.ETag
 
 			// Validate the ACL first
-			if err := api.ValidateACL(&acl); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("ACL validation failed: %v", err)},
-					},
-				}, nil
+			if err := api.ValidateACL(ctx, acl); err != nil {
+				return errorResult("ACL validation failed", err), nil
+			}
+
+			if params.DryRun {
+				current, err := api.GetACL(ctx)
+				if err != nil {
+					return errorResult("Error getting ACL", err), nil
+				}
+				var result strings.Builder
+				result.WriteString("Dry run: update the ACL policy\n\n✓ The policy is valid\n")
+				policyDiff := diff.Unified("policy.hujson (current)", "policy.hujson (proposed)", current.RawPolicy, acl.RawPolicy)
+				if policyDiff == "" {
+					result.WriteString("\nThe policy is unchanged. Nothing to do.\n")
+					return structuredResult(result.String(), map[string]interface{}{"dry_run": true, "changed": false}), nil
+				}
+				if params.ETag != "" && current.ETag != "" && params.ETag != current.ETag {
+					result.WriteString("⚠ The policy has changed since the given etag was fetched; the update would fail\n")
+				}
+				result.WriteString("\nPolicy diff:\n")
+				result.WriteString(policyDiff)
+				result.WriteString("\nSet dry_run=false to apply it.\n")
+				return structuredResult(result.String(), map[string]interface{}{"dry_run": true, "changed": true, "diff": policyDiff}), nil
 			}
 
 			// Update the ACL
-			if err := api.SetACL(&acl); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error updating ACL: %v", err)},
-					},
-				}, nil
+			if _, err := api.SetACL(ctx, acl); err != nil {
+				return errorResult("Error updating ACL", err), nil
 			}
 
 			return &mcp.CallToolResult{
@@ -120,12 +170,13 @@ func RegisterACLTools(server *mcp.Server, api *tailscale.APIClient) {
 		&mcp.Tool{
 			Name:        "validate_acl",
 			Description: "Validate an ACL (Access Control List) policy without applying it",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
 					"acl": {
 						Type:        "string",
-						Description: "ACL policy in JSON format to validate",
+						Description: "ACL policy in HuJSON or JSON format to validate",
 					},
 				},
 				Required: []string{"acl"},
@@ -133,45 +184,281 @@ func RegisterACLTools(server *mcp.Server, api *tailscale.APIClient) {
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
 			}
 
 			var params struct {
 				ACL string `json:"acl"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			acl, problem := parsePolicyInput(params.ACL)
+			if problem != "" {
+				return invalidInputResult(problem), nil
+			}
+
+			// Validate the ACL
+			if err := api.ValidateACL(ctx, acl); err != nil {
+				return errorResult("ACL validation failed", err), nil
+			}
+
+			return structuredResult("ACL policy is valid.", map[string]interface{}{"valid": true}), nil
+		}),
+	)
+	// Rename tag tool
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "rename_tag",
+			Description: "Rename a tag across the ACL policy (tagOwners, acls, grants, ssh, autoApprovers, tests) and retag every device carrying it. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"old_tag": {
+						Type:        "string",
+						Description: "Current tag name (e.g., tag:web or web)",
+					},
+					"new_tag": {
+						Type:        "string",
+						Description: "New tag name (e.g., tag:frontend or frontend)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff and affected devices without changing anything (default: true)",
+					},
+				},
+				Required: []string{"old_tag", "new_tag"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				OldTag string `json:"old_tag"`
+				NewTag string `json:"new_tag"`
+				DryRun *bool  `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			oldTag := tailscale.NormalizeTag(params.OldTag)
+			newTag := tailscale.NormalizeTag(params.NewTag)
+			if oldTag == "" || newTag == "" || oldTag == newTag {
+				return invalidInputResult("old_tag and new_tag are required and must differ"), nil
+			}
+			dryRun := params.DryRun == nil || *params.DryRun
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+
+			policy, err := tailscale.ParseACL(acl)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+
+			renamed := policy.Clone()
+			counts, err := renamed.RenameTag(oldTag, newTag)
+			if err != nil {
+				return errorResult("Cannot rename tag", err), nil
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+
+			var affected []tailscale.Device
+			for _, device := range devices {
+				if containsString(device.Tags, oldTag) {
+					affected = append(affected, device)
+				}
+			}
+
+			var result strings.Builder
+			if dryRun {
+				result.WriteString(fmt.Sprintf("Dry run: rename %s → %s\n\n", oldTag, newTag))
+			} else {
+				result.WriteString(fmt.Sprintf("Renaming %s → %s\n\n", oldTag, newTag))
+			}
+
+			if len(counts) == 0 && len(affected) == 0 {
+				result.WriteString(fmt.Sprintf("%s is not referenced in the ACL policy or carried by any device. Nothing to do.\n", oldTag))
 				return &mcp.CallToolResult{
 					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
+						&mcp.TextContent{Text: result.String()},
 					},
 				}, nil
 			}
 
-			// Try to parse as JSON first, otherwise treat as HuJSON
-			var acl tailscale.ACL
-			if err := json.Unmarshal([]byte(params.ACL), &acl); err != nil {
-				// Not valid JSON, treat as HuJSON
-				acl.RawPolicy = params.ACL
+			// Summarize policy changes per section
+			sections := make([]string, 0, len(counts))
+			for section := range counts {
+				sections = append(sections, section)
 			}
+			sort.Strings(sections)
 
-			// Validate the ACL
-			if err := api.ValidateACL(&acl); err != nil {
+			result.WriteString("Policy references:\n")
+			if len(sections) == 0 {
+				result.WriteString("  (none)\n")
+			}
+			for _, section := range sections {
+				result.WriteString(fmt.Sprintf("  %s: %d\n", section, counts[section]))
+			}
+
+			result.WriteString(fmt.Sprintf("\nDevices to retag (%d):\n", len(affected)))
+			if len(affected) == 0 {
+				result.WriteString("  (none)\n")
+			}
+			for _, device := range affected {
+				result.WriteString(fmt.Sprintf("  %s (%s): %s\n", device.Name, device.ID, strings.Join(device.Tags, ", ")))
+			}
+
+			if dryRun {
+				if policyDiff := diff.Unified("policy.hujson (current)", "policy.hujson (renamed)", policy.String(), renamed.String()); policyDiff != "" {
+					result.WriteString("\nPolicy diff:\n")
+					result.WriteString(policyDiff)
+				}
+				result.WriteString("\nRe-run with dry_run=false to apply these changes.\n")
 				return &mcp.CallToolResult{
 					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("ACL validation failed: %v", err)},
+						&mcp.TextContent{Text: result.String()},
 					},
 				}, nil
 			}
 
+			// Apply in three steps so devices keep their access while being retagged:
+			// 1. a transitional policy that grants both tags the same access
+			// 2. retag the devices
+			// 3. the final policy with the old tag removed
+			transitional := policy.Clone()
+			transitional.AliasTag(oldTag, newTag)
+
+			for _, step := range []*tailscale.Policy{transitional, renamed} {
+				if err := api.ValidateACL(ctx, step.ACL()); err != nil {
+					return errorResult("ACL validation failed, nothing was changed", err), nil
+				}
+			}
+
+			etag, err := api.SetACL(ctx, transitional.ACL())
+			if err != nil {
+				return errorResult("Error applying transitional ACL, nothing was changed", err), nil
+			}
+			// The final policy replaces the transitional one we just saved
+			renamed.SetETag(etag)
+			result.WriteString("\n✓ Applied transitional policy (both tags allowed)\n")
+
+			var failed []string
+			for _, device := range affected {
+				tags := renameInList(device.Tags, oldTag, newTag)
+				if err := api.SetDeviceTags(ctx, device.ID, tags); err != nil {
+					failed = append(failed, fmt.Sprintf("%s (%s): %v", device.Name, device.ID, err))
+					continue
+				}
+				result.WriteString(fmt.Sprintf("✓ Retagged %s\n", device.Name))
+			}
+
+			if len(failed) > 0 {
+				result.WriteString(fmt.Sprintf("\n✗ Failed to retag %d device(s):\n", len(failed)))
+				for _, f := range failed {
+					result.WriteString(fmt.Sprintf("  %s\n", f))
+				}
+				result.WriteString(fmt.Sprintf("\nThe transitional policy was left in place so %s and %s both keep working. Fix the failures and re-run to finish.\n", oldTag, newTag))
+				return reportErrorResult(result.String(), fmt.Errorf("failed to retag %d device(s)", len(failed))), nil
+			}
+
+			if _, err := api.SetACL(ctx, renamed.ACL()); err != nil {
+				result.WriteString(fmt.Sprintf("\n✗ Error applying final ACL: %v\n", err))
+				result.WriteString("The transitional policy is still in place; re-run to finish.\n")
+				return reportErrorResult(result.String(), err), nil
+			}
+			result.WriteString(fmt.Sprintf("✓ Applied final policy (%s removed)\n", oldTag))
+
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: "ACL policy is valid."},
+					&mcp.TextContent{Text: result.String()},
 				},
 			}, nil
 		}),
 	)
-}
\ No newline at end of file
+}
+
+// parsePolicyInput parses a policy passed to a tool and checks its grants,
+// returning a message for the user if it is unusable
+func parsePolicyInput(raw string) (*tailscale.ACL, string) {
+	policy, err := tailscale.ParsePolicy(raw)
+	if err != nil {
+		return nil, fmt.Sprintf("Error parsing ACL: %v", err)
+	}
+	problems, err := policy.GrantProblems()
+	if err != nil {
+		return nil, fmt.Sprintf("Error parsing ACL: %v", err)
+	}
+	if len(problems) > 0 {
+		return nil, "ACL validation failed:\n  " + strings.Join(problems, "\n  ")
+	}
+	return policy.ACL(), ""
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// renameInList replaces oldValue with newValue, dropping duplicates
+func renameInList(list []string, oldValue, newValue string) []string {
+	var result []string
+	for _, item := range list {
+		if item == oldValue {
+			item = newValue
+		}
+		if !containsString(result, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// updatePolicy fetches the current policy, applies edit, validates the result
+// and saves it. It returns a unified diff of the change (empty if nothing changed).
+func updatePolicy(ctx context.Context, api *tailscale.APIClient, edit func(policy *tailscale.Policy) error) (string, error) {
+	acl, err := api.GetACL(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get ACL: %w", err)
+	}
+
+	policy, err := tailscale.ParseACL(acl)
+	if err != nil {
+		return "", err
+	}
+
+	updated := policy.Clone()
+	if err := edit(updated); err != nil {
+		return "", err
+	}
+
+	policyDiff := diff.Unified("policy.hujson (current)", "policy.hujson (updated)", policy.String(), updated.String())
+	if policyDiff == "" {
+		return "", nil
+	}
+
+	if err := api.ValidateACL(ctx, updated.ACL()); err != nil {
+		return policyDiff, fmt.Errorf("ACL validation failed: %w", err)
+	}
+	if _, err := api.SetACL(ctx, updated.ACL()); err != nil {
+		return policyDiff, fmt.Errorf("failed to update ACL: %w", err)
+	}
+
+	return policyDiff, nil
+}
@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/diff"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// aclDiffInfo is the structured form of the acl_diff tool's output
+type aclDiffInfo struct {
+	// Changed is set when the text differs at all; SemanticChange only when
+	// the policy itself does, as opposed to comments or formatting
+	Changed        bool            `json:"changed"`
+	SemanticChange bool            `json:"semantic_change"`
+	Sections       []sectionChange `json:"sections,omitempty"`
+	Valid          *bool           `json:"valid,omitempty"`
+	ValidationErr  string          `json:"validation_error,omitempty"`
+	Diff           string          `json:"diff,omitempty"`
+}
+
+// sectionChange describes how a top-level policy section changed. Before and
+// After count the section's entries
+type sectionChange struct {
+	Section string `json:"section"`
+	Change  string `json:"change"` // added, removed or modified
+	Before  int    `json:"before"`
+	After   int    `json:"after"`
+}
+
+// RegisterACLDiffTools registers the tool for reviewing a proposed policy
+func RegisterACLDiffTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_diff",
+			Description: "Compare a proposed ACL policy (HuJSON) with the deployed one and show exactly what would change, section by section and as a unified diff, before calling update_acl",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"acl": {
+						Type:        "string",
+						Description: "Proposed ACL policy in HuJSON or JSON format",
+					},
+					"validate": {
+						Type:        "boolean",
+						Description: "Also check the proposed policy against the validate endpoint (default: true)",
+					},
+				},
+				Required: []string{"acl"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				ACL      string `json:"acl"`
+				Validate *bool  `json:"validate"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			proposed, err := tailscale.ParsePolicy(params.ACL)
+			if err != nil {
+				return errorResult("Error parsing proposed ACL", err), nil
+			}
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+			current, err := tailscale.ParsePolicy(acl.RawPolicy)
+			if err != nil {
+				return errorResult("Error parsing current ACL", err), nil
+			}
+
+			info := aclDiffInfo{
+				Diff: diff.Unified("policy.hujson (current)", "policy.hujson (proposed)", current.String(), proposed.String()),
+			}
+			info.Changed = info.Diff != ""
+			info.Sections, err = diffPolicySections(current, proposed)
+			if err != nil {
+				return errorResult("Error comparing policies", err), nil
+			}
+			info.SemanticChange = len(info.Sections) > 0
+
+			var result strings.Builder
+			result.WriteString("ACL Diff (deployed → proposed)\n\n")
+			switch {
+			case !info.Changed:
+				result.WriteString("✓ The proposed policy is identical to the deployed one.\n")
+			case !info.SemanticChange:
+				result.WriteString("Only comments or formatting differ; the effective policy is unchanged.\n")
+			default:
+				result.WriteString("Changed sections:\n")
+				for _, section := range info.Sections {
+					switch section.Change {
+					case "added":
+						result.WriteString(fmt.Sprintf("  + %s (%d entries)\n", section.Section, section.After))
+					case "removed":
+						result.WriteString(fmt.Sprintf("  - %s (%d entries)\n", section.Section, section.Before))
+					default:
+						result.WriteString(fmt.Sprintf("  ~ %s (%d → %d entries)\n", section.Section, section.Before, section.After))
+					}
+				}
+			}
+
+			if info.Changed && (params.Validate == nil || *params.Validate) {
+				valid := true
+				if err := api.ValidateACL(ctx, proposed.ACL()); err != nil {
+					valid = false
+					info.ValidationErr = err.Error()
+					result.WriteString(fmt.Sprintf("\n✗ Proposed policy failed validation: %v\n", err))
+				} else {
+					result.WriteString("\n✓ Proposed policy passed validation\n")
+				}
+				info.Valid = &valid
+			}
+
+			if info.Changed {
+				result.WriteString("\nPolicy diff:\n")
+				result.WriteString(info.Diff)
+			}
+
+			return structuredResult(result.String(), info), nil
+		}),
+	)
+}
+
+// diffPolicySections compares the decoded top-level sections of two policies,
+// ignoring comments and formatting
+func diffPolicySections(before, after *tailscale.Policy) ([]sectionChange, error) {
+	var old, cur map[string]interface{}
+	if err := before.Decode(&old); err != nil {
+		return nil, err
+	}
+	if err := after.Decode(&cur); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(old)+len(cur))
+	for name := range old {
+		names = append(names, name)
+	}
+	for name := range cur {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []sectionChange
+	for _, name := range names {
+		oldValue, inOld := old[name]
+		newValue, inNew := cur[name]
+		change := sectionChange{Section: name, Before: entryCount(oldValue), After: entryCount(newValue)}
+		switch {
+		case !inOld:
+			change.Change = "added"
+		case !inNew:
+			change.Change = "removed"
+		case !reflect.DeepEqual(oldValue, newValue):
+			change.Change = "modified"
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// entryCount is the number of rules or keys in a section (1 for scalars)
+func entryCount(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case []interface{}:
+		return len(v)
+	case map[string]interface{}:
+		return len(v)
+	}
+	return 1
+}
@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/diff"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// RegisterACLEditTools registers tools that make targeted edits to the policy
+// file, so agents don't have to rewrite the whole policy to change one entry
+func RegisterACLEditTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_add_rule",
+			Description: "Append an access rule to the policy's acls section, keeping comments and formatting. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: updateTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"src": {
+						Type:        "array",
+						Description: "Sources: users, groups, tags, hosts, IPs or * (e.g., group:eng, tag:ci)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"dst": {
+						Type:        "array",
+						Description: "Destinations as target:ports (e.g., tag:db:5432, 10.0.0.0/24:*)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"comment": {
+						Type:        "string",
+						Description: "Comment written above the rule (optional)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"src", "dst"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Src     []string `json:"src"`
+				Dst     []string `json:"dst"`
+				Comment string   `json:"comment"`
+				DryRun  *bool    `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if len(params.Src) == 0 || len(params.Dst) == 0 {
+				return invalidInputResult("Both src and dst need at least one entry"), nil
+			}
+
+			rule := tailscale.PolicyRule{Action: "accept", Src: params.Src, Dst: params.Dst}
+			title := fmt.Sprintf("add rule %s → %s", strings.Join(rule.Src, ", "), strings.Join(rule.Dst, ", "))
+			return runPolicyEdit(ctx, api, title, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				if policy.HasACLRule(rule) {
+					return "", nil
+				}
+				if err := policy.AddACLRule(rule, params.Comment); err != nil {
+					return "", err
+				}
+				return "Added the rule to acls", nil
+			}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_remove_rule",
+			Description: "Remove an access rule (matched by its exact src and dst lists) from the policy's acls section, along with its comment. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"src": {
+						Type:        "array",
+						Description: "Sources of the rule, in the order they appear in the policy",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"dst": {
+						Type:        "array",
+						Description: "Destinations of the rule, in the order they appear in the policy",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"src", "dst"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Src    []string `json:"src"`
+				Dst    []string `json:"dst"`
+				DryRun *bool    `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			rule := tailscale.PolicyRule{Action: "accept", Src: params.Src, Dst: params.Dst}
+			title := fmt.Sprintf("remove rule %s → %s", strings.Join(rule.Src, ", "), strings.Join(rule.Dst, ", "))
+			return runPolicyEdit(ctx, api, title, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				removed, err := policy.RemoveACLRule(rule)
+				if err != nil {
+					return "", err
+				}
+				if !removed {
+					return "", fmt.Errorf("no acls rule has exactly src %v and dst %v", rule.Src, rule.Dst)
+				}
+				return "Removed the rule from acls", nil
+			}), nil
+		}),
+	)
+}
+
+// runPolicyEdit fetches the policy, applies edit to a copy and reports the
+// resulting diff. edit returns a one-line summary of what it changed. The
+// edited policy is always validated, and saved unless dryRun is set.
+func runPolicyEdit(ctx context.Context, api *tailscale.APIClient, title string, dryRun bool, edit func(policy *tailscale.Policy) (string, error)) *mcp.CallToolResult {
+	if api == nil || !api.IsAvailable() {
+		return apiNotConfiguredResult()
+	}
+
+	acl, err := api.GetACL(ctx)
+	if err != nil {
+		return errorResult("Error getting ACL", err)
+	}
+	policy, err := tailscale.ParseACL(acl)
+	if err != nil {
+		return errorResult("Error parsing ACL", err)
+	}
+
+	draft := policy.Clone()
+	summary, err := edit(draft)
+	if err != nil {
+		return invalidInputResult(fmt.Sprintf("Cannot %s: %v", title, err))
+	}
+
+	var result strings.Builder
+	if dryRun {
+		result.WriteString(fmt.Sprintf("Dry run: %s\n\n", title))
+	} else {
+		result.WriteString(fmt.Sprintf("Policy edit: %s\n\n", title))
+	}
+
+	policyDiff := diff.Unified("policy.hujson (current)", "policy.hujson (updated)", policy.String(), draft.String())
+	if policyDiff == "" {
+		result.WriteString("The policy already has this change. Nothing to do.\n")
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: result.String()},
+			},
+		}
+	}
+	result.WriteString(summary + "\n\nPolicy diff:\n")
+	result.WriteString(policyDiff)
+
+	if err := api.ValidateACL(ctx, draft.ACL()); err != nil {
+		result.WriteString(fmt.Sprintf("\n✗ Draft failed validation, nothing was changed: %v\n", err))
+		return reportErrorResult(result.String(), err)
+	}
+	result.WriteString("\n✓ Draft passed validation\n")
+
+	if dryRun {
+		result.WriteString("\nRe-run with dry_run=false to save the change.\n")
+	} else if _, err := api.SetACL(ctx, draft.ACL()); err != nil {
+		result.WriteString(fmt.Sprintf("\n✗ Error saving ACL: %v\n", err))
+		return reportErrorResult(result.String(), err)
+	} else {
+		result.WriteString("✓ Saved the updated policy\n")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result.String()},
+		},
+	}
+}
@@ -0,0 +1,262 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// grantInfo is a grants entry with its position and validation state
+type grantInfo struct {
+	Index int `json:"index"`
+	tailscale.PolicyGrant
+	Problem string `json:"problem,omitempty"`
+}
+
+// RegisterACLGrantTools registers tools for managing the policy's grants
+func RegisterACLGrantTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_list_grants",
+			Description: "List the ACL policy's grants with their network (ip) and application (app) capabilities, flagging malformed entries",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"src": {
+						Type:        "string",
+						Description: "Only show grants with this source (optional)",
+					},
+					"dst": {
+						Type:        "string",
+						Description: "Only show grants with this destination (optional)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Src string `json:"src"`
+				Dst string `json:"dst"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+			policy, err := tailscale.ParseACL(acl)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+			grants, err := policy.Grants()
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+
+			infos := []grantInfo{}
+			for i, grant := range grants {
+				if params.Src != "" && !containsString(grant.Src, params.Src) {
+					continue
+				}
+				if params.Dst != "" && !containsString(grant.Dst, params.Dst) {
+					continue
+				}
+				info := grantInfo{Index: i, PolicyGrant: grant}
+				if err := grant.Validate(); err != nil {
+					info.Problem = err.Error()
+				}
+				infos = append(infos, info)
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Grants (%d of %d)\n\n", len(infos), len(grants)))
+			if len(infos) == 0 {
+				result.WriteString("No matching grants.\n")
+			}
+			for _, info := range infos {
+				result.WriteString(fmt.Sprintf("[%d] %s → %s\n", info.Index, strings.Join(info.Src, ", "), strings.Join(info.Dst, ", ")))
+				if len(info.IP) > 0 {
+					result.WriteString(fmt.Sprintf("  ip: %s\n", strings.Join(info.IP, ", ")))
+				}
+				for _, capability := range sortedKeys(info.App) {
+					result.WriteString(fmt.Sprintf("  app: %s (%d parameter set(s))\n", capability, len(info.App[capability])))
+				}
+				if len(info.Via) > 0 {
+					result.WriteString(fmt.Sprintf("  via: %s\n", strings.Join(info.Via, ", ")))
+				}
+				if len(info.SrcPosture) > 0 {
+					result.WriteString(fmt.Sprintf("  srcPosture: %s\n", strings.Join(info.SrcPosture, ", ")))
+				}
+				if info.Problem != "" {
+					result.WriteString(fmt.Sprintf("  ✗ %s\n", info.Problem))
+				}
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"grants": infos}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_add_grant",
+			Description: "Add a grant to the ACL policy's grants section, giving src network access (ip) and/or application capabilities (app) on dst. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: updateTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"src": {
+						Type:        "array",
+						Description: "Sources: users, groups, tags, autogroups, hosts, IPs or *",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"dst": {
+						Type:        "array",
+						Description: "Destinations without ports: tags, groups, autogroups, hosts, IPs, CIDRs or *",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"ip": {
+						Type:        "array",
+						Description: "Allowed ports and protocols, e.g. *, 443, 8000-8099, tcp:22, icmp:*",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"app": {
+						Type:        "object",
+						Description: "Application capabilities: a map from capability name (e.g., example.com/cap/admin) to a list of parameter objects",
+						AdditionalProperties: &jsonschema.Schema{
+							Type:  "array",
+							Items: &jsonschema.Schema{Type: "object"},
+						},
+					},
+					"via": {
+						Type:        "array",
+						Description: "Route the traffic through these tagged subnet routers or exit nodes (optional)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"src_posture": {
+						Type:        "array",
+						Description: "Device posture conditions the source must meet, e.g. posture:latestMac (optional)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"comment": {
+						Type:        "string",
+						Description: "Comment written above the grant (optional)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"src", "dst"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Src        []string                     `json:"src"`
+				Dst        []string                     `json:"dst"`
+				IP         []string                     `json:"ip"`
+				App        map[string][]json.RawMessage `json:"app"`
+				Via        []string                     `json:"via"`
+				SrcPosture []string                     `json:"src_posture"`
+				Comment    string                       `json:"comment"`
+				DryRun     *bool                        `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			grant := tailscale.PolicyGrant{
+				Src:        params.Src,
+				Dst:        params.Dst,
+				IP:         params.IP,
+				App:        params.App,
+				Via:        params.Via,
+				SrcPosture: params.SrcPosture,
+			}
+			if err := grant.Validate(); err != nil {
+				return errorResult("Invalid grant", err), nil
+			}
+
+			title := fmt.Sprintf("add grant %s → %s", strings.Join(grant.Src, ", "), strings.Join(grant.Dst, ", "))
+			return runPolicyEdit(ctx, api, title, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				grants, err := policy.Grants()
+				if err != nil {
+					return "", err
+				}
+				for _, existing := range grants {
+					if equalGrants(existing, grant) {
+						return "", nil
+					}
+				}
+				if err := policy.AddGrant(grant, params.Comment); err != nil {
+					return "", err
+				}
+				return "Added the grant to grants", nil
+			}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_remove_grant",
+			Description: "Remove a grant, identified by its index from acl_list_grants, from the ACL policy. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: destructiveTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"index": {
+						Type:        "integer",
+						Description: "Index of the grant as shown by acl_list_grants",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"index"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Index  int   `json:"index"`
+				DryRun *bool `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			return runPolicyEdit(ctx, api, fmt.Sprintf("remove grants[%d]", params.Index), params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				grants, err := policy.Grants()
+				if err != nil {
+					return "", err
+				}
+				if err := policy.RemoveGrant(params.Index); err != nil {
+					return "", err
+				}
+				removed := grants[params.Index]
+				return fmt.Sprintf("Removed the grant %s → %s", strings.Join(removed.Src, ", "), strings.Join(removed.Dst, ", ")), nil
+			}), nil
+		}),
+	)
+}
+
+// equalGrants compares grants by their JSON form, which also normalizes the
+// whitespace in app parameters
+func equalGrants(a, b tailscale.PolicyGrant) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aJSON) == string(bJSON)
+}
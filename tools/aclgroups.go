@@ -0,0 +1,272 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// groupInfo describes a group in the policy's groups section
+type groupInfo struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+	// UnknownMembers are members that aren't users of the tailnet
+	UnknownMembers []string `json:"unknown_members,omitempty"`
+	// UsedIn lists the policy sections that reference the group
+	UsedIn []string `json:"used_in,omitempty"`
+}
+
+// RegisterACLGroupTools registers tools for managing the policy's groups
+func RegisterACLGroupTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_list_groups",
+			Description: "List the groups defined in the ACL policy with their members, flagging members who aren't users of the tailnet and groups no rule uses",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"group": {
+						Type:        "string",
+						Description: "Only show this group (e.g., group:eng or eng) (optional)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Group string `json:"group"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			filter := tailscale.NormalizeGroup(params.Group)
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+			policy, err := tailscale.ParseACL(acl)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+			var doc map[string]interface{}
+			var sections struct {
+				Groups map[string][]string `json:"groups"`
+			}
+			if err = policy.Decode(&doc); err == nil {
+				err = policy.Decode(&sections)
+			}
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+
+			// Membership checks are best effort; listing users may need more
+			// scopes than reading the policy
+			users, usersErr := tailnetLogins(ctx, api)
+
+			groups := []groupInfo{}
+			for _, name := range sortedKeys(sections.Groups) {
+				if filter != "" && name != filter {
+					continue
+				}
+				group := groupInfo{Name: name, Members: sections.Groups[name]}
+				if group.Members == nil {
+					group.Members = []string{}
+				}
+				if usersErr == nil {
+					for _, member := range group.Members {
+						if !users[strings.ToLower(member)] {
+							group.UnknownMembers = append(group.UnknownMembers, member)
+						}
+					}
+				}
+				for _, section := range sortedKeys(doc) {
+					if section != "groups" && referenced(name, collectStrings(doc[section], nil)) {
+						group.UsedIn = append(group.UsedIn, section)
+					}
+				}
+				groups = append(groups, group)
+			}
+			if filter != "" && len(groups) == 0 {
+				return notFoundResult(fmt.Sprintf("The policy has no group %s", filter)), nil
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("ACL Groups (%d)\n\n", len(groups)))
+			if len(groups) == 0 {
+				result.WriteString("The policy defines no groups.\n")
+			}
+			for _, group := range groups {
+				result.WriteString(fmt.Sprintf("%s (%d members)\n", group.Name, len(group.Members)))
+				for _, member := range group.Members {
+					if containsString(group.UnknownMembers, member) {
+						result.WriteString(fmt.Sprintf("  ⚠ %s (not a user of the tailnet)\n", member))
+					} else {
+						result.WriteString(fmt.Sprintf("  - %s\n", member))
+					}
+				}
+				if len(group.UsedIn) > 0 {
+					result.WriteString(fmt.Sprintf("  Used in: %s\n", strings.Join(group.UsedIn, ", ")))
+				} else {
+					result.WriteString("  ⚠ Not used by any rule\n")
+				}
+				result.WriteString("\n")
+			}
+			if usersErr != nil {
+				result.WriteString(fmt.Sprintf("⚠ Could not list tailnet users to check memberships: %v\n", usersErr))
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"groups": groups}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_add_group_member",
+			Description: "Add members to a group in the policy's groups section, creating the group if it doesn't exist yet. Members must be users of the tailnet unless allow_unknown is set. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"group": {
+						Type:        "string",
+						Description: "Group name (e.g., group:eng or eng)",
+					},
+					"members": {
+						Type:        "array",
+						Description: "Login names to add (e.g., alice@example.com)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"allow_unknown": {
+						Type:        "boolean",
+						Description: "Add members who haven't joined the tailnet yet (default: false)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"group", "members"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Group        string   `json:"group"`
+				Members      []string `json:"members"`
+				AllowUnknown bool     `json:"allow_unknown"`
+				DryRun       *bool    `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			group := tailscale.NormalizeGroup(params.Group)
+			if group == "" || len(params.Members) == 0 {
+				return invalidInputResult("group and at least one member are required"), nil
+			}
+
+			if !params.AllowUnknown && api != nil && api.IsAvailable() {
+				users, err := tailnetLogins(ctx, api)
+				if err != nil {
+					te := classifyError(err)
+					te.Message = fmt.Sprintf("Error listing users to check the members: %v", err)
+					te.Hint = "Pass allow_unknown=true to skip the check."
+					return toolErrorResult(te), nil
+				}
+				var unknown []string
+				for _, member := range params.Members {
+					if !users[strings.ToLower(member)] {
+						unknown = append(unknown, member)
+					}
+				}
+				if len(unknown) > 0 {
+					return invalidInputResult(fmt.Sprintf("Not users of the tailnet: %s. Check the login names, or pass allow_unknown=true to add them before they join.", strings.Join(unknown, ", "))), nil
+				}
+			}
+
+			return runPolicyEdit(ctx, api, "add members to "+group, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				added, err := policy.AddGroupMembers(group, params.Members)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Added %d member(s) to %s", added, group), nil
+			}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_remove_group_member",
+			Description: "Remove members from a group in the policy's groups section. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"group": {
+						Type:        "string",
+						Description: "Group name (e.g., group:eng or eng)",
+					},
+					"members": {
+						Type:        "array",
+						Description: "Login names to remove",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"group", "members"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Group   string   `json:"group"`
+				Members []string `json:"members"`
+				DryRun  *bool    `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			group := tailscale.NormalizeGroup(params.Group)
+			if group == "" || len(params.Members) == 0 {
+				return invalidInputResult("group and at least one member are required"), nil
+			}
+
+			return runPolicyEdit(ctx, api, "remove members from "+group, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				removed, err := policy.RemoveGroupMembers(group, params.Members)
+				if err != nil {
+					return "", err
+				}
+				if removed == 0 {
+					return "", fmt.Errorf("none of them are members of %s", group)
+				}
+				return fmt.Sprintf("Removed %d member(s) from %s", removed, group), nil
+			}), nil
+		}),
+	)
+}
+
+// tailnetLogins returns the lowercased login names of the tailnet's users
+func tailnetLogins(ctx context.Context, api *tailscale.APIClient) (map[string]bool, error) {
+	users, err := api.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logins := make(map[string]bool, len(users))
+	for _, user := range users {
+		logins[strings.ToLower(user.LoginName)] = true
+	}
+	return logins, nil
+}
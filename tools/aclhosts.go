@@ -0,0 +1,264 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// hostInfo describes an alias in the policy's hosts section
+type hostInfo struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	// UsedIn lists the policy sections that reference the alias
+	UsedIn []string `json:"used_in,omitempty"`
+}
+
+// RegisterACLHostTools registers tools for managing the policy's host aliases
+func RegisterACLHostTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_list_hosts",
+			Description: "List the named hosts (aliases for IPs and CIDRs) in the ACL policy's hosts section and where each is used",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type:       "object",
+				Properties: map[string]*jsonschema.Schema{},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+			policy, err := tailscale.ParseACL(acl)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+			var doc map[string]interface{}
+			var sections struct {
+				Hosts map[string]string `json:"hosts"`
+			}
+			if err = policy.Decode(&doc); err == nil {
+				err = policy.Decode(&sections)
+			}
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+
+			hosts := []hostInfo{}
+			for _, name := range sortedKeys(sections.Hosts) {
+				host := hostInfo{Name: name, Address: sections.Hosts[name]}
+				for _, section := range sortedKeys(doc) {
+					if section != "hosts" && referenced(name, collectStrings(doc[section], nil)) {
+						host.UsedIn = append(host.UsedIn, section)
+					}
+				}
+				hosts = append(hosts, host)
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("ACL Hosts (%d)\n\n", len(hosts)))
+			if len(hosts) == 0 {
+				result.WriteString("The policy defines no hosts.\n")
+			}
+			for _, host := range hosts {
+				result.WriteString(fmt.Sprintf("%s → %s\n", host.Name, host.Address))
+				if len(host.UsedIn) > 0 {
+					result.WriteString(fmt.Sprintf("  Used in: %s\n", strings.Join(host.UsedIn, ", ")))
+				} else {
+					result.WriteString("  ⚠ Not used by any rule\n")
+				}
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"hosts": hosts}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_add_host",
+			Description: "Add a named host to the ACL policy's hosts section, pointing it at an IP or CIDR. Set replace=true to change an existing alias. Warns when another alias already covers the address. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {
+						Type:        "string",
+						Description: "Alias to use in rules (e.g., prod-db)",
+					},
+					"address": {
+						Type:        "string",
+						Description: "IP address or CIDR the alias stands for (e.g., 100.64.0.5 or 10.1.0.0/16)",
+					},
+					"replace": {
+						Type:        "boolean",
+						Description: "Overwrite the alias if it already exists with a different address (default: false)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"name", "address"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Name    string `json:"name"`
+				Address string `json:"address"`
+				Replace bool   `json:"replace"`
+				DryRun  *bool  `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			name := strings.TrimSpace(params.Name)
+			if err := validateHostName(name); err != nil {
+				return errorResult("Invalid host name", err), nil
+			}
+			prefix, err := parseHostAddress(strings.TrimSpace(params.Address))
+			if err != nil {
+				return errorResult("Invalid address", err), nil
+			}
+			address := strings.TrimSpace(params.Address)
+
+			return runPolicyEdit(ctx, api, fmt.Sprintf("add host %s → %s", name, address), params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				var sections struct {
+					Hosts map[string]string `json:"hosts"`
+				}
+				if err := policy.Decode(&sections); err != nil {
+					return "", err
+				}
+
+				existing, exists := sections.Hosts[name]
+				if exists && existing != address && !params.Replace {
+					return "", fmt.Errorf("%s already points at %s; pass replace=true to change it", name, existing)
+				}
+
+				var overlaps []string
+				for _, other := range sortedKeys(sections.Hosts) {
+					if other == name {
+						continue
+					}
+					if otherPrefix, err := parseHostAddress(sections.Hosts[other]); err == nil && otherPrefix.Overlaps(prefix) {
+						overlaps = append(overlaps, fmt.Sprintf("%s (%s)", other, sections.Hosts[other]))
+					}
+				}
+
+				if err := policy.SetHost(name, address); err != nil {
+					return "", err
+				}
+
+				summary := fmt.Sprintf("Added host %s → %s", name, address)
+				if exists && existing != address {
+					summary = fmt.Sprintf("Changed host %s from %s to %s", name, existing, address)
+				}
+				if len(overlaps) > 0 {
+					summary += fmt.Sprintf("\n⚠ Overlaps with existing aliases: %s", strings.Join(overlaps, ", "))
+				}
+				return summary, nil
+			}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_remove_host",
+			Description: "Remove a named host from the ACL policy's hosts section. Refused while rules still reference it. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {
+						Type:        "string",
+						Description: "Alias to remove",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Name   string `json:"name"`
+				DryRun *bool  `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			name := strings.TrimSpace(params.Name)
+
+			return runPolicyEdit(ctx, api, "remove host "+name, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				var doc map[string]interface{}
+				if err := policy.Decode(&doc); err != nil {
+					return "", err
+				}
+				var usedIn []string
+				for _, section := range sortedKeys(doc) {
+					if section != "hosts" && referenced(name, collectStrings(doc[section], nil)) {
+						usedIn = append(usedIn, section)
+					}
+				}
+				if len(usedIn) > 0 {
+					return "", fmt.Errorf("%s is still used in %s; remove those references first", name, strings.Join(usedIn, ", "))
+				}
+
+				removed, err := policy.RemoveHost(name)
+				if err != nil {
+					return "", err
+				}
+				if !removed {
+					return "", fmt.Errorf("the policy has no host %s", name)
+				}
+				return fmt.Sprintf("Removed host %s", name), nil
+			}), nil
+		}),
+	)
+}
+
+// validateHostName checks that name can be used as a host alias without being
+// mistaken for an address, user, or other kind of policy reference
+func validateHostName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("name is required")
+	case name == "*":
+		return fmt.Errorf("* is reserved")
+	case strings.ContainsAny(name, ":@/ "):
+		return fmt.Errorf("%q must not contain ':', '@', '/' or spaces", name)
+	}
+	if _, err := netip.ParseAddr(name); err == nil {
+		return fmt.Errorf("%q is an IP address, not a name", name)
+	}
+	return nil
+}
+
+// parseHostAddress parses a hosts value, which is an IP or a CIDR, as a prefix
+func parseHostAddress(address string) (netip.Prefix, error) {
+	if ip, err := netip.ParseAddr(address); err == nil {
+		return netip.PrefixFrom(ip, ip.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(address)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%q is not an IP address or CIDR", address)
+	}
+	if prefix != prefix.Masked() {
+		return netip.Prefix{}, fmt.Errorf("%s has host bits set; did you mean %s?", address, prefix.Masked())
+	}
+	return prefix, nil
+}
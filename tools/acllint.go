@@ -0,0 +1,266 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// Lint finding severities, most severe first
+const (
+	severityHigh   = "high"
+	severityMedium = "medium"
+	severityLow    = "low"
+)
+
+var severityRank = map[string]int{severityHigh: 0, severityMedium: 1, severityLow: 2}
+
+// lintFinding is a risky pattern found in the policy
+type lintFinding struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Location string `json:"location"` // e.g. acls[2] or tagOwners
+	Message  string `json:"message"`
+}
+
+// lintPolicy is the part of the policy the lint checks look at
+type lintPolicy struct {
+	Groups    map[string][]string `json:"groups"`
+	Hosts     map[string]string   `json:"hosts"`
+	TagOwners map[string][]string `json:"tagOwners"`
+	ACLs      []struct {
+		Src []string `json:"src"`
+		Dst []string `json:"dst"`
+	} `json:"acls"`
+	Grants []struct {
+		Src []string `json:"src"`
+		Dst []string `json:"dst"`
+		IP  []string `json:"ip"`
+	} `json:"grants"`
+	SSH []struct {
+		Action string   `json:"action"`
+		Src    []string `json:"src"`
+		Dst    []string `json:"dst"`
+		Users  []string `json:"users"`
+	} `json:"ssh"`
+}
+
+// RegisterACLLintTools registers the policy lint tool
+func RegisterACLLintTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_lint",
+			Description: "Check the ACL policy for risky patterns: wide-open rules, wildcard sources, undeclared or ownerless tags, unused groups and hosts, and SSH rules that grant root broadly. Findings are ranked by severity.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"acl": {
+						Type:        "string",
+						Description: "Candidate ACL policy in HuJSON or JSON format (optional; defaults to the deployed policy)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				ACL string `json:"acl"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			raw := params.ACL
+			if raw == "" {
+				if api == nil || !api.IsAvailable() {
+					return toolErrorResult(toolError{
+						Code:     "api_not_configured",
+						Category: categoryConfig,
+						Message:  "API client not configured. Please set TAILSCALE_API_KEY environment variable, or pass the policy as acl.",
+					}), nil
+				}
+				acl, err := api.GetACL(ctx)
+				if err != nil {
+					return errorResult("Error getting ACL", err), nil
+				}
+				raw = acl.RawPolicy
+			}
+
+			policy, err := tailscale.ParsePolicy(raw)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+			findings, err := lintACL(policy)
+			if err != nil {
+				return errorResult("Error checking ACL", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString("ACL Lint\n\n")
+			if len(findings) == 0 {
+				result.WriteString("✓ No risky patterns found.\n")
+			}
+			counts := map[string]int{}
+			for _, f := range findings {
+				marker := "⚠"
+				if f.Severity == severityHigh {
+					marker = "✗"
+				}
+				result.WriteString(fmt.Sprintf("%s [%s] %s: %s\n", marker, f.Severity, f.Location, f.Message))
+				counts[f.Severity]++
+			}
+			if len(findings) > 0 {
+				result.WriteString(fmt.Sprintf("\n%d finding(s): %d high, %d medium, %d low\n", len(findings), counts[severityHigh], counts[severityMedium], counts[severityLow]))
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"findings": findings}), nil
+		}),
+	)
+}
+
+// lintACL runs every check against the policy, returning findings ordered by severity
+func lintACL(policy *tailscale.Policy) ([]lintFinding, error) {
+	var doc lintPolicy
+	if err := policy.Decode(&doc); err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := policy.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	var findings []lintFinding
+	add := func(severity, check, location, format string, args ...interface{}) {
+		findings = append(findings, lintFinding{Severity: severity, Check: check, Location: location, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i, rule := range doc.ACLs {
+		location := fmt.Sprintf("acls[%d]", i)
+		anySrc := containsString(rule.Src, "*")
+		anyDst := containsString(rule.Dst, "*:*")
+		switch {
+		case anySrc && anyDst:
+			add(severityHigh, "wide-open", location, "allows everyone to reach every device on every port (* → *:*)")
+		case anySrc:
+			add(severityMedium, "wildcard-src", location, "src * includes shared-in users and external devices; use autogroup:member to limit it to tailnet members")
+		case anyDst:
+			add(severityLow, "wildcard-dst", location, "%s can reach every device on every port, including other users' personal devices; consider autogroup:self or tags", strings.Join(rule.Src, ", "))
+		}
+	}
+
+	for i, grant := range doc.Grants {
+		location := fmt.Sprintf("grants[%d]", i)
+		anySrc := containsString(grant.Src, "*")
+		anyDst := containsString(grant.Dst, "*") && containsString(grant.IP, "*")
+		switch {
+		case anySrc && anyDst:
+			add(severityHigh, "wide-open", location, "grants everyone access to every device on every port")
+		case anySrc:
+			add(severityMedium, "wildcard-src", location, "src * includes shared-in users and external devices; use autogroup:member to limit it to tailnet members")
+		}
+	}
+
+	for i, rule := range doc.SSH {
+		if !containsString(rule.Users, "root") {
+			continue
+		}
+		location := fmt.Sprintf("ssh[%d]", i)
+		broad := containsString(rule.Src, "*") || containsString(rule.Src, "autogroup:member")
+		switch {
+		case broad:
+			add(severityHigh, "ssh-root", location, "lets %s log in as root; restrict src to an admin group", strings.Join(rule.Src, ", "))
+		case rule.Action != "check":
+			add(severityMedium, "ssh-root", location, "allows root logins without re-authentication; consider action \"check\"")
+		}
+	}
+
+	// References to groups, hosts and tags from anywhere except their own definitions
+	var refs []string
+	for section, value := range raw {
+		if section == "groups" || section == "hosts" {
+			continue
+		}
+		refs = collectStrings(value, refs)
+	}
+	sort.Strings(refs)
+
+	declared := map[string]bool{}
+	for _, tag := range sortedKeys(doc.TagOwners) {
+		declared[tag] = true
+		if len(doc.TagOwners[tag]) == 0 {
+			add(severityLow, "tag-no-owners", "tagOwners", "%s has no owners, so only admins can apply it", tag)
+		}
+	}
+	undeclared := map[string]bool{}
+	for _, ref := range refs {
+		if !strings.HasPrefix(ref, "tag:") {
+			continue
+		}
+		tag := "tag:" + strings.SplitN(strings.TrimPrefix(ref, "tag:"), ":", 2)[0]
+		if !declared[tag] && !undeclared[tag] {
+			undeclared[tag] = true
+			add(severityHigh, "tag-undeclared", "tagOwners", "%s is used in the policy but has no tagOwners entry", tag)
+		}
+	}
+
+	for _, group := range sortedKeys(doc.Groups) {
+		if !referenced(group, refs) {
+			add(severityLow, "unused-group", "groups", "%s is not used by any rule", group)
+		}
+	}
+	for _, host := range sortedKeys(doc.Hosts) {
+		if !referenced(host, refs) {
+			add(severityLow, "unused-host", "hosts", "%s is not used by any rule", host)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+	})
+	return findings, nil
+}
+
+// collectStrings appends every string value and object key in v to out
+func collectStrings(v interface{}, out []string) []string {
+	switch v := v.(type) {
+	case string:
+		out = append(out, v)
+	case []interface{}:
+		for _, elem := range v {
+			out = collectStrings(elem, out)
+		}
+	case map[string]interface{}:
+		for key, elem := range v {
+			out = append(out, key)
+			out = collectStrings(elem, out)
+		}
+	}
+	return out
+}
+
+// referenced reports whether name appears in refs on its own or with a port
+func referenced(name string, refs []string) bool {
+	for _, ref := range refs {
+		if ref == name || strings.HasPrefix(ref, name+":") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
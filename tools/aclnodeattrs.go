@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// nodeAttrInfo is a nodeAttrs entry with its position in the section
+type nodeAttrInfo struct {
+	Index int `json:"index"`
+	tailscale.PolicyNodeAttr
+}
+
+// RegisterACLNodeAttrTools registers tools for managing the policy's nodeAttrs
+func RegisterACLNodeAttrTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_list_node_attrs",
+			Description: "List the ACL policy's nodeAttrs entries, which give node attributes such as funnel or mullvad to targets. Optionally filter by attribute or target.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"attr": {
+						Type:        "string",
+						Description: "Only show entries granting this attribute, e.g. funnel (optional)",
+					},
+					"target": {
+						Type:        "string",
+						Description: "Only show entries targeting this tag, group, user or autogroup (optional)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Attr   string `json:"attr"`
+				Target string `json:"target"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+			policy, err := tailscale.ParseACL(acl)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+			entries, err := policy.NodeAttrs()
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+
+			infos := []nodeAttrInfo{}
+			for i, entry := range entries {
+				if params.Attr != "" && !containsString(entry.Attr, params.Attr) {
+					continue
+				}
+				if params.Target != "" && !containsString(entry.Target, params.Target) {
+					continue
+				}
+				infos = append(infos, nodeAttrInfo{Index: i, PolicyNodeAttr: entry})
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Node Attributes (%d of %d entries)\n\n", len(infos), len(entries)))
+			if len(infos) == 0 {
+				result.WriteString("No matching nodeAttrs entries.\n")
+			}
+			for _, info := range infos {
+				result.WriteString(fmt.Sprintf("[%d] %s\n", info.Index, strings.Join(info.Target, ", ")))
+				if len(info.Attr) > 0 {
+					result.WriteString(fmt.Sprintf("  attr: %s\n", strings.Join(info.Attr, ", ")))
+				}
+				for _, capability := range sortedKeys(info.App) {
+					result.WriteString(fmt.Sprintf("  app: %s\n", capability))
+				}
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"node_attrs": infos}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_add_node_attr",
+			Description: "Give node attributes (e.g., funnel, mullvad, drive:share) to targets in the ACL policy's nodeAttrs section. Attributes are added to the entry with exactly the same targets if there is one. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"target": {
+						Type:        "array",
+						Description: "Tags, groups, users or autogroups to give the attributes to (e.g., tag:web, autogroup:member)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"attr": {
+						Type:        "array",
+						Description: "Attributes to give (e.g., funnel, mullvad)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"comment": {
+						Type:        "string",
+						Description: "Comment written above a new entry (optional)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"target", "attr"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Target  []string `json:"target"`
+				Attr    []string `json:"attr"`
+				Comment string   `json:"comment"`
+				DryRun  *bool    `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if len(params.Target) == 0 || len(params.Attr) == 0 {
+				return invalidInputResult("target and attr need at least one entry"), nil
+			}
+
+			title := fmt.Sprintf("give %s to %s", strings.Join(params.Attr, ", "), strings.Join(params.Target, ", "))
+			return runPolicyEdit(ctx, api, title, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				added, err := policy.AddNodeAttrs(params.Target, params.Attr, params.Comment)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Added %d attribute(s) to nodeAttrs", added), nil
+			}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_remove_node_attr",
+			Description: "Take node attributes away from targets in the ACL policy's nodeAttrs section, removing entries left empty. Targets must match an entry's target list exactly. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"target": {
+						Type:        "array",
+						Description: "Target list of the entry, as shown by acl_list_node_attrs",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"attr": {
+						Type:        "array",
+						Description: "Attributes to remove",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"target", "attr"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Target []string `json:"target"`
+				Attr   []string `json:"attr"`
+				DryRun *bool    `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			title := fmt.Sprintf("remove %s from %s", strings.Join(params.Attr, ", "), strings.Join(params.Target, ", "))
+			return runPolicyEdit(ctx, api, title, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				removed, err := policy.RemoveNodeAttrs(params.Target, params.Attr)
+				if err != nil {
+					return "", err
+				}
+				if removed == 0 {
+					return "", fmt.Errorf("no nodeAttrs entry with target %v has those attributes", params.Target)
+				}
+				return fmt.Sprintf("Removed %d attribute(s) from nodeAttrs", removed), nil
+			}), nil
+		}),
+	)
+}
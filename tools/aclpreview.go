@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// aclPreviewInfo is the structured form of the acl_preview tool's output
+type aclPreviewInfo struct {
+	Type       string             `json:"type"`
+	PreviewFor string             `json:"preview_for"`
+	Candidate  bool               `json:"candidate"`
+	Matches    []aclPreviewResult `json:"matches"`
+}
+
+type aclPreviewResult struct {
+	Users []string `json:"users"`
+	Ports []string `json:"ports"`
+	Line  int      `json:"line,omitempty"`
+	// Rule is the policy text at Line, when it could be located
+	Rule string `json:"rule,omitempty"`
+}
+
+// RegisterACLPreviewTools registers the tool for previewing who can reach what
+// under the deployed or a candidate policy
+func RegisterACLPreviewTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_preview",
+			Description: "Preview a policy without applying it: list the rules that let a user reach something, or that allow traffic to a device's port. Uses the deployed policy unless a candidate acl is given.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"user": {
+						Type:        "string",
+						Description: "Login name to preview (e.g., alice@example.com)",
+					},
+					"device": {
+						Type:        "string",
+						Description: "Device hostname, MagicDNS name or Tailscale IP to preview incoming access for (use instead of user)",
+					},
+					"port": {
+						Type:        "string",
+						Description: "Port on the device to check (required with device, e.g., 22)",
+					},
+					"acl": {
+						Type:        "string",
+						Description: "Candidate ACL policy in HuJSON or JSON format (optional; defaults to the deployed policy)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				User   string `json:"user"`
+				Device string `json:"device"`
+				Port   string `json:"port"`
+				ACL    string `json:"acl"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if (params.User == "") == (params.Device == "") {
+				return invalidInputResult("Specify exactly one of user or device"), nil
+			}
+			if params.Device != "" && params.Port == "" {
+				return invalidInputResult("port is required when previewing a device"), nil
+			}
+
+			var acl *tailscale.ACL
+			if params.ACL != "" {
+				policy, err := tailscale.ParsePolicy(params.ACL)
+				if err != nil {
+					return errorResult("Error parsing candidate ACL", err), nil
+				}
+				acl = policy.ACL()
+			} else {
+				var err error
+				acl, err = api.GetACL(ctx)
+				if err != nil {
+					return errorResult("Error getting ACL", err), nil
+				}
+			}
+
+			previewType, previewFor := "user", params.User
+			target := params.User
+			if params.Device != "" {
+				ip, err := resolveDeviceIP(ctx, api, params.Device)
+				if err != nil {
+					return errorResult("Error resolving device", err), nil
+				}
+				previewType = "ipport"
+				previewFor = net.JoinHostPort(ip.String(), params.Port)
+				target = fmt.Sprintf("%s (%s)", params.Device, previewFor)
+			}
+
+			preview, err := api.PreviewACL(ctx, acl, previewType, previewFor)
+			if err != nil {
+				return errorResult("Error previewing ACL", err), nil
+			}
+
+			info := aclPreviewInfo{
+				Type:       previewType,
+				PreviewFor: previewFor,
+				Candidate:  params.ACL != "",
+				Matches:    []aclPreviewResult{},
+			}
+			lines := strings.Split(acl.RawPolicy, "\n")
+			for _, match := range preview.Matches {
+				result := aclPreviewResult{Users: match.Users, Ports: match.Ports, Line: match.LineNumber}
+				if match.LineNumber > 0 && match.LineNumber <= len(lines) {
+					result.Rule = strings.TrimSpace(lines[match.LineNumber-1])
+				}
+				info.Matches = append(info.Matches, result)
+			}
+
+			source := "deployed policy"
+			if info.Candidate {
+				source = "candidate policy"
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("ACL Preview for %s (%s)\n\n", target, source))
+			if len(info.Matches) == 0 {
+				if previewType == "user" {
+					result.WriteString("✗ No rules grant this user access to anything.\n")
+				} else {
+					result.WriteString("✗ No rules allow traffic to this port.\n")
+				}
+				return structuredResult(result.String(), info), nil
+			}
+
+			if previewType == "user" {
+				result.WriteString(fmt.Sprintf("%d matching rule(s) give this user access to:\n", len(info.Matches)))
+			} else {
+				result.WriteString(fmt.Sprintf("%d matching rule(s) allow traffic from:\n", len(info.Matches)))
+			}
+			for _, match := range info.Matches {
+				if previewType == "user" {
+					result.WriteString(fmt.Sprintf("  ✓ %s", strings.Join(match.Ports, ", ")))
+				} else {
+					result.WriteString(fmt.Sprintf("  ✓ %s", strings.Join(match.Users, ", ")))
+				}
+				if match.Line > 0 {
+					result.WriteString(fmt.Sprintf(" (line %d)", match.Line))
+				}
+				result.WriteString("\n")
+				if match.Rule != "" {
+					result.WriteString(fmt.Sprintf("      %s\n", match.Rule))
+				}
+			}
+
+			return structuredResult(result.String(), info), nil
+		}),
+	)
+}
+
+// resolveDeviceIP returns the first Tailscale IP of the device named by host,
+// or host itself when it already is an IP
+func resolveDeviceIP(ctx context.Context, api *tailscale.APIClient, host string) (netip.Addr, error) {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return ip, nil
+	}
+
+	devices, err := api.ListDevices(ctx)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	device := findDeviceByHost(devices, host)
+	if device == nil {
+		return netip.Addr{}, fmt.Errorf("no device named %q", host)
+	}
+	for _, addr := range device.Addresses {
+		if ip, err := netip.ParseAddr(addr); err == nil {
+			return ip, nil
+		}
+	}
+	return netip.Addr{}, fmt.Errorf("device %q has no Tailscale IP", host)
+}
@@ -0,0 +1,575 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// sshRuleInfo is an ssh rule with the state of the nodes it targets
+type sshRuleInfo struct {
+	Index int `json:"index"`
+	tailscale.PolicySSHRule
+	Targets []sshTarget `json:"targets,omitempty"`
+}
+
+// sshTarget is a node matched by an ssh rule's dst
+type sshTarget struct {
+	Name       string `json:"name"`
+	Online     bool   `json:"online"`
+	SSHEnabled bool   `json:"ssh_enabled"`
+}
+
+// sshAccess is whether the local node may connect to a device with Tailscale SSH
+type sshAccess struct {
+	Name       string `json:"name"`
+	Online     bool   `json:"online"`
+	SSHEnabled bool   `json:"ssh_enabled"`
+	// Allowed is yes, no or unknown when a matching rule's src couldn't be
+	// resolved from the local status (e.g. autogroup:admin)
+	Allowed    string   `json:"allowed"`
+	Users      []string `json:"users,omitempty"`
+	CheckMode  bool     `json:"check_mode,omitempty"`
+	Rules      []int    `json:"rules,omitempty"`
+	Unresolved []string `json:"unresolved,omitempty"`
+}
+
+// RegisterACLSSHTools registers tools for managing the policy's ssh section.
+// The local status is used to check that the rules' targets run Tailscale SSH.
+func RegisterACLSSHTools(server *mcp.Server, cli *tailscale.CLI, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_list_ssh_rules",
+			Description: "List the Tailscale SSH rules in the ACL policy and check whether the nodes each rule targets have Tailscale SSH enabled",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type:       "object",
+				Properties: map[string]*jsonschema.Schema{},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+			policy, err := tailscale.ParseACL(acl)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+			rules, err := sshRules(policy)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+
+			// Without the local status the rules are still listed, just unchecked
+			status, statusErr := cli.Status(ctx)
+
+			infos := []sshRuleInfo{}
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("SSH Rules (%d)\n\n", len(rules)))
+			if len(rules) == 0 {
+				result.WriteString("The policy has no ssh rules, so Tailscale SSH is denied everywhere.\n")
+			}
+			for i, rule := range rules {
+				info := sshRuleInfo{Index: i, PolicySSHRule: rule}
+				result.WriteString(fmt.Sprintf("[%d] %s: %s → %s as %s", i, rule.Action, strings.Join(rule.Src, ", "), strings.Join(rule.Dst, ", "), strings.Join(rule.Users, ", ")))
+				if rule.CheckPeriod != "" {
+					result.WriteString(fmt.Sprintf(" (re-check every %s)", rule.CheckPeriod))
+				}
+				result.WriteString("\n")
+				if status != nil {
+					info.Targets = sshTargets(status, rule.Dst)
+					writeSSHTargets(&result, info.Targets)
+				}
+				infos = append(infos, info)
+			}
+			if statusErr != nil {
+				result.WriteString(fmt.Sprintf("\n⚠ Could not get the local status to check targets: %v\n", statusErr))
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"rules": infos}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_add_ssh_rule",
+			Description: "Add a Tailscale SSH rule to the ACL policy's ssh section and check that the target nodes have Tailscale SSH enabled. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: updateTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"action": {
+						Type:        "string",
+						Description: "accept, or check to require recent re-authentication (default: check)",
+						Enum:        []interface{}{"accept", "check"},
+					},
+					"src": {
+						Type:        "array",
+						Description: "Who may connect: users, groups, tags or autogroup:member",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"dst": {
+						Type:        "array",
+						Description: "Nodes they may connect to: tags, autogroup:self or users (whose devices are targeted)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"users": {
+						Type:        "array",
+						Description: "Local users they may log in as, e.g. ubuntu, autogroup:nonroot or root",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"check_period": {
+						Type:        "string",
+						Description: "How long a check-mode authentication lasts, e.g. 12h or always (optional; only with action check)",
+					},
+					"comment": {
+						Type:        "string",
+						Description: "Comment written above the rule (optional)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"src", "dst", "users"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Action      string   `json:"action"`
+				Src         []string `json:"src"`
+				Dst         []string `json:"dst"`
+				Users       []string `json:"users"`
+				CheckPeriod string   `json:"check_period"`
+				Comment     string   `json:"comment"`
+				DryRun      *bool    `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if params.Action == "" {
+				params.Action = "check"
+			}
+			var problem string
+			switch {
+			case params.Action != "accept" && params.Action != "check":
+				problem = "action must be accept or check"
+			case len(params.Src) == 0 || len(params.Dst) == 0 || len(params.Users) == 0:
+				problem = "src, dst and users need at least one entry"
+			case params.CheckPeriod != "" && params.Action != "check":
+				problem = "check_period only applies to action check"
+			}
+			if problem != "" {
+				return invalidInputResult(problem), nil
+			}
+
+			rule := tailscale.PolicySSHRule{
+				Action:      params.Action,
+				Src:         params.Src,
+				Dst:         params.Dst,
+				Users:       params.Users,
+				CheckPeriod: params.CheckPeriod,
+			}
+			title := fmt.Sprintf("add ssh rule %s → %s as %s", strings.Join(rule.Src, ", "), strings.Join(rule.Dst, ", "), strings.Join(rule.Users, ", "))
+
+			result := runPolicyEdit(ctx, api, title, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				rules, err := sshRules(policy)
+				if err != nil {
+					return "", err
+				}
+				for _, existing := range rules {
+					if equalSSHRules(existing, rule) {
+						return "", nil
+					}
+				}
+				if err := policy.AddSSHRule(rule, params.Comment); err != nil {
+					return "", err
+				}
+				return "Added the rule to ssh", nil
+			})
+
+			// Append the target check to the edit's report
+			if status, err := cli.Status(ctx); err == nil && len(result.Content) == 1 {
+				if text, ok := result.Content[0].(*mcp.TextContent); ok {
+					var check strings.Builder
+					check.WriteString("\nTargets:\n")
+					targets := sshTargets(status, rule.Dst)
+					writeSSHTargets(&check, targets)
+					if len(targets) == 0 {
+						check.WriteString("  ⚠ No nodes in the local status match dst yet\n")
+					}
+					text.Text += check.String()
+				}
+			}
+			return result, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "check_ssh_access",
+			Description: "Report, per device, whether Tailscale SSH is running on it and whether the ACL policy's ssh rules let this node connect, and as which users. Combines the local status with the policy; access that depends on roles (e.g. autogroup:admin) is reported as unknown.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"devices": {
+						Type:        "array",
+						Description: "Device hostnames or Tailscale IPs to check (default: all peers)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Devices []string `json:"devices"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			status, err := cli.Status(ctx)
+			if err != nil || status.Self == nil {
+				if err == nil {
+					err = fmt.Errorf("no local node in status")
+				}
+				return errorResult("Error getting status", err), nil
+			}
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+			policy, err := tailscale.ParseACL(acl)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+			var doc struct {
+				Groups map[string][]string       `json:"groups"`
+				SSH    []tailscale.PolicySSHRule `json:"ssh"`
+			}
+			if err := policy.Decode(&doc); err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+
+			logins := statusLogins(status)
+			self := status.Self
+			selfOwner := logins[strings.Trim(string(self.UserID), `"`)]
+
+			var peers []*tailscale.PeerStatus
+			var missing []string
+			if len(params.Devices) > 0 {
+				for _, d := range params.Devices {
+					peer := findPeerByHost(status, d)
+					for _, p := range status.Peer {
+						if peer == nil && p != nil && containsString(p.TailscaleIPs, d) {
+							peer = p
+						}
+					}
+					if peer == nil {
+						missing = append(missing, d)
+					} else if !slices.Contains(peers, peer) {
+						peers = append(peers, peer)
+					}
+				}
+			} else {
+				for _, peer := range status.Peer {
+					if peer != nil {
+						peers = append(peers, peer)
+					}
+				}
+			}
+			sort.Slice(peers, func(i, j int) bool { return peers[i].HostName < peers[j].HostName })
+
+			accesses := []sshAccess{}
+			for _, peer := range peers {
+				owner := logins[strings.Trim(string(peer.UserID), `"`)]
+				accesses = append(accesses, evaluateSSHAccess(doc.SSH, doc.Groups, self, selfOwner, peer, owner))
+			}
+
+			var result strings.Builder
+			identity := selfOwner
+			if len(self.Tags) > 0 {
+				identity = strings.Join(self.Tags, ", ")
+			}
+			result.WriteString(fmt.Sprintf("Tailscale SSH access from %s (%s)\n", self.HostName, identity))
+			if prefs, err := cli.Prefs(ctx); err == nil {
+				if prefs.RunSSH {
+					result.WriteString("This node runs the Tailscale SSH server.\n")
+				} else {
+					result.WriteString("This node doesn't run the Tailscale SSH server.\n")
+				}
+			}
+			if len(doc.SSH) == 0 {
+				result.WriteString("⚠ The policy has no ssh rules, so Tailscale SSH is denied everywhere.\n")
+			}
+			result.WriteString(fmt.Sprintf("\nDevices (%d):\n", len(accesses)))
+
+			counts := map[string]int{}
+			for _, a := range accesses {
+				name := a.Name
+				if !a.Online {
+					name += " (offline)"
+				}
+				rules := make([]string, len(a.Rules))
+				for i, r := range a.Rules {
+					rules[i] = fmt.Sprintf("%d", r)
+				}
+				switch {
+				case a.Allowed == "yes" && !a.SSHEnabled:
+					counts["not running"]++
+					result.WriteString(fmt.Sprintf("  ⚠ %s: allowed as %s by rule %s, but Tailscale SSH isn't running on it (run `tailscale set --ssh` there)\n", name, strings.Join(a.Users, ", "), strings.Join(rules, ", ")))
+				case a.Allowed == "yes":
+					counts["allowed"]++
+					result.WriteString(fmt.Sprintf("  ✓ %s: allowed as %s by rule %s", name, strings.Join(a.Users, ", "), strings.Join(rules, ", ")))
+					if a.CheckMode {
+						result.WriteString(" (check mode: needs interactive re-authentication)")
+					}
+					result.WriteString("\n")
+				case a.Allowed == "unknown":
+					counts["unknown"]++
+					result.WriteString(fmt.Sprintf("  ? %s: rule %s allows %s if this node is in %s, which the local status can't tell\n", name, strings.Join(rules, ", "), strings.Join(a.Users, ", "), strings.Join(a.Unresolved, ", ")))
+				default:
+					counts["denied"]++
+					running := "SSH running"
+					if !a.SSHEnabled {
+						running = "SSH not running"
+					}
+					result.WriteString(fmt.Sprintf("  ✗ %s: no ssh rule allows this node (%s)\n", name, running))
+				}
+			}
+			for _, d := range missing {
+				result.WriteString(fmt.Sprintf("  ✗ %s: not found in the local status\n", d))
+			}
+			if len(accesses) > 1 {
+				result.WriteString("\nSummary:\n")
+				writeCounts(&result, counts)
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{
+				"node":    self.HostName,
+				"devices": accesses,
+			}), nil
+		}),
+	)
+}
+
+// evaluateSSHAccess applies the ssh rules to a connection from self (owned
+// by selfOwner) to peer (owned by owner). Tagged nodes are identified by their
+// tags rather than the user who tagged them.
+func evaluateSSHAccess(rules []tailscale.PolicySSHRule, groups map[string][]string, self *tailscale.PeerStatus, selfOwner string, peer *tailscale.PeerStatus, owner string) sshAccess {
+	access := sshAccess{
+		Name:       peer.HostName,
+		Online:     peer.Online,
+		SSHEnabled: len(peer.SSHHostKeys) > 0,
+		Allowed:    "no",
+	}
+	var unknownUsers []string
+	unknownRules := []int{}
+	for i, rule := range rules {
+		dst := false
+		for _, d := range rule.Dst {
+			if d == "autogroup:self" {
+				// Only the user's own devices, from their own untagged devices
+				dst = len(peer.Tags) == 0 && len(self.Tags) == 0 && selfOwner != "" && strings.EqualFold(owner, selfOwner)
+			} else {
+				dst = sshDstMatches(peer, owner, d)
+			}
+			if dst {
+				break
+			}
+		}
+		if !dst {
+			continue
+		}
+
+		src, unresolved := sshSrcMatches(self, selfOwner, groups, rule.Src)
+		switch {
+		case src:
+			access.Allowed = "yes"
+			access.Rules = append(access.Rules, i)
+			access.Users = appendUnique(access.Users, rule.Users...)
+			if rule.Action == "check" {
+				access.CheckMode = true
+			}
+		case len(unresolved) > 0:
+			unknownRules = append(unknownRules, i)
+			unknownUsers = appendUnique(unknownUsers, rule.Users...)
+			access.Unresolved = appendUnique(access.Unresolved, unresolved...)
+		}
+	}
+	if access.Allowed == "no" && len(unknownRules) > 0 {
+		access.Allowed = "unknown"
+		access.Rules = unknownRules
+		access.Users = unknownUsers
+	} else if access.Allowed == "yes" {
+		access.Unresolved = nil
+	}
+	return access
+}
+
+// sshSrcMatches reports whether self is matched by an ssh rule's src entries.
+// Entries that depend on information the local status lacks, like user roles,
+// are returned as unresolved.
+func sshSrcMatches(self *tailscale.PeerStatus, owner string, groups map[string][]string, src []string) (bool, []string) {
+	tagged := len(self.Tags) > 0
+	var unresolved []string
+	for _, s := range src {
+		switch {
+		case s == "*":
+			return true, nil
+		case strings.HasPrefix(s, "tag:"):
+			if containsString(self.Tags, s) {
+				return true, nil
+			}
+		case s == "autogroup:tagged":
+			if tagged {
+				return true, nil
+			}
+		case s == "autogroup:member":
+			if !tagged {
+				return true, nil
+			}
+		case strings.HasPrefix(s, "group:"):
+			if tagged {
+				continue
+			}
+			for _, member := range groups[s] {
+				if strings.EqualFold(member, owner) {
+					return true, nil
+				}
+			}
+		case strings.Contains(s, "@"):
+			if !tagged && strings.EqualFold(s, owner) {
+				return true, nil
+			}
+		case strings.HasPrefix(s, "autogroup:"):
+			if !tagged {
+				unresolved = append(unresolved, s)
+			}
+		}
+	}
+	return false, unresolved
+}
+
+// appendUnique appends the values not already in list
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !containsString(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// sshRules decodes the policy's ssh section
+func sshRules(policy *tailscale.Policy) ([]tailscale.PolicySSHRule, error) {
+	var doc struct {
+		SSH []tailscale.PolicySSHRule `json:"ssh"`
+	}
+	if err := policy.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc.SSH, nil
+}
+
+func equalSSHRules(a, b tailscale.PolicySSHRule) bool {
+	return a.Action == b.Action && a.CheckPeriod == b.CheckPeriod &&
+		slices.Equal(a.Src, b.Src) && slices.Equal(a.Dst, b.Dst) && slices.Equal(a.Users, b.Users)
+}
+
+// sshTargets returns the nodes in status matched by an ssh rule's dst entries:
+// tags match tagged nodes, autogroup:self and autogroup:member match nodes
+// owned by users, and login names match that user's nodes
+func sshTargets(status *tailscale.Status, dst []string) []sshTarget {
+	logins := statusLogins(status)
+
+	var targets []sshTarget
+	for _, node := range statusNodes(status) {
+		owner := logins[strings.Trim(string(node.UserID), `"`)]
+		matched := false
+		for _, d := range dst {
+			if matched = sshDstMatches(node, owner, d); matched {
+				break
+			}
+		}
+		if matched {
+			online := node.Online || node == status.Self
+			targets = append(targets, sshTarget{Name: node.HostName, Online: online, SSHEnabled: len(node.SSHHostKeys) > 0})
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+	return targets
+}
+
+// statusLogins maps user IDs in status to login names
+func statusLogins(status *tailscale.Status) map[string]string {
+	logins := map[string]string{}
+	for id, user := range status.User {
+		if user != nil {
+			logins[id] = user.LoginName
+		}
+	}
+	return logins
+}
+
+// statusNodes returns the local node followed by its peers
+func statusNodes(status *tailscale.Status) []*tailscale.PeerStatus {
+	var nodes []*tailscale.PeerStatus
+	if status.Self != nil {
+		nodes = append(nodes, status.Self)
+	}
+	for _, peer := range status.Peer {
+		if peer != nil {
+			nodes = append(nodes, peer)
+		}
+	}
+	return nodes
+}
+
+// sshDstMatches reports whether node, owned by owner, is matched by an ssh
+// rule dst entry. autogroup:self is treated as any user-owned node; callers
+// that know the source user narrow it further.
+func sshDstMatches(node *tailscale.PeerStatus, owner, d string) bool {
+	switch {
+	case strings.HasPrefix(d, "tag:"):
+		return containsString(node.Tags, d)
+	case d == "autogroup:self" || d == "autogroup:member":
+		return len(node.Tags) == 0
+	case strings.Contains(d, "@"):
+		return len(node.Tags) == 0 && strings.EqualFold(owner, d)
+	default:
+		return strings.EqualFold(node.HostName, d) || containsString(node.TailscaleIPs, d)
+	}
+}
+
+func writeSSHTargets(result *strings.Builder, targets []sshTarget) {
+	for _, t := range targets {
+		switch {
+		case !t.SSHEnabled:
+			result.WriteString(fmt.Sprintf("  ⚠ %s: Tailscale SSH is not enabled (run `tailscale set --ssh` on it)\n", t.Name))
+		case !t.Online:
+			result.WriteString(fmt.Sprintf("  ✓ %s: SSH enabled (offline)\n", t.Name))
+		default:
+			result.WriteString(fmt.Sprintf("  ✓ %s: SSH enabled\n", t.Name))
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/diff"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// aclSyncInfo is the structured form of the acl_sync tool's output
+type aclSyncInfo struct {
+	File string `json:"file"`
+	// Ref and Commit are set when the policy was read from git
+	Ref    string `json:"ref,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	// Uncommitted is set when the working copy of the file has changes not
+	// in HEAD, i.e. it doesn't match what's in version control
+	Uncommitted    bool            `json:"uncommitted,omitempty"`
+	Drift          bool            `json:"drift"`
+	SemanticChange bool            `json:"semantic_change"`
+	Sections       []sectionChange `json:"sections,omitempty"`
+	Diff           string          `json:"diff,omitempty"`
+	Pushed         bool            `json:"pushed"`
+	ETag           string          `json:"etag,omitempty"`
+}
+
+// RegisterACLSyncTools registers the tool for syncing the policy with a file
+// kept in version control
+func RegisterACLSyncTools(server *mcp.Server, api *tailscale.APIClient, output *OutputWriter) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_sync",
+			Description: "Compare the tailnet's live ACL policy with a policy file on disk (optionally at a git revision) and report drift. Set dry_run=false to push the file's version to the tailnet after validating it.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"file": {
+						Type:        "string",
+						Description: "Path to the policy file (relative to the client's roots or the configured output directory)",
+					},
+					"ref": {
+						Type:        "string",
+						Description: "Git revision to read the file at, e.g. main or origin/main (optional; defaults to the working copy)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only report drift without pushing the file (default: true)",
+					},
+				},
+				Required: []string{"file"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				File   string `json:"file"`
+				Ref    string `json:"ref"`
+				DryRun *bool  `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			dryRun := params.DryRun == nil || *params.DryRun
+
+			path, err := output.Resolve(ctx, req.Session, params.File)
+			if err != nil {
+				return errorResult("Error", err), nil
+			}
+
+			info := aclSyncInfo{File: path, Ref: params.Ref}
+			var content string
+			if params.Ref != "" {
+				content, info.Commit, err = gitShowFile(ctx, path, params.Ref)
+			} else {
+				var data []byte
+				if data, err = os.ReadFile(path); err == nil {
+					content = string(data)
+					// Not being in a git checkout is fine; the file is synced as is
+					info.Commit, info.Uncommitted = gitFileState(ctx, path)
+				}
+			}
+			if err != nil {
+				return errorResult(fmt.Sprintf("Error reading %s", params.File), err), nil
+			}
+
+			desired, err := tailscale.ParsePolicy(content)
+			if err != nil {
+				return errorResult(fmt.Sprintf("Error parsing %s", params.File), err), nil
+			}
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+			live, err := tailscale.ParseACL(acl)
+			if err != nil {
+				return errorResult("Error parsing live ACL", err), nil
+			}
+
+			info.Diff = diff.Unified("policy.hujson (live)", filepath.Base(path)+" ("+sourceLabel(params.Ref)+")", live.String(), desired.String())
+			info.Drift = info.Diff != ""
+			if info.Sections, err = diffPolicySections(live, desired); err != nil {
+				return errorResult("Error comparing policies", err), nil
+			}
+			info.SemanticChange = len(info.Sections) > 0
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("ACL Sync: %s", path))
+			if info.Commit != "" {
+				result.WriteString(fmt.Sprintf(" @ %s (%s)", sourceLabel(params.Ref), shortCommit(info.Commit)))
+			}
+			result.WriteString("\n\n")
+			if info.Uncommitted {
+				result.WriteString("⚠ The file has uncommitted changes; the working copy differs from HEAD\n\n")
+			}
+
+			switch {
+			case !info.Drift:
+				result.WriteString("✓ No drift: the live policy matches the file.\n")
+				return structuredResult(result.String(), info), nil
+			case !info.SemanticChange:
+				result.WriteString("⚠ Only comments or formatting differ; the effective policy is the same\n")
+			default:
+				result.WriteString("⚠ Drift detected in:\n")
+				for _, section := range info.Sections {
+					result.WriteString(fmt.Sprintf("  ~ %s (%s, %d → %d entries)\n", section.Section, section.Change, section.Before, section.After))
+				}
+			}
+			result.WriteString("\nDiff (live → file):\n")
+			result.WriteString(info.Diff)
+
+			if dryRun {
+				result.WriteString("\nRe-run with dry_run=false to push the file to the tailnet.\n")
+				return structuredResult(result.String(), info), nil
+			}
+
+			// Push the file as written, with the live ETag so edits made in
+			// the admin console since the fetch aren't overwritten
+			pushed := &tailscale.ACL{RawPolicy: content, ETag: acl.ETag}
+			if err := api.ValidateACL(ctx, pushed); err != nil {
+				result.WriteString(fmt.Sprintf("\n✗ The file failed validation, nothing was pushed: %v\n", err))
+				return structuredResult(result.String(), info), nil
+			}
+			etag, err := api.SetACL(ctx, pushed)
+			if err != nil {
+				result.WriteString(fmt.Sprintf("\n✗ Error pushing the policy: %v\n", err))
+				return structuredResult(result.String(), info), nil
+			}
+			info.Pushed, info.ETag = true, etag
+			result.WriteString("\n✓ Pushed the file's policy to the tailnet\n")
+
+			return structuredResult(result.String(), info), nil
+		}),
+	)
+}
+
+// gitShowFile reads path as of ref from the git checkout containing it and
+// returns its content and the commit ref resolves to
+func gitShowFile(ctx context.Context, path, ref string) (string, string, error) {
+	dir := filepath.Dir(path)
+	top, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", "", fmt.Errorf("%s is not in a git checkout: %w", path, err)
+	}
+	resolvedTop, err := filepath.EvalSymlinks(top)
+	if err != nil {
+		return "", "", err
+	}
+	resolvedPath, err := resolveExisting(path)
+	if err != nil {
+		return "", "", err
+	}
+	rel, err := filepath.Rel(resolvedTop, resolvedPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	// A ref starting with "-" would be read as a git option
+	if strings.HasPrefix(ref, "-") {
+		return "", "", fmt.Errorf("invalid revision %q", ref)
+	}
+	commit, err := runGit(ctx, dir, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", "", fmt.Errorf("unknown revision %s: %w", ref, err)
+	}
+	content, err := runGit(ctx, dir, "show", commit+":"+filepath.ToSlash(rel))
+	if err != nil {
+		return "", "", err
+	}
+	return content, commit, nil
+}
+
+// gitFileState returns the HEAD commit of the checkout containing path and
+// whether the file differs from it. Both are zero outside a checkout.
+func gitFileState(ctx context.Context, path string) (string, bool) {
+	dir := filepath.Dir(path)
+	commit, err := runGit(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", false
+	}
+	status, err := runGit(ctx, dir, "status", "--porcelain", "--", filepath.Base(path))
+	return commit, err == nil && status != ""
+}
+
+// runGit runs git in dir and returns its output with surrounding whitespace
+// trimmed, except for show whose output is file content
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	if args[0] == "show" {
+		return stdout.String(), nil
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// sourceLabel names where the file's content came from
+func sourceLabel(ref string) string {
+	if ref == "" {
+		return "working copy"
+	}
+	return ref
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
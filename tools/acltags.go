@@ -0,0 +1,298 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// tagOwnerInfo describes a tag declared in the policy's tagOwners section
+type tagOwnerInfo struct {
+	Tag    string   `json:"tag"`
+	Owners []string `json:"owners"`
+	// Devices is the number of devices carrying the tag, if they could be listed
+	Devices *int `json:"devices,omitempty"`
+	// UsedIn lists the other policy sections that reference the tag
+	UsedIn []string `json:"used_in,omitempty"`
+}
+
+// RegisterACLTagOwnerTools registers tools for managing the policy's tagOwners
+func RegisterACLTagOwnerTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_list_tag_owners",
+			Description: "List the tags declared in the ACL policy's tagOwners section with their owners, how many devices carry each tag, and which policy sections use it",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"tag": {
+						Type:        "string",
+						Description: "Only show this tag (e.g., tag:web or web) (optional)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Tag string `json:"tag"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			filter := tailscale.NormalizeTag(params.Tag)
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+			policy, err := tailscale.ParseACL(acl)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+			var doc map[string]interface{}
+			var sections struct {
+				TagOwners map[string][]string `json:"tagOwners"`
+			}
+			if err = policy.Decode(&doc); err == nil {
+				err = policy.Decode(&sections)
+			}
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+
+			// Device counts are best effort, like the membership checks for groups
+			devices, devicesErr := api.ListDevices(ctx)
+
+			tags := []tagOwnerInfo{}
+			for _, tag := range sortedKeys(sections.TagOwners) {
+				if filter != "" && tag != filter {
+					continue
+				}
+				info := tagOwnerInfo{Tag: tag, Owners: sections.TagOwners[tag]}
+				if info.Owners == nil {
+					info.Owners = []string{}
+				}
+				if devicesErr == nil {
+					count := len(devicesWithTag(devices, tag))
+					info.Devices = &count
+				}
+				for _, section := range sortedKeys(doc) {
+					if section != "tagOwners" && referenced(tag, collectStrings(doc[section], nil)) {
+						info.UsedIn = append(info.UsedIn, section)
+					}
+				}
+				tags = append(tags, info)
+			}
+			if filter != "" && len(tags) == 0 {
+				return notFoundResult(fmt.Sprintf("%s is not declared in tagOwners", filter)), nil
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Tag Owners (%d)\n\n", len(tags)))
+			if len(tags) == 0 {
+				result.WriteString("The policy declares no tags.\n")
+			}
+			for _, tag := range tags {
+				owners := strings.Join(tag.Owners, ", ")
+				if owners == "" {
+					owners = "none (only admins can apply it)"
+				}
+				result.WriteString(fmt.Sprintf("%s\n  Owners: %s\n", tag.Tag, owners))
+				if tag.Devices != nil {
+					result.WriteString(fmt.Sprintf("  Devices: %d\n", *tag.Devices))
+				}
+				if len(tag.UsedIn) > 0 {
+					result.WriteString(fmt.Sprintf("  Used in: %s\n", strings.Join(tag.UsedIn, ", ")))
+				}
+				result.WriteString("\n")
+			}
+			if devicesErr != nil {
+				result.WriteString(fmt.Sprintf("⚠ Could not list devices to count tag usage: %v\n", devicesErr))
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"tags": tags}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_add_tag_owner",
+			Description: "Add owners to a tag in the policy's tagOwners section, declaring the tag if it doesn't exist yet. Shows a diff by default; set dry_run=false to apply.",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"tag": {
+						Type:        "string",
+						Description: "Tag name (e.g., tag:web or web)",
+					},
+					"owners": {
+						Type:        "array",
+						Description: "Users, groups, autogroups or tags allowed to apply the tag (e.g., group:ops, autogroup:admin). Empty declares a tag only admins can apply",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"tag"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Tag    string   `json:"tag"`
+				Owners []string `json:"owners"`
+				DryRun *bool    `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			tag := tailscale.NormalizeTag(params.Tag)
+			if tag == "" {
+				return invalidInputResult("tag is required"), nil
+			}
+
+			return runPolicyEdit(ctx, api, "add owners to "+tag, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				declared := policy.HasTagOwner(tag)
+				added, err := policy.AddTagOwners(tag, params.Owners)
+				if err != nil {
+					return "", err
+				}
+				if !declared {
+					return fmt.Sprintf("Declared %s with %d owner(s)", tag, added), nil
+				}
+				return fmt.Sprintf("Added %d owner(s) to %s", added, tag), nil
+			}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_remove_tag_owner",
+			Description: "Remove owners from a tag in the policy's tagOwners section, or remove the tag's declaration entirely when no owners are given (refused while devices still carry the tag). Shows a diff by default; set dry_run=false to apply.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"tag": {
+						Type:        "string",
+						Description: "Tag name (e.g., tag:web or web)",
+					},
+					"owners": {
+						Type:        "array",
+						Description: "Owners to remove (optional; omit to remove the whole tag declaration)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the policy diff without saving it (default: true)",
+					},
+				},
+				Required: []string{"tag"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Tag    string   `json:"tag"`
+				Owners []string `json:"owners"`
+				DryRun *bool    `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			tag := tailscale.NormalizeTag(params.Tag)
+			if tag == "" {
+				return invalidInputResult("tag is required"), nil
+			}
+
+			if len(params.Owners) == 0 && api != nil && api.IsAvailable() {
+				devices, err := api.ListDevices(ctx)
+				if err != nil {
+					return errorResult("Error listing devices", err), nil
+				}
+				if tagged := devicesWithTag(devices, tag); len(tagged) > 0 {
+					return refusedResult(fmt.Sprintf("%d device(s) still carry %s (%s). Retag them with set_device_tags before removing the tag.", len(tagged), tag, strings.Join(tagged, ", "))), nil
+				}
+			}
+
+			return runPolicyEdit(ctx, api, "remove owners from "+tag, params.DryRun == nil || *params.DryRun, func(policy *tailscale.Policy) (string, error) {
+				if len(params.Owners) == 0 {
+					removed, err := policy.RemoveTagOwner(tag)
+					if err != nil {
+						return "", err
+					}
+					if !removed {
+						return "", fmt.Errorf("%s is not declared in tagOwners", tag)
+					}
+					return fmt.Sprintf("Removed the declaration of %s", tag), nil
+				}
+
+				removed, err := policy.RemoveTagOwners(tag, params.Owners)
+				if err != nil {
+					return "", err
+				}
+				if removed == 0 {
+					return "", fmt.Errorf("none of them own %s", tag)
+				}
+				return fmt.Sprintf("Removed %d owner(s) from %s", removed, tag), nil
+			}), nil
+		}),
+	)
+}
+
+// devicesWithTag returns the names of the devices carrying tag
+func devicesWithTag(devices []tailscale.Device, tag string) []string {
+	var names []string
+	for _, d := range devices {
+		if containsString(d.Tags, tag) {
+			names = append(names, d.Hostname)
+		}
+	}
+	return names
+}
+
+// undeclaredTags returns the tags that the policy doesn't declare in tagOwners
+func undeclaredTags(policy *tailscale.Policy, tags []string) []string {
+	var missing []string
+	for _, tag := range tags {
+		if !policy.HasTagOwner(tag) {
+			missing = append(missing, tag)
+		}
+	}
+	return missing
+}
+
+// checkTagOwners refuses tags the policy doesn't declare in tagOwners, with a
+// pointer to the fix; the API's own error doesn't name the missing entry. It
+// returns nil when every tag is declared or the policy can't be read, since
+// the API still enforces tagOwners itself.
+func checkTagOwners(ctx context.Context, api *tailscale.APIClient, tags []string) *mcp.CallToolResult {
+	if len(tags) == 0 {
+		return nil
+	}
+	acl, err := api.GetACL(ctx)
+	if err != nil {
+		return nil
+	}
+	policy, err := tailscale.ParseACL(acl)
+	if err != nil {
+		return nil
+	}
+	if missing := undeclaredTags(policy, tags); len(missing) > 0 {
+		return refusedResult(fmt.Sprintf("Not declared in the policy's tagOwners: %s. Declare them with acl_add_tag_owner first.", strings.Join(missing, ", ")))
+	}
+	return nil
+}
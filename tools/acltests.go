@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// aclTestsInfo is the structured form of the acl_run_tests tool's output
+type aclTestsInfo struct {
+	Source string `json:"source"` // policy or ad-hoc
+	Passed int    `json:"passed"`
+	Failed int    `json:"failed"`
+	// PolicyError is set when the policy itself was rejected, in which case
+	// no test results are available
+	PolicyError string          `json:"policy_error,omitempty"`
+	Tests       []aclTestResult `json:"tests"`
+}
+
+type aclTestResult struct {
+	tailscale.PolicyTest
+	Passed   bool     `json:"passed"`
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// RegisterACLTestTools registers the tool for running policy tests
+func RegisterACLTestTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_run_tests",
+			Description: "Run ACL tests against the deployed policy (or a candidate acl) and report pass/fail for each test. Runs the policy's own tests section unless ad-hoc tests are given.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"tests": {
+						Type:        "array",
+						Description: "Ad-hoc tests to run instead of the policy's tests section (optional)",
+						Items: &jsonschema.Schema{
+							Type: "object",
+							Properties: map[string]*jsonschema.Schema{
+								"src": {
+									Type:        "string",
+									Description: "User, group or tag the traffic comes from (e.g., alice@example.com, tag:ci)",
+								},
+								"accept": {
+									Type:        "array",
+									Description: "Destinations that must be reachable, as host:port (e.g., tag:db:5432)",
+									Items:       &jsonschema.Schema{Type: "string"},
+								},
+								"deny": {
+									Type:        "array",
+									Description: "Destinations that must not be reachable, as host:port",
+									Items:       &jsonschema.Schema{Type: "string"},
+								},
+							},
+							Required: []string{"src"},
+						},
+					},
+					"acl": {
+						Type:        "string",
+						Description: "Candidate ACL policy in HuJSON or JSON format (optional; defaults to the deployed policy)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Tests []tailscale.PolicyTest `json:"tests"`
+				ACL   string                 `json:"acl"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			raw := params.ACL
+			if raw == "" {
+				acl, err := api.GetACL(ctx)
+				if err != nil {
+					return errorResult("Error getting ACL", err), nil
+				}
+				raw = acl.RawPolicy
+			}
+			policy, err := tailscale.ParsePolicy(raw)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+
+			info := aclTestsInfo{Source: "policy", Tests: []aclTestResult{}}
+			tests := params.Tests
+			if len(tests) > 0 {
+				info.Source = "ad-hoc"
+				if err := policy.SetTests(tests); err != nil {
+					return errorResult("Error adding tests to the policy", err), nil
+				}
+			} else if tests, err = policy.Tests(); err != nil {
+				return errorResult("Error reading tests", err), nil
+			}
+			if len(tests) == 0 {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: "The policy has no tests section. Pass tests to run ad-hoc ones."},
+					},
+				}, nil
+			}
+
+			report, err := api.TestACL(ctx, policy.ACL())
+			if err != nil {
+				return errorResult("Error running tests", err), nil
+			}
+
+			// Failures are reported per test source, so every test with a
+			// failing src gets that source's errors
+			failures := map[string]tailscale.ACLTestFailure{}
+			for _, failure := range report.Data {
+				failures[failure.User] = failure
+			}
+			if report.Message != "" && len(report.Data) == 0 {
+				info.PolicyError = report.Message
+			}
+
+			for _, test := range tests {
+				result := aclTestResult{PolicyTest: test, Passed: true}
+				if failure, ok := failures[test.Src]; ok {
+					result.Passed = len(failure.Errors) == 0
+					result.Errors = failure.Errors
+					result.Warnings = failure.Warnings
+				}
+				if info.PolicyError != "" {
+					result.Passed = false
+				}
+				if result.Passed {
+					info.Passed++
+				} else {
+					info.Failed++
+				}
+				info.Tests = append(info.Tests, result)
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("ACL Tests (%d %s test(s))\n\n", len(tests), info.Source))
+			if info.PolicyError != "" {
+				result.WriteString(fmt.Sprintf("✗ The policy was rejected, so the tests could not run: %s\n", info.PolicyError))
+				return structuredResult(result.String(), info), nil
+			}
+
+			for _, test := range info.Tests {
+				marker := "✓"
+				if !test.Passed {
+					marker = "✗"
+				}
+				result.WriteString(fmt.Sprintf("%s %s", marker, test.Src))
+				if len(test.Accept) > 0 {
+					result.WriteString(fmt.Sprintf(" accept [%s]", strings.Join(test.Accept, ", ")))
+				}
+				if len(test.Deny) > 0 {
+					result.WriteString(fmt.Sprintf(" deny [%s]", strings.Join(test.Deny, ", ")))
+				}
+				result.WriteString("\n")
+				for _, e := range test.Errors {
+					result.WriteString(fmt.Sprintf("    %s\n", e))
+				}
+				for _, w := range test.Warnings {
+					result.WriteString(fmt.Sprintf("    ⚠ %s\n", w))
+				}
+			}
+
+			result.WriteString(fmt.Sprintf("\n%d passed, %d failed\n", info.Passed, info.Failed))
+			return structuredResult(result.String(), info), nil
+		}),
+	)
+}
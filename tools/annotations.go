@@ -0,0 +1,28 @@
+package tools
+
+import "github.com/modelcontextprotocol/go-sdk/mcp"
+
+// Tool annotations tell clients which tools only read state and which change
+// or remove something, so they can ask before running the latter. Tools that
+// default to a dry run are annotated by what they do when applied.
+
+// readOnlyTool annotates a tool that doesn't change the tailnet or the
+// device. Saving a report or a baseline to local files doesn't count.
+func readOnlyTool() *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{ReadOnlyHint: true}
+}
+
+// updateTool annotates a tool that creates or changes something without
+// removing anything. With idempotent, calling it again with the same
+// arguments has no further effect.
+func updateTool(idempotent bool) *mcp.ToolAnnotations {
+	destructive := false
+	return &mcp.ToolAnnotations{DestructiveHint: &destructive, IdempotentHint: idempotent}
+}
+
+// destructiveTool annotates a tool that can delete or replace state, or cut
+// off access or connectivity
+func destructiveTool(idempotent bool) *mcp.ToolAnnotations {
+	destructive := true
+	return &mcp.ToolAnnotations{DestructiveHint: &destructive, IdempotentHint: idempotent}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -12,16 +13,61 @@ import (
 	"github.com/phildougherty/go-tailscale-mcp/tailscale"
 )
 
+// maxAuthKeyExpiry is the longest expiry the API accepts for an auth key
+const maxAuthKeyExpiry = 90 * 24 * time.Hour
+
+// authKeyDescriptionPattern is what the API accepts as a key description
+var authKeyDescriptionPattern = regexp.MustCompile(`^[A-Za-z0-9 -]{1,50}$`)
+
+// writeAuthKey writes a key's metadata. The key itself is written by the caller.
+func writeAuthKey(result *strings.Builder, key tailscale.AuthKey) {
+	if key.Description != "" {
+		result.WriteString(fmt.Sprintf("Description: %s\n", key.Description))
+	}
+	if key.KeyType != "" {
+		result.WriteString(fmt.Sprintf("Type: %s\n", key.KeyType))
+	}
+	result.WriteString(fmt.Sprintf("Created: %s\n", key.Created.Format("2006-01-02 15:04:05")))
+	result.WriteString(fmt.Sprintf("Expires: %s\n", key.Expires.Format("2006-01-02 15:04:05")))
+	switch {
+	case key.Revoked != nil && !key.Revoked.IsZero():
+		result.WriteString(fmt.Sprintf("Status: REVOKED (%s)\n", key.Revoked.Format("2006-01-02 15:04:05")))
+	case key.Invalid:
+		result.WriteString("Status: INVALID\n")
+	case !key.Expires.IsZero() && time.Now().After(key.Expires):
+		result.WriteString("Status: EXPIRED\n")
+	default:
+		result.WriteString("Status: Active\n")
+	}
+	if key.UserID != "" {
+		result.WriteString(fmt.Sprintf("Created by: %s\n", key.UserID))
+	}
+	result.WriteString(fmt.Sprintf("Reusable: %t\n", key.Reusable))
+	result.WriteString(fmt.Sprintf("Ephemeral: %t\n", key.Ephemeral))
+	result.WriteString(fmt.Sprintf("Preauthorized: %t\n", key.Preauthorized))
+	if len(key.Tags) > 0 {
+		result.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(key.Tags, ", ")))
+	}
+	if len(key.Scopes) > 0 {
+		result.WriteString(fmt.Sprintf("Scopes: %s\n", strings.Join(key.Scopes, ", ")))
+	}
+}
+
 // RegisterAuthKeyTools registers authentication key management tools
-func RegisterAuthKeyTools(server *mcp.Server, api *tailscale.APIClient) {
+func RegisterAuthKeyTools(server *mcp.Server, api *tailscale.APIClient, output *OutputWriter) {
 	// Create auth key tool
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "create_auth_key",
-			Description: "Create a new authentication key with specified options",
+			Description: "Create a new authentication key with specified options. Tags must be declared in tagOwners; keys created with OAuth client credentials must be tagged.",
+			Annotations: updateTool(false),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
+					"description": {
+						Type:        "string",
+						Description: "What the key is for, up to 50 letters, digits, spaces or hyphens (e.g., ci-runners)",
+					},
 					"reusable": {
 						Type:        "boolean",
 						Description: "Whether the key can be used multiple times (default: false)",
@@ -35,39 +81,41 @@ func RegisterAuthKeyTools(server *mcp.Server, api *tailscale.APIClient) {
 						Description: "Whether devices using this key are automatically authorized (default: false)",
 					},
 					"tags": {
-						Type: "array",
-						Items: &jsonschema.Schema{Type: "string"},
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
 						Description: "Tags to assign to devices using this key",
 					},
 					"expiry_seconds": {
 						Type:        "integer",
-						Description: "Key expiration time in seconds (default: 3600)",
+						Description: "Key expiration time in seconds, at most 90 days (default: the tailnet's device key expiry, capped at 90 days; 3600 if the settings can't be read)",
 					},
+					"secrets_file": secretsFileProperty("key"),
 				},
 			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
 			}
 
 			var params struct {
+				Description   string   `json:"description"`
 				Reusable      *bool    `json:"reusable"`
 				Ephemeral     *bool    `json:"ephemeral"`
 				Preauthorized *bool    `json:"preauthorized"`
 				Tags          []string `json:"tags"`
 				ExpirySeconds *int     `json:"expiry_seconds"`
+				SecretsFile   string   `json:"secrets_file"`
 			}
-			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.SecretsFile != "" {
+				if _, err := output.Resolve(ctx, req.Session, params.SecretsFile); err != nil {
+					return invalidParamsResult(err), nil
+				}
 			}
 
 			// Set defaults
@@ -75,7 +123,8 @@ func RegisterAuthKeyTools(server *mcp.Server, api *tailscale.APIClient) {
 				Reusable:      false,
 				Ephemeral:     false,
 				Preauthorized: false,
-				ExpirySeconds: 3600, // 1 hour default
+				ExpirySeconds: 3600, // 1 hour if the tailnet settings can't be read
+				Description:   strings.TrimSpace(params.Description),
 			}
 
 			if params.Reusable != nil {
@@ -87,39 +136,67 @@ func RegisterAuthKeyTools(server *mcp.Server, api *tailscale.APIClient) {
 			if params.Preauthorized != nil {
 				options.Preauthorized = *params.Preauthorized
 			}
-			if params.Tags != nil {
-				options.Tags = params.Tags
+			for _, tag := range params.Tags {
+				if tag = tailscale.NormalizeTag(tag); tag != "" && !containsString(options.Tags, tag) {
+					options.Tags = append(options.Tags, tag)
+				}
 			}
+			expirySource := "default"
 			if params.ExpirySeconds != nil {
 				options.ExpirySeconds = *params.ExpirySeconds
+				expirySource = ""
+			} else if settings, err := api.GetTailnetSettings(ctx); err == nil && settings.DevicesKeyDurationDays > 0 {
+				expiry := min(time.Duration(settings.DevicesKeyDurationDays)*24*time.Hour, maxAuthKeyExpiry)
+				options.ExpirySeconds = int(expiry.Seconds())
+				expirySource = fmt.Sprintf("tailnet key expiry of %d days", settings.DevicesKeyDurationDays)
+				if expiry == maxAuthKeyExpiry && settings.DevicesKeyDurationDays > 90 {
+					expirySource += ", capped at 90"
+				}
 			}
 
-			authKey, err := api.CreateAuthKey(options)
+			scopes, usesOAuth := api.OAuthScopes()
+			var problem string
+			switch {
+			case options.Description != "" && !authKeyDescriptionPattern.MatchString(options.Description):
+				problem = "description must be at most 50 letters, digits, spaces or hyphens"
+			case options.ExpirySeconds <= 0 || time.Duration(options.ExpirySeconds)*time.Second > maxAuthKeyExpiry:
+				problem = fmt.Sprintf("expiry_seconds must be between 1 and %d (90 days)", int(maxAuthKeyExpiry.Seconds()))
+			case usesOAuth && len(options.Tags) == 0:
+				problem = "Keys created with OAuth client credentials must have tags; pass the tags the OAuth client is allowed to assign"
+			case usesOAuth && len(scopes) > 0 && !containsString(scopes, "auth_keys") && !containsString(scopes, "all"):
+				problem = fmt.Sprintf("The OAuth client has scopes %s; creating auth keys needs the auth_keys scope", strings.Join(scopes, ", "))
+			}
+			if problem != "" {
+				return invalidInputResult(problem), nil
+			}
+
+			if refused := checkTagOwners(ctx, api, options.Tags); refused != nil {
+				return refused, nil
+			}
+
+			authKey, err := api.CreateAuthKey(ctx, options)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error creating auth key: %v", err)},
-					},
-				}, nil
+				return errorResult("Error creating auth key", err), nil
 			}
 
 			var result strings.Builder
 			result.WriteString("Authentication Key Created:\n\n")
 			result.WriteString(fmt.Sprintf("ID: %s\n", authKey.ID))
 			result.WriteString(fmt.Sprintf("Key: %s\n", authKey.Key))
-			result.WriteString(fmt.Sprintf("Created: %s\n", authKey.Created.Format("2006-01-02 15:04:05")))
-			result.WriteString(fmt.Sprintf("Expires: %s\n", authKey.Expires.Format("2006-01-02 15:04:05")))
-			result.WriteString(fmt.Sprintf("Reusable: %t\n", authKey.Reusable))
-			result.WriteString(fmt.Sprintf("Ephemeral: %t\n", authKey.Ephemeral))
-			result.WriteString(fmt.Sprintf("Preauthorized: %t\n", authKey.Preauthorized))
-			if len(authKey.Tags) > 0 {
-				result.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(authKey.Tags, ", ")))
+			writeAuthKey(&result, *authKey)
+			if expirySource != "" {
+				result.WriteString(fmt.Sprintf("\nExpiry from the %s.\n", expirySource))
+			}
+			if usesOAuth {
+				result.WriteString(fmt.Sprintf("Created with OAuth client credentials (scopes: %s).\n", strings.Join(scopes, ", ")))
+			}
+			content := []mcp.Content{}
+			if link := saveSecret(ctx, output, req.Session, params.SecretsFile, "key", authKey.Key, &result); link != nil {
+				content = append(content, link)
 			}
 
 			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result.String()},
-				},
+				Content: append([]mcp.Content{&mcp.TextContent{Text: result.String()}}, content...),
 			}, nil
 		}),
 	)
@@ -129,24 +206,17 @@ func RegisterAuthKeyTools(server *mcp.Server, api *tailscale.APIClient) {
 		&mcp.Tool{
 			Name:        "list_auth_keys",
 			Description: "List all authentication keys",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{Type: "object"},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
 			}
 
-			authKeys, err := api.ListAuthKeys()
+			authKeys, err := api.ListAuthKeys(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error listing auth keys: %v", err)},
-					},
-				}, nil
+				return errorResult("Error listing auth keys", err), nil
 			}
 
 			if len(authKeys) == 0 {
@@ -160,6 +230,7 @@ func RegisterAuthKeyTools(server *mcp.Server, api *tailscale.APIClient) {
 			var result strings.Builder
 			result.WriteString("Authentication Keys:\n\n")
 
+			keys := make([]tailscale.AuthKey, 0, len(authKeys))
 			for _, key := range authKeys {
 				result.WriteString(fmt.Sprintf("ID: %s\n", key.ID))
 
@@ -169,31 +240,15 @@ func RegisterAuthKeyTools(server *mcp.Server, api *tailscale.APIClient) {
 					keyDisplay = keyDisplay[:20] + "..."
 				}
 				result.WriteString(fmt.Sprintf("Key: %s\n", keyDisplay))
+				redacted := key
+				redacted.Key = keyDisplay
+				keys = append(keys, redacted)
 
-				result.WriteString(fmt.Sprintf("Created: %s\n", key.Created.Format("2006-01-02 15:04:05")))
-				result.WriteString(fmt.Sprintf("Expires: %s\n", key.Expires.Format("2006-01-02 15:04:05")))
-
-				// Check if expired
-				if time.Now().After(key.Expires) {
-					result.WriteString("Status: EXPIRED\n")
-				} else {
-					result.WriteString("Status: Active\n")
-				}
-
-				result.WriteString(fmt.Sprintf("Reusable: %t\n", key.Reusable))
-				result.WriteString(fmt.Sprintf("Ephemeral: %t\n", key.Ephemeral))
-				result.WriteString(fmt.Sprintf("Preauthorized: %t\n", key.Preauthorized))
-				if len(key.Tags) > 0 {
-					result.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(key.Tags, ", ")))
-				}
+				writeAuthKey(&result, key)
 				result.WriteString("\n")
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result.String()},
-				},
-			}, nil
+			return structuredResult(result.String(), map[string]interface{}{"keys": keys}), nil
 		}),
 	)
 
@@ -202,6 +257,7 @@ func RegisterAuthKeyTools(server *mcp.Server, api *tailscale.APIClient) {
 		&mcp.Tool{
 			Name:        "delete_auth_key",
 			Description: "Delete an authentication key",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -215,30 +271,18 @@ func RegisterAuthKeyTools(server *mcp.Server, api *tailscale.APIClient) {
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
 			}
 
 			var params struct {
 				KeyID string `json:"key_id"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
-			if err := api.DeleteAuthKey(params.KeyID); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error deleting auth key: %v", err)},
-					},
-				}, nil
+			if err := api.DeleteAuthKey(ctx, params.KeyID); err != nil {
+				return errorResult("Error deleting auth key", err), nil
 			}
 
 			return &mcp.CallToolResult{
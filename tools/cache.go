@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// Cache keys for the lookups repeated most often within a conversation
+const (
+	statusCacheKey = "status"
+	dnsCacheKey    = "dns"
+)
+
+// CachedTools are served from the response cache; calling any other tool
+// may change the network, so it invalidates the cache
+var CachedTools = []string{"status", "list_devices", "get_dns_config"}
+
+// ResponseCache keeps recent CLI and API lookups for a short TTL so repeated
+// read-only calls don't re-run the CLI or spend API rate limit. A nil cache,
+// or one with a zero TTL, never caches.
+type ResponseCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// NewResponseCache creates a cache whose entries live for ttl
+func NewResponseCache(ttl time.Duration) *ResponseCache {
+	return &ResponseCache{ttl: ttl, entries: map[string]cacheEntry{}}
+}
+
+// Clear drops every cached entry
+func (c *ResponseCache) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]cacheEntry{}
+}
+
+// cached returns the cached value for key, calling fetch when there is none,
+// it has expired, or refresh is set. Errors are never cached. Lookups for
+// another tailnet (see tailscale.WithTailnet) are cached separately.
+func cached[T any](ctx context.Context, c *ResponseCache, key string, refresh bool, fetch func(context.Context) (T, error)) (T, error) {
+	if c == nil || c.ttl <= 0 {
+		return fetch(ctx)
+	}
+	if tailnet := tailscale.TailnetFromContext(ctx); tailnet != "" {
+		key += "@" + tailnet
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && !refresh && time.Now().Before(entry.expires) {
+		if value, ok := entry.value.(T); ok {
+			return value, nil
+		}
+	}
+
+	value, err := fetch(ctx)
+	if err != nil {
+		return value, err
+	}
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{value: value, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return value, nil
+}
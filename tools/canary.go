@@ -0,0 +1,382 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const (
+	defaultProbeTimeout = 5 * time.Second
+	// policyPropagationDelay is how long to wait after applying a policy before
+	// probing, so the new packet filter has reached the canary devices
+	policyPropagationDelay = 5 * time.Second
+)
+
+// probeHostPattern limits probe targets to characters that are safe to place in a remote shell command
+var probeHostPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// canaryProbe is a single expected allow/deny check run from a canary device
+type canaryProbe struct {
+	Canary string `json:"canary"`
+	Target string `json:"target"`
+	Port   int    `json:"port"`
+	Type   string `json:"type"`
+	Expect string `json:"expect"`
+}
+
+// RegisterACLCanaryTools registers the canary-based ACL integration test tool.
+// Live probes run over SSH and are only available when sshEnabled is set.
+func RegisterACLCanaryTools(server *mcp.Server, cli *tailscale.CLI, api *tailscale.APIClient, sshEnabled bool) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_canary_test",
+			Description: "Test an ACL change with canary devices: validates the draft policy (with the expectations added as policy tests), then runs live TCP/ping probes from the canaries over SSH to confirm allow/deny behavior. Optionally applies the draft first and rolls back if any probe fails.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"acl": {
+						Type:        "string",
+						Description: "Draft ACL policy in JSON or HuJSON (default: the current policy)",
+					},
+					"probes": {
+						Type:        "array",
+						Description: "Expected behavior to check from canary devices",
+						Items: &jsonschema.Schema{
+							Type: "object",
+							Properties: map[string]*jsonschema.Schema{
+								"canary": {Type: "string", Description: "Canary device hostname to probe from"},
+								"target": {Type: "string", Description: "Host, IP, or tag to probe (e.g., db-1, 100.101.102.103)"},
+								"port":   {Type: "integer", Description: "TCP port (required for tcp probes)"},
+								"type":   {Type: "string", Description: "Probe type", Enum: []any{"tcp", "ping"}},
+								"expect": {Type: "string", Description: "Expected result", Enum: []any{"allow", "deny"}},
+							},
+							Required: []string{"canary", "target", "expect"},
+						},
+					},
+					"ssh_user": {
+						Type:        "string",
+						Description: "User to SSH to the canaries as (default: root)",
+					},
+					"apply": {
+						Type:        "boolean",
+						Description: "Apply the draft policy before probing (default: false, probes run against the current policy)",
+					},
+					"rollback_on_failure": {
+						Type:        "boolean",
+						Description: "Restore the previous policy if any probe fails after applying (default: true)",
+					},
+					"timeout_seconds": {
+						Type:        "integer",
+						Description: "Per-probe timeout in seconds (default: 5)",
+					},
+				},
+				Required: []string{"probes"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				ACL               string        `json:"acl"`
+				Probes            []canaryProbe `json:"probes"`
+				SSHUser           string        `json:"ssh_user"`
+				Apply             bool          `json:"apply"`
+				RollbackOnFailure *bool         `json:"rollback_on_failure"`
+				TimeoutSeconds    int           `json:"timeout_seconds"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			if len(params.Probes) == 0 {
+				return invalidInputResult("At least one probe is required"), nil
+			}
+			for i := range params.Probes {
+				if err := normalizeProbe(&params.Probes[i]); err != nil {
+					return errorResult(fmt.Sprintf("Invalid probe %d", i+1), err), nil
+				}
+			}
+
+			sshUser := params.SSHUser
+			if sshUser == "" {
+				sshUser = "root"
+			}
+			timeout := defaultProbeTimeout
+			if params.TimeoutSeconds > 0 {
+				timeout = time.Duration(params.TimeoutSeconds) * time.Second
+			}
+			rollback := params.RollbackOnFailure == nil || *params.RollbackOnFailure
+
+			current, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+
+			draftSource := current.RawPolicy
+			if params.ACL != "" {
+				draftSource = params.ACL
+			}
+			draft, err := tailscale.ParseACL(&tailscale.ACL{RawPolicy: draftSource, ETag: current.ETag})
+			if err != nil {
+				return errorResult("Error parsing draft ACL", err), nil
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString("ACL Canary Test\n\n")
+
+			// Step 1: static check against the validate endpoint, with each
+			// tcp expectation added as a policy test from the canary's identity
+			tests, skipped := canaryPolicyTests(params.Probes, devices)
+			withTests := draft.Clone()
+			if err := withTests.AddTests(tests); err != nil {
+				return errorResult("Error adding policy tests", err), nil
+			}
+
+			// Validation, the rollout and each probe are progress steps
+			progress := newProgress(req, len(params.Probes)+2)
+			result.WriteString(fmt.Sprintf("Step 1: Validating draft with %d policy test(s)...\n", len(tests)))
+			if err := api.ValidateACL(ctx, withTests.ACL()); err != nil {
+				result.WriteString(fmt.Sprintf("  ✗ Validation failed: %v\n", err))
+				result.WriteString("\nThe draft was not applied and no live probes were run.\n")
+				return reportErrorResult(result.String(), err), nil
+			}
+			result.WriteString("  ✓ Draft is valid and all policy tests pass\n")
+			progress.step(ctx, "validated draft")
+			for _, note := range skipped {
+				result.WriteString(fmt.Sprintf("  - %s\n", note))
+			}
+
+			if !sshEnabled {
+				result.WriteString("\nStep 2: Live probes skipped: SSH execution is disabled.\n")
+				result.WriteString("Set enable_ssh_exec: true in the config file (or TAILSCALE_MCP_ENABLE_SSH_EXEC=true) to run probes from canary devices.\n")
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: result.String()},
+					},
+				}, nil
+			}
+
+			// Step 2: optionally roll the draft out so the probes exercise it
+			applied := false
+			var appliedETag string
+			if params.Apply && params.ACL != "" {
+				appliedETag, err = api.SetACL(ctx, draft.ACL())
+				if err != nil {
+					result.WriteString(fmt.Sprintf("\n✗ Error applying draft: %v\n", err))
+					return reportErrorResult(result.String(), err), nil
+				}
+				applied = true
+				result.WriteString("\nStep 2: Applied draft policy, waiting for it to propagate...\n")
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(policyPropagationDelay):
+				}
+			} else {
+				result.WriteString("\nStep 2: Probing against the currently applied policy\n")
+			}
+			progress.step(ctx, "policy ready for probes")
+
+			// Step 3: live probes
+			result.WriteString("\nStep 3: Live probes\n")
+			failures := 0
+			for i, probe := range params.Probes {
+				if ctx.Err() != nil {
+					failures += len(params.Probes) - i
+					result.WriteString(fmt.Sprintf("  ✗ Canceled, %d probe(s) not run\n", len(params.Probes)-i))
+					break
+				}
+				outcome, err := runCanaryProbe(ctx, cli, sshUser, probe, timeout)
+				progress.step(ctx, "probed "+describeProbe(probe))
+				if err != nil {
+					failures++
+					result.WriteString(fmt.Sprintf("  ✗ %s: probe error: %v\n", describeProbe(probe), err))
+					continue
+				}
+				if outcome.allowed == (probe.Expect == "allow") {
+					result.WriteString(fmt.Sprintf("  ✓ %s: %s\n", describeProbe(probe), outcome.detail))
+				} else {
+					failures++
+					result.WriteString(fmt.Sprintf("  ✗ %s: %s (expected %s)\n", describeProbe(probe), outcome.detail, probe.Expect))
+				}
+			}
+
+			result.WriteString(fmt.Sprintf("\nResult: %d/%d probes matched expectations\n", len(params.Probes)-failures, len(params.Probes)))
+
+			if applied && failures > 0 {
+				if rollback {
+					// Conditional on the draft so edits made during the probes aren't
+					// clobbered, and done even if the call was canceled so the draft
+					// isn't left applied
+					if _, err := api.SetACL(context.WithoutCancel(ctx), &tailscale.ACL{RawPolicy: current.RawPolicy, ETag: appliedETag}); err != nil {
+						result.WriteString(fmt.Sprintf("✗ Rollback failed, the draft is still applied: %v\n", err))
+					} else {
+						result.WriteString("↩ Rolled back to the previous policy\n")
+					}
+				} else {
+					result.WriteString("⚠ The draft remains applied (rollback_on_failure=false)\n")
+				}
+			} else if applied {
+				result.WriteString("✓ The draft policy remains applied\n")
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: result.String()},
+				},
+			}, nil
+		}),
+	)
+}
+
+// normalizeProbe applies defaults and validates a probe
+func normalizeProbe(probe *canaryProbe) error {
+	if probe.Type == "" {
+		probe.Type = "tcp"
+	}
+	probe.Expect = strings.ToLower(probe.Expect)
+
+	if probe.Canary == "" || !probeHostPattern.MatchString(probe.Canary) {
+		return fmt.Errorf("canary must be a device hostname")
+	}
+	if probe.Target == "" || !probeHostPattern.MatchString(probe.Target) {
+		return fmt.Errorf("target must be a hostname, IP, or tag")
+	}
+	if probe.Expect != "allow" && probe.Expect != "deny" {
+		return fmt.Errorf("expect must be allow or deny")
+	}
+
+	switch probe.Type {
+	case "tcp":
+		if probe.Port <= 0 || probe.Port > 65535 {
+			return fmt.Errorf("tcp probes require a port between 1 and 65535")
+		}
+	case "ping":
+	default:
+		return fmt.Errorf("type must be tcp or ping")
+	}
+	return nil
+}
+
+// canaryPolicyTests converts tcp probes into policy tests using each canary's
+// identity (its first tag, or its owner for untagged devices)
+func canaryPolicyTests(probes []canaryProbe, devices []tailscale.Device) ([]tailscale.PolicyTest, []string) {
+	var tests []tailscale.PolicyTest
+	var skipped []string
+
+	for _, probe := range probes {
+		if probe.Type != "tcp" {
+			skipped = append(skipped, fmt.Sprintf("%s: only checked live (ping has no policy test)", describeProbe(probe)))
+			continue
+		}
+		if addr, err := netip.ParseAddr(probe.Target); err == nil && addr.Is6() {
+			skipped = append(skipped, fmt.Sprintf("%s: only checked live (IPv6 targets aren't supported in policy tests)", describeProbe(probe)))
+			continue
+		}
+
+		device := findDeviceByHost(devices, probe.Canary)
+		if device == nil {
+			skipped = append(skipped, fmt.Sprintf("%s: only checked live (canary not found in the device list)", describeProbe(probe)))
+			continue
+		}
+
+		src := device.User
+		if len(device.Tags) > 0 {
+			src = device.Tags[0]
+		}
+
+		dst := fmt.Sprintf("%s:%d", probe.Target, probe.Port)
+		test := tailscale.PolicyTest{Src: src}
+		if probe.Expect == "allow" {
+			test.Accept = []string{dst}
+		} else {
+			test.Deny = []string{dst}
+		}
+		tests = append(tests, test)
+	}
+
+	return tests, skipped
+}
+
+// findDeviceByHost matches a device by hostname, short MagicDNS name, or FQDN
+func findDeviceByHost(devices []tailscale.Device, host string) *tailscale.Device {
+	for i := range devices {
+		d := &devices[i]
+		short := strings.SplitN(d.Name, ".", 2)[0]
+		if strings.EqualFold(d.Hostname, host) || strings.EqualFold(short, host) || strings.EqualFold(strings.TrimSuffix(d.Name, "."), host) {
+			return d
+		}
+		for _, addr := range d.Addresses {
+			if addr == host {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+type probeOutcome struct {
+	allowed bool
+	detail  string
+}
+
+// runCanaryProbe runs one probe on the canary over SSH
+func runCanaryProbe(ctx context.Context, cli *tailscale.CLI, user string, probe canaryProbe, timeout time.Duration) (*probeOutcome, error) {
+	seconds := int(timeout / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	var command string
+	if probe.Type == "ping" {
+		command = fmt.Sprintf("ping -c 1 -W %d %s 2>&1", seconds, probe.Target)
+	} else {
+		// Prefer nc, falling back to bash's /dev/tcp where nc isn't installed
+		command = fmt.Sprintf(
+			"if command -v nc >/dev/null 2>&1; then nc -z -v -w %d %s %d 2>&1; else timeout %d bash -c '</dev/tcp/%s/%d' 2>&1; fi",
+			seconds, probe.Target, probe.Port, seconds, probe.Target, probe.Port)
+	}
+
+	// Allow time for the SSH connection on top of the probe itself
+	res, err := cli.SSHExec(ctx, user, probe.Canary, command, timeout+15*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	output := strings.ToLower(res.Stdout + " " + res.Stderr)
+	switch {
+	case res.ExitCode == 0:
+		return &probeOutcome{allowed: true, detail: "reachable"}, nil
+	case res.ExitCode == 127:
+		return nil, fmt.Errorf("probe command not available on %s: %s", probe.Canary, res.Stdout)
+	case strings.Contains(output, "refused"):
+		// A refusal means packets reached the host, so the policy allowed them
+		return &probeOutcome{allowed: true, detail: "reachable (connection refused, nothing listening)"}, nil
+	default:
+		return &probeOutcome{allowed: false, detail: "blocked (no response)"}, nil
+	}
+}
+
+func describeProbe(probe canaryProbe) string {
+	if probe.Type == "ping" {
+		return fmt.Sprintf("%s → %s (ping)", probe.Canary, probe.Target)
+	}
+	return fmt.Sprintf("%s → %s:%d", probe.Canary, probe.Target, probe.Port)
+}
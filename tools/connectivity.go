@@ -0,0 +1,437 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const (
+	defaultLatencyPings       = 3
+	defaultLatencyConcurrency = 8
+)
+
+// peerLatency is one row of the latency matrix
+type peerLatency struct {
+	Peer string                `json:"peer"`
+	IP   string                `json:"ip"`
+	Ping tailscale.PingSummary `json:"ping"`
+	// Error is set when the peer couldn't be pinged at all
+	Error string `json:"error,omitempty"`
+}
+
+// connectionPath is the structured result of connection_path
+type connectionPath struct {
+	Peer        string                `json:"peer"`
+	Online      bool                  `json:"online"`
+	Path        string                `json:"path"`
+	Endpoint    string                `json:"endpoint,omitempty"`
+	DERPRegion  string                `json:"derp_region,omitempty"`
+	Ping        tailscale.PingSummary `json:"ping"`
+	PeerAddrs   []string              `json:"peer_endpoints"`
+	LocalNAT    string                `json:"local_nat,omitempty"`
+	LocalUDP    *bool                 `json:"local_udp,omitempty"`
+	PortMapping *bool                 `json:"port_mapping,omitempty"`
+	Reasons     []string              `json:"reasons,omitempty"`
+}
+
+// cgnatRange is the shared address space carrier-grade NATs hand out,
+// which is no more reachable from outside than a private address
+var cgnatRange = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicEndpoints returns the endpoints that aren't private, loopback or
+// link-local addresses, i.e. ones reachable from outside the peer's LAN
+func publicEndpoints(addrs []string) []string {
+	var public []string
+	for _, addr := range addrs {
+		ap, err := netip.ParseAddrPort(addr)
+		if err != nil {
+			continue
+		}
+		ip := ap.Addr().Unmap()
+		if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || cgnatRange.Contains(ip) {
+			continue
+		}
+		public = append(public, addr)
+	}
+	return public
+}
+
+// explainRelay lists the likely reasons a peer is reached through DERP
+// rather than directly, from this node's netcheck and the peer's endpoints
+func explainRelay(peer *tailscale.PeerStatus, report *tailscale.NetcheckReport) []string {
+	var reasons []string
+	if report != nil {
+		if !report.UDP {
+			reasons = append(reasons, "UDP is blocked on this node's network (firewall or proxy), so only DERP over HTTPS works. Allow outbound UDP, ideally to port 41641 and 3478 (STUN).")
+		}
+		if report.HardNAT() {
+			reason := "This node is behind a hard NAT (the public port varies by destination, as with symmetric NATs and many CGNATs), so the peer can't predict which port to send to."
+			if report.PortMapping() {
+				reason += " A port mapping service (UPnP/NAT-PMP/PCP) is available, which usually works around this."
+			} else {
+				reason += " Enable UPnP, NAT-PMP or PCP on the router, or forward UDP port 41641 to this node."
+			}
+			reasons = append(reasons, reason)
+		}
+	}
+
+	public := publicEndpoints(peer.Addrs)
+	switch {
+	case len(peer.Addrs) == 0:
+		reasons = append(reasons, "The peer has reported no endpoints, so it likely can't send UDP at all (firewall, or a network that only allows TCP).")
+	case len(public) == 0:
+		reasons = append(reasons, "The peer only has private (LAN) endpoints: it couldn't discover a public address, which usually means UDP to STUN is blocked on its network, so it's only directly reachable from the same LAN.")
+	}
+	if report != nil && report.HardNAT() && len(public) > 0 {
+		reasons = append(reasons, "If the peer is also behind a hard NAT, a direct path is impossible without port mapping on one side; run connection_path on the peer to check.")
+	}
+
+	if len(reasons) == 0 {
+		reasons = append(reasons, "Both sides look reachable. A stateful firewall dropping unsolicited UDP on either side is the most common remaining cause; the path may also still be being negotiated, so try again in a few seconds.")
+	}
+	return reasons
+}
+
+// selectLatencyPeers picks the online peers to ping: the given devices, or
+// every online peer, optionally narrowed to a tag and OS. Devices that can't
+// be pinged are returned as notes.
+func selectLatencyPeers(status *tailscale.Status, devices []string, tag, osName string) ([]*tailscale.PeerStatus, []string) {
+	var peers []*tailscale.PeerStatus
+	var notes []string
+	if tag != "" {
+		tag = tailscale.NormalizeTag(tag)
+	}
+	usable := func(peer *tailscale.PeerStatus) bool {
+		switch {
+		case !peer.Online:
+			notes = append(notes, fmt.Sprintf("%s: skipped (offline)", peerHost(peer)))
+		case len(peer.TailscaleIPs) == 0:
+			notes = append(notes, fmt.Sprintf("%s: skipped (no Tailscale IP)", peerHost(peer)))
+		default:
+			return true
+		}
+		return false
+	}
+
+	if len(devices) > 0 {
+		for _, d := range devices {
+			peer, err := resolveDevice(status, d, false)
+			if err != nil {
+				if _, ok := err.(*ambiguousDeviceError); ok {
+					notes = append(notes, fmt.Sprintf("%s: skipped (matches several peers, use a full name or IP)", d))
+				} else {
+					notes = append(notes, fmt.Sprintf("%s: skipped (not a peer)", d))
+				}
+				continue
+			}
+			if usable(peer) {
+				peers = append(peers, peer)
+			}
+		}
+	} else {
+		for _, peer := range status.Peer {
+			if peer == nil || (tag != "" && !containsString(peer.Tags, tag)) || (osName != "" && !strings.EqualFold(peer.OS, osName)) {
+				continue
+			}
+			if usable(peer) {
+				peers = append(peers, peer)
+			}
+		}
+	}
+
+	sort.Slice(peers, func(i, j int) bool { return peerHost(peers[i]) < peerHost(peers[j]) })
+	sort.Strings(notes)
+	return peers, notes
+}
+
+// measureLatency pings peers concurrently, at most concurrency at a time,
+// reporting each peer pinged as a progress step
+func measureLatency(ctx context.Context, cli *tailscale.CLI, peers []*tailscale.PeerStatus, count, concurrency int, progress *progressReporter) []peerLatency {
+	results := make([]peerLatency, len(peers))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(i int, peer *tailscale.PeerStatus) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			row := peerLatency{Peer: peerHost(peer), IP: peer.TailscaleIPs[0]}
+			if err := ctx.Err(); err != nil {
+				row.Error = err.Error()
+				row.Ping = tailscale.SummarizePings(nil)
+				results[i] = row
+				return
+			}
+			replies, err := cli.PingResults(ctx, row.IP, count)
+			row.Ping = tailscale.SummarizePings(replies)
+			if err != nil {
+				row.Error = err.Error()
+			}
+			results[i] = row
+			progress.step(ctx, "pinged "+row.Peer)
+		}(i, peer)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// RegisterConnectivityTools registers tools that analyze how this node
+// reaches its peers
+func RegisterConnectivityTools(server *mcp.Server, cli *tailscale.CLI) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "connection_path",
+			Description: "Explain how this node reaches a peer: pings it to find whether the path is direct (and via which endpoint) or relayed through DERP, and if relayed, why (UDP blocked, hard NAT, no public endpoints), using netcheck and status data",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"device": {
+						Type:        "string",
+						Description: "Peer hostname, MagicDNS name, Tailscale IP, or a unique part of a name",
+					},
+					"count": {
+						Type:        "integer",
+						Description: "Pings to send while a direct path is set up (default: 5)",
+					},
+				},
+				Required: []string{"device"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Device string `json:"device"`
+				Count  int    `json:"count"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if params.Count <= 0 {
+				params.Count = 5
+			}
+
+			status, err := cli.Status(ctx)
+			if err != nil {
+				return errorResult("Error getting status", err), nil
+			}
+			peer, err := resolveDevice(status, params.Device, false)
+			if err != nil {
+				return errorResult("", err), nil
+			}
+
+			path := connectionPath{
+				Peer:      peerHost(peer),
+				Online:    peer.Online,
+				PeerAddrs: peer.Addrs,
+			}
+			if path.PeerAddrs == nil {
+				path.PeerAddrs = []string{}
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("=== Connection Path to %s ===\n\n", path.Peer))
+			if !peer.Online {
+				path.Path = "none"
+				result.WriteString("✗ The peer is offline, so there is no path to it.\n")
+				return structuredResult(result.String(), path), nil
+			}
+
+			target := params.Device
+			if len(peer.TailscaleIPs) > 0 {
+				target = peer.TailscaleIPs[0]
+			}
+			replies, pingErr := cli.PingResults(ctx, target, params.Count)
+			path.Ping = tailscale.SummarizePings(replies)
+			path.Path = path.Ping.Path
+			path.Endpoint = path.Ping.Endpoint
+			path.DERPRegion = path.Ping.DERPRegionCode
+			if path.Path == "none" && peer.CurAddr != "" {
+				// No pong, but the status still shows the last direct path
+				path.Endpoint = peer.CurAddr
+			}
+			if path.DERPRegion == "" && path.Path != "direct" {
+				path.DERPRegion = peer.Relay
+			}
+
+			switch path.Path {
+			case "direct":
+				result.WriteString(fmt.Sprintf("✓ Direct connection via %s\n", path.Endpoint))
+				if path.Ping.BecameDirect {
+					result.WriteString("  The first pings were relayed through DERP while the direct path was set up.\n")
+				}
+			case "derp":
+				result.WriteString(fmt.Sprintf("⚠ Relayed through DERP region %s\n", path.DERPRegion))
+			default:
+				result.WriteString("✗ No ping replies")
+				if pingErr != nil {
+					result.WriteString(fmt.Sprintf(": %v", pingErr))
+				}
+				result.WriteString("\n")
+			}
+			if path.Ping.Received > 0 {
+				result.WriteString(fmt.Sprintf("  Latency min/avg/max: %.1f/%.1f/%.1f ms (%d/%d replies)\n", path.Ping.MinLatencyMs, path.Ping.AvgLatencyMs, path.Ping.MaxLatencyMs, path.Ping.Received, path.Ping.Sent))
+			}
+
+			result.WriteString("\nPeer endpoints:\n")
+			if len(peer.Addrs) == 0 {
+				result.WriteString("  (none)\n")
+			}
+			for _, addr := range peer.Addrs {
+				result.WriteString("  " + addr + "\n")
+			}
+			if peer.Relay != "" {
+				result.WriteString(fmt.Sprintf("  Home DERP region: %s\n", peer.Relay))
+			}
+
+			report, netcheckErr := cli.Netcheck(ctx)
+			result.WriteString("\nThis node's network:\n")
+			if netcheckErr != nil {
+				result.WriteString(fmt.Sprintf("  ⚠ netcheck failed: %v\n", netcheckErr))
+				report = nil
+			} else {
+				udp := report.UDP
+				mapping := report.PortMapping()
+				path.LocalUDP = &udp
+				path.PortMapping = &mapping
+				switch {
+				case report.MappingVariesByDestIP == nil:
+					path.LocalNAT = "unknown"
+				case report.HardNAT():
+					path.LocalNAT = "hard"
+				default:
+					path.LocalNAT = "easy"
+				}
+				result.WriteString(fmt.Sprintf("  %s UDP\n", checkMark(report.UDP)))
+				result.WriteString(fmt.Sprintf("  NAT: %s", path.LocalNAT))
+				if report.GlobalV4 != "" {
+					result.WriteString(fmt.Sprintf(" (public address %s)", report.GlobalV4))
+				}
+				result.WriteString("\n")
+				result.WriteString(fmt.Sprintf("  %s Port mapping (UPnP/NAT-PMP/PCP)\n", checkMark(mapping)))
+			}
+
+			if path.Path != "direct" {
+				path.Reasons = explainRelay(peer, report)
+				result.WriteString("\nWhy it isn't direct:\n")
+				for _, reason := range path.Reasons {
+					result.WriteString("  - " + reason + "\n")
+				}
+			}
+
+			return structuredResult(result.String(), path), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "latency_matrix",
+			Description: "Ping every online peer (or the given devices, or those with a tag or OS) concurrently and return a table of reachability, path (direct or DERP) and latency per peer, to spot broken or relayed mesh links at a glance",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"devices": {
+						Type:        "array",
+						Description: "Peers to ping by hostname, MagicDNS name, Tailscale IP or a unique part of a name (default: all online peers)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"tag": {
+						Type:        "string",
+						Description: "Only ping peers carrying this tag (e.g., tag:server)",
+					},
+					"os": {
+						Type:        "string",
+						Description: "Only ping peers running this OS (e.g., linux, windows, macOS)",
+					},
+					"count": {
+						Type:        "integer",
+						Description: fmt.Sprintf("Pings per peer (default: %d)", defaultLatencyPings),
+					},
+					"concurrency": {
+						Type:        "integer",
+						Description: fmt.Sprintf("Maximum number of peers pinged at once (default: %d)", defaultLatencyConcurrency),
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Devices     []string `json:"devices"`
+				Tag         string   `json:"tag"`
+				OS          string   `json:"os"`
+				Count       int      `json:"count"`
+				Concurrency int      `json:"concurrency"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Count <= 0 {
+				params.Count = defaultLatencyPings
+			}
+			if params.Concurrency <= 0 {
+				params.Concurrency = defaultLatencyConcurrency
+			}
+
+			status, err := cli.Status(ctx)
+			if err != nil {
+				return errorResult("Error getting status", err), nil
+			}
+
+			peers, notes := selectLatencyPeers(status, params.Devices, params.Tag, params.OS)
+			rows := measureLatency(ctx, cli, peers, params.Count, params.Concurrency, newProgress(req, len(peers)))
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("=== Latency Matrix (%d peers, %d pings each) ===\n\n", len(rows), params.Count))
+			counts := map[string]int{}
+			if len(rows) > 0 {
+				result.WriteString(fmt.Sprintf("  %-30s %-16s %-8s %-24s %s\n", "PEER", "IP", "REPLIES", "PATH", "MIN/AVG/MAX MS"))
+			}
+			for _, row := range rows {
+				mark, path := "✓", "direct "+row.Ping.Endpoint
+				switch row.Ping.Path {
+				case "derp":
+					mark, path = "⚠", "DERP("+row.Ping.DERPRegionCode+")"
+				case "none":
+					mark, path = "✗", "unreachable"
+				}
+				counts[row.Ping.Path]++
+				latency := "-"
+				if row.Ping.Received > 0 {
+					latency = fmt.Sprintf("%.1f/%.1f/%.1f", row.Ping.MinLatencyMs, row.Ping.AvgLatencyMs, row.Ping.MaxLatencyMs)
+				}
+				result.WriteString(fmt.Sprintf("%s %-30s %-16s %-8s %-24s %s\n", mark, row.Peer, row.IP, fmt.Sprintf("%d/%d", row.Ping.Received, row.Ping.Sent), path, latency))
+			}
+
+			result.WriteString(fmt.Sprintf("\nDirect: %d, relayed: %d, unreachable: %d\n", counts["direct"], counts["derp"], counts["none"]))
+			if counts["derp"] > 0 || counts["none"] > 0 {
+				result.WriteString("Run connection_path on relayed or unreachable peers to see why.\n")
+			}
+			if len(notes) > 0 {
+				result.WriteString("\nSkipped:\n")
+				for _, note := range notes {
+					result.WriteString(fmt.Sprintf("  - %s\n", note))
+				}
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{
+				"peers":   rows,
+				"skipped": notes,
+			}), nil
+		}),
+	)
+}
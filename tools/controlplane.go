@@ -0,0 +1,268 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const (
+	defaultControlURL  = "https://controlplane.tailscale.com"
+	controlDialTimeout = 10 * time.Second
+	// Skew above clockSkewWarn is worth fixing; above clockSkewFail, TLS and
+	// key exchange with the control server start failing
+	clockSkewWarn = 30 * time.Second
+	clockSkewFail = 5 * time.Minute
+	// certExpiryWarn flags control server certificates close to expiry
+	certExpiryWarn = 14 * 24 * time.Hour
+)
+
+// controlPlaneReport is the structured result of control_plane_check
+type controlPlaneReport struct {
+	ControlURL string              `json:"control_url"`
+	Source     string              `json:"source"`
+	Checks     []controlPlaneCheck `json:"checks"`
+	Fixes      []string            `json:"fixes"`
+	// ClockSkewSeconds is positive when the local clock is ahead
+	ClockSkewSeconds *float64   `json:"clock_skew_seconds,omitempty"`
+	TLSVersion       string     `json:"tls_version,omitempty"`
+	CertIssuer       string     `json:"cert_issuer,omitempty"`
+	CertExpires      *time.Time `json:"cert_expires,omitempty"`
+}
+
+// controlPlaneCheck is one check of control_plane_check: dns, tls or clock,
+// with a status of pass, warn or fail
+type controlPlaneCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// RegisterControlPlaneTools registers control server connectivity checks
+func RegisterControlPlaneTools(server *mcp.Server, cli *tailscale.CLI) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "control_plane_check",
+			Description: "Check connectivity to the control server (the default Tailscale coordination server or a custom login server): DNS, TLS certificate validation, and local clock skew, a frequent cause of authentication failures. Reports fixes for any problems found.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"control_url": {
+						Type:        "string",
+						Description: "Control server URL to check (default: the one this node is configured with)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				ControlURL string `json:"control_url"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			controlURL := params.ControlURL
+			source := "from parameter"
+			if controlURL == "" {
+				controlURL, source = configuredControlURL(ctx, cli)
+			}
+
+			u, err := url.Parse(controlURL)
+			if err != nil || u.Host == "" {
+				return invalidInputResult(fmt.Sprintf("Invalid control URL %q", controlURL)), nil
+			}
+			host := u.Hostname()
+			port := u.Port()
+			if port == "" {
+				port = "443"
+			}
+
+			var result strings.Builder
+			fixes := []string{}
+			out := controlPlaneReport{ControlURL: controlURL, Source: source}
+			check := func(name, status, detail string) {
+				out.Checks = append(out.Checks, controlPlaneCheck{Name: name, Status: status, Detail: detail})
+			}
+			result.WriteString("=== Control Plane Check ===\n\n")
+			result.WriteString(fmt.Sprintf("Control server: %s (%s)\n\n", controlURL, source))
+
+			// DNS
+			addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+			if err != nil {
+				result.WriteString(fmt.Sprintf("✗ DNS: could not resolve %s: %v\n", host, err))
+				check("dns", "fail", fmt.Sprintf("could not resolve %s: %v", host, err))
+				fixes = append(fixes, "Check the system DNS resolver; if MagicDNS or a custom resolver is involved, confirm it can resolve public names")
+			} else {
+				result.WriteString(fmt.Sprintf("✓ DNS: %s resolves to %s\n", host, strings.Join(addrs, ", ")))
+				check("dns", "pass", fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", ")))
+			}
+
+			// TLS with full certificate verification
+			dialer := &tls.Dialer{
+				NetDialer: &net.Dialer{Timeout: controlDialTimeout},
+				Config:    &tls.Config{ServerName: host},
+			}
+			conn, tlsErr := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+			if tlsErr != nil {
+				result.WriteString(fmt.Sprintf("✗ TLS: %v\n", tlsErr))
+				check("tls", "fail", tlsErr.Error())
+				fixes = append(fixes, tlsFixes(tlsErr)...)
+			} else {
+				state := conn.(*tls.Conn).ConnectionState()
+				conn.Close()
+				result.WriteString(fmt.Sprintf("✓ TLS: %s, certificate verified\n", tls.VersionName(state.Version)))
+				out.TLSVersion = tls.VersionName(state.Version)
+				status, detail := "pass", "certificate verified"
+				if len(state.PeerCertificates) > 0 {
+					cert := state.PeerCertificates[0]
+					result.WriteString(fmt.Sprintf("  Issuer: %s\n", cert.Issuer.CommonName))
+					result.WriteString(fmt.Sprintf("  Expires: %s\n", cert.NotAfter.Format(time.RFC3339)))
+					out.CertIssuer = cert.Issuer.CommonName
+					out.CertExpires = &cert.NotAfter
+					if time.Until(cert.NotAfter) < certExpiryWarn {
+						result.WriteString("  ⚠ Certificate expires within 14 days\n")
+						status, detail = "warn", "certificate expires within 14 days"
+						if controlURL != defaultControlURL {
+							fixes = append(fixes, "Renew the control server's TLS certificate before it expires")
+						}
+					}
+				}
+				check("tls", status, detail)
+			}
+
+			// Clock skew, measured against the server's Date header. Verification
+			// is skipped here so skew can still be measured when TLS fails because of it.
+			skew, err := measureClockSkew(ctx, controlURL)
+			if err != nil {
+				result.WriteString(fmt.Sprintf("⚠ Clock: could not measure skew: %v\n", err))
+				check("clock", "warn", fmt.Sprintf("could not measure skew: %v", err))
+			} else {
+				seconds := skew.Seconds()
+				out.ClockSkewSeconds = &seconds
+				abs := skew
+				if abs < 0 {
+					abs = -abs
+				}
+				direction := "ahead of"
+				if skew < 0 {
+					direction = "behind"
+				}
+				switch {
+				case abs >= clockSkewFail:
+					result.WriteString(fmt.Sprintf("✗ Clock: local time is %s %s the control server\n", abs.Round(time.Second), direction))
+					check("clock", "fail", fmt.Sprintf("local time is %s %s the control server", abs.Round(time.Second), direction))
+					fixes = append(fixes, "Fix the system clock: enable NTP (e.g., `timedatectl set-ntp true` on Linux, or Settings → Date & Time on macOS/Windows), then run `tailscale up` again")
+				case abs >= clockSkewWarn:
+					result.WriteString(fmt.Sprintf("⚠ Clock: local time is %s %s the control server\n", abs.Round(time.Second), direction))
+					check("clock", "warn", fmt.Sprintf("local time is %s %s the control server", abs.Round(time.Second), direction))
+					fixes = append(fixes, "Enable time synchronization (NTP) to avoid intermittent authentication failures")
+				default:
+					result.WriteString(fmt.Sprintf("✓ Clock: within %s of the control server\n", clockSkewWarn))
+					check("clock", "pass", fmt.Sprintf("within %s of the control server", clockSkewWarn))
+				}
+			}
+
+			if len(fixes) > 0 {
+				result.WriteString("\nSuggested fixes:\n")
+				for _, fix := range fixes {
+					result.WriteString(fmt.Sprintf("• %s\n", fix))
+				}
+			} else {
+				result.WriteString("\n✓ Control server is reachable and the local clock is in sync\n")
+			}
+			out.Fixes = fixes
+
+			return structuredResult(result.String(), out), nil
+		}),
+	)
+}
+
+// configuredControlURL returns the control URL from the local preferences,
+// falling back to the configured login server and then the default
+// coordination server
+func configuredControlURL(ctx context.Context, cli *tailscale.CLI) (string, string) {
+	if prefs, err := cli.Prefs(ctx); err == nil && prefs.ControlURL != "" {
+		return prefs.ControlURL, "from local preferences"
+	}
+	if loginServer := cli.LoginServer(); loginServer != "" {
+		return loginServer, "from login_server"
+	}
+	return defaultControlURL, "Tailscale default"
+}
+
+// tlsFixes suggests fixes for a TLS handshake failure based on its cause
+func tlsFixes(err error) []string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return []string{
+			"The certificate isn't signed by a trusted CA. A TLS-intercepting proxy or firewall is likely; install its CA certificate or exempt the control server from inspection",
+			"On minimal systems, install the ca-certificates package",
+		}
+	case errors.As(err, &hostnameErr):
+		return []string{"The certificate doesn't match the host name. Check for DNS hijacking or a captive portal, and that the control URL is correct"}
+	case errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired:
+		return []string{"The certificate appears expired or not yet valid. This is usually caused by a wrong system clock; see the clock check"}
+	default:
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return []string{"The connection timed out. Check that outbound TCP 443 is allowed and no proxy is required"}
+		}
+		return []string{"Check that outbound HTTPS to the control server is allowed by firewalls and proxies"}
+	}
+}
+
+// measureClockSkew returns how far the local clock is ahead of the server's (negative if behind)
+func measureClockSkew(ctx context.Context, controlURL string) (time.Duration, error) {
+	client := &http.Client{
+		Timeout: controlDialTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", controlURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	received := time.Now()
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("no usable Date header in response")
+	}
+
+	// Compare against the midpoint of the request; Date has one-second resolution
+	local := sent.Add(received.Sub(sent) / 2)
+	return local.Sub(serverTime).Round(time.Second), nil
+}
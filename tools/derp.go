@@ -0,0 +1,284 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/store"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const (
+	derpBaselineBucket = "derp_baseline"
+	// defaultDERPThresholdMs is how much slower than before (or than the
+	// best region) a region must be to be flagged
+	defaultDERPThresholdMs = 20
+)
+
+// derpBaseline is the previous derp_report measurement regressions are
+// compared against
+type derpBaseline struct {
+	MeasuredAt time.Time `json:"measured_at"`
+	Preferred  int       `json:"preferred"`
+	// LatencyMs is the latency by region ID; unreachable regions are absent
+	LatencyMs map[int]float64 `json:"latency_ms"`
+}
+
+// derpRegionLatency is one region's row in the DERP report
+type derpRegionLatency struct {
+	ID        int     `json:"id"`
+	Code      string  `json:"code"`
+	Name      string  `json:"name,omitempty"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	Reachable bool    `json:"reachable"`
+	Preferred bool    `json:"preferred,omitempty"`
+	// PreviousMs is the latency in the baseline, if the region was reachable then
+	PreviousMs float64 `json:"previous_ms,omitempty"`
+}
+
+// derpReport is the structured result of derp_report
+type derpReport struct {
+	Preferred     string              `json:"preferred"`
+	Best          string              `json:"best,omitempty"`
+	Regions       []derpRegionLatency `json:"regions"`
+	Warnings      []string            `json:"warnings,omitempty"`
+	Regressions   []string            `json:"regressions,omitempty"`
+	BaselineAt    *time.Time          `json:"baseline_at,omitempty"`
+	BaselineSaved bool                `json:"baseline_saved"`
+}
+
+// buildDERPRegions joins netcheck's latencies with the region names in the
+// DERP map (if available), fastest first and unreachable regions last
+func buildDERPRegions(report *tailscale.NetcheckReport, derpMap *tailscale.DERPMap) []derpRegionLatency {
+	ids := map[int]bool{}
+	for id := range report.RegionLatency {
+		ids[id] = true
+	}
+	if derpMap != nil {
+		for id, region := range derpMap.Regions {
+			if region != nil && !region.Avoid {
+				ids[id] = true
+			}
+		}
+	}
+
+	var regions []derpRegionLatency
+	for id := range ids {
+		row := derpRegionLatency{ID: id, Code: fmt.Sprintf("%d", id), Preferred: id == report.PreferredDERP}
+		if derpMap != nil && derpMap.Regions[id] != nil {
+			row.Code = derpMap.Regions[id].RegionCode
+			row.Name = derpMap.Regions[id].RegionName
+		}
+		if latency, ok := report.RegionLatency[id]; ok {
+			row.Reachable = true
+			row.LatencyMs = float64(latency) / float64(time.Millisecond)
+		}
+		regions = append(regions, row)
+	}
+
+	sort.Slice(regions, func(i, j int) bool {
+		a, b := regions[i], regions[j]
+		if a.Reachable != b.Reachable {
+			return a.Reachable
+		}
+		if a.LatencyMs != b.LatencyMs {
+			return a.LatencyMs < b.LatencyMs
+		}
+		return a.ID < b.ID
+	})
+	return regions
+}
+
+// compareDERPBaseline flags regions that got slower by more than threshold
+// (and by at least half) or became unreachable since the baseline, and a
+// change of preferred region
+func compareDERPBaseline(regions []derpRegionLatency, preferred int, baseline derpBaseline, thresholdMs float64) []string {
+	var regressions []string
+	codes := map[int]string{}
+	for i := range regions {
+		row := &regions[i]
+		codes[row.ID] = row.Code
+		previous, ok := baseline.LatencyMs[row.ID]
+		if !ok {
+			continue
+		}
+		row.PreviousMs = previous
+		switch {
+		case !row.Reachable:
+			regressions = append(regressions, fmt.Sprintf("%s became unreachable (was %.1f ms)", row.Code, previous))
+		case row.LatencyMs-previous > thresholdMs && row.LatencyMs > previous*1.5:
+			regressions = append(regressions, fmt.Sprintf("%s latency rose from %.1f ms to %.1f ms", row.Code, previous, row.LatencyMs))
+		}
+	}
+	if baseline.Preferred != 0 && baseline.Preferred != preferred {
+		from := codes[baseline.Preferred]
+		if from == "" {
+			from = fmt.Sprintf("%d", baseline.Preferred)
+		}
+		regressions = append(regressions, fmt.Sprintf("preferred region changed from %s to %s", from, codes[preferred]))
+	}
+	return regressions
+}
+
+// RegisterDERPTools registers the DERP relay health report
+func RegisterDERPTools(server *mcp.Server, cli *tailscale.CLI, st *store.Store) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "derp_report",
+			Description: "Measure latency from this node to every DERP relay region (netcheck), compare it against the preferred region and the previous run, and flag regressions: a preferred region much slower than the best one, regions that got slower or unreachable, or a changed preferred region. Each run becomes the baseline for the next, so it works well on a schedule.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"threshold_ms": {
+						Type:        "number",
+						Description: fmt.Sprintf("Latency increase that counts as a regression (default: %d)", defaultDERPThresholdMs),
+					},
+					"update_baseline": {
+						Type:        "boolean",
+						Description: "Save this run as the baseline for the next comparison (default: true)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				ThresholdMs    float64 `json:"threshold_ms"`
+				UpdateBaseline *bool   `json:"update_baseline"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.ThresholdMs <= 0 {
+				params.ThresholdMs = defaultDERPThresholdMs
+			}
+
+			report, err := cli.Netcheck(ctx)
+			if err != nil {
+				return errorResult("Error running netcheck", err), nil
+			}
+			// Without the map regions are shown by ID only
+			derpMap, mapErr := cli.DERPMap(ctx)
+			if mapErr != nil {
+				derpMap = nil
+			}
+
+			regions := buildDERPRegions(report, derpMap)
+			out := derpReport{Regions: regions}
+			if out.Regions == nil {
+				out.Regions = []derpRegionLatency{}
+			}
+
+			var baseline derpBaseline
+			if err := st.Load(derpBaselineBucket, &baseline); err != nil {
+				out.Warnings = append(out.Warnings, fmt.Sprintf("couldn't load the previous run: %v", err))
+			}
+			if !baseline.MeasuredAt.IsZero() {
+				at := baseline.MeasuredAt
+				out.BaselineAt = &at
+				out.Regressions = compareDERPBaseline(regions, report.PreferredDERP, baseline, params.ThresholdMs)
+			}
+
+			var preferred, best *derpRegionLatency
+			for i := range regions {
+				if regions[i].Preferred {
+					preferred = &regions[i]
+				}
+				if best == nil && regions[i].Reachable {
+					best = &regions[i]
+				}
+			}
+			if best != nil {
+				out.Best = best.Code
+			}
+			switch {
+			case preferred == nil:
+				out.Preferred = "none"
+				out.Warnings = append(out.Warnings, "no preferred DERP region: this node can't reach any DERP relay, so relayed connections will fail")
+			case !preferred.Reachable:
+				out.Preferred = preferred.Code
+				out.Warnings = append(out.Warnings, fmt.Sprintf("the preferred region %s didn't answer this netcheck", preferred.Code))
+			default:
+				out.Preferred = preferred.Code
+				if best != nil && preferred.LatencyMs-best.LatencyMs > params.ThresholdMs {
+					out.Warnings = append(out.Warnings, fmt.Sprintf("the preferred region %s (%.1f ms) is %.1f ms slower than %s (%.1f ms); tailscaled only switches when a region is clearly better, so this may be transient", preferred.Code, preferred.LatencyMs, preferred.LatencyMs-best.LatencyMs, best.Code, best.LatencyMs))
+				}
+			}
+			var unreachable []string
+			for _, row := range regions {
+				if !row.Reachable {
+					unreachable = append(unreachable, row.Code)
+				}
+			}
+			if len(unreachable) > 0 && len(unreachable) < len(regions) {
+				out.Warnings = append(out.Warnings, fmt.Sprintf("%d region(s) didn't answer: %s", len(unreachable), strings.Join(unreachable, ", ")))
+			}
+
+			if params.UpdateBaseline == nil || *params.UpdateBaseline {
+				next := derpBaseline{MeasuredAt: time.Now().UTC(), Preferred: report.PreferredDERP, LatencyMs: map[int]float64{}}
+				for _, row := range regions {
+					if row.Reachable {
+						next.LatencyMs[row.ID] = row.LatencyMs
+					}
+				}
+				if err := st.Save(derpBaselineBucket, next); err != nil {
+					out.Warnings = append(out.Warnings, fmt.Sprintf("couldn't save this run as the baseline: %v", err))
+				} else {
+					out.BaselineSaved = true
+				}
+			}
+
+			var result strings.Builder
+			result.WriteString("=== DERP Region Health ===\n\n")
+			result.WriteString(fmt.Sprintf("Preferred region: %s\n", out.Preferred))
+			if out.BaselineAt != nil {
+				result.WriteString(fmt.Sprintf("Compared with the run at %s\n", out.BaselineAt.Local().Format("2006-01-02 15:04:05")))
+			}
+			result.WriteString(fmt.Sprintf("\n  %-8s %-24s %10s %10s\n", "REGION", "NAME", "LATENCY", "PREVIOUS"))
+			for _, row := range regions {
+				latency, previous := "-", "-"
+				if row.Reachable {
+					latency = fmt.Sprintf("%.1f ms", row.LatencyMs)
+				}
+				if row.PreviousMs > 0 {
+					previous = fmt.Sprintf("%.1f ms", row.PreviousMs)
+				}
+				marker := " "
+				if row.Preferred {
+					marker = "*"
+				}
+				result.WriteString(fmt.Sprintf("%s %-8s %-24s %10s %10s\n", marker, row.Code, row.Name, latency, previous))
+			}
+			result.WriteString("(* preferred)\n")
+			if mapErr != nil {
+				result.WriteString(fmt.Sprintf("⚠ Region names unavailable: %v\n", mapErr))
+			}
+
+			if len(out.Regressions) > 0 {
+				result.WriteString(fmt.Sprintf("\n✗ Regressions (%d):\n", len(out.Regressions)))
+				for _, r := range out.Regressions {
+					result.WriteString(fmt.Sprintf("  - %s\n", r))
+				}
+			}
+			if len(out.Warnings) > 0 {
+				result.WriteString(fmt.Sprintf("\n⚠ Warnings (%d):\n", len(out.Warnings)))
+				for _, w := range out.Warnings {
+					result.WriteString(fmt.Sprintf("  - %s\n", w))
+				}
+			}
+			if len(out.Regressions) == 0 && len(out.Warnings) == 0 {
+				result.WriteString("\n✓ DERP connectivity looks healthy\n")
+			}
+
+			return structuredResult(result.String(), out), nil
+		}),
+	)
+}
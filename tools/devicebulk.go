@@ -0,0 +1,359 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const defaultBulkConcurrency = 8
+
+// deviceSelector picks devices by ID or by attributes. All the attributes
+// that are set must match.
+type deviceSelector struct {
+	IDs []string `json:"device_ids"`
+	// Name is a glob matched against the hostname and the short MagicDNS name
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+	User string `json:"user"`
+	OS   string `json:"os"`
+}
+
+// selectorProperties are the input schema properties of a deviceSelector
+func selectorProperties(verb string) map[string]*jsonschema.Schema {
+	return map[string]*jsonschema.Schema{
+		"device_ids": {
+			Type:        "array",
+			Description: fmt.Sprintf("IDs of the devices to %s", verb),
+			Items:       &jsonschema.Schema{Type: "string"},
+		},
+		"name": {
+			Type:        "string",
+			Description: "Select devices whose name matches this glob, e.g. web-* (* selects all)",
+		},
+		"tag": {
+			Type:        "string",
+			Description: "Select devices carrying this tag (e.g., tag:server)",
+		},
+		"user": {
+			Type:        "string",
+			Description: "Select devices owned by this login name",
+		},
+		"os": {
+			Type:        "string",
+			Description: "Select devices running this OS (e.g., linux, windows, macOS)",
+		},
+	}
+}
+
+func (s deviceSelector) validate() error {
+	if len(s.IDs) == 0 && s.Name == "" && s.Tag == "" && s.User == "" && s.OS == "" {
+		return fmt.Errorf("give device_ids or at least one of name, tag, user or os")
+	}
+	if _, err := path.Match(s.Name, ""); err != nil {
+		return fmt.Errorf("invalid name pattern %q", s.Name)
+	}
+	return nil
+}
+
+func (s deviceSelector) matches(d tailscale.Device) bool {
+	if len(s.IDs) > 0 && !containsString(s.IDs, d.ID) && !containsString(s.IDs, d.NodeID) {
+		return false
+	}
+	if s.Name != "" {
+		short := strings.SplitN(d.Name, ".", 2)[0]
+		hostMatch, _ := path.Match(strings.ToLower(s.Name), strings.ToLower(d.Hostname))
+		shortMatch, _ := path.Match(strings.ToLower(s.Name), strings.ToLower(short))
+		if !hostMatch && !shortMatch {
+			return false
+		}
+	}
+	if s.Tag != "" && !containsString(d.Tags, tailscale.NormalizeTag(s.Tag)) {
+		return false
+	}
+	if s.User != "" && !strings.EqualFold(d.User, s.User) {
+		return false
+	}
+	if s.OS != "" && !strings.EqualFold(d.OS, s.OS) {
+		return false
+	}
+	return true
+}
+
+// selectDevices returns the devices matching the selector and the requested
+// IDs that don't belong to any device
+func selectDevices(devices []tailscale.Device, s deviceSelector) ([]tailscale.Device, []string) {
+	var selected []tailscale.Device
+	for _, d := range devices {
+		if s.matches(d) {
+			selected = append(selected, d)
+		}
+	}
+	var missing []string
+	for _, id := range s.IDs {
+		if !containsDeviceID(devices, id) {
+			missing = append(missing, id)
+		}
+	}
+	return selected, missing
+}
+
+func containsDeviceID(devices []tailscale.Device, id string) bool {
+	for _, d := range devices {
+		if d.ID == id || d.NodeID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// bulkResult is the outcome of a bulk operation on one device
+type bulkResult struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Error    string `json:"error,omitempty"`
+}
+
+// runBulk applies op to the devices concurrently, at most concurrency at a
+// time, reporting each finished device as a progress step, and returns the
+// results in the order of devices
+func runBulk(ctx context.Context, devices []tailscale.Device, concurrency int, progress *progressReporter, op func(context.Context, tailscale.Device) error) []bulkResult {
+	results := make([]bulkResult, len(devices))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, device := range devices {
+		wg.Add(1)
+		go func(i int, device tailscale.Device) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = bulkResult{DeviceID: device.ID, Name: device.Hostname}
+			if err := ctx.Err(); err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			if err := op(ctx, device); err != nil {
+				results[i].Error = err.Error()
+				progress.step(ctx, fmt.Sprintf("✗ %s: %v", device.Hostname, err))
+				return
+			}
+			progress.step(ctx, "✓ "+device.Hostname)
+		}(i, device)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// writeBulkResults reports each device's outcome and returns the number of failures
+func writeBulkResults(result *strings.Builder, results []bulkResult) int {
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			result.WriteString(fmt.Sprintf("✗ %s (%s): %s\n", r.Name, r.DeviceID, r.Error))
+			failed++
+		} else {
+			result.WriteString(fmt.Sprintf("✓ %s (%s)\n", r.Name, r.DeviceID))
+		}
+	}
+	return failed
+}
+
+// RegisterBulkDeviceTools registers tools that act on many devices at once
+func RegisterBulkDeviceTools(server *mcp.Server, api *tailscale.APIClient) {
+	authorizeProperties := selectorProperties("authorize")
+	authorizeProperties["concurrency"] = &jsonschema.Schema{
+		Type:        "integer",
+		Description: fmt.Sprintf("Maximum number of concurrent API requests (default: %d)", defaultBulkConcurrency),
+	}
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "authorize_devices",
+			Description: "Authorize many pending devices at once, given by ID or selected by name pattern, tag, user or OS. Only devices awaiting authorization are touched; reports success or failure per device.",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type:       "object",
+				Properties: authorizeProperties,
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				deviceSelector
+				Concurrency int `json:"concurrency"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if err := params.validate(); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if params.Concurrency <= 0 {
+				params.Concurrency = defaultBulkConcurrency
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+			selected, missing := selectDevices(devices, params.deviceSelector)
+
+			var pending []tailscale.Device
+			var already []string
+			for _, d := range selected {
+				if d.Authorized {
+					already = append(already, d.Hostname)
+				} else {
+					pending = append(pending, d)
+				}
+			}
+
+			results := runBulk(ctx, pending, params.Concurrency, newProgress(req, len(pending)), func(ctx context.Context, d tailscale.Device) error {
+				return api.AuthorizeDevice(ctx, d.ID)
+			})
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Authorizing %d device(s)\n\n", len(pending)))
+			if len(pending) == 0 {
+				result.WriteString("No matching devices are waiting for authorization.\n")
+			}
+			failed := writeBulkResults(&result, results)
+			if len(already) > 0 {
+				result.WriteString(fmt.Sprintf("\nAlready authorized: %s\n", strings.Join(already, ", ")))
+			}
+			if len(missing) > 0 {
+				result.WriteString(fmt.Sprintf("\n⚠ No device with ID: %s\n", strings.Join(missing, ", ")))
+			}
+			result.WriteString(fmt.Sprintf("\n%d authorized, %d failed\n", len(results)-failed, failed))
+
+			return structuredResult(result.String(), map[string]interface{}{
+				"results":            results,
+				"already_authorized": already,
+				"not_found":          missing,
+			}), nil
+		}),
+	)
+
+	tagProperties := selectorProperties("tag")
+	tagProperties["tags"] = &jsonschema.Schema{
+		Type:        "array",
+		Description: "Tags to give the selected devices, replacing their current tags (empty removes all tags)",
+		Items:       &jsonschema.Schema{Type: "string"},
+	}
+	tagProperties["concurrency"] = authorizeProperties["concurrency"]
+	tagProperties["dry_run"] = &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "Only list the affected devices without changing their tags (default: true)",
+	}
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "set_tags_bulk",
+			Description: "Set the same tags on many devices, given by ID or selected by name pattern, current tag, user or OS. Lists the affected devices with their tag changes by default; set dry_run=false to apply.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type:       "object",
+				Properties: tagProperties,
+				Required:   []string{"tags"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				deviceSelector
+				Tags        []string `json:"tags"`
+				Concurrency int      `json:"concurrency"`
+				DryRun      *bool    `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			err := params.validate()
+			if err == nil && params.Tags == nil {
+				err = fmt.Errorf("tags is required; pass [] to remove all tags")
+			}
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if params.Concurrency <= 0 {
+				params.Concurrency = defaultBulkConcurrency
+			}
+			tags := make([]string, 0, len(params.Tags))
+			for _, tag := range params.Tags {
+				if tag = tailscale.NormalizeTag(tag); tag != "" && !containsString(tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+			sort.Strings(tags)
+
+			if refused := checkTagOwners(ctx, api, tags); refused != nil {
+				return refused, nil
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+			selected, missing := selectDevices(devices, params.deviceSelector)
+
+			var changes []tailscale.Device
+			unchanged := 0
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Setting tags [%s] on %d device(s)\n\n", strings.Join(tags, ", "), len(selected)))
+			for _, d := range selected {
+				current := slices.Sorted(slices.Values(d.Tags))
+				if slices.Equal(current, tags) {
+					unchanged++
+					continue
+				}
+				changes = append(changes, d)
+				result.WriteString(fmt.Sprintf("%s (%s): [%s] → [%s]\n", d.Hostname, d.ID, strings.Join(current, ", "), strings.Join(tags, ", ")))
+			}
+			if len(selected) == 0 {
+				result.WriteString("No devices match the selection.\n")
+			}
+			if unchanged > 0 {
+				result.WriteString(fmt.Sprintf("%d device(s) already have these tags\n", unchanged))
+			}
+			if len(missing) > 0 {
+				result.WriteString(fmt.Sprintf("⚠ No device with ID: %s\n", strings.Join(missing, ", ")))
+			}
+
+			if params.DryRun == nil || *params.DryRun {
+				if len(changes) > 0 {
+					result.WriteString("\nDry run: no tags were changed. Set dry_run=false to apply.\n")
+				}
+				return structuredResult(result.String(), map[string]interface{}{"devices": changes, "not_found": missing}), nil
+			}
+
+			results := runBulk(ctx, changes, params.Concurrency, newProgress(req, len(changes)), func(ctx context.Context, d tailscale.Device) error {
+				return api.SetDeviceTags(ctx, d.ID, tags)
+			})
+			result.WriteString("\n")
+			failed := writeBulkResults(&result, results)
+			result.WriteString(fmt.Sprintf("\n%d retagged, %d failed\n", len(results)-failed, failed))
+
+			return structuredResult(result.String(), map[string]interface{}{"results": results, "not_found": missing}), nil
+		}),
+	)
+}
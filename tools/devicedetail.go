@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// deviceDetail is the structured result of get_device: the local status
+// view of the device merged with the fields only the API returns. Source is
+// status, api, or status+api.
+type deviceDetail struct {
+	deviceInfo
+	Source            string     `json:"source"`
+	DeviceID          string     `json:"device_id,omitempty"`
+	NodeID            string     `json:"node_id,omitempty"`
+	Authorized        *bool      `json:"authorized,omitempty"`
+	Created           *time.Time `json:"created,omitempty"`
+	KeyExpiry         *time.Time `json:"key_expiry,omitempty"`
+	KeyExpiryDisabled bool       `json:"key_expiry_disabled,omitempty"`
+	MachineKey        string     `json:"machine_key,omitempty"`
+	NodeKey           string     `json:"node_key,omitempty"`
+	ClientVersion     string     `json:"client_version,omitempty"`
+	UpdateAvailable   bool       `json:"update_available,omitempty"`
+}
+
+// findAPIDevice finds the API device for a status node (by node ID or a
+// shared address) or, for a device not in the status, by ID, node ID, name
+// or address
+func findAPIDevice(devices []tailscale.Device, node *tailscale.PeerStatus, query string) *tailscale.Device {
+	if node != nil {
+		for i, d := range devices {
+			if node.ID != "" && d.NodeID == node.ID {
+				return &devices[i]
+			}
+		}
+		for i, d := range devices {
+			for _, ip := range node.TailscaleIPs {
+				if containsString(d.Addresses, ip) {
+					return &devices[i]
+				}
+			}
+		}
+		return nil
+	}
+
+	q := strings.TrimSuffix(strings.ToLower(query), ".")
+	for i, d := range devices {
+		name := strings.ToLower(d.Name)
+		if d.ID == query || d.NodeID == query || name == q || strings.SplitN(name, ".", 2)[0] == q ||
+			strings.ToLower(d.Hostname) == q || containsString(d.Addresses, query) {
+			return &devices[i]
+		}
+	}
+	return nil
+}
+
+// findStatusNodeForDevice finds the status node for an API device that was
+// looked up by ID
+func findStatusNodeForDevice(status *tailscale.Status, device *tailscale.Device) *tailscale.PeerStatus {
+	for _, node := range statusNodes(status) {
+		if device.NodeID != "" && node.ID == device.NodeID {
+			return node
+		}
+		for _, ip := range node.TailscaleIPs {
+			if containsString(device.Addresses, ip) {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+// newDeviceDetail merges a status node and an API device; either may be nil
+// but not both. API tags win, since they are what the control server
+// enforces.
+func newDeviceDetail(node *tailscale.PeerStatus, self bool, device *tailscale.Device) deviceDetail {
+	var detail deviceDetail
+	switch {
+	case node != nil && device != nil:
+		detail.Source = "status+api"
+	case node != nil:
+		detail.Source = "status"
+	default:
+		detail.Source = "api"
+	}
+	if node != nil {
+		detail.deviceInfo = newDeviceInfo(node, self)
+		detail.Online = detail.Online || self
+	}
+	if device == nil {
+		return detail
+	}
+
+	if node == nil {
+		detail.Name = device.Hostname
+		detail.DNSName = device.Name
+		detail.OS = device.OS
+		detail.Online = device.Online
+		detail.IPs = device.Addresses
+		if !device.LastSeen.IsZero() {
+			lastSeen := device.LastSeen
+			detail.LastSeen = &lastSeen
+		}
+	}
+	detail.Tags = device.Tags
+	detail.User = device.User
+	detail.DeviceID = device.ID
+	detail.NodeID = device.NodeID
+	authorized := device.Authorized
+	detail.Authorized = &authorized
+	if !device.Created.IsZero() {
+		created := device.Created
+		detail.Created = &created
+	}
+	if !device.KeyExpiry.IsZero() {
+		expiry := device.KeyExpiry
+		detail.KeyExpiry = &expiry
+	}
+	detail.KeyExpiryDisabled = device.KeyExpiryDisabled
+	detail.MachineKey = device.MachineKey
+	detail.NodeKey = device.NodeKey
+	detail.ClientVersion = device.ClientVersion
+	detail.UpdateAvailable = device.UpdateAvailable
+	return detail
+}
+
+// writeDeviceDetail renders get_device's text output. Traffic counters are
+// only shown for peers, since they count traffic with this node.
+func writeDeviceDetail(result *strings.Builder, d deviceDetail, node *tailscale.PeerStatus) {
+	if d.Self {
+		result.WriteString(fmt.Sprintf("Device Details: %s (Your Device)\n\n", d.Name))
+	} else {
+		result.WriteString(fmt.Sprintf("Device Details: %s\n\n", d.Name))
+	}
+	result.WriteString(fmt.Sprintf("Hostname: %s\n", d.Name))
+	result.WriteString(fmt.Sprintf("DNS Name: %s\n", d.DNSName))
+	result.WriteString(fmt.Sprintf("OS: %s\n", d.OS))
+	if d.User != "" {
+		result.WriteString(fmt.Sprintf("User: %s\n", d.User))
+	}
+	result.WriteString(fmt.Sprintf("Online: %v\n", d.Online))
+	if node != nil {
+		result.WriteString(fmt.Sprintf("Active: %v\n", d.Active))
+	}
+	if len(d.IPs) > 0 {
+		result.WriteString(fmt.Sprintf("Tailscale IPs: %s\n", strings.Join(d.IPs, ", ")))
+	}
+	if len(d.AllowedIPs) > 0 {
+		result.WriteString(fmt.Sprintf("Allowed IPs: %s\n", strings.Join(d.AllowedIPs, ", ")))
+	}
+	if d.ExitNode {
+		result.WriteString("Role: Exit Node\n")
+	}
+	if d.ExitNodeOption {
+		result.WriteString("Available as Exit Node: Yes\n")
+	}
+	if len(d.Tags) > 0 {
+		result.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(d.Tags, ", ")))
+	}
+	if d.PublicKey != "" {
+		result.WriteString(fmt.Sprintf("Public Key: %s\n", d.PublicKey))
+	}
+	if d.LastSeen != nil {
+		result.WriteString(fmt.Sprintf("Last Seen: %s\n", d.LastSeen.Format("2006-01-02 15:04:05")))
+	}
+	if node != nil && !d.Self {
+		result.WriteString(fmt.Sprintf("RX Bytes: %d\n", d.RxBytes))
+		result.WriteString(fmt.Sprintf("TX Bytes: %d\n", d.TxBytes))
+	}
+
+	if d.Authorized == nil {
+		return
+	}
+	result.WriteString("\nFrom the API:\n")
+	result.WriteString(fmt.Sprintf("Device ID: %s\n", d.DeviceID))
+	if d.NodeID != "" {
+		result.WriteString(fmt.Sprintf("Node ID: %s\n", d.NodeID))
+	}
+	result.WriteString(fmt.Sprintf("Authorized: %v\n", *d.Authorized))
+	if d.Created != nil {
+		result.WriteString(fmt.Sprintf("Created: %s\n", d.Created.Format("2006-01-02 15:04:05")))
+	}
+	switch {
+	case d.KeyExpiryDisabled:
+		result.WriteString("Key Expiry: disabled\n")
+	case d.KeyExpiry != nil:
+		result.WriteString(fmt.Sprintf("Key Expiry: %s\n", d.KeyExpiry.Format("2006-01-02 15:04:05")))
+	}
+	if d.MachineKey != "" {
+		result.WriteString(fmt.Sprintf("Machine Key: %s\n", d.MachineKey))
+	}
+	if d.NodeKey != "" && d.NodeKey != d.PublicKey {
+		result.WriteString(fmt.Sprintf("Node Key: %s\n", d.NodeKey))
+	}
+	if d.ClientVersion != "" {
+		version := d.ClientVersion
+		if d.UpdateAvailable {
+			version += " (update available)"
+		}
+		result.WriteString(fmt.Sprintf("Client Version: %s\n", version))
+	}
+	if node == nil {
+		result.WriteString("\n⚠ This device isn't in this node's status; the ACL may not let this node see it\n")
+	}
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const defaultDeviceListLimit = 50
+
+// deviceListFields are the fields list_devices can return, named as in the
+// structured output
+var deviceListFields = []string{"name", "dns_name", "os", "user", "online", "active", "ips", "allowed_ips", "tags", "exit_node", "exit_node_option", "self", "public_key", "last_seen", "rx_bytes", "tx_bytes"}
+
+// deviceListSorts are the orders list_devices accepts
+var deviceListSorts = []string{"name", "last_seen", "os", "online"}
+
+// deviceFilter selects devices from the local status. Empty fields match
+// every device; OS, user and name compare case-insensitively, and name
+// matches any part of the hostname or MagicDNS name.
+type deviceFilter struct {
+	Online *bool  `json:"online"`
+	OS     string `json:"os"`
+	Tag    string `json:"tag"`
+	User   string `json:"user"`
+	Name   string `json:"name"`
+}
+
+// matches reports whether a node passes the filter; user is its login name
+func (f deviceFilter) matches(node *tailscale.PeerStatus, self bool, user string) bool {
+	online := node.Online || self
+	if f.Online != nil && online != *f.Online {
+		return false
+	}
+	if f.OS != "" && !strings.EqualFold(node.OS, f.OS) {
+		return false
+	}
+	if f.Tag != "" && !containsString(node.Tags, tailscale.NormalizeTag(f.Tag)) {
+		return false
+	}
+	if f.User != "" && !strings.EqualFold(user, f.User) && !strings.EqualFold(strings.SplitN(user, "@", 2)[0], f.User) {
+		return false
+	}
+	if f.Name != "" {
+		name := strings.ToLower(f.Name)
+		if !strings.Contains(strings.ToLower(node.HostName), name) && !strings.Contains(strings.ToLower(node.DNSName), name) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortDeviceInfos orders devices by name, most recently seen (online ones
+// first), OS or online state, falling back to name
+func sortDeviceInfos(devices []deviceInfo, by string) {
+	sort.SliceStable(devices, func(i, j int) bool {
+		a, b := devices[i], devices[j]
+		switch by {
+		case "last_seen":
+			if a.Online != b.Online {
+				return a.Online
+			}
+			if !a.Online && (a.LastSeen == nil) != (b.LastSeen == nil) {
+				return a.LastSeen != nil
+			}
+			if !a.Online && a.LastSeen != nil && !a.LastSeen.Equal(*b.LastSeen) {
+				return a.LastSeen.After(*b.LastSeen)
+			}
+		case "os":
+			if !strings.EqualFold(a.OS, b.OS) {
+				return strings.ToLower(a.OS) < strings.ToLower(b.OS)
+			}
+		case "online":
+			if a.Online != b.Online {
+				return a.Online
+			}
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+}
+
+// selectDeviceFields keeps only the requested fields of each device. The
+// name is always kept so every entry can be told apart; unset fields (false,
+// empty) are left out as in the full output.
+func selectDeviceFields(devices []deviceInfo, fields []string) ([]map[string]interface{}, error) {
+	selected := make([]map[string]interface{}, 0, len(devices))
+	for _, d := range devices {
+		data, err := json.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+		// Numbers stay json.Number so byte counts don't print as floats
+		var all map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&all); err != nil {
+			return nil, err
+		}
+		entry := map[string]interface{}{"name": d.Name}
+		for _, field := range fields {
+			if v, ok := all[field]; ok {
+				entry[field] = v
+			}
+		}
+		selected = append(selected, entry)
+	}
+	return selected, nil
+}
+
+// formatDeviceFields renders a device with selected fields as one line
+func formatDeviceFields(entry map[string]interface{}, fields []string) string {
+	line := fmt.Sprintf("• %v", entry["name"])
+	for _, field := range fields {
+		if field == "name" {
+			continue
+		}
+		v, ok := entry[field]
+		if !ok {
+			continue
+		}
+		if list, ok := v.([]interface{}); ok {
+			parts := make([]string, len(list))
+			for i, item := range list {
+				parts[i] = fmt.Sprint(item)
+			}
+			v = strings.Join(parts, ",")
+		}
+		line += fmt.Sprintf(" %s=%v", field, v)
+	}
+	return line + "\n"
+}
+
+// formatDeviceLine renders a device as a single line, like formatPeerLine
+// with the owner and the local node marked
+func formatDeviceLine(d deviceInfo) string {
+	state := "offline"
+	if d.Online {
+		state = "online"
+	}
+	ip := ""
+	if len(d.IPs) > 0 {
+		ip = d.IPs[0]
+	}
+
+	line := fmt.Sprintf("• %s (%s) %s, %s", d.Name, ip, d.OS, state)
+	if d.Self {
+		line += ", this device"
+	}
+	if d.User != "" && len(d.Tags) == 0 {
+		line += ", " + d.User
+	}
+	if d.ExitNodeOption {
+		line += ", exit node"
+	}
+	if len(d.Tags) > 0 {
+		line += fmt.Sprintf(", tags: %s", strings.Join(d.Tags, ", "))
+	}
+	return line + "\n"
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -12,69 +14,163 @@ import (
 )
 
 // RegisterDeviceTools registers device operation tools
-func RegisterDeviceTools(server *mcp.Server, cli *tailscale.CLI) {
+func RegisterDeviceTools(server *mcp.Server, cli *tailscale.CLI, cache *ResponseCache) {
+	registerDeviceTools(server, cli, nil, cache)
+}
+
+// registerDeviceTools registers the device operation tools that work from
+// the CLI alone. When api is set, get_device adds the API's device fields.
+func registerDeviceTools(server *mcp.Server, cli *tailscale.CLI, api *tailscale.APIClient, cache *ResponseCache) {
 	// List devices tool
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "list_devices",
-			Description: "List all devices in the Tailscale network",
-			InputSchema: &jsonschema.Schema{Type: "object"},
+			Description: "List devices in the Tailscale network, one line each, filtered by online state, OS, tag, user or part of the name. Results are paginated (limit/offset) and fields can be limited, so large tailnets stay readable.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"refresh": {
+						Type:        "boolean",
+						Description: "Bypass the response cache and fetch fresh data",
+					},
+					"online": {
+						Type:        "boolean",
+						Description: "Only online (true) or offline (false) devices",
+					},
+					"os": {
+						Type:        "string",
+						Description: "Only devices running this OS (e.g., linux, windows, macOS, iOS, android)",
+					},
+					"tag": {
+						Type:        "string",
+						Description: "Only devices carrying this tag (e.g., tag:server)",
+					},
+					"user": {
+						Type:        "string",
+						Description: "Only devices owned by this user (login name, or the part before @)",
+					},
+					"name": {
+						Type:        "string",
+						Description: "Only devices whose hostname or MagicDNS name contains this text",
+					},
+					"sort": {
+						Type:        "string",
+						Description: "Sort order (default: name); last_seen lists online devices first, then the most recently seen",
+						Enum:        []interface{}{"name", "last_seen", "os", "online"},
+					},
+					"fields": {
+						Type:        "array",
+						Description: fmt.Sprintf("Only return these fields (name is always included): %s", strings.Join(deviceListFields, ", ")),
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"limit": {
+						Type:        "integer",
+						Description: fmt.Sprintf("Maximum devices to return (default: %d)", defaultDeviceListLimit),
+					},
+					"offset": {
+						Type:        "integer",
+						Description: "Skip this many matching devices, to fetch the next page (default: 0)",
+					},
+				},
+			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			status, err := cli.Status()
+			var params struct {
+				deviceFilter
+				Refresh bool     `json:"refresh"`
+				Sort    string   `json:"sort"`
+				Fields  []string `json:"fields"`
+				Limit   int      `json:"limit"`
+				Offset  int      `json:"offset"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Sort == "" {
+				params.Sort = "name"
+			}
+			if !containsString(deviceListSorts, params.Sort) {
+				return invalidInputResult(fmt.Sprintf("Invalid parameters: sort must be one of %s", strings.Join(deviceListSorts, ", "))), nil
+			}
+			for _, field := range params.Fields {
+				if !containsString(deviceListFields, field) {
+					return invalidInputResult(fmt.Sprintf("Invalid parameters: unknown field %q (valid: %s)", field, strings.Join(deviceListFields, ", "))), nil
+				}
+			}
+			if params.Limit <= 0 {
+				params.Limit = defaultDeviceListLimit
+			}
+			if params.Offset < 0 {
+				params.Offset = 0
+			}
+
+			status, err := cached(ctx, cache, statusCacheKey, params.Refresh, cli.Status)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting device list: %v", err)},
-					},
-				}, nil
+				return errorResult("Error getting device list", err), nil
 			}
 
-			var result strings.Builder
-			result.WriteString("Tailscale Network Devices:\n\n")
-
-			// Show self device first
-			if status.Self != nil {
-				result.WriteString("Your Device:\n")
-				result.WriteString(fmt.Sprintf("  Name: %s\n", status.Self.HostName))
-				result.WriteString(fmt.Sprintf("  OS: %s\n", status.Self.OS))
-				result.WriteString(fmt.Sprintf("  Online: %v\n", status.Self.Online))
-				if len(status.Self.TailscaleIPs) > 0 {
-					result.WriteString(fmt.Sprintf("  IPs: %s\n", strings.Join(status.Self.TailscaleIPs, ", ")))
+			logins := statusLogins(status)
+			var devices []deviceInfo
+			for _, node := range statusNodes(status) {
+				self := node == status.Self
+				user := logins[strings.Trim(string(node.UserID), `"`)]
+				if !params.matches(node, self, user) {
+					continue
 				}
-				if status.Self.ExitNode {
-					result.WriteString("  Role: Exit Node\n")
+				info := newDeviceInfo(node, self)
+				info.Online = info.Online || self
+				info.User = user
+				devices = append(devices, info)
+			}
+			sortDeviceInfos(devices, params.Sort)
+
+			total := len(devices)
+			page := []deviceInfo{}
+			if params.Offset < total {
+				end := params.Offset + params.Limit
+				if end > total {
+					end = total
 				}
-				result.WriteString("\n")
+				page = devices[params.Offset:end]
+			}
+			data := map[string]interface{}{"total": total, "offset": params.Offset, "count": len(page)}
+			if next := params.Offset + len(page); next < total {
+				data["next_offset"] = next
 			}
 
-			// Show peer devices
-			if len(status.Peer) > 0 {
-				result.WriteString("Other Devices:\n")
-				for _, peer := range status.Peer {
-					result.WriteString(fmt.Sprintf("  Name: %s\n", peer.HostName))
-					result.WriteString(fmt.Sprintf("  OS: %s\n", peer.OS))
-					result.WriteString(fmt.Sprintf("  Online: %v\n", peer.Online))
-					if len(peer.TailscaleIPs) > 0 {
-						result.WriteString(fmt.Sprintf("  IPs: %s\n", strings.Join(peer.TailscaleIPs, ", ")))
-					}
-					if peer.ExitNode {
-						result.WriteString("  Role: Exit Node\n")
-					}
-					if peer.ExitNodeOption {
-						result.WriteString("  Available as Exit Node\n")
-					}
-					result.WriteString("\n")
+			var result strings.Builder
+			switch {
+			case total == 0:
+				result.WriteString("No matching devices found\n")
+			case len(page) == 0:
+				result.WriteString(fmt.Sprintf("No devices at offset %d (%d matching)\n", params.Offset, total))
+			default:
+				result.WriteString(fmt.Sprintf("Tailscale Network Devices: %d-%d of %d\n\n", params.Offset+1, params.Offset+len(page), total))
+			}
+
+			if len(params.Fields) > 0 {
+				selected, err := selectDeviceFields(page, params.Fields)
+				if err != nil {
+					return errorResult("Error selecting fields", err), nil
+				}
+				for _, entry := range selected {
+					result.WriteString(formatDeviceFields(entry, params.Fields))
 				}
+				data["devices"] = selected
 			} else {
-				result.WriteString("No other devices found in network\n")
+				for _, d := range page {
+					result.WriteString(formatDeviceLine(d))
+				}
+				data["devices"] = page
+			}
+			if next, ok := data["next_offset"]; ok {
+				result.WriteString(fmt.Sprintf("\nMore devices match; call again with offset=%d\n", next))
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result.String()},
-				},
-			}, nil
+			return structuredResult(result.String(), data), nil
 		}),
 	)
 
@@ -82,11 +178,12 @@ func RegisterDeviceTools(server *mcp.Server, cli *tailscale.CLI) {
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "get_device",
-			Description: "Get detailed information about a specific device",
+			Description: "Get detailed information about a specific device by hostname, MagicDNS name, Tailscale IP or device ID. With API access, adds the fields only the API knows (authorization, creation time, key expiry, machine key, client version, server-side tags), including for devices this node can't see.",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
-					"device": {Type: "string", Description: "Device name or hostname to get information for"},
+					"device": {Type: "string", Description: "Device hostname, MagicDNS name, Tailscale IP, API device ID / node ID, or a unique part of a name"},
 				},
 				Required: []string{"device"},
 			},
@@ -96,104 +193,49 @@ func RegisterDeviceTools(server *mcp.Server, cli *tailscale.CLI) {
 				Device string `json:"device"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
-			status, err := cli.Status()
+			status, err := cli.Status(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting device information: %v", err)},
-					},
-				}, nil
+				return errorResult("Error getting device information", err), nil
+			}
+			node, err := resolveDevice(status, params.Device, true)
+			if _, ok := err.(*ambiguousDeviceError); ok {
+				return errorResult("", err), nil
 			}
 
-			var targetPeer *tailscale.PeerStatus
-			deviceName := strings.ToLower(params.Device)
-
-			// Check if it's the self device
-			if status.Self != nil && strings.ToLower(status.Self.HostName) == deviceName {
-				var result strings.Builder
-				result.WriteString(fmt.Sprintf("Device Details: %s (Your Device)\n\n", status.Self.HostName))
-				result.WriteString(fmt.Sprintf("Hostname: %s\n", status.Self.HostName))
-				result.WriteString(fmt.Sprintf("DNS Name: %s\n", status.Self.DNSName))
-				result.WriteString(fmt.Sprintf("OS: %s\n", status.Self.OS))
-				result.WriteString(fmt.Sprintf("Online: %v\n", status.Self.Online))
-				result.WriteString(fmt.Sprintf("Active: %v\n", status.Self.Active))
-				if len(status.Self.TailscaleIPs) > 0 {
-					result.WriteString(fmt.Sprintf("Tailscale IPs: %s\n", strings.Join(status.Self.TailscaleIPs, ", ")))
-				}
-				if len(status.Self.AllowedIPs) > 0 {
-					result.WriteString(fmt.Sprintf("Allowed IPs: %s\n", strings.Join(status.Self.AllowedIPs, ", ")))
-				}
-				if status.Self.ExitNode {
-					result.WriteString("Role: Exit Node\n")
+			// The API also knows devices this node can't see, so a miss in
+			// the status isn't final when it is configured
+			var device *tailscale.Device
+			var apiErr error
+			if api != nil && api.IsAvailable() {
+				var devices []tailscale.Device
+				devices, apiErr = api.ListDevices(ctx)
+				if apiErr == nil {
+					device = findAPIDevice(devices, node, params.Device)
 				}
-				if status.Self.ExitNodeOption {
-					result.WriteString("Available as Exit Node: Yes\n")
+				if node == nil && device != nil {
+					node = findStatusNodeForDevice(status, device)
 				}
-				result.WriteString(fmt.Sprintf("Public Key: %s\n", status.Self.PublicKey))
-				result.WriteString(fmt.Sprintf("Last Seen: %s\n", status.Self.LastSeen.Format("2006-01-02 15:04:05")))
-
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: result.String()},
-					},
-				}, nil
 			}
 
-			// Look for the device in peers
-			for _, peer := range status.Peer {
-				if strings.ToLower(peer.HostName) == deviceName || strings.ToLower(peer.DNSName) == deviceName {
-					targetPeer = peer
-					break
+			if node == nil && device == nil {
+				text := fmt.Sprintf("Device '%s' not found in network", params.Device)
+				if apiErr != nil {
+					text += fmt.Sprintf(" (API lookup failed: %v)", apiErr)
 				}
+				return notFoundResult(text), nil
 			}
 
-			if targetPeer == nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Device '%s' not found in network", params.Device)},
-					},
-				}, nil
+			detail := newDeviceDetail(node, node != nil && node == status.Self, device)
+			var result strings.Builder
+			writeDeviceDetail(&result, detail, node)
+			if apiErr != nil {
+				result.WriteString(fmt.Sprintf("\n⚠ API details unavailable: %v\n", apiErr))
 			}
 
-			var result strings.Builder
-			result.WriteString(fmt.Sprintf("Device Details: %s\n\n", targetPeer.HostName))
-			result.WriteString(fmt.Sprintf("Hostname: %s\n", targetPeer.HostName))
-			result.WriteString(fmt.Sprintf("DNS Name: %s\n", targetPeer.DNSName))
-			result.WriteString(fmt.Sprintf("OS: %s\n", targetPeer.OS))
-			result.WriteString(fmt.Sprintf("Online: %v\n", targetPeer.Online))
-			result.WriteString(fmt.Sprintf("Active: %v\n", targetPeer.Active))
-			if len(targetPeer.TailscaleIPs) > 0 {
-				result.WriteString(fmt.Sprintf("Tailscale IPs: %s\n", strings.Join(targetPeer.TailscaleIPs, ", ")))
-			}
-			if len(targetPeer.AllowedIPs) > 0 {
-				result.WriteString(fmt.Sprintf("Allowed IPs: %s\n", strings.Join(targetPeer.AllowedIPs, ", ")))
-			}
-			if targetPeer.ExitNode {
-				result.WriteString("Role: Exit Node\n")
-			}
-			if targetPeer.ExitNodeOption {
-				result.WriteString("Available as Exit Node: Yes\n")
-			}
-			if len(targetPeer.Tags) > 0 {
-				result.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(targetPeer.Tags, ", ")))
-			}
-			result.WriteString(fmt.Sprintf("Public Key: %s\n", targetPeer.PublicKey))
-			result.WriteString(fmt.Sprintf("Last Seen: %s\n", targetPeer.LastSeen.Format("2006-01-02 15:04:05")))
-			result.WriteString(fmt.Sprintf("RX Bytes: %d\n", targetPeer.RxBytes))
-			result.WriteString(fmt.Sprintf("TX Bytes: %d\n", targetPeer.TxBytes))
-
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result.String()},
-				},
-			}, nil
+			return structuredResult(result.String(), detail), nil
 		}),
 	)
 
@@ -202,10 +244,11 @@ func RegisterDeviceTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "ping_device",
 			Description: "Ping a specific device in the Tailscale network",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
-					"device": {Type: "string", Description: "Device name, hostname, or IP address to ping"},
+					"device": {Type: "string", Description: "Device name, hostname, IP address, or a unique part of a name to ping"},
 					"count":  {Type: "integer", Description: "Number of pings to send (default: 4)"},
 				},
 				Required: []string{"device"},
@@ -217,11 +260,7 @@ func RegisterDeviceTools(server *mcp.Server, cli *tailscale.CLI) {
 				Count  int    `json:"count"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			// Default count to 4 if not specified
@@ -229,34 +268,113 @@ func RegisterDeviceTools(server *mcp.Server, cli *tailscale.CLI) {
 				params.Count = 4
 			}
 
-			result, err := cli.Ping(params.Device, params.Count)
+			// Anything that isn't a known peer, like a subnet IP, is passed
+			// to tailscale ping as given
+			target, name := params.Device, params.Device
+			if status, err := cli.Status(ctx); err == nil {
+				peer, err := resolveDevice(status, params.Device, false)
+				if _, ok := err.(*ambiguousDeviceError); ok {
+					return errorResult("", err), nil
+				}
+				if peer != nil && len(peer.TailscaleIPs) > 0 {
+					target, name = peer.TailscaleIPs[0], peerHost(peer)
+				}
+			}
+
+			replies, err := cli.PingResults(ctx, target, params.Count)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to ping %s: %v", params.Device, err)},
-					},
-				}, nil
+				return errorResult(fmt.Sprintf("Failed to ping %s", params.Device), err), nil
 			}
+			summary := tailscale.SummarizePings(replies)
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Ping results for %s:\n\n%s", params.Device, result)},
-				},
-			}, nil
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Ping results for %s:\n\n", name))
+			for _, reply := range replies {
+				result.WriteString(reply.String() + "\n")
+			}
+			result.WriteString(fmt.Sprintf("\n%d/%d replies", summary.Received, summary.Sent))
+			if summary.Received > 0 {
+				result.WriteString(fmt.Sprintf(", latency min/avg/max %.1f/%.1f/%.1f ms", summary.MinLatencyMs, summary.AvgLatencyMs, summary.MaxLatencyMs))
+			}
+			result.WriteString("\n")
+			switch summary.Path {
+			case "direct":
+				result.WriteString(fmt.Sprintf("Path: direct via %s\n", summary.Endpoint))
+			case "derp":
+				result.WriteString(fmt.Sprintf("Path: relayed through DERP (%s); run connection_path to see why\n", summary.DERPRegionCode))
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{
+				"device":  name,
+				"summary": summary,
+				"replies": replies,
+			}), nil
 		}),
 	)
 }
 
 // RegisterDeviceToolsWithAPI registers device operation tools with API client support
-func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tailscale.APIClient) {
+func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tailscale.APIClient, cache *ResponseCache) {
 	// Register all existing CLI-based tools first
-	RegisterDeviceTools(server, cli)
+	registerDeviceTools(server, cli, api, cache)
+
+	// List pending devices tool (API only)
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "list_pending_devices",
+			Description: "List devices waiting for authorization (authorized=false), newest first, with the requesting user, OS and when they were added. Approve them with authorize_device.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+
+			pending := []tailscale.Device{}
+			for _, device := range devices {
+				if !device.Authorized {
+					pending = append(pending, device)
+				}
+			}
+			sort.Slice(pending, func(i, j int) bool { return pending[i].Created.After(pending[j].Created) })
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Pending Devices (%d)\n\n", len(pending)))
+			if len(pending) == 0 {
+				result.WriteString("No devices are waiting for authorization.\n")
+			}
+			for _, device := range pending {
+				result.WriteString(fmt.Sprintf("%s (ID: %s)\n", device.Hostname, device.ID))
+				result.WriteString(fmt.Sprintf("  User: %s\n", device.User))
+				result.WriteString(fmt.Sprintf("  OS: %s\n", device.OS))
+				if !device.Created.IsZero() {
+					result.WriteString(fmt.Sprintf("  Requested: %s (%s ago)\n", device.Created.Local().Format("2006-01-02 15:04:05"), time.Since(device.Created).Round(time.Minute)))
+				}
+				if len(device.Tags) > 0 {
+					result.WriteString(fmt.Sprintf("  Tags: %s\n", strings.Join(device.Tags, ", ")))
+				}
+				result.WriteString("\n")
+			}
+			if len(pending) > 0 {
+				result.WriteString("Approve a device with authorize_device and its ID.\n")
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"devices": pending}), nil
+		}),
+	)
 
 	// Authorize device tool (API-enhanced)
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "authorize_device",
 			Description: "Authorize a device in the Tailscale network",
+			Annotations: updateTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -273,21 +391,13 @@ func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tai
 				DeviceID string `json:"device_id"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			// Try API first if available
 			if api != nil && api.IsAvailable() {
-				if err := api.AuthorizeDevice(params.DeviceID); err != nil {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: fmt.Sprintf("Error authorizing device via API: %v", err)},
-						},
-					}, nil
+				if err := api.AuthorizeDevice(ctx, params.DeviceID); err != nil {
+					return errorResult("Error authorizing device via API", err), nil
 				}
 
 				return &mcp.CallToolResult{
@@ -298,11 +408,7 @@ func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tai
 			}
 
 			// Fallback to CLI (if implemented)
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: "API client not configured. Device authorization requires API access. Please set TAILSCALE_API_KEY environment variable."},
-				},
-			}, nil
+			return apiNotConfiguredResult(), nil
 		}),
 	)
 
@@ -311,6 +417,7 @@ func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tai
 		&mcp.Tool{
 			Name:        "delete_device",
 			Description: "Remove a device from the Tailscale network",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -318,6 +425,7 @@ func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tai
 						Type:        "string",
 						Description: "Device ID to remove",
 					},
+					"dry_run": dryRunProperty("show the device that would be removed"),
 				},
 				Required: []string{"device_id"},
 			},
@@ -325,23 +433,23 @@ func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tai
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			var params struct {
 				DeviceID string `json:"device_id"`
+				DryRun   bool   `json:"dry_run"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			// Try API first if available
 			if api != nil && api.IsAvailable() {
-				if err := api.DeleteDevice(params.DeviceID); err != nil {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: fmt.Sprintf("Error deleting device via API: %v", err)},
-						},
-					}, nil
+				if params.DryRun {
+					device, err := api.GetDevice(ctx, params.DeviceID)
+					if err != nil {
+						return errorResult("Error getting device", err), nil
+					}
+					return structuredResult(formatDeviceDeletion(device), newDeviceDetail(nil, false, device)), nil
+				}
+				if err := api.DeleteDevice(ctx, params.DeviceID); err != nil {
+					return errorResult("Error deleting device via API", err), nil
 				}
 
 				return &mcp.CallToolResult{
@@ -352,11 +460,7 @@ func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tai
 			}
 
 			// Fallback to CLI (if implemented)
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: "API client not configured. Device deletion requires API access. Please set TAILSCALE_API_KEY environment variable."},
-				},
-			}, nil
+			return apiNotConfiguredResult(), nil
 		}),
 	)
 
@@ -364,7 +468,8 @@ func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tai
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "set_device_tags",
-			Description: "Set tags for a device",
+			Description: "Set tags for a device. Tags must be declared in the policy's tagOwners",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -387,21 +492,23 @@ func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tai
 				Tags     []string `json:"tags"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			// Try API first if available
 			if api != nil && api.IsAvailable() {
-				if err := api.SetDeviceTags(params.DeviceID, params.Tags); err != nil {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: fmt.Sprintf("Error setting device tags via API: %v", err)},
-						},
-					}, nil
+				tags := make([]string, len(params.Tags))
+				for i, tag := range params.Tags {
+					tags[i] = tailscale.NormalizeTag(tag)
+				}
+				params.Tags = tags
+
+				if refused := checkTagOwners(ctx, api, params.Tags); refused != nil {
+					return refused, nil
+				}
+
+				if err := api.SetDeviceTags(ctx, params.DeviceID, params.Tags); err != nil {
+					return errorResult("Error setting device tags via API", err), nil
 				}
 
 				return &mcp.CallToolResult{
@@ -412,11 +519,152 @@ func RegisterDeviceToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *tai
 			}
 
 			// Fallback to CLI (if implemented)
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: "API client not configured. Setting device tags requires API access. Please set TAILSCALE_API_KEY environment variable."},
+			return apiNotConfiguredResult(), nil
+		}),
+	)
+	// Rename device tool (API only)
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "rename_device",
+			Description: "Change a device's machine name, which is also its MagicDNS name. Shows the resulting FQDN by default; set dry_run=false to apply.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"device_id": {
+						Type:        "string",
+						Description: "Device ID to rename",
+					},
+					"name": {
+						Type:        "string",
+						Description: "New machine name: letters, digits and hyphens, up to 63 characters",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the resulting MagicDNS name without renaming (default: true)",
+					},
 				},
-			}, nil
+				Required: []string{"device_id", "name"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				DeviceID string `json:"device_id"`
+				Name     string `json:"name"`
+				DryRun   *bool  `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			// MagicDNS names are case-insensitive and stored in lower case
+			name := strings.ToLower(strings.TrimSpace(params.Name))
+			if err := validateMachineName(name); err != nil {
+				return errorResult("Invalid name", err), nil
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+			var device *tailscale.Device
+			for i := range devices {
+				if devices[i].ID == params.DeviceID || devices[i].NodeID == params.DeviceID {
+					device = &devices[i]
+				}
+			}
+			if device == nil {
+				return notFoundResult(fmt.Sprintf("Device not found: %s", params.DeviceID)), nil
+			}
+
+			current, suffix, _ := strings.Cut(strings.TrimSuffix(device.Name, "."), ".")
+			fqdn := name
+			if suffix != "" {
+				fqdn = name + "." + suffix
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("%s → %s\n", device.Name, fqdn))
+			if current == name {
+				result.WriteString("The device already has this name.\n")
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: result.String()},
+					},
+				}, nil
+			}
+			for _, other := range devices {
+				if other.ID != device.ID && strings.EqualFold(strings.SplitN(other.Name, ".", 2)[0], name) {
+					result.WriteString(fmt.Sprintf("⚠ %s already uses this name, so the control plane would make it unique with a numeric suffix\n", other.Hostname))
+				}
+			}
+
+			if params.DryRun == nil || *params.DryRun {
+				result.WriteString("\nDry run: the device was not renamed. Set dry_run=false to apply.\n")
+				result.WriteString("Clients, scripts and ACL hosts entries using the old name need updating after the rename.\n")
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: result.String()},
+					},
+				}, nil
+			}
+
+			if err := api.SetDeviceName(ctx, device.ID, name); err != nil {
+				return errorResult("Error renaming device via API", err), nil
+			}
+			result.WriteString("\n✓ Device renamed\n")
+
+			return structuredResult(result.String(), map[string]interface{}{"device_id": device.ID, "old_name": device.Name, "name": fqdn}), nil
 		}),
 	)
-}
\ No newline at end of file
+}
+
+// validateMachineName checks that name is a valid DNS label, which MagicDNS
+// requires of machine names
+func validateMachineName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("name is required")
+	case len(name) > 63:
+		return fmt.Errorf("%q is longer than 63 characters", name)
+	case strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-"):
+		return fmt.Errorf("%q must not start or end with a hyphen", name)
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("%q may only contain letters, digits and hyphens", name)
+		}
+	}
+	return nil
+}
+
+// formatDeviceDeletion describes the device delete_device would remove
+func formatDeviceDeletion(device *tailscale.Device) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Dry run: %s (%s) would be deleted\n\n", device.Name, device.ID))
+	result.WriteString(fmt.Sprintf("Hostname: %s\n", device.Hostname))
+	if device.User != "" {
+		result.WriteString(fmt.Sprintf("User: %s\n", device.User))
+	}
+	result.WriteString(fmt.Sprintf("OS: %s\n", device.OS))
+	if len(device.Addresses) > 0 {
+		result.WriteString(fmt.Sprintf("Addresses: %s\n", strings.Join(device.Addresses, ", ")))
+	}
+	if len(device.Tags) > 0 {
+		result.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(device.Tags, ", ")))
+	}
+	if !device.LastSeen.IsZero() {
+		result.WriteString(fmt.Sprintf("Last Seen: %s\n", device.LastSeen.Format("2006-01-02 15:04:05")))
+	}
+	if len(device.PrimaryRoutes) > 0 {
+		result.WriteString(fmt.Sprintf("⚠ Primary subnet router for %s; traffic to those routes would stop unless another router takes over\n", strings.Join(device.PrimaryRoutes, ", ")))
+	}
+	if device.ExitNode {
+		result.WriteString("⚠ Offers an exit node; clients using it would lose internet access through it\n")
+	}
+	result.WriteString("\nThe device would have to log in again to rejoin the tailnet. Set dry_run=false to delete it.\n")
+	return result.String()
+}
@@ -17,12 +17,13 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "netcheck",
 			Description: "Analyze network conditions and connectivity",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
 					"verbose": {
 						Type:        "boolean",
-						Description: "Show detailed output (optional)",
+						Description: "Also show hair pinning and unreachable DERP regions (optional)",
 					},
 				},
 			},
@@ -31,33 +32,20 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 			var params struct {
 				Verbose bool `json:"verbose"`
 			}
-			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
-			}
-
-			cmdArgs := []string{"netcheck"}
-			if params.Verbose {
-				cmdArgs = append(cmdArgs, "--verbose")
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
 			}
 
-			output, err := cli.Execute(cmdArgs...)
+			report, err := cli.Netcheck(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error running netcheck: %v", err)},
-					},
-				}, nil
+				return errorResult("Error running netcheck", err), nil
 			}
+			derpMap, _ := cli.DERPMap(ctx)
+			info := newNetcheckInfo(report, derpMap)
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: output},
-				},
-			}, nil
+			return structuredResult(formatNetcheck(info, params.Verbose), info), nil
 		}),
 	)
 
@@ -66,6 +54,7 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "whois",
 			Description: "Show machine and user info for a Tailscale IP",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -82,35 +71,27 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 				IP string `json:"ip"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			if params.IP == "" {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "IP address is required"},
-					},
-				}, nil
+				return invalidInputResult("IP address is required"), nil
 			}
 
-			output, err := cli.Execute("whois", params.IP)
+			output, err := cli.Execute(ctx, "whois", params.IP)
+			if err != nil {
+				return errorResult("Error running whois", err), nil
+			}
+
+			who, err := cli.WhoIs(ctx, params.IP)
 			if err != nil {
 				return &mcp.CallToolResult{
 					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error running whois: %v", err)},
+						&mcp.TextContent{Text: output},
 					},
 				}, nil
 			}
-
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: output},
-				},
-			}, nil
+			return structuredResult(output, who), nil
 		}),
 	)
 
@@ -119,6 +100,7 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "bugreport",
 			Description: "Generate a shareable identifier for diagnosing issues",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -134,11 +116,7 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 				Note string `json:"note"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			cmdArgs := []string{"bugreport"}
@@ -146,20 +124,13 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 				cmdArgs = append(cmdArgs, "--note", params.Note)
 			}
 
-			output, err := cli.Execute(cmdArgs...)
+			output, err := cli.Execute(ctx, cmdArgs...)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error generating bugreport: %v", err)},
-					},
-				}, nil
+				return errorResult("Error generating bugreport", err), nil
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: output},
-				},
-			}, nil
+			// The CLI prints the marker to quote to Tailscale support
+			return structuredResult(output, map[string]interface{}{"marker": strings.TrimSpace(output)}), nil
 		}),
 	)
 
@@ -168,6 +139,7 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "serve_status",
 			Description: "Show status of Tailscale serve and funnel configurations",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -183,11 +155,7 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 				JSON bool `json:"json"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			cmdArgs := []string{"serve", "status"}
@@ -195,28 +163,16 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 				cmdArgs = append(cmdArgs, "--json")
 			}
 
-			output, err := cli.Execute(cmdArgs...)
+			output, err := cli.Execute(ctx, cmdArgs...)
 			if err != nil {
 				// Check if serve is not configured
 				if strings.Contains(err.Error(), "no serve config") || strings.Contains(output, "no serve config") {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: "No serve configurations found"},
-						},
-					}, nil
+					return structuredResult("No serve configurations found", serveStatusInfo{}), nil
 				}
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting serve status: %v", err)},
-					},
-				}, nil
+				return errorResult("Error getting serve status", err), nil
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: output},
-				},
-			}, nil
+			return structuredResult(output, serveStatus(ctx, cli, "serve", params.JSON, output)), nil
 		}),
 	)
 
@@ -225,6 +181,7 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "funnel_status",
 			Description: "Show status of Tailscale funnel configurations",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -240,11 +197,7 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 				JSON bool `json:"json"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			cmdArgs := []string{"funnel", "status"}
@@ -252,117 +205,16 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 				cmdArgs = append(cmdArgs, "--json")
 			}
 
-			output, err := cli.Execute(cmdArgs...)
+			output, err := cli.Execute(ctx, cmdArgs...)
 			if err != nil {
 				// Check if funnel is not configured
 				if strings.Contains(err.Error(), "no funnel config") || strings.Contains(output, "no funnel config") {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: "No funnel configurations found"},
-						},
-					}, nil
-				}
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting funnel status: %v", err)},
-					},
-				}, nil
-			}
-
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: output},
-				},
-			}, nil
-		}),
-	)
-
-	// lock_status tool
-	server.AddTool(
-		&mcp.Tool{
-			Name:        "lock_status",
-			Description: "Show tailnet lock status and signing keys",
-			InputSchema: &jsonschema.Schema{
-				Type:       "object",
-				Properties: map[string]*jsonschema.Schema{},
-			},
-		},
-		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			output, err := cli.Execute("lock", "status")
-			if err != nil {
-				// Check if lock is not enabled
-				if strings.Contains(err.Error(), "not enabled") || strings.Contains(output, "not enabled") {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: "Tailnet lock is not enabled"},
-						},
-					}, nil
+					return structuredResult("No funnel configurations found", serveStatusInfo{}), nil
 				}
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting lock status: %v", err)},
-					},
-				}, nil
-			}
-
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: output},
-				},
-			}, nil
-		}),
-	)
-
-	// lock_sign tool
-	server.AddTool(
-		&mcp.Tool{
-			Name:        "lock_sign",
-			Description: "Sign a node key and generate a signature for tailnet lock",
-			InputSchema: &jsonschema.Schema{
-				Type: "object",
-				Properties: map[string]*jsonschema.Schema{
-					"node_key": {
-						Type:        "string",
-						Description: "Node key to sign (e.g., nodekey:abcd1234...)",
-					},
-				},
-				Required: []string{"node_key"},
-			},
-		},
-		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			var params struct {
-				NodeKey string `json:"node_key"`
-			}
-			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
-			}
-
-			if params.NodeKey == "" {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "Node key is required"},
-					},
-				}, nil
+				return errorResult("Error getting funnel status", err), nil
 			}
 
-			output, err := cli.Execute("lock", "sign", params.NodeKey)
-			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error signing node key: %v", err)},
-					},
-				}, nil
-			}
-
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: output},
-				},
-			}, nil
+			return structuredResult(output, serveStatus(ctx, cli, "funnel", params.JSON, output)), nil
 		}),
 	)
 
@@ -371,34 +223,23 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "dns_status",
 			Description: "Diagnose the internal DNS forwarder",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type:       "object",
 				Properties: map[string]*jsonschema.Schema{},
 			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			output, err := cli.Execute("dns", "status")
+			output, err := cli.Execute(ctx, "dns", "status")
 			if err != nil {
 				// Some systems may not have the DNS forwarder enabled
 				if strings.Contains(err.Error(), "not running") || strings.Contains(output, "not running") {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: "DNS forwarder is not running on this system"},
-						},
-					}, nil
+					return structuredResult("DNS forwarder is not running on this system", dnsStatusInfo{Sections: []dnsStatusSection{}}), nil
 				}
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting DNS status: %v", err)},
-					},
-				}, nil
+				return errorResult("Error getting DNS status", err), nil
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: output},
-				},
-			}, nil
+			return structuredResult(output, parseDNSStatus(output)), nil
 		}),
 	)
 
@@ -407,6 +248,7 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "nc",
 			Description: "Test connectivity to a specific port on a Tailscale host",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -433,28 +275,16 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 				Timeout float64 `json:"timeout"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			if params.Host == "" {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "Host is required"},
-					},
-				}, nil
+				return invalidInputResult("Host is required"), nil
 			}
 
 			port := int(params.Port)
 			if port == 0 {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "Port must be a valid number"},
-					},
-				}, nil
+				return invalidInputResult("Port must be a valid number"), nil
 			}
 
 			cmdArgs := []string{"nc"}
@@ -469,40 +299,210 @@ func RegisterDiagnosticTools(server *mcp.Server, cli *tailscale.CLI) {
 			// Add host and port
 			cmdArgs = append(cmdArgs, params.Host, fmt.Sprintf("%d", port))
 
-			output, err := cli.Execute(cmdArgs...)
+			info := ncResult{Host: params.Host, Port: port}
+			output, err := cli.Execute(ctx, cmdArgs...)
 			if err != nil {
 				if strings.Contains(err.Error(), "connection refused") {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: fmt.Sprintf("Connection refused to %s:%d", params.Host, port)},
-						},
-					}, nil
+					info.Failure = "refused"
+					return structuredResult(fmt.Sprintf("Connection refused to %s:%d", params.Host, port), info), nil
 				}
 				if strings.Contains(err.Error(), "timeout") {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: fmt.Sprintf("Connection timeout to %s:%d", params.Host, port)},
-						},
-					}, nil
+					info.Failure = "timeout"
+					return structuredResult(fmt.Sprintf("Connection timeout to %s:%d", params.Host, port), info), nil
 				}
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to connect: %v", err)},
-					},
-				}, nil
+				return errorResult("Failed to connect", err), nil
 			}
 
 			// If connection succeeded
+			info.Connected = true
+			info.Output = output
 			result := fmt.Sprintf("Successfully connected to %s:%d", params.Host, port)
 			if output != "" {
 				result += "\n" + output
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result},
-				},
-			}, nil
+			return structuredResult(result, info), nil
 		}),
 	)
-}
\ No newline at end of file
+}
+
+// ncResult is the structured result of nc. Failure is "refused" or
+// "timeout" when the port couldn't be reached.
+type ncResult struct {
+	Host      string `json:"host"`
+	Port      int    `json:"port"`
+	Connected bool   `json:"connected"`
+	Failure   string `json:"failure,omitempty"`
+	Output    string `json:"output,omitempty"`
+}
+
+// serveStatusInfo is the structured result of serve_status and
+// funnel_status. Config is the serve config as `tailscale serve status
+// --json` prints it.
+type serveStatusInfo struct {
+	Configured bool                   `json:"configured"`
+	Config     map[string]interface{} `json:"config,omitempty"`
+}
+
+// serveStatus parses the serve config for the structured result, reusing
+// output when it is already JSON
+func serveStatus(ctx context.Context, cli *tailscale.CLI, command string, isJSON bool, output string) serveStatusInfo {
+	if !isJSON {
+		var err error
+		if output, err = cli.Execute(ctx, command, "status", "--json"); err != nil {
+			return serveStatusInfo{Configured: true}
+		}
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &config); err != nil {
+		return serveStatusInfo{Configured: true}
+	}
+	return serveStatusInfo{Configured: len(config) > 0, Config: config}
+}
+
+// dnsStatusInfo is the structured result of dns_status: whether the
+// forwarder runs, the settings it states plainly, and each "=== title ==="
+// section of `tailscale dns status` as text
+type dnsStatusInfo struct {
+	Running      bool               `json:"running"`
+	TailscaleDNS *bool              `json:"tailscale_dns,omitempty"`
+	MagicDNS     *bool              `json:"magic_dns,omitempty"`
+	Sections     []dnsStatusSection `json:"sections"`
+}
+
+type dnsStatusSection struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+func parseDNSStatus(output string) dnsStatusInfo {
+	info := dnsStatusInfo{Running: true, Sections: []dnsStatusSection{}}
+	enabled := func(line, prefix string) *bool {
+		value := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, prefix)))
+		b := strings.HasPrefix(value, "enabled")
+		if !b && !strings.HasPrefix(value, "disabled") {
+			return nil
+		}
+		return &b
+	}
+
+	var section *dnsStatusSection
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "===") && strings.HasSuffix(trimmed, "===") {
+			title := strings.Trim(strings.Trim(trimmed, "="), " ")
+			info.Sections = append(info.Sections, dnsStatusSection{Title: title})
+			section = &info.Sections[len(info.Sections)-1]
+			continue
+		}
+		switch {
+		case strings.HasPrefix(trimmed, "Tailscale DNS:"):
+			info.TailscaleDNS = enabled(trimmed, "Tailscale DNS:")
+		case strings.HasPrefix(trimmed, "MagicDNS:"):
+			info.MagicDNS = enabled(trimmed, "MagicDNS:")
+		}
+		if section != nil {
+			section.Text += line + "\n"
+		}
+	}
+	for i := range info.Sections {
+		info.Sections[i].Text = strings.TrimSpace(info.Sections[i].Text)
+	}
+	return info
+}
+
+// netcheckInfo is the structured result of netcheck
+type netcheckInfo struct {
+	UDP      bool   `json:"udp"`
+	IPv4     bool   `json:"ipv4"`
+	IPv6     bool   `json:"ipv6"`
+	GlobalV4 string `json:"global_v4,omitempty"`
+	GlobalV6 string `json:"global_v6,omitempty"`
+	// HardNAT, HairPinning and CaptivePortal are omitted when netcheck
+	// couldn't tell
+	HardNAT       *bool               `json:"hard_nat,omitempty"`
+	HairPinning   *bool               `json:"hair_pinning,omitempty"`
+	CaptivePortal *bool               `json:"captive_portal,omitempty"`
+	PortMapping   []string            `json:"port_mapping"`
+	PreferredDERP string              `json:"preferred_derp,omitempty"`
+	Regions       []derpRegionLatency `json:"regions"`
+}
+
+func newNetcheckInfo(report *tailscale.NetcheckReport, derpMap *tailscale.DERPMap) netcheckInfo {
+	info := netcheckInfo{
+		UDP:           report.UDP,
+		IPv4:          report.IPv4,
+		IPv6:          report.IPv6,
+		GlobalV4:      report.GlobalV4,
+		GlobalV6:      report.GlobalV6,
+		HardNAT:       report.MappingVariesByDestIP,
+		HairPinning:   report.HairPinning,
+		CaptivePortal: report.CaptivePortal,
+		PortMapping:   []string{},
+		Regions:       buildDERPRegions(report, derpMap),
+	}
+	for _, mapping := range []struct {
+		name  string
+		found *bool
+	}{{"UPnP", report.UPnP}, {"NAT-PMP", report.PMP}, {"PCP", report.PCP}} {
+		if mapping.found != nil && *mapping.found {
+			info.PortMapping = append(info.PortMapping, mapping.name)
+		}
+	}
+	for _, region := range info.Regions {
+		if region.Preferred {
+			info.PreferredDERP = region.Code
+		}
+	}
+	if info.Regions == nil {
+		info.Regions = []derpRegionLatency{}
+	}
+	return info
+}
+
+// formatNetcheck renders the report like `tailscale netcheck`. Unreachable
+// DERP regions are only listed when verbose.
+func formatNetcheck(info netcheckInfo, verbose bool) string {
+	optional := func(b *bool) string {
+		if b == nil {
+			return "unknown"
+		}
+		return fmt.Sprintf("%t", *b)
+	}
+
+	var result strings.Builder
+	result.WriteString("Report:\n")
+	result.WriteString(fmt.Sprintf("  * UDP: %t\n", info.UDP))
+	result.WriteString(fmt.Sprintf("  * IPv4: %s\n", describeGlobal(info.IPv4, info.GlobalV4)))
+	result.WriteString(fmt.Sprintf("  * IPv6: %s\n", describeGlobal(info.IPv6, info.GlobalV6)))
+	result.WriteString(fmt.Sprintf("  * MappingVariesByDestIP: %s\n", optional(info.HardNAT)))
+	if verbose {
+		result.WriteString(fmt.Sprintf("  * HairPinning: %s\n", optional(info.HairPinning)))
+	}
+	if len(info.PortMapping) > 0 {
+		result.WriteString(fmt.Sprintf("  * PortMapping: %s\n", strings.Join(info.PortMapping, ", ")))
+	} else {
+		result.WriteString("  * PortMapping: none\n")
+	}
+	result.WriteString(fmt.Sprintf("  * CaptivePortal: %s\n", optional(info.CaptivePortal)))
+	if info.PreferredDERP != "" {
+		result.WriteString(fmt.Sprintf("  * Nearest DERP: %s\n", info.PreferredDERP))
+	}
+
+	result.WriteString("  * DERP latency:\n")
+	for _, region := range info.Regions {
+		if !region.Reachable && !verbose {
+			continue
+		}
+		latency := "unreachable"
+		if region.Reachable {
+			latency = fmt.Sprintf("%.1fms", region.LatencyMs)
+		}
+		line := fmt.Sprintf("    - %s: %s", region.Code, latency)
+		if region.Name != "" {
+			line += fmt.Sprintf(" (%s)", region.Name)
+		}
+		result.WriteString(line + "\n")
+	}
+	return result.String()
+}
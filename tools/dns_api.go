@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/google/jsonschema-go/jsonschema"
@@ -12,30 +13,42 @@ import (
 )
 
 // RegisterDNSAPITools registers DNS management tools using the API
-func RegisterDNSAPITools(server *mcp.Server, api *tailscale.APIClient) {
+func RegisterDNSAPITools(server *mcp.Server, api *tailscale.APIClient, cache *ResponseCache) {
 	// Get DNS configuration tool
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "get_dns_config",
 			Description: "Get the current DNS configuration",
-			InputSchema: &jsonschema.Schema{Type: "object"},
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"refresh": {
+						Type:        "boolean",
+						Description: "Bypass the response cache and fetch fresh data",
+					},
+				},
+			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Refresh bool `json:"refresh"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
 			}
 
-			dnsConfig, err := api.GetDNS()
+			dnsConfig, err := cached(ctx, cache, dnsCacheKey, params.Refresh, func(ctx context.Context) (*tailscale.DNSConfig, error) {
+				return api.GetDNS(ctx)
+			})
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting DNS configuration: %v", err)},
-					},
-				}, nil
+				return errorResult("Error getting DNS configuration", err), nil
 			}
 
 			var result strings.Builder
@@ -67,11 +80,7 @@ func RegisterDNSAPITools(server *mcp.Server, api *tailscale.APIClient) {
 				}
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result.String()},
-				},
-			}, nil
+			return structuredResult(result.String(), dnsConfig), nil
 		}),
 	)
 
@@ -80,6 +89,7 @@ func RegisterDNSAPITools(server *mcp.Server, api *tailscale.APIClient) {
 		&mcp.Tool{
 			Name:        "set_dns_nameservers",
 			Description: "Set DNS nameservers",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -88,44 +98,38 @@ func RegisterDNSAPITools(server *mcp.Server, api *tailscale.APIClient) {
 						Items: &jsonschema.Schema{Type: "string"},
 						Description: "List of DNS nameserver IP addresses (e.g., ['8.8.8.8', '1.1.1.1'])",
 					},
+					"dry_run": dryRunProperty("show the current and proposed nameservers"),
 				},
 				Required: []string{"nameservers"},
 			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
 			}
 
 			var params struct {
 				Nameservers []string `json:"nameservers"`
+				DryRun      bool     `json:"dry_run"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			if len(params.Nameservers) == 0 {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "No nameservers specified. Please provide at least one nameserver."},
-					},
-				}, nil
+				return invalidInputResult("No nameservers specified. Please provide at least one nameserver."), nil
 			}
 
-			if err := api.SetDNSNameservers(params.Nameservers); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error setting DNS nameservers: %v", err)},
-					},
-				}, nil
+			if params.DryRun {
+				current, err := api.GetDNSNameservers(ctx)
+				if err != nil {
+					return errorResult("Error getting DNS nameservers", err), nil
+				}
+				return dnsDryRunResult("nameservers", current, params.Nameservers), nil
+			}
+
+			if err := api.SetDNSNameservers(ctx, params.Nameservers); err != nil {
+				return errorResult("Error setting DNS nameservers", err), nil
 			}
 
 			return &mcp.CallToolResult{
@@ -141,6 +145,7 @@ func RegisterDNSAPITools(server *mcp.Server, api *tailscale.APIClient) {
 		&mcp.Tool{
 			Name:        "set_dns_preferences",
 			Description: "Set DNS preferences including MagicDNS on/off",
+			Annotations: updateTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -154,30 +159,18 @@ func RegisterDNSAPITools(server *mcp.Server, api *tailscale.APIClient) {
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
 			}
 
 			var params struct {
 				MagicDNS bool `json:"magic_dns"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
-			if err := api.SetDNSPreferences(params.MagicDNS); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error setting DNS preferences: %v", err)},
-					},
-				}, nil
+			if err := api.SetDNSPreferences(ctx, params.MagicDNS); err != nil {
+				return errorResult("Error setting DNS preferences", err), nil
 			}
 
 			status := "disabled"
@@ -198,6 +191,7 @@ func RegisterDNSAPITools(server *mcp.Server, api *tailscale.APIClient) {
 		&mcp.Tool{
 			Name:        "set_dns_search_paths",
 			Description: "Set DNS search paths",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -206,44 +200,38 @@ func RegisterDNSAPITools(server *mcp.Server, api *tailscale.APIClient) {
 						Items: &jsonschema.Schema{Type: "string"},
 						Description: "List of DNS search domain paths (e.g., ['example.com', 'company.local'])",
 					},
+					"dry_run": dryRunProperty("show the current and proposed search paths"),
 				},
 				Required: []string{"search_paths"},
 			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
 			}
 
 			var params struct {
 				SearchPaths []string `json:"search_paths"`
+				DryRun      bool     `json:"dry_run"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			if len(params.SearchPaths) == 0 {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "No search paths specified. Please provide at least one search path."},
-					},
-				}, nil
+				return invalidInputResult("No search paths specified. Please provide at least one search path."), nil
 			}
 
-			if err := api.SetDNSSearchPaths(params.SearchPaths); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error setting DNS search paths: %v", err)},
-					},
-				}, nil
+			if params.DryRun {
+				current, err := api.GetDNSSearchPaths(ctx)
+				if err != nil {
+					return errorResult("Error getting DNS search paths", err), nil
+				}
+				return dnsDryRunResult("search paths", current, params.SearchPaths), nil
+			}
+
+			if err := api.SetDNSSearchPaths(ctx, params.SearchPaths); err != nil {
+				return errorResult("Error setting DNS search paths", err), nil
 			}
 
 			return &mcp.CallToolResult{
@@ -253,4 +241,22 @@ func RegisterDNSAPITools(server *mcp.Server, api *tailscale.APIClient) {
 			}, nil
 		}),
 	)
-}
\ No newline at end of file
+}
+
+// dnsDryRunResult previews replacing the tailnet's DNS list (nameservers or
+// search paths), which the API replaces as a whole
+func dnsDryRunResult(label string, current, proposed []string) *mcp.CallToolResult {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Dry run: set DNS %s\n\n", label))
+	writeListChange(&result, "DNS "+label, current, proposed)
+	if slices.Equal(current, proposed) {
+		result.WriteString("\nNothing would change.\n")
+	} else {
+		result.WriteString("\nThe whole list would be replaced. Set dry_run=false to apply it.\n")
+	}
+	return structuredResult(result.String(), map[string]interface{}{
+		"dry_run":  true,
+		"current":  current,
+		"proposed": proposed,
+	})
+}
@@ -0,0 +1,279 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/k8s"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// DoctorCheck is one check of the doctor report. Status is pass, warn, fail
+// or skip (not applicable to this setup); Hint says how to fix a problem.
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// RunDoctor checks what the server depends on: the tailscale binary,
+// tailscaled, the API credentials and tailnet, and, when the Kubernetes
+// tools are enabled, the cluster. Later checks still run when earlier ones
+// fail, so the report shows everything that needs fixing at once.
+func RunDoctor(ctx context.Context, cli *tailscale.CLI, api *tailscale.APIClient, kubernetes bool) []DoctorCheck {
+	binary, version := checkBinary(ctx, cli)
+	checks := []DoctorCheck{binary}
+
+	var status *tailscale.Status
+	if checks[0].Status == "fail" {
+		checks = append(checks, DoctorCheck{Name: "tailscaled", Status: "skip", Detail: "not checked, the tailscale binary doesn't work"})
+	} else {
+		var statusCheck DoctorCheck
+		status, statusCheck = checkDaemon(ctx, cli)
+		checks = append(checks, statusCheck)
+	}
+	if status != nil && status.Version != "" && checks[0].Status == "pass" {
+		if daemon := versionNumber(status.Version); daemon != version {
+			checks[0].Status = "warn"
+			checks[0].Hint = fmt.Sprintf("The CLI is %s but tailscaled is %s; update both (update_tailscale) so commands and the daemon agree.", version, daemon)
+		}
+	}
+
+	checks = append(checks, checkAPI(ctx, api, status)...)
+	checks = append(checks, checkKubernetes(kubernetes))
+	return checks
+}
+
+// DoctorFailed reports whether any check failed
+func DoctorFailed(checks []DoctorCheck) bool {
+	for _, check := range checks {
+		if check.Status == "fail" {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatDoctor renders the report with a marker per check and its hint
+func FormatDoctor(checks []DoctorCheck) string {
+	var result strings.Builder
+	result.WriteString("=== Tailscale MCP Doctor ===\n\n")
+	failed, warned := 0, 0
+	for _, check := range checks {
+		mark := "-"
+		switch check.Status {
+		case "pass":
+			mark = "✓"
+		case "warn":
+			mark = "⚠"
+			warned++
+		case "fail":
+			mark = "✗"
+			failed++
+		}
+		result.WriteString(fmt.Sprintf("%s %s: %s\n", mark, check.Name, check.Detail))
+		if check.Hint != "" {
+			result.WriteString(fmt.Sprintf("    → %s\n", strings.ReplaceAll(check.Hint, "\n", "\n      ")))
+		}
+	}
+
+	result.WriteString("\n")
+	switch {
+	case failed > 0:
+		result.WriteString(fmt.Sprintf("✗ %d check(s) failed, %d warning(s)\n", failed, warned))
+	case warned > 0:
+		result.WriteString(fmt.Sprintf("⚠ All checks passed with %d warning(s)\n", warned))
+	default:
+		result.WriteString("✓ All checks passed\n")
+	}
+	return result.String()
+}
+
+// RegisterDoctorTools registers the doctor tool
+func RegisterDoctorTools(server *mcp.Server, cli *tailscale.CLI, api *tailscale.APIClient, kubernetes bool) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "doctor",
+			Description: "Check the server's setup: the tailscale binary and its version, the connection to tailscaled, the API credentials, the configured tailnet, and Kubernetes access when the operator tools are enabled. Every failing check comes with a hint on how to fix it.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			checks := RunDoctor(ctx, cli, api, kubernetes)
+			return structuredResult(FormatDoctor(checks), map[string]interface{}{
+				"checks": checks,
+				"ok":     !DoctorFailed(checks),
+			}), nil
+		}),
+	)
+}
+
+// checkBinary runs tailscale version, returning the check and the version
+func checkBinary(ctx context.Context, cli *tailscale.CLI) (DoctorCheck, string) {
+	path, err := cli.BinaryPath()
+	if err != nil {
+		return DoctorCheck{Name: "tailscale binary", Status: "fail", Detail: err.Error(),
+			Hint: "Install Tailscale (https://tailscale.com/download), or set tailscale_bin (TAILSCALE_BIN) to the tailscale binary."}, ""
+	}
+	out, err := cli.Version(ctx)
+	if err != nil {
+		return failedCheck("tailscale binary", fmt.Sprintf("%s version failed", path), err), ""
+	}
+	version := versionNumber(out)
+	return DoctorCheck{Name: "tailscale binary", Status: "pass", Detail: fmt.Sprintf("%s (%s)", version, path)}, version
+}
+
+func checkDaemon(ctx context.Context, cli *tailscale.CLI) (*tailscale.Status, DoctorCheck) {
+	status, err := cli.Status(ctx)
+	if err != nil {
+		detail := "can't get the status from tailscaled"
+		if socket := cli.Socket(); socket != "" {
+			detail += " at " + socket
+		}
+		return nil, failedCheck("tailscaled", detail, err)
+	}
+
+	check := DoctorCheck{Name: "tailscaled", Status: "pass", Detail: fmt.Sprintf("reachable, backend state %s", status.BackendState)}
+	switch status.BackendState {
+	case "Running":
+		if len(status.Health) > 0 {
+			check.Status = "warn"
+			check.Detail += fmt.Sprintf(", %d health warning(s): %s", len(status.Health), strings.Join(status.Health, "; "))
+			check.Hint = "Run health_check with deep=true for details."
+		}
+	case "NeedsLogin":
+		check.Status, check.Hint = "warn", "Log in with connect (or tailscale up)."
+	case "NeedsMachineAuth":
+		check.Status, check.Hint = "warn", "Approve this device in the admin console, or with authorize_device from a server that has API access."
+	case "Stopped":
+		check.Status, check.Hint = "warn", "Tailscale is stopped; start it with connect (or tailscale up)."
+	default:
+		check.Status = "warn"
+	}
+	return status, check
+}
+
+// checkAPI checks the API credentials and then the tailnet they're used for
+func checkAPI(ctx context.Context, api *tailscale.APIClient, status *tailscale.Status) []DoctorCheck {
+	if api == nil {
+		return []DoctorCheck{{
+			Name:   "API credentials",
+			Status: "skip",
+			Detail: "not configured; API tools are unavailable",
+			Hint:   "Set TAILSCALE_API_KEY (or TS_OAUTH_CLIENT_ID and TS_OAUTH_CLIENT_SECRET) to manage the tailnet.",
+		}}
+	}
+
+	kind := "API key"
+	if scopes, ok := api.OAuthScopes(); ok {
+		kind = "OAuth client"
+		if len(scopes) > 0 {
+			kind += fmt.Sprintf(" (scopes: %s)", strings.Join(scopes, ", "))
+		}
+	}
+	credentials := DoctorCheck{Name: "API credentials", Status: "pass", Detail: kind + " accepted"}
+	if err := api.VerifyCredentials(ctx); err != nil {
+		var apiErr *tailscale.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == 403 {
+			credentials.Status = "warn"
+			credentials.Detail = kind + " accepted, but it can't list devices"
+			credentials.Hint = classifyError(err).Hint
+		} else if errors.As(err, &apiErr) && apiErr.StatusCode == 401 {
+			credentials = failedCheck("API credentials", kind+" rejected", err)
+		} else {
+			credentials = failedCheck("API credentials", kind+" could not be verified", err)
+		}
+	}
+
+	// The tailnet the API tools manage, which follows switch_profile
+	name := api.Tailnet()
+	if current := tailscale.TailnetFromContext(ctx); current != "" {
+		name = current
+	}
+	label := name
+	if name == "-" {
+		label = "the credentials' own tailnet"
+	}
+	tailnet := DoctorCheck{Name: "tailnet", Status: "pass"}
+	switch {
+	case credentials.Status == "fail":
+		tailnet.Status = "skip"
+		tailnet.Detail = fmt.Sprintf("%s (not checked, the credentials don't work)", label)
+	default:
+		devices, err := api.ListDevices(ctx)
+		if err != nil {
+			tailnet = failedCheck("tailnet", fmt.Sprintf("%s can't be read", label), err)
+			break
+		}
+		tailnet.Detail = fmt.Sprintf("%s (%d devices)", label, len(devices))
+		if name == "-" {
+			tailnet.Detail += ", name not discovered"
+			tailnet.Hint = "The name is taken from this node's status when the node is in the tailnet; otherwise set TAILSCALE_TAILNET, so switch_profile and the tailnets config can match it."
+		} else if status != nil && status.CurrentTailnet != nil && status.CurrentTailnet.Name != "" && !strings.EqualFold(status.CurrentTailnet.Name, name) {
+			tailnet.Status = "warn"
+			tailnet.Hint = fmt.Sprintf("This node is in tailnet %s, but the API tools manage %s. That is fine if intended; otherwise fix TAILSCALE_TAILNET, or add its credentials under tailnets so the API tools follow switch_profile.", status.CurrentTailnet.Name, name)
+		}
+	}
+	return []DoctorCheck{credentials, tailnet}
+}
+
+func checkKubernetes(enabled bool) DoctorCheck {
+	if !enabled {
+		return DoctorCheck{Name: "Kubernetes", Status: "skip", Detail: "operator tools not enabled (ENABLE_K8S_OPERATOR)"}
+	}
+
+	client, err := k8s.NewClient()
+	if err != nil {
+		return kubernetesFailure(err)
+	}
+	version, err := client.GetServerVersion()
+	if err != nil {
+		return kubernetesFailure(err)
+	}
+	detail := "cluster " + version
+	if context, err := client.GetCurrentContext(); err == nil && context != "" {
+		detail += fmt.Sprintf(" (context %s)", context)
+	}
+	return DoctorCheck{Name: "Kubernetes", Status: "pass", Detail: detail}
+}
+
+// kubernetesFailure reports a failed Kubernetes check with the error's
+// troubleshooting tips
+func kubernetesFailure(err error) DoctorCheck {
+	check := DoctorCheck{Name: "Kubernetes", Status: "fail", Detail: err.Error()}
+	var k8sErr *k8s.K8sError
+	if errors.As(err, &k8sErr) {
+		check.Hint = k8sErr.GetTroubleshootingHint()
+	}
+	return check
+}
+
+// failedCheck reports a check that failed with err, with the hint the tool
+// error classification has for it
+func failedCheck(name, detail string, err error) DoctorCheck {
+	te := classifyError(err)
+	check := DoctorCheck{Name: name, Status: "fail", Detail: fmt.Sprintf("%s: %v", detail, err), Hint: te.Hint}
+	if check.Hint == "" && te.Retryable {
+		check.Hint = "This may be temporary; run doctor again."
+	}
+	return check
+}
+
+// versionNumber returns the short version from `tailscale version` output
+// or a daemon version string (1.76.1 from "1.76.1-t0d3..." or multi-line
+// output)
+func versionNumber(version string) string {
+	version = strings.TrimSpace(version)
+	if i := strings.IndexByte(version, '\n'); i >= 0 {
+		version = strings.TrimSpace(version[:i])
+	}
+	if i := strings.IndexByte(version, '-'); i >= 0 {
+		version = version[:i]
+	}
+	return version
+}
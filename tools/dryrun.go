@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// DryRunTools take a dry_run argument that defaults to false. The server's
+// dry_run setting turns it on for calls that don't pass one, so that nothing
+// changes until the agent repeats the call with dry_run=false. Tools that
+// already preview by default (the ACL edit, bulk and lock tools) aren't
+// listed.
+var DryRunTools = []string{"delete_device", "update_acl", "set_dns_nameservers", "set_dns_search_paths"}
+
+// dryRunProperty is the dry_run argument of the tools in DryRunTools
+func dryRunProperty(preview string) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "boolean",
+		Description: fmt.Sprintf("Only %s without changing anything (default: false, or the server's dry_run setting)", preview),
+	}
+}
+
+// writeListChange renders the change from current to proposed, one entry per
+// line, marking added entries with + and removed ones with -
+func writeListChange(result *strings.Builder, label string, current, proposed []string) {
+	result.WriteString(fmt.Sprintf("%s:\n", label))
+	if len(current) == 0 && len(proposed) == 0 {
+		result.WriteString("  (none)\n")
+		return
+	}
+	for _, item := range proposed {
+		if containsString(current, item) {
+			result.WriteString(fmt.Sprintf("    %s\n", item))
+		} else {
+			result.WriteString(fmt.Sprintf("  + %s\n", item))
+		}
+	}
+	for _, item := range current {
+		if !containsString(proposed, item) {
+			result.WriteString(fmt.Sprintf("  - %s\n", item))
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// Error categories group error codes by what the caller can do about them
+const (
+	categoryInput        = "input"        // fix the arguments
+	categoryConfig       = "config"       // fix the server's configuration
+	categoryAuth         = "auth"         // fix credentials or permissions
+	categoryNotFound     = "not_found"    // the target doesn't exist
+	categoryConflict     = "conflict"     // state changed underneath; refetch
+	categoryPrecondition = "precondition" // the current state doesn't allow it
+	categoryUnavailable  = "unavailable"  // tailscaled or the API can't be reached
+	categoryCanceled     = "canceled"     // the request was canceled or timed out
+	categoryInternal     = "internal"     // anything else
+)
+
+// toolError is the structured payload of a failed tool call, returned as
+// {"error": {...}} in the result's structured content
+type toolError struct {
+	Code       string `json:"code"`
+	Category   string `json:"category"`
+	Message    string `json:"message"`
+	Retryable  bool   `json:"retryable"`
+	Hint       string `json:"hint,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Command    string `json:"command,omitempty"`
+}
+
+// errorResult reports a failed tool call: the text is message and err (or
+// just err when message is empty), followed by a hint when one is known, and
+// IsError is set so clients can tell failures apart without parsing the text
+func errorResult(message string, err error) *mcp.CallToolResult {
+	te := classifyError(err)
+	te.Message = err.Error()
+	if message != "" {
+		te.Message = message + ": " + te.Message
+	}
+	return toolErrorResult(te)
+}
+
+// reportErrorResult reports a failure partway through a tool that builds
+// a report, keeping the report (diffs, completed steps) as the text and
+// classifying err in the structured payload
+func reportErrorResult(report string, err error) *mcp.CallToolResult {
+	te := classifyError(err)
+	te.Message = err.Error()
+	result := toolErrorResult(te)
+	result.Content = []mcp.Content{&mcp.TextContent{Text: report}}
+	if te.Hint != "" {
+		result.Content = []mcp.Content{&mcp.TextContent{Text: strings.TrimRight(report, "\n") + "\n\nHint: " + te.Hint}}
+	}
+	return result
+}
+
+// invalidParamsResult reports arguments that couldn't be parsed or validated
+func invalidParamsResult(err error) *mcp.CallToolResult {
+	return toolErrorResult(toolError{
+		Code:     "invalid_params",
+		Category: categoryInput,
+		Message:  fmt.Sprintf("Invalid parameters: %v", err),
+	})
+}
+
+// invalidInputResult reports arguments that parsed but don't make sense
+func invalidInputResult(message string) *mcp.CallToolResult {
+	return toolErrorResult(toolError{Code: "invalid_params", Category: categoryInput, Message: message})
+}
+
+// notFoundResult reports that the device, key, rule or other object named in
+// the arguments doesn't exist
+func notFoundResult(message string) *mcp.CallToolResult {
+	return toolErrorResult(toolError{Code: "not_found", Category: categoryNotFound, Message: message})
+}
+
+// refusedResult reports a change the tool won't make in the current state,
+// e.g. tags that aren't declared yet or removing the last trusted key
+func refusedResult(message string) *mcp.CallToolResult {
+	return toolErrorResult(toolError{Code: "precondition_failed", Category: categoryPrecondition, Message: message})
+}
+
+// apiNotConfiguredResult reports a call to an API tool without credentials
+func apiNotConfiguredResult() *mcp.CallToolResult {
+	return toolErrorResult(toolError{
+		Code:     "api_not_configured",
+		Category: categoryConfig,
+		Message:  "API client not configured. Please set TAILSCALE_API_KEY environment variable.",
+		Hint:     "Set TAILSCALE_API_KEY (or TS_OAUTH_CLIENT_ID and TS_OAUTH_CLIENT_SECRET) and restart the server.",
+	})
+}
+
+func toolErrorResult(te toolError) *mcp.CallToolResult {
+	text := te.Message
+	if te.Hint != "" {
+		text += "\n\nHint: " + te.Hint
+	}
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+		StructuredContent: map[string]interface{}{"error": te},
+	}
+}
+
+// classifyError assigns a code, category and hint to an error from the CLI,
+// the LocalAPI, the Tailscale API or the device resolver. Errors whose
+// message already says what to do (policy conflicts, device lookups) get no
+// separate hint.
+func classifyError(err error) toolError {
+	var apiErr *tailscale.APIError
+	var cmdErr *tailscale.CommandError
+	var cmdTimeout *tailscale.CommandTimeoutError
+	var notFound *deviceNotFoundError
+	var ambiguous *ambiguousDeviceError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return toolError{Code: "canceled", Category: categoryCanceled}
+	case errors.As(err, &cmdTimeout):
+		return toolError{Code: "command_timeout", Category: categoryCanceled, Retryable: true, Command: cmdTimeout.Command(),
+			Hint: "tailscaled may be hung or unreachable; check it with doctor, or raise cli_timeout (TAILSCALE_MCP_CLI_TIMEOUT) for slow commands."}
+	case errors.Is(err, context.DeadlineExceeded):
+		return toolError{Code: "timeout", Category: categoryCanceled, Retryable: true}
+	case errors.Is(err, tailscale.ErrPolicyConflict):
+		return toolError{Code: "policy_conflict", Category: categoryConflict, Retryable: true}
+	case errors.As(err, &notFound):
+		return toolError{Code: "device_not_found", Category: categoryNotFound}
+	case errors.As(err, &ambiguous):
+		return toolError{Code: "ambiguous_device", Category: categoryInput}
+	case errors.As(err, &apiErr):
+		te := classifyAPIStatus(apiErr.StatusCode)
+		te.StatusCode = apiErr.StatusCode
+		return te
+	case errors.As(err, &cmdErr):
+		te := classifyCommand(cmdErr)
+		te.Command = cmdErr.Command()
+		return te
+	}
+	return toolError{Code: "internal", Category: categoryInternal}
+}
+
+// classifyAPIStatus maps a Tailscale API error status to an error
+func classifyAPIStatus(status int) toolError {
+	switch {
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return toolError{Code: "api_invalid_request", Category: categoryInput}
+	case status == http.StatusUnauthorized:
+		return toolError{Code: "api_unauthorized", Category: categoryAuth,
+			Hint: "The API key or OAuth client was rejected; check that it hasn't expired or been revoked."}
+	case status == http.StatusForbidden:
+		return toolError{Code: "api_forbidden", Category: categoryAuth,
+			Hint: "The credentials lack the scope or role for this operation; OAuth clients need the matching scope (e.g., devices:core, policy_file)."}
+	case status == http.StatusNotFound:
+		return toolError{Code: "api_not_found", Category: categoryNotFound,
+			Hint: "Check the ID or name, and that the tailnet in TAILSCALE_TAILNET is the right one."}
+	case status == http.StatusConflict || status == http.StatusPreconditionFailed:
+		return toolError{Code: "api_conflict", Category: categoryConflict, Retryable: true}
+	case status == http.StatusTooManyRequests:
+		return toolError{Code: "api_rate_limited", Category: categoryUnavailable, Retryable: true,
+			Hint: "The API rate limit was hit; wait a moment before retrying."}
+	case status >= 500:
+		return toolError{Code: "api_unavailable", Category: categoryUnavailable, Retryable: true}
+	}
+	return toolError{Code: "api_error", Category: categoryInternal}
+}
+
+// classifyCommand recognizes the common ways tailscale CLI commands fail
+func classifyCommand(err *tailscale.CommandError) toolError {
+	stderr := strings.ToLower(err.Stderr)
+	switch {
+	case errors.Is(err.Err, exec.ErrNotFound):
+		return toolError{Code: "tailscale_not_installed", Category: categoryConfig,
+			Hint: "Install Tailscale, or point tailscale_bin (TAILSCALE_BIN) at the tailscale binary."}
+	case strings.Contains(stderr, "doesn't appear to be running") || strings.Contains(stderr, "failed to connect to local tailscale"):
+		return toolError{Code: "tailscaled_not_running", Category: categoryUnavailable, Retryable: true,
+			Hint: "Start tailscaled (e.g., sudo systemctl start tailscaled) and try again. If it runs with a non-default socket (userspace networking, containers), set socket (TS_SOCKET) to it."}
+	case strings.Contains(stderr, "access denied") || strings.Contains(stderr, "permission denied"):
+		return toolError{Code: "permission_denied", Category: categoryAuth,
+			Hint: "Run the server as root, or let its user manage Tailscale with: sudo tailscale set --operator=$USER"}
+	case strings.Contains(stderr, "logged out") || strings.Contains(stderr, "needslogin") || strings.Contains(stderr, "not logged in"):
+		return toolError{Code: "not_logged_in", Category: categoryAuth,
+			Hint: "Log in first with connect (or tailscale up)."}
+	case strings.Contains(stderr, "unknown peer") || strings.Contains(stderr, "no matching peer") || strings.Contains(stderr, "invalid value") && strings.Contains(stderr, "exit-node"):
+		return toolError{Code: "peer_not_found", Category: categoryNotFound,
+			Hint: "Use list_devices to see device names."}
+	case strings.Contains(stderr, "flag provided but not defined") || strings.Contains(stderr, "unknown subcommand"):
+		return toolError{Code: "cli_unsupported", Category: categoryConfig,
+			Hint: "This tailscale version doesn't support the command; update the client with update_tailscale."}
+	}
+	return toolError{Code: "cli_error", Category: categoryInternal}
+}
@@ -0,0 +1,237 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// URIs of the device inventory resources
+const (
+	DevicesCSVResourceURI  = "tailscale://devices.csv"
+	DevicesJSONResourceURI = "tailscale://devices.json"
+)
+
+// deviceRecord is one device in the inventory export
+type deviceRecord struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Hostname         string   `json:"hostname"`
+	User             string   `json:"user"`
+	OS               string   `json:"os"`
+	Addresses        []string `json:"addresses"`
+	Tags             []string `json:"tags"`
+	Authorized       bool     `json:"authorized"`
+	KeyExpiry        string   `json:"key_expiry"`
+	LastSeen         string   `json:"last_seen"`
+	Created          string   `json:"created"`
+	AdvertisedRoutes []string `json:"advertised_routes"`
+	EnabledRoutes    []string `json:"enabled_routes"`
+}
+
+// csvHeader is the column order of the CSV export
+var csvHeader = []string{"id", "name", "hostname", "user", "os", "addresses", "tags", "authorized", "key_expiry", "last_seen", "created", "advertised_routes", "enabled_routes"}
+
+// exportTime formats a timestamp for the export, leaving unset ones empty
+func exportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// nonNil keeps empty lists as [] rather than null in the JSON export
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// deviceRecords flattens devices into export records sorted by name
+func deviceRecords(devices []tailscale.Device) []deviceRecord {
+	records := make([]deviceRecord, 0, len(devices))
+	for _, d := range devices {
+		expiry := exportTime(d.KeyExpiry)
+		if d.KeyExpiryDisabled {
+			expiry = "disabled"
+		}
+		records = append(records, deviceRecord{
+			ID:               d.ID,
+			Name:             strings.SplitN(d.Name, ".", 2)[0],
+			Hostname:         d.Hostname,
+			User:             d.User,
+			OS:               d.OS,
+			Addresses:        nonNil(d.Addresses),
+			Tags:             nonNil(d.Tags),
+			Authorized:       d.Authorized,
+			KeyExpiry:        expiry,
+			LastSeen:         exportTime(d.LastSeen),
+			Created:          exportTime(d.Created),
+			AdvertisedRoutes: nonNil(d.AdvertisedRoutes),
+			EnabledRoutes:    nonNil(d.EnabledRoutes),
+		})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records
+}
+
+// deviceExport is the structured result of export_devices, describing the
+// export returned as a resource or saved to a file
+type deviceExport struct {
+	Format  string `json:"format"`
+	Devices int    `json:"devices"`
+	URI     string `json:"uri"`
+	Size    int64  `json:"size"`
+	Saved   bool   `json:"saved"`
+}
+
+// encodeDevices renders the records as CSV (lists joined with spaces, so
+// each cell stays one value for spreadsheets) or indented JSON
+func encodeDevices(records []deviceRecord, format string) ([]byte, string, error) {
+	if format == "json" {
+		data, err := json.MarshalIndent(map[string]interface{}{"devices": records}, "", "  ")
+		return data, "application/json", err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, "", err
+	}
+	for _, r := range records {
+		row := []string{
+			r.ID, r.Name, r.Hostname, r.User, r.OS,
+			strings.Join(r.Addresses, " "), strings.Join(r.Tags, " "),
+			fmt.Sprintf("%t", r.Authorized), r.KeyExpiry, r.LastSeen, r.Created,
+			strings.Join(r.AdvertisedRoutes, " "), strings.Join(r.EnabledRoutes, " "),
+		}
+		if err := w.Write(row); err != nil {
+			return nil, "", err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), "text/csv", w.Error()
+}
+
+// RegisterExportTools registers the device inventory export, as a tool and
+// as the tailscale://devices.csv and tailscale://devices.json resources
+func RegisterExportTools(server *mcp.Server, api *tailscale.APIClient, output *OutputWriter) {
+	for uri, format := range map[string]string{DevicesCSVResourceURI: "csv", DevicesJSONResourceURI: "json"} {
+		server.AddResource(&mcp.Resource{
+			URI:         uri,
+			Name:        "devices." + format,
+			Title:       fmt.Sprintf("Tailnet device inventory (%s)", strings.ToUpper(format)),
+			Description: "Every device in the tailnet with its user, OS, IPs, tags, key expiry, last seen time and routes",
+			MIMEType:    map[string]string{"csv": "text/csv", "json": "application/json"}[format],
+		}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			devices, err := api.ListAllDeviceFields(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list devices: %w", err)
+			}
+			data, mimeType, err := encodeDevices(deviceRecords(devices), format)
+			if err != nil {
+				return nil, err
+			}
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{{
+					URI:      req.Params.URI,
+					MIMEType: mimeType,
+					Text:     string(data),
+				}},
+			}, nil
+		})
+	}
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "export_devices",
+			Description: "Export every device in the tailnet (name, user, OS, IPs, tags, key expiry, last seen, routes) as CSV or JSON for audits and spreadsheets. Returned inline as an embedded resource, or saved to output_file. Also readable as the tailscale://devices.csv and tailscale://devices.json resources.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"format": {
+						Type:        "string",
+						Description: "Export format (default: csv)",
+						Enum:        []interface{}{"csv", "json"},
+					},
+					"output_file": {
+						Type:        "string",
+						Description: "Save the export to this file (relative to the client's roots or the configured output directory) instead of returning it inline (optional)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Format     string `json:"format"`
+				OutputFile string `json:"output_file"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Format == "" {
+				params.Format = "csv"
+			}
+			if params.Format != "csv" && params.Format != "json" {
+				return invalidInputResult("Invalid parameters: format must be csv or json"), nil
+			}
+
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			devices, err := api.ListAllDeviceFields(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+			records := deviceRecords(devices)
+			data, mimeType, err := encodeDevices(records, params.Format)
+			if err != nil {
+				return errorResult("Error encoding export", err), nil
+			}
+
+			if params.OutputFile != "" {
+				link, err := output.Write(ctx, req.Session, params.OutputFile, data, mimeType)
+				if err != nil {
+					return errorResult("Error saving export", err), nil
+				}
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Exported %d devices as %s to %s (%d bytes)", len(records), strings.ToUpper(params.Format), link.URI, *link.Size)},
+						link,
+					},
+					StructuredContent: deviceExport{Format: params.Format, Devices: len(records), URI: link.URI, Size: *link.Size, Saved: true},
+				}, nil
+			}
+
+			uri := DevicesCSVResourceURI
+			if params.Format == "json" {
+				uri = DevicesJSONResourceURI
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Exported %d devices as %s:", len(records), strings.ToUpper(params.Format))},
+					&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+						URI:      uri,
+						MIMEType: mimeType,
+						Text:     string(data),
+					}},
+				},
+				StructuredContent: deviceExport{Format: params.Format, Devices: len(records), URI: uri, Size: int64(len(data))},
+			}, nil
+		}),
+	)
+}
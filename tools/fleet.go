@@ -0,0 +1,324 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const (
+	defaultInventoryConcurrency = 8
+	defaultInventoryTimeout     = 30 * time.Second
+)
+
+// inventoryCommand is the read-only command set run on each device. Sections
+// are separated by markers so the output can be split reliably.
+const inventoryCommand = `echo '==uname=='; uname -srm; echo '==os-release=='; cat /etc/os-release 2>/dev/null; echo '==uptime=='; uptime`
+
+// deviceInventory is the OS and kernel information collected from one device
+type deviceInventory struct {
+	Host   string `json:"host"`
+	OS     string `json:"os,omitempty"`
+	Kernel string `json:"kernel,omitempty"`
+	Uptime string `json:"uptime,omitempty"`
+	Err    error  `json:"-"`
+	// Error holds Err's message in structured output
+	Error string `json:"error,omitempty"`
+}
+
+// RegisterFleetTools registers tools that run read-only commands across devices
+// over Tailscale SSH. Only registered when SSH execution is enabled.
+func RegisterFleetTools(server *mcp.Server, cli *tailscale.CLI) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "fleet_inventory",
+			Description: "Collect an OS/kernel inventory from Linux devices over Tailscale SSH (uname, /etc/os-release, uptime). Runs read-only commands concurrently and aggregates versions across the fleet. Defaults to all online Linux peers.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"devices": {
+						Type:        "array",
+						Description: "Device hostnames to inventory (default: all online Linux peers)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"tag": {
+						Type:        "string",
+						Description: "Only inventory peers carrying this tag (e.g., tag:server)",
+					},
+					"ssh_user": {
+						Type:        "string",
+						Description: "User to SSH as (default: root)",
+					},
+					"concurrency": {
+						Type:        "integer",
+						Description: "Maximum number of devices queried at once (default: 8)",
+					},
+					"timeout_seconds": {
+						Type:        "integer",
+						Description: "Per-device timeout in seconds (default: 30)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Devices        []string `json:"devices"`
+				Tag            string   `json:"tag"`
+				SSHUser        string   `json:"ssh_user"`
+				Concurrency    int      `json:"concurrency"`
+				TimeoutSeconds int      `json:"timeout_seconds"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			sshUser := params.SSHUser
+			if sshUser == "" {
+				sshUser = "root"
+			}
+			concurrency := params.Concurrency
+			if concurrency <= 0 {
+				concurrency = defaultInventoryConcurrency
+			}
+			timeout := defaultInventoryTimeout
+			if params.TimeoutSeconds > 0 {
+				timeout = time.Duration(params.TimeoutSeconds) * time.Second
+			}
+
+			status, err := cli.Status(ctx)
+			if err != nil {
+				return errorResult("Error getting status", err), nil
+			}
+
+			hosts, notes := selectInventoryHosts(status, params.Devices, params.Tag)
+			if len(hosts) == 0 {
+				var result strings.Builder
+				result.WriteString("No matching devices to inventory\n")
+				for _, note := range notes {
+					result.WriteString(fmt.Sprintf("  - %s\n", note))
+				}
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: result.String()},
+					},
+				}, nil
+			}
+
+			inventory := collectInventory(ctx, cli, sshUser, hosts, concurrency, timeout, newProgress(req, len(hosts)))
+			for i := range inventory {
+				if inventory[i].Err != nil {
+					inventory[i].Error = inventory[i].Err.Error()
+				}
+			}
+
+			return structuredResult(formatInventory(inventory, notes), map[string]interface{}{
+				"devices": inventory,
+				"skipped": notes,
+			}), nil
+		}),
+	)
+}
+
+// selectInventoryHosts picks the peers to query. Explicit devices are used as
+// given; otherwise online Linux peers are selected, optionally filtered by tag.
+func selectInventoryHosts(status *tailscale.Status, devices []string, tag string) ([]string, []string) {
+	var hosts, notes []string
+	if tag != "" {
+		tag = tailscale.NormalizeTag(tag)
+	}
+
+	if len(devices) > 0 {
+		for _, d := range devices {
+			if !probeHostPattern.MatchString(d) {
+				notes = append(notes, fmt.Sprintf("%s: skipped (invalid hostname)", d))
+				continue
+			}
+			hosts = append(hosts, d)
+		}
+		return hosts, notes
+	}
+
+	for _, peer := range status.Peer {
+		if peer == nil || !strings.EqualFold(peer.OS, "linux") {
+			continue
+		}
+		if tag != "" && !containsString(peer.Tags, tag) {
+			continue
+		}
+		if !peer.Online {
+			notes = append(notes, fmt.Sprintf("%s: skipped (offline)", peer.HostName))
+			continue
+		}
+		hosts = append(hosts, peerHost(peer))
+	}
+
+	sort.Strings(hosts)
+	sort.Strings(notes)
+	return hosts, notes
+}
+
+// peerHost returns the name to SSH to for a peer, preferring its MagicDNS name
+func peerHost(peer *tailscale.PeerStatus) string {
+	if name := strings.SplitN(peer.DNSName, ".", 2)[0]; name != "" {
+		return name
+	}
+	return peer.HostName
+}
+
+// collectInventory queries hosts concurrently, at most concurrency at a time,
+// reporting each host queried as a progress step
+func collectInventory(ctx context.Context, cli *tailscale.CLI, user string, hosts []string, concurrency int, timeout time.Duration, progress *progressReporter) []deviceInventory {
+	results := make([]deviceInventory, len(hosts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			defer progress.step(ctx, "queried "+host)
+
+			res, err := cli.SSHExec(ctx, user, host, inventoryCommand, timeout)
+			if err == nil && res.ExitCode != 0 && res.Stdout == "" {
+				err = fmt.Errorf("command failed with exit code %d: %s", res.ExitCode, res.Stderr)
+			}
+			if err != nil {
+				results[i] = deviceInventory{Host: host, Err: err}
+				return
+			}
+			results[i] = parseInventory(host, res.Stdout)
+		}(i, host)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// parseInventory splits the marker-delimited command output into its sections
+func parseInventory(host, output string) deviceInventory {
+	inv := deviceInventory{Host: host}
+	sections := map[string][]string{}
+	section := ""
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "==") && strings.HasSuffix(line, "==") && len(line) > 4 {
+			section = strings.Trim(line, "=")
+			continue
+		}
+		if section != "" && line != "" {
+			sections[section] = append(sections[section], line)
+		}
+	}
+
+	inv.Kernel = strings.Join(sections["uname"], " ")
+	inv.Uptime = strings.Join(sections["uptime"], " ")
+
+	release := map[string]string{}
+	for _, line := range sections["os-release"] {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		release[key] = strings.Trim(value, `"'`)
+	}
+	inv.OS = release["PRETTY_NAME"]
+	if inv.OS == "" {
+		inv.OS = strings.TrimSpace(release["NAME"] + " " + release["VERSION_ID"])
+	}
+	if inv.OS == "" {
+		inv.OS = "unknown"
+	}
+
+	return inv
+}
+
+// formatInventory renders per-device results followed by fleet-wide counts
+func formatInventory(inventory []deviceInventory, notes []string) string {
+	var result strings.Builder
+	osCounts := map[string]int{}
+	kernelCounts := map[string]int{}
+	var failed []deviceInventory
+
+	result.WriteString(fmt.Sprintf("Fleet Inventory (%d devices)\n\n", len(inventory)))
+	for _, inv := range inventory {
+		if inv.Err != nil {
+			failed = append(failed, inv)
+			continue
+		}
+		osCounts[inv.OS]++
+		kernelCounts[kernelRelease(inv.Kernel)]++
+
+		result.WriteString(fmt.Sprintf("✓ %s\n", inv.Host))
+		result.WriteString(fmt.Sprintf("  OS: %s\n", inv.OS))
+		result.WriteString(fmt.Sprintf("  Kernel: %s\n", inv.Kernel))
+		if inv.Uptime != "" {
+			result.WriteString(fmt.Sprintf("  Uptime: %s\n", inv.Uptime))
+		}
+	}
+
+	if len(osCounts) > 0 {
+		result.WriteString("\nOperating systems:\n")
+		writeCounts(&result, osCounts)
+		result.WriteString("\nKernels:\n")
+		writeCounts(&result, kernelCounts)
+	}
+
+	if len(failed) > 0 {
+		result.WriteString(fmt.Sprintf("\nUnreachable (%d):\n", len(failed)))
+		for _, inv := range failed {
+			result.WriteString(fmt.Sprintf("  ✗ %s: %v\n", inv.Host, inv.Err))
+		}
+	}
+
+	if len(notes) > 0 {
+		result.WriteString("\nSkipped:\n")
+		for _, note := range notes {
+			result.WriteString(fmt.Sprintf("  - %s\n", note))
+		}
+	}
+
+	return result.String()
+}
+
+// kernelRelease extracts the release from `uname -srm` output (e.g., 6.1.0-18-amd64)
+func kernelRelease(uname string) string {
+	fields := strings.Fields(uname)
+	if len(fields) >= 2 {
+		return fields[1]
+	}
+	if uname == "" {
+		return "unknown"
+	}
+	return uname
+}
+
+// writeCounts lists values by descending count, then name
+func writeCounts(result *strings.Builder, counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		result.WriteString(fmt.Sprintf("  %-40s %d\n", k, counts[k]))
+	}
+}
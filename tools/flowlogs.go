@@ -0,0 +1,324 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// flowTalker is the traffic one node reported
+type flowTalker struct {
+	Node    string `json:"node"`
+	NodeID  string `json:"node_id"`
+	TxBytes int64  `json:"tx_bytes"`
+	RxBytes int64  `json:"rx_bytes"`
+}
+
+// flowPair is the traffic between two addresses
+type flowPair struct {
+	A     string `json:"a"`
+	B     string `json:"b"`
+	Bytes int64  `json:"bytes"`
+}
+
+// flowSummary aggregates flow logs into top talkers and peer pairs
+type flowSummary struct {
+	Start       time.Time    `json:"start"`
+	End         time.Time    `json:"end"`
+	Logs        int          `json:"logs"`
+	Connections int          `json:"connections"`
+	Talkers     []flowTalker `json:"top_talkers"`
+	Pairs       []flowPair   `json:"top_pairs"`
+}
+
+// RegisterFlowLogTools registers tools for investigating network flow logs
+func RegisterFlowLogTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "get_flow_logs",
+			Description: "Summarize the tailnet's network flow logs for a time range: the nodes moving the most traffic and the busiest peer pairs. Requires network flow logging to be turned on (see update_tailnet_settings).",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"since": {
+						Type:        "string",
+						Description: "How far back to look, e.g. 15m or 6h (default: 1h). Ignored when start is given",
+					},
+					"start": {
+						Type:        "string",
+						Description: "Start of the range as an RFC 3339 time (optional)",
+					},
+					"end": {
+						Type:        "string",
+						Description: "End of the range as an RFC 3339 time (default: now)",
+					},
+					"node": {
+						Type:        "string",
+						Description: "Only include traffic to or from this device (hostname, IP or node ID) (optional)",
+					},
+					"traffic": {
+						Type:        "string",
+						Description: "Kind of traffic: virtual (between tailnet nodes, the default), subnet, exit, physical or all",
+						Enum:        []interface{}{"virtual", "subnet", "exit", "physical", "all"},
+					},
+					"top": {
+						Type:        "integer",
+						Description: "Number of talkers and pairs to show (default: 10)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Since   string `json:"since"`
+				Start   string `json:"start"`
+				End     string `json:"end"`
+				Node    string `json:"node"`
+				Traffic string `json:"traffic"`
+				Top     int    `json:"top"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Top <= 0 {
+				params.Top = 10
+			}
+			if params.Traffic == "" {
+				params.Traffic = "virtual"
+			}
+
+			start, end, err := flowLogRange(params.Since, params.Start, params.End, time.Now())
+			if err == nil && !containsString([]string{"virtual", "subnet", "exit", "physical", "all"}, params.Traffic) {
+				err = fmt.Errorf("traffic must be virtual, subnet, exit, physical or all")
+			}
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			// Devices are only needed for names, unless filtering by node
+			devices, devicesErr := api.ListDevices(ctx)
+			var node *tailscale.Device
+			if params.Node != "" {
+				if devicesErr != nil {
+					return errorResult("Error listing devices", devicesErr), nil
+				}
+				node = findDeviceByHost(devices, params.Node)
+				for i := range devices {
+					if node == nil && devices[i].NodeID == params.Node {
+						node = &devices[i]
+					}
+				}
+				if node == nil {
+					return notFoundResult(fmt.Sprintf("Device not found: %s", params.Node)), nil
+				}
+			}
+
+			logs, err := api.GetNetworkFlowLogs(ctx, start, end)
+			if err != nil {
+				te := classifyError(err)
+				te.Message = fmt.Sprintf("Error getting flow logs: %v", err)
+				te.Hint = "Flow logs need network flow logging turned on in the tailnet settings."
+				return toolErrorResult(te), nil
+			}
+
+			summary := summarizeFlowLogs(logs, devices, node, params.Traffic, params.Top)
+			summary.Start, summary.End = start, end
+			names := flowNames(devices)
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Flow Logs (%s traffic, %s to %s)\n\n", params.Traffic, start.Local().Format("2006-01-02 15:04"), end.Local().Format("2006-01-02 15:04")))
+			if node != nil {
+				result.WriteString(fmt.Sprintf("Node: %s\n", node.Hostname))
+			}
+			result.WriteString(fmt.Sprintf("Logs: %d, connections: %d\n", summary.Logs, summary.Connections))
+			if summary.Connections == 0 {
+				result.WriteString("\nNo traffic was logged in this range. Check that network flow logging is turned on.\n")
+			} else {
+				result.WriteString("\nTop talkers (as reported by each node):\n")
+				for _, t := range summary.Talkers {
+					result.WriteString(fmt.Sprintf("  %s: sent %s, received %s\n", t.Node, formatByteSize(t.TxBytes), formatByteSize(t.RxBytes)))
+				}
+				result.WriteString("\nTop peer pairs:\n")
+				for _, p := range summary.Pairs {
+					result.WriteString(fmt.Sprintf("  %s ↔ %s: %s\n", flowLabel(names, p.A), flowLabel(names, p.B), formatByteSize(p.Bytes)))
+				}
+			}
+			if devicesErr != nil {
+				result.WriteString(fmt.Sprintf("\n⚠ Could not list devices to name addresses: %v\n", devicesErr))
+			}
+
+			return structuredResult(result.String(), summary), nil
+		}),
+	)
+}
+
+// flowLogRange works out the requested time range. Without start the range
+// is the since duration (default 1h) before end, which defaults to now.
+func flowLogRange(since, start, end string, now time.Time) (time.Time, time.Time, error) {
+	to := now
+	if end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("end %q is not an RFC 3339 time", end)
+		}
+		to = t
+	}
+	if start != "" {
+		from, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("start %q is not an RFC 3339 time", start)
+		}
+		if !from.Before(to) {
+			return time.Time{}, time.Time{}, fmt.Errorf("start must be before end")
+		}
+		return from, to, nil
+	}
+	window := time.Hour
+	if since != "" {
+		d, err := time.ParseDuration(since)
+		if err != nil || d <= 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("since %q is not a positive duration (e.g., 15m, 6h)", since)
+		}
+		window = d
+	}
+	return to.Add(-window), to, nil
+}
+
+// summarizeFlowLogs totals the traffic of the selected kind. Talkers use each
+// node's own counts. Both ends of a connection may log it, so a pair's bytes
+// are taken from whichever end reported more rather than summed.
+func summarizeFlowLogs(logs []tailscale.NetworkFlowLog, devices []tailscale.Device, node *tailscale.Device, traffic string, top int) *flowSummary {
+	nodeNames := map[string]string{}
+	for _, d := range devices {
+		if d.NodeID != "" {
+			nodeNames[d.NodeID] = d.Hostname
+		}
+	}
+
+	summary := &flowSummary{Talkers: []flowTalker{}, Pairs: []flowPair{}}
+	talkers := map[string]*flowTalker{}
+	pairs := map[[2]string]map[string]int64{}
+	for _, log := range logs {
+		var conns []tailscale.FlowConnection
+		if traffic == "virtual" || traffic == "all" {
+			conns = append(conns, log.VirtualTraffic...)
+		}
+		if traffic == "subnet" || traffic == "all" {
+			conns = append(conns, log.SubnetTraffic...)
+		}
+		if traffic == "exit" || traffic == "all" {
+			conns = append(conns, log.ExitTraffic...)
+		}
+		if traffic == "physical" || traffic == "all" {
+			conns = append(conns, log.PhysicalTraffic...)
+		}
+
+		counted := false
+		for _, conn := range conns {
+			src, dst := flowHost(conn.Src), flowHost(conn.Dst)
+			if node != nil && log.NodeID != node.NodeID && !containsString(node.Addresses, src) && !containsString(node.Addresses, dst) {
+				continue
+			}
+			counted = true
+			summary.Connections++
+
+			talker := talkers[log.NodeID]
+			if talker == nil {
+				name := nodeNames[log.NodeID]
+				if name == "" {
+					name = log.NodeID
+				}
+				talker = &flowTalker{Node: name, NodeID: log.NodeID}
+				talkers[log.NodeID] = talker
+			}
+			talker.TxBytes += int64(conn.TxBytes)
+			talker.RxBytes += int64(conn.RxBytes)
+
+			key := [2]string{src, dst}
+			if dst < src {
+				key = [2]string{dst, src}
+			}
+			if pairs[key] == nil {
+				pairs[key] = map[string]int64{}
+			}
+			pairs[key][log.NodeID] += int64(conn.TxBytes + conn.RxBytes)
+		}
+		if counted {
+			summary.Logs++
+		}
+	}
+
+	for _, t := range talkers {
+		summary.Talkers = append(summary.Talkers, *t)
+	}
+	sort.Slice(summary.Talkers, func(i, j int) bool {
+		a, b := summary.Talkers[i], summary.Talkers[j]
+		if a.TxBytes+a.RxBytes != b.TxBytes+b.RxBytes {
+			return a.TxBytes+a.RxBytes > b.TxBytes+b.RxBytes
+		}
+		return a.Node < b.Node
+	})
+	for key, reporters := range pairs {
+		var bytes int64
+		for _, n := range reporters {
+			bytes = max(bytes, n)
+		}
+		summary.Pairs = append(summary.Pairs, flowPair{A: key[0], B: key[1], Bytes: bytes})
+	}
+	sort.Slice(summary.Pairs, func(i, j int) bool {
+		a, b := summary.Pairs[i], summary.Pairs[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.A+a.B < b.A+b.B
+	})
+
+	if len(summary.Talkers) > top {
+		summary.Talkers = summary.Talkers[:top]
+	}
+	if len(summary.Pairs) > top {
+		summary.Pairs = summary.Pairs[:top]
+	}
+	return summary
+}
+
+// flowHost strips the port from a flow log address
+func flowHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// flowNames maps device addresses to hostnames
+func flowNames(devices []tailscale.Device) map[string]string {
+	names := map[string]string{}
+	for _, d := range devices {
+		for _, addr := range d.Addresses {
+			names[addr] = d.Hostname
+		}
+	}
+	return names
+}
+
+func flowLabel(names map[string]string, addr string) string {
+	if name, ok := names[addr]; ok {
+		return fmt.Sprintf("%s (%s)", name, addr)
+	}
+	return addr
+}
@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/diff"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// RegisterGrantMigrationTools registers tools for moving a policy from acls to grants
+func RegisterGrantMigrationTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_migrate_to_grants",
+			Description: "Convert the policy's acls rules into equivalent grants in a draft, keeping comments. Lists rules that need manual attention (non-accept actions, legacy syntax, unparseable destinations) and validates the draft. Shows a diff by default; set dry_run=false to save it.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the converted policy diff and validation result (default: true)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				DryRun *bool `json:"dry_run"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			dryRun := params.DryRun == nil || *params.DryRun
+
+			acl, err := api.GetACL(ctx)
+			if err != nil {
+				return errorResult("Error getting ACL", err), nil
+			}
+
+			policy, err := tailscale.ParseACL(acl)
+			if err != nil {
+				return errorResult("Error parsing ACL", err), nil
+			}
+
+			draft := policy.Clone()
+			migration, err := draft.MigrateACLsToGrants()
+			if err != nil {
+				return errorResult("Cannot convert acls", err), nil
+			}
+
+			var result strings.Builder
+			if dryRun {
+				result.WriteString("Dry run: migrate acls to grants\n\n")
+			} else {
+				result.WriteString("Migrating acls to grants\n\n")
+			}
+
+			if migration.Converted == 0 && len(migration.Manual) == 0 {
+				result.WriteString("The policy has no acls rules. Nothing to do.\n")
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: result.String()},
+					},
+				}, nil
+			}
+
+			result.WriteString(fmt.Sprintf("Converted %d acls rule(s) into %d grant(s)\n", migration.Converted, migration.Grants))
+			if len(migration.Manual) > 0 {
+				result.WriteString(fmt.Sprintf("\n⚠ %d rule(s) left in acls need manual attention:\n", len(migration.Manual)))
+				for _, item := range migration.Manual {
+					result.WriteString(fmt.Sprintf("  • %s\n", item))
+				}
+			}
+
+			if migration.Converted == 0 {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: result.String()},
+					},
+				}, nil
+			}
+
+			if policyDiff := diff.Unified("policy.hujson (current)", "policy.hujson (grants)", policy.String(), draft.String()); policyDiff != "" {
+				result.WriteString("\nPolicy diff:\n")
+				result.WriteString(policyDiff)
+			}
+
+			if err := api.ValidateACL(ctx, draft.ACL()); err != nil {
+				result.WriteString(fmt.Sprintf("\n✗ Draft failed validation, nothing was changed: %v\n", err))
+				return reportErrorResult(result.String(), err), nil
+			}
+			result.WriteString("\n✓ Draft passed validation\n")
+
+			if dryRun {
+				result.WriteString("\nRe-run with dry_run=false to save the converted policy.\n")
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: result.String()},
+					},
+				}, nil
+			}
+
+			if _, err := api.SetACL(ctx, draft.ACL()); err != nil {
+				result.WriteString(fmt.Sprintf("\n✗ Error saving ACL: %v\n", err))
+				return reportErrorResult(result.String(), err), nil
+			}
+			result.WriteString("✓ Saved the converted policy\n")
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: result.String()},
+				},
+			}, nil
+		}),
+	)
+}
@@ -0,0 +1,268 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const (
+	defaultHealthPeerSample = 5
+	// magicDNSAddr is the Tailscale-provided DNS resolver every node runs
+	magicDNSAddr    = "100.100.100.100:53"
+	dnsCheckTimeout = 5 * time.Second
+)
+
+// healthCheckResult is one check of the deep health report. Status is pass,
+// warn or fail; a warning earns half the check's weight.
+type healthCheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Weight int    `json:"weight"`
+}
+
+// healthScore is the weighted share of checks passed, out of 100
+func healthScore(checks []healthCheckResult) int {
+	total, earned := 0, 0
+	for _, c := range checks {
+		total += c.Weight * 2
+		switch c.Status {
+		case "pass":
+			earned += c.Weight * 2
+		case "warn":
+			earned += c.Weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return earned * 100 / total
+}
+
+// healthMark is the marker used for a check status in text output
+func healthMark(status string) string {
+	switch status {
+	case "pass":
+		return "✓"
+	case "warn":
+		return "⚠"
+	}
+	return "✗"
+}
+
+// runDeepHealthChecks runs the checks health_check adds with deep: true:
+// the basic connection state, netcheck, DERP, key expiry, MagicDNS and pings
+// to a sample of online peers. The three slow stages (netcheck and DERP,
+// MagicDNS, peer pings) are reported as progress steps.
+func runDeepHealthChecks(ctx context.Context, cli *tailscale.CLI, status *tailscale.Status, sampleSize int, progress *progressReporter) []healthCheckResult {
+	var checks []healthCheckResult
+
+	connection := healthCheckResult{Name: "connection", Weight: 25, Status: "pass", Detail: "connected and running"}
+	switch {
+	case status.BackendState != "Running":
+		connection.Status, connection.Detail = "fail", fmt.Sprintf("backend state is %s", status.BackendState)
+	case len(status.Health) > 0:
+		connection.Status, connection.Detail = "warn", fmt.Sprintf("%d health warning(s): %s", len(status.Health), strings.Join(status.Health, "; "))
+	}
+	checks = append(checks, connection)
+
+	checks = append(checks, checkKeyExpiry(status))
+
+	report, err := cli.Netcheck(ctx)
+	if err != nil {
+		checks = append(checks,
+			healthCheckResult{Name: "netcheck", Weight: 15, Status: "fail", Detail: fmt.Sprintf("netcheck failed: %v", err)},
+			healthCheckResult{Name: "derp", Weight: 15, Status: "fail", Detail: "not checked: netcheck failed"},
+		)
+	} else {
+		checks = append(checks, checkNetcheck(report), checkDERP(ctx, cli, report))
+	}
+	progress.step(ctx, "checked netcheck and DERP")
+
+	checks = append(checks, checkMagicDNS(ctx, status))
+	progress.step(ctx, "checked MagicDNS")
+
+	checks = append(checks, checkPeerSample(ctx, cli, status, sampleSize))
+	progress.step(ctx, "pinged sample peers")
+	return checks
+}
+
+// checkKeyExpiry fails an expired node key and warns about one expiring soon
+func checkKeyExpiry(status *tailscale.Status) healthCheckResult {
+	check := healthCheckResult{Name: "key_expiry", Weight: 15, Status: "pass"}
+	self := status.Self
+	switch {
+	case self == nil:
+		check.Status, check.Detail = "fail", "no node information"
+	case self.Expired:
+		check.Status, check.Detail = "fail", "this node's key has expired; log in again"
+	case self.KeyExpiry.IsZero():
+		check.Detail = "key expiry is disabled for this node"
+	case time.Until(self.KeyExpiry) < keyExpiryWarning:
+		check.Status, check.Detail = "warn", fmt.Sprintf("this node's key expires %s (in %s)", self.KeyExpiry.Local().Format("2006-01-02 15:04"), time.Until(self.KeyExpiry).Round(time.Hour))
+	default:
+		check.Detail = fmt.Sprintf("this node's key expires %s", self.KeyExpiry.Local().Format("2006-01-02"))
+	}
+
+	var expiring []string
+	for _, peer := range status.Peer {
+		if peer != nil && !peer.KeyExpiry.IsZero() && time.Until(peer.KeyExpiry) < keyExpiryWarning {
+			expiring = append(expiring, peerHost(peer))
+		}
+	}
+	if len(expiring) > 0 {
+		sort.Strings(expiring)
+		if check.Status == "pass" {
+			check.Status = "warn"
+		}
+		check.Detail += fmt.Sprintf("; peer keys expired or expiring within 7 days: %s", strings.Join(expiring, ", "))
+	}
+	return check
+}
+
+// checkNetcheck fails when UDP is blocked and warns behind a hard NAT
+func checkNetcheck(report *tailscale.NetcheckReport) healthCheckResult {
+	check := healthCheckResult{Name: "netcheck", Weight: 15, Status: "pass", Detail: "UDP works"}
+	switch {
+	case !report.UDP:
+		check.Status, check.Detail = "fail", "UDP is blocked, so every connection is relayed through DERP"
+	case report.HardNAT() && !report.PortMapping():
+		check.Status, check.Detail = "warn", "behind a hard NAT without port mapping; direct connections to other hard-NAT peers will be relayed"
+	case report.HardNAT():
+		check.Detail = "UDP works; behind a hard NAT, but port mapping is available"
+	}
+	return check
+}
+
+// checkDERP fails when no DERP region is reachable and warns about a slow
+// preferred region
+func checkDERP(ctx context.Context, cli *tailscale.CLI, report *tailscale.NetcheckReport) healthCheckResult {
+	check := healthCheckResult{Name: "derp", Weight: 15}
+	derpMap, _ := cli.DERPMap(ctx)
+	regions := buildDERPRegions(report, derpMap)
+
+	var preferred *derpRegionLatency
+	reachable := 0
+	for i := range regions {
+		if regions[i].Reachable {
+			reachable++
+		}
+		if regions[i].Preferred {
+			preferred = &regions[i]
+		}
+	}
+	switch {
+	case reachable == 0 || preferred == nil:
+		check.Status, check.Detail = "fail", "no DERP region is reachable, so relayed connections will fail"
+	case !preferred.Reachable:
+		check.Status, check.Detail = "warn", fmt.Sprintf("the preferred region %s didn't answer", preferred.Code)
+	case preferred.LatencyMs > 150:
+		check.Status, check.Detail = "warn", fmt.Sprintf("the preferred region %s is slow (%.1f ms)", preferred.Code, preferred.LatencyMs)
+	default:
+		check.Status, check.Detail = "pass", fmt.Sprintf("preferred region %s at %.1f ms, %d of %d regions reachable", preferred.Code, preferred.LatencyMs, reachable, len(regions))
+	}
+	return check
+}
+
+// checkMagicDNS resolves this node's MagicDNS name through 100.100.100.100
+// and the system resolver. The former working and the latter not means the
+// OS isn't using the tailnet DNS settings.
+func checkMagicDNS(ctx context.Context, status *tailscale.Status) healthCheckResult {
+	check := healthCheckResult{Name: "magicdns", Weight: 15}
+	if status.CurrentTailnet == nil || !status.CurrentTailnet.MagicDNSEnabled {
+		check.Status, check.Detail = "warn", "MagicDNS is disabled for the tailnet"
+		return check
+	}
+	if status.Self == nil || status.Self.DNSName == "" {
+		check.Status, check.Detail = "fail", "this node has no MagicDNS name"
+		return check
+	}
+	name := status.Self.DNSName
+
+	ctx, cancel := context.WithTimeout(ctx, dnsCheckTimeout)
+	defer cancel()
+	quad100 := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, magicDNSAddr)
+		},
+	}
+	direct, directErr := quad100.LookupHost(ctx, name)
+	system, systemErr := net.DefaultResolver.LookupHost(ctx, name)
+
+	matches := func(addrs []string) bool {
+		for _, addr := range addrs {
+			if containsString(status.Self.TailscaleIPs, addr) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case directErr != nil:
+		check.Status, check.Detail = "fail", fmt.Sprintf("100.100.100.100 couldn't resolve %s: %v", name, directErr)
+	case !matches(direct):
+		check.Status, check.Detail = "fail", fmt.Sprintf("100.100.100.100 resolved %s to %s, not this node's Tailscale IPs", name, strings.Join(direct, ", "))
+	case systemErr != nil || !matches(system):
+		check.Status, check.Detail = "warn", fmt.Sprintf("MagicDNS works, but the system resolver doesn't resolve %s; check accept_dns and the OS DNS configuration", name)
+	default:
+		check.Status, check.Detail = "pass", fmt.Sprintf("%s resolves to %s", strings.TrimSuffix(name, "."), strings.Join(system, ", "))
+	}
+	return check
+}
+
+// checkPeerSample pings up to sampleSize online peers, preferring ones with
+// recent traffic, and fails if none answer
+func checkPeerSample(ctx context.Context, cli *tailscale.CLI, status *tailscale.Status, sampleSize int) healthCheckResult {
+	check := healthCheckResult{Name: "peer_connectivity", Weight: 15}
+	var online []*tailscale.PeerStatus
+	for _, peer := range status.Peer {
+		if peer != nil && peer.Online && len(peer.TailscaleIPs) > 0 {
+			online = append(online, peer)
+		}
+	}
+	if len(online) == 0 {
+		check.Status, check.Detail = "warn", "no online peers to test"
+		return check
+	}
+	sort.Slice(online, func(i, j int) bool {
+		if online[i].Active != online[j].Active {
+			return online[i].Active
+		}
+		return peerHost(online[i]) < peerHost(online[j])
+	})
+	if len(online) > sampleSize {
+		online = online[:sampleSize]
+	}
+
+	rows := measureLatency(ctx, cli, online, 1, len(online), nil)
+	var unreachable []string
+	relayed := 0
+	for _, row := range rows {
+		switch row.Ping.Path {
+		case "none":
+			unreachable = append(unreachable, row.Peer)
+		case "derp":
+			relayed++
+		}
+	}
+	reached := len(rows) - len(unreachable)
+	check.Detail = fmt.Sprintf("%d of %d sampled peers answered (%d direct, %d relayed)", reached, len(rows), reached-relayed, relayed)
+	switch {
+	case reached == 0:
+		check.Status = "fail"
+	case len(unreachable) > 0:
+		check.Status = "warn"
+		check.Detail += fmt.Sprintf("; no reply from %s", strings.Join(unreachable, ", "))
+	default:
+		check.Status = "pass"
+	}
+	return check
+}
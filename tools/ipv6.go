@@ -0,0 +1,294 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// familySupport summarizes which address families a device has
+type familySupport struct {
+	name         string
+	tailscaleV4  bool
+	tailscaleV6  bool
+	endpointV4   bool
+	endpointV6   bool
+	currentPath  string
+	online       bool
+	routesV4     int
+	routesV6     int
+	hasEndpoints bool
+}
+
+// ipv6Report is the structured result of ipv6_report. Local is omitted when
+// netcheck failed.
+type ipv6Report struct {
+	Local         *ipv6Local   `json:"local,omitempty"`
+	NetcheckError string       `json:"netcheck_error,omitempty"`
+	Devices       []ipv6Device `json:"devices"`
+	Warnings      []string     `json:"warnings"`
+}
+
+// ipv6Local is the local network's address family support from netcheck
+type ipv6Local struct {
+	UDP       bool   `json:"udp"`
+	IPv4      bool   `json:"ipv4"`
+	IPv6      bool   `json:"ipv6"`
+	GlobalV4  string `json:"global_v4,omitempty"`
+	GlobalV6  string `json:"global_v6,omitempty"`
+	OSHasIPv6 bool   `json:"os_has_ipv6"`
+}
+
+// ipv6Device is the structured form of familySupport
+type ipv6Device struct {
+	Name        string `json:"name"`
+	Online      bool   `json:"online"`
+	Tailnet     string `json:"tailnet"`
+	Endpoints   string `json:"endpoints,omitempty"`
+	CurrentPath string `json:"current_path,omitempty"`
+	RoutesV4    int    `json:"routes_v4"`
+	RoutesV6    int    `json:"routes_v6"`
+}
+
+func (d familySupport) info() ipv6Device {
+	info := ipv6Device{
+		Name:        d.name,
+		Online:      d.online,
+		Tailnet:     familyLabel(d.tailscaleV4, d.tailscaleV6),
+		CurrentPath: d.currentPath,
+		RoutesV4:    d.routesV4,
+		RoutesV6:    d.routesV6,
+	}
+	if d.hasEndpoints {
+		info.Endpoints = familyLabel(d.endpointV4, d.endpointV6)
+	}
+	return info
+}
+
+// RegisterIPv6Tools registers IPv6 and dual-stack diagnostic tools
+func RegisterIPv6Tools(server *mcp.Server, cli *tailscale.CLI, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "ipv6_report",
+			Description: "Report IPv4/IPv6 support across the tailnet: whether the local network has working IPv6 (netcheck), which families each peer has Tailscale addresses, endpoints and subnet routes in, and peers or routes only reachable over one family",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			status, err := cli.Status(ctx)
+			if err != nil {
+				return errorResult("Error getting status", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString("=== IPv6 / Dual-Stack Report ===\n\n")
+
+			// Local network support
+			out := ipv6Report{Devices: []ipv6Device{}, Warnings: []string{}}
+			report, netcheckErr := cli.Netcheck(ctx)
+			result.WriteString("Local network:\n")
+			if netcheckErr != nil {
+				result.WriteString(fmt.Sprintf("  ⚠ netcheck failed: %v\n", netcheckErr))
+				out.NetcheckError = netcheckErr.Error()
+			} else {
+				out.Local = &ipv6Local{
+					UDP:       report.UDP,
+					IPv4:      report.IPv4,
+					IPv6:      report.IPv6,
+					GlobalV4:  report.GlobalV4,
+					GlobalV6:  report.GlobalV6,
+					OSHasIPv6: report.OSHasIPv6,
+				}
+				result.WriteString(fmt.Sprintf("  %s IPv4 %s\n", checkMark(report.IPv4), describeGlobal(report.IPv4, report.GlobalV4)))
+				result.WriteString(fmt.Sprintf("  %s IPv6 %s\n", checkMark(report.IPv6), describeGlobal(report.IPv6, report.GlobalV6)))
+				if !report.IPv6 {
+					switch {
+					case !report.OSHasIPv6:
+						result.WriteString("    The OS has no IPv6 support or it is disabled\n")
+					case report.IPv6CanSend:
+						result.WriteString("    IPv6 packets can be sent but no replies came back (firewall or broken upstream IPv6)\n")
+					default:
+						result.WriteString("    No global IPv6 connectivity on this network\n")
+					}
+				}
+				if !report.UDP {
+					result.WriteString("  ✗ UDP is blocked, all connections will be relayed over DERP\n")
+				}
+			}
+
+			devices := collectFamilySupport(ctx, status, api)
+
+			result.WriteString(fmt.Sprintf("\nDevices (%d):\n", len(devices)))
+			var warnings []string
+			localV6 := netcheckErr == nil && report.IPv6
+			localV4 := netcheckErr != nil || report.IPv4
+
+			for _, d := range devices {
+				line := fmt.Sprintf("  • %s: tailnet %s", d.name, familyLabel(d.tailscaleV4, d.tailscaleV6))
+				if d.hasEndpoints {
+					line += fmt.Sprintf(", endpoints %s", familyLabel(d.endpointV4, d.endpointV6))
+				}
+				if d.currentPath != "" {
+					line += fmt.Sprintf(", path %s", d.currentPath)
+				}
+				if d.routesV4+d.routesV6 > 0 {
+					line += fmt.Sprintf(", routes %d v4/%d v6", d.routesV4, d.routesV6)
+				}
+				if !d.online {
+					line += " (offline)"
+				}
+				result.WriteString(line + "\n")
+				out.Devices = append(out.Devices, d.info())
+
+				if d.tailscaleV4 && !d.tailscaleV6 {
+					warnings = append(warnings, fmt.Sprintf("%s has no Tailscale IPv6 address (IPv6 may be disabled by a node attribute)", d.name))
+				}
+				if d.online && d.hasEndpoints {
+					if d.endpointV6 && !d.endpointV4 && !localV6 {
+						warnings = append(warnings, fmt.Sprintf("%s is only reachable over IPv6 but this network has no IPv6, so traffic is relayed", d.name))
+					}
+					if d.endpointV4 && !d.endpointV6 && !localV4 {
+						warnings = append(warnings, fmt.Sprintf("%s is only reachable over IPv4 but this network has no IPv4, so traffic is relayed", d.name))
+					}
+				}
+				if d.routesV4 > 0 && d.routesV6 == 0 {
+					warnings = append(warnings, fmt.Sprintf("%s routes IPv4 subnets only; IPv6-only clients can't reach them", d.name))
+				} else if d.routesV6 > 0 && d.routesV4 == 0 {
+					warnings = append(warnings, fmt.Sprintf("%s routes IPv6 subnets only; IPv4-only clients can't reach them", d.name))
+				}
+			}
+
+			if len(warnings) > 0 {
+				out.Warnings = warnings
+				result.WriteString("\nFamily asymmetries:\n")
+				for _, w := range warnings {
+					result.WriteString(fmt.Sprintf("  ⚠ %s\n", w))
+				}
+			} else {
+				result.WriteString("\n✓ No IPv4/IPv6 asymmetries found\n")
+			}
+
+			return structuredResult(result.String(), out), nil
+		}),
+	)
+}
+
+// collectFamilySupport combines the local status (endpoints and paths) with the
+// API device list, which also covers devices this node can't see
+func collectFamilySupport(ctx context.Context, status *tailscale.Status, api *tailscale.APIClient) []familySupport {
+	byName := map[string]*familySupport{}
+
+	for _, peer := range status.Peer {
+		if peer == nil {
+			continue
+		}
+		d := &familySupport{name: peer.HostName, online: peer.Online}
+		for _, ip := range peer.TailscaleIPs {
+			addAddrFamily(ip, &d.tailscaleV4, &d.tailscaleV6)
+		}
+		for _, ep := range peer.Addrs {
+			if addrPort, err := netip.ParseAddrPort(ep); err == nil {
+				d.hasEndpoints = true
+				if addrPort.Addr().Unmap().Is4() {
+					d.endpointV4 = true
+				} else {
+					d.endpointV6 = true
+				}
+			}
+		}
+		switch {
+		case peer.CurAddr != "":
+			if addrPort, err := netip.ParseAddrPort(peer.CurAddr); err == nil && addrPort.Addr().Unmap().Is6() {
+				d.currentPath = "direct IPv6"
+			} else {
+				d.currentPath = "direct IPv4"
+			}
+		case peer.Active:
+			d.currentPath = "relayed"
+		}
+		for _, route := range peer.PrimaryRoutes {
+			if prefix, err := netip.ParsePrefix(route); err == nil {
+				if prefix.Addr().Is4() {
+					d.routesV4++
+				} else {
+					d.routesV6++
+				}
+			}
+		}
+		byName[strings.ToLower(peer.HostName)] = d
+	}
+
+	if api != nil && api.IsAvailable() {
+		if devices, err := api.ListDevices(ctx); err == nil {
+			for _, dev := range devices {
+				key := strings.ToLower(dev.Hostname)
+				d, ok := byName[key]
+				if !ok {
+					// Not visible to this node, so only its addresses are known
+					d = &familySupport{name: dev.Hostname, online: dev.Online}
+					byName[key] = d
+				}
+				for _, ip := range dev.Addresses {
+					addAddrFamily(ip, &d.tailscaleV4, &d.tailscaleV6)
+				}
+			}
+		}
+	}
+
+	devices := make([]familySupport, 0, len(byName))
+	for _, d := range byName {
+		devices = append(devices, *d)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].name < devices[j].name
+	})
+	return devices
+}
+
+func addAddrFamily(ip string, v4, v6 *bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return
+	}
+	if addr.Unmap().Is4() {
+		*v4 = true
+	} else {
+		*v6 = true
+	}
+}
+
+func familyLabel(v4, v6 bool) string {
+	switch {
+	case v4 && v6:
+		return "dual-stack"
+	case v4:
+		return "IPv4 only"
+	case v6:
+		return "IPv6 only"
+	default:
+		return "none"
+	}
+}
+
+func checkMark(ok bool) string {
+	if ok {
+		return "✓"
+	}
+	return "✗"
+}
+
+func describeGlobal(ok bool, addr string) string {
+	if !ok {
+		return "unavailable"
+	}
+	if addr == "" {
+		return "available"
+	}
+	return fmt.Sprintf("available (public address %s)", addr)
+}
@@ -0,0 +1,267 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const defaultKeyExpiryDays = 30
+
+// expiringKey is a device in the key expiry report
+type expiringKey struct {
+	DeviceID string    `json:"device_id"`
+	Name     string    `json:"name"`
+	User     string    `json:"user"`
+	Tags     []string  `json:"tags,omitempty"`
+	Expires  time.Time `json:"expires"`
+	Expired  bool      `json:"expired"`
+	DaysLeft int       `json:"days_left"`
+}
+
+// expiringKeys returns the devices whose keys expire before cutoff (or have
+// already expired, if includeExpired), soonest first
+func expiringKeys(devices []tailscale.Device, now, cutoff time.Time, includeExpired bool) []expiringKey {
+	var keys []expiringKey
+	for _, d := range devices {
+		if d.KeyExpiryDisabled || d.KeyExpiry.IsZero() || d.KeyExpiry.After(cutoff) {
+			continue
+		}
+		expired := !d.KeyExpiry.After(now)
+		if expired && !includeExpired {
+			continue
+		}
+		keys = append(keys, expiringKey{
+			DeviceID: d.ID,
+			Name:     strings.SplitN(d.Name, ".", 2)[0],
+			User:     d.User,
+			Tags:     d.Tags,
+			Expires:  d.KeyExpiry,
+			Expired:  expired,
+			DaysLeft: int(d.KeyExpiry.Sub(now).Hours() / 24),
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Expires.Before(keys[j].Expires) })
+	return keys
+}
+
+// groupExpiringKeys groups the report by owner or by tag. Tagged devices are
+// grouped under each of their tags, untagged ones under "(untagged)".
+func groupExpiringKeys(keys []expiringKey, by string) ([]string, map[string][]expiringKey) {
+	groups := map[string][]expiringKey{}
+	for _, k := range keys {
+		if by == "tag" {
+			if len(k.Tags) == 0 {
+				groups["(untagged)"] = append(groups["(untagged)"], k)
+			}
+			for _, tag := range k.Tags {
+				groups[tag] = append(groups[tag], k)
+			}
+			continue
+		}
+		owner := k.User
+		if owner == "" {
+			owner = "(unknown user)"
+		}
+		groups[owner] = append(groups[owner], k)
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, groups
+}
+
+// describeExpiry says when a key expires relative to now
+func describeExpiry(k expiringKey) string {
+	if k.Expired {
+		return fmt.Sprintf("expired %s", k.Expires.Local().Format("2006-01-02"))
+	}
+	return fmt.Sprintf("expires %s (%d days)", k.Expires.Local().Format("2006-01-02"), k.DaysLeft)
+}
+
+// formatKeyExpiryReport renders the grouped report as Markdown, for sharing
+func formatKeyExpiryReport(names []string, groups map[string][]expiringKey, total, days int, by string, now time.Time) string {
+	var b strings.Builder
+	b.WriteString("# Tailscale Key Expiry Report\n\n")
+	b.WriteString(fmt.Sprintf("Generated %s. %d device(s) with node keys expiring within %d days, by %s.\n", now.Local().Format("2006-01-02 15:04"), total, days, by))
+	for _, name := range names {
+		b.WriteString(fmt.Sprintf("\n## %s\n\n", name))
+		b.WriteString("| Device | Expires | Days left |\n|---|---|---|\n")
+		for _, k := range groups[name] {
+			left := fmt.Sprintf("%d", k.DaysLeft)
+			if k.Expired {
+				left = "expired"
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %s |\n", k.Name, k.Expires.Local().Format("2006-01-02"), left))
+		}
+	}
+	return b.String()
+}
+
+// RegisterKeyExpiryTools registers the tailnet-wide key expiry report
+func RegisterKeyExpiryTools(server *mcp.Server, api *tailscale.APIClient, output *OutputWriter) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "key_expiry_report",
+			Description: "List devices whose node keys expire within a number of days (or have expired), grouped by user or tag. Optionally saves the list as a Markdown report, or disables key expiry on the listed devices (dry run by default).",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"days": {
+						Type:        "integer",
+						Description: fmt.Sprintf("Report keys expiring within this many days (default: %d)", defaultKeyExpiryDays),
+					},
+					"group_by": {
+						Type:        "string",
+						Description: "Group devices by owner or by tag (default: user)",
+						Enum:        []interface{}{"user", "tag"},
+					},
+					"include_expired": {
+						Type:        "boolean",
+						Description: "Include keys that have already expired (default: true)",
+					},
+					"report_file": {
+						Type:        "string",
+						Description: "Also save the report as Markdown to this file (must be inside the allowed directories)",
+					},
+					"disable_expiry": {
+						Type:        "boolean",
+						Description: "Disable key expiry on the reported devices, or only device_ids if given (default: false)",
+					},
+					"device_ids": {
+						Type:        "array",
+						Description: "With disable_expiry, only disable expiry on these reported devices",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "With disable_expiry, only show which devices would change (default: true)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Days           int      `json:"days"`
+				GroupBy        string   `json:"group_by"`
+				IncludeExpired *bool    `json:"include_expired"`
+				ReportFile     string   `json:"report_file"`
+				DisableExpiry  bool     `json:"disable_expiry"`
+				DeviceIDs      []string `json:"device_ids"`
+				DryRun         *bool    `json:"dry_run"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Days <= 0 {
+				params.Days = defaultKeyExpiryDays
+			}
+			if params.GroupBy == "" {
+				params.GroupBy = "user"
+			}
+			if params.GroupBy != "user" && params.GroupBy != "tag" {
+				return invalidInputResult("Invalid parameters: group_by must be user or tag"), nil
+			}
+			includeExpired := params.IncludeExpired == nil || *params.IncludeExpired
+			dryRun := params.DryRun == nil || *params.DryRun
+
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+
+			now := time.Now()
+			keys := expiringKeys(devices, now, now.AddDate(0, 0, params.Days), includeExpired)
+			names, groups := groupExpiringKeys(keys, params.GroupBy)
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Key Expiry Report: %d device(s) expiring within %d days\n", len(keys), params.Days))
+			if len(keys) == 0 {
+				result.WriteString("\n✓ No node keys expire in that window.\n")
+			}
+			for _, name := range names {
+				result.WriteString(fmt.Sprintf("\n%s (%d):\n", name, len(groups[name])))
+				for _, k := range groups[name] {
+					mark := "⚠"
+					if k.Expired {
+						mark = "✗"
+					}
+					result.WriteString(fmt.Sprintf("  %s %s (%s): %s\n", mark, k.Name, k.DeviceID, describeExpiry(k)))
+				}
+			}
+
+			content := []mcp.Content{}
+			if params.ReportFile != "" {
+				report := formatKeyExpiryReport(names, groups, len(keys), params.Days, params.GroupBy, now)
+				link, err := output.Write(ctx, req.Session, params.ReportFile, []byte(report), "text/markdown")
+				if err != nil {
+					result.WriteString(fmt.Sprintf("\n✗ Could not save the report: %v\n", err))
+				} else {
+					result.WriteString(fmt.Sprintf("\nReport saved to %s\n", link.URI))
+					content = append(content, link)
+				}
+			}
+
+			data := map[string]interface{}{"devices": keys}
+			if params.DisableExpiry {
+				var targets []tailscale.Device
+				var missing []string
+				for _, k := range keys {
+					if len(params.DeviceIDs) == 0 || containsString(params.DeviceIDs, k.DeviceID) {
+						targets = append(targets, tailscale.Device{ID: k.DeviceID, Hostname: k.Name})
+					}
+				}
+				for _, id := range params.DeviceIDs {
+					found := false
+					for _, k := range keys {
+						if k.DeviceID == id {
+							found = true
+						}
+					}
+					if !found {
+						missing = append(missing, id)
+					}
+				}
+
+				switch {
+				case len(targets) == 0:
+					result.WriteString("\nNo reported devices to disable key expiry on.\n")
+				case dryRun:
+					result.WriteString(fmt.Sprintf("\nDry run: would disable key expiry on %d device(s). Set dry_run=false to apply.\n", len(targets)))
+				default:
+					result.WriteString(fmt.Sprintf("\nDisabling key expiry on %d device(s):\n", len(targets)))
+					results := runBulk(ctx, targets, defaultBulkConcurrency, newProgress(req, len(targets)), func(ctx context.Context, d tailscale.Device) error {
+						return api.SetDeviceKeyExpiry(ctx, d.ID, true)
+					})
+					failed := writeBulkResults(&result, results)
+					result.WriteString(fmt.Sprintf("Disabled on %d of %d device(s). Devices whose keys already expired must still log in again.\n", len(results)-failed, len(results)))
+					data["disabled"] = results
+				}
+				if len(missing) > 0 {
+					result.WriteString(fmt.Sprintf("⚠ Not in the report, skipped: %s\n", strings.Join(missing, ", ")))
+				}
+			}
+
+			return &mcp.CallToolResult{
+				Content:           append([]mcp.Content{&mcp.TextContent{Text: result.String()}}, content...),
+				StructuredContent: data,
+			}, nil
+		}),
+	)
+}
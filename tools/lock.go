@@ -0,0 +1,735 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// lockKeySchema is the schema of a list of tailnet lock keys
+var lockKeySchema = &jsonschema.Schema{
+	Type:        "array",
+	Description: "Tailnet lock public keys (tlpub:...), as shown by lock_status on each signing node",
+	Items:       &jsonschema.Schema{Type: "string"},
+}
+
+// validLockKeys checks that every key is a tailnet lock public key
+func validLockKeys(keys []string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("keys needs at least one entry")
+	}
+	for _, key := range keys {
+		if !tailscale.ValidLockKey(key) {
+			return fmt.Errorf("%q is not a tailnet lock key (tlpub:...)", key)
+		}
+	}
+	return nil
+}
+
+// maskSecret hides all but the last four characters of a disablement secret
+func maskSecret(secret string) string {
+	value := strings.TrimPrefix(secret, "disablement-secret:")
+	if len(value) <= 4 {
+		return "disablement-secret:****"
+	}
+	return "disablement-secret:…" + value[len(value)-4:]
+}
+
+// lockPeerMatches reports whether node is the peer's name, MagicDNS name or
+// Tailscale IP
+func lockPeerMatches(peer tailscale.LockPeer, node string) bool {
+	short, _, _ := strings.Cut(peer.Name, ".")
+	return strings.EqualFold(short, node) || strings.EqualFold(strings.TrimSuffix(peer.Name, "."), node) || containsString(peer.TailscaleIPs, node)
+}
+
+// resolveLockNode finds the node key of a node to sign: a node key as given,
+// or the node key of a peer by name or Tailscale IP. Peers hidden by tailnet
+// lock are only in the lock status, so those are checked first.
+func resolveLockNode(ctx context.Context, cli *tailscale.CLI, lock *tailscale.LockStatus, node string) (string, string, error) {
+	if tailscale.ValidNodeKey(node) {
+		return node, node, nil
+	}
+	for _, peer := range lock.FilteredPeers {
+		if lockPeerMatches(peer, node) {
+			short, _, _ := strings.Cut(peer.Name, ".")
+			return peer.NodeKey, short, nil
+		}
+	}
+	status, err := cli.Status(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("error getting status: %w", err)
+	}
+	if peer := findPeerByHost(status, node); peer != nil {
+		return peer.PublicKey, peer.HostName, nil
+	}
+	for _, peer := range status.Peer {
+		if peer != nil && containsString(peer.TailscaleIPs, node) {
+			return peer.PublicKey, peer.HostName, nil
+		}
+	}
+	return "", "", fmt.Errorf("no node named %q found; pass its node key (nodekey:...) instead", node)
+}
+
+// RegisterTailnetLockTools registers tools for operating tailnet lock, which
+// requires every node key to be signed by a trusted key held on a signing node.
+// Without revealSecrets results have secrets redacted, so lock_init needs a
+// secrets_file to keep the disablement secrets.
+func RegisterTailnetLockTools(server *mcp.Server, cli *tailscale.CLI, output *OutputWriter, revealSecrets bool) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "lock_status",
+			Description: "Show whether tailnet lock is enabled, this node's tailnet lock key and signature, the trusted signing keys, and peers hidden because their node key isn't signed",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			lock, err := cli.LockStatus(ctx)
+			if err != nil {
+				return errorResult("Error getting tailnet lock status", err), nil
+			}
+
+			var result strings.Builder
+			if lock.Enabled {
+				result.WriteString("Tailnet lock: enabled\n")
+			} else {
+				result.WriteString("Tailnet lock: not enabled\n")
+			}
+			result.WriteString(fmt.Sprintf("This node's lock key: %s\n", lock.PublicKey))
+			trusted := false
+			for _, key := range lock.TrustedKeys {
+				if key.Key == lock.PublicKey {
+					trusted = true
+				}
+			}
+			if lock.Enabled {
+				if trusted {
+					result.WriteString("  ✓ Trusted: this node can sign other nodes\n")
+				} else {
+					result.WriteString("  This key isn't trusted, so this node can't sign other nodes\n")
+				}
+				if lock.NodeKeySigned {
+					result.WriteString(fmt.Sprintf("✓ This node's key (%s) is signed\n", lock.NodeKey))
+				} else {
+					result.WriteString(fmt.Sprintf("✗ This node's key (%s) isn't signed; it is locked out until a signing node runs lock_sign for it\n", lock.NodeKey))
+				}
+
+				result.WriteString(fmt.Sprintf("\nTrusted Keys (%d):\n", len(lock.TrustedKeys)))
+				for _, key := range lock.TrustedKeys {
+					line := fmt.Sprintf("  %s (votes: %d)", key.Key, key.Votes)
+					if key.Key == lock.PublicKey {
+						line += " [this node]"
+					}
+					result.WriteString(line + "\n")
+				}
+
+				if len(lock.FilteredPeers) > 0 {
+					result.WriteString(fmt.Sprintf("\n⚠ Locked-out Peers (%d):\n", len(lock.FilteredPeers)))
+					for _, peer := range lock.FilteredPeers {
+						result.WriteString(fmt.Sprintf("  %s %s (%s)\n", peer.Name, strings.Join(peer.TailscaleIPs, ", "), peer.NodeKey))
+					}
+					result.WriteString("Sign them from a trusted node with lock_sign.\n")
+				}
+			} else {
+				result.WriteString("\nEnable it with lock_init, passing the lock keys of the nodes that should be able to sign.\n")
+			}
+
+			return structuredResult(result.String(), lock), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "lock_sign",
+			Description: "Sign a node's key with this node's tailnet lock key so it can join a locked tailnet. This node's key must be trusted.",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"node": {
+						Type:        "string",
+						Description: "Node to sign: its node key (nodekey:...), hostname or Tailscale IP",
+					},
+					"node_key": {
+						Type:        "string",
+						Description: "Node key to sign; same as node, kept for compatibility",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Node    string `json:"node"`
+				NodeKey string `json:"node_key"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if params.Node == "" {
+				params.Node = params.NodeKey
+			}
+			if params.Node == "" {
+				return invalidInputResult("Invalid parameters: node is required"), nil
+			}
+
+			lock, err := cli.LockStatus(ctx)
+			if err != nil {
+				return errorResult("Error getting tailnet lock status", err), nil
+			}
+			if !lock.Enabled {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: "Tailnet lock is not enabled, so node keys don't need signing."},
+					},
+				}, nil
+			}
+
+			nodeKey, name, err := resolveLockNode(ctx, cli, lock, params.Node)
+			if err != nil {
+				return errorResult("", err), nil
+			}
+			if err := cli.LockSign(ctx, nodeKey); err != nil {
+				return errorResult(fmt.Sprintf("Failed to sign %s", name), err), nil
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("✓ Signed %s (%s)", name, nodeKey)},
+				},
+			}, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "lock_init",
+			Description: "Enable tailnet lock with the given trusted signing keys, generating disablement secrets the tailnet lock can be turned off with. The secrets are shown only once; save them with secrets_file. Shows what would happen by default; set dry_run=false to enable.",
+			Annotations: destructiveTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"keys": {
+						Type:        "array",
+						Description: "Tailnet lock keys (tlpub:...) of the nodes that may sign (default: this node's key). Include at least two nodes so losing one doesn't strand the tailnet.",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"disablements": {
+						Type:        "integer",
+						Description: "Number of disablement secrets to generate (default: 1)",
+					},
+					"support_disablement": {
+						Type:        "boolean",
+						Description: "Also generate a secret shared with Tailscale support, so they can disable tailnet lock if the other secrets are lost (default: false)",
+					},
+					"secrets_file": {
+						Type:        "string",
+						Description: "Save the disablement secrets to this file (relative to the client's roots or the configured output directory) (optional)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show what would happen without enabling tailnet lock (default: true)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Keys               []string `json:"keys"`
+				Disablements       int      `json:"disablements"`
+				SupportDisablement bool     `json:"support_disablement"`
+				SecretsFile        string   `json:"secrets_file"`
+				DryRun             *bool    `json:"dry_run"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Disablements == 0 {
+				params.Disablements = 1
+			}
+
+			lock, err := cli.LockStatus(ctx)
+			if err != nil {
+				return errorResult("Error getting tailnet lock status", err), nil
+			}
+			if lock.Enabled {
+				return refusedResult("Tailnet lock is already enabled. Use lock_add_keys and lock_remove_keys to change the trusted keys."), nil
+			}
+			if len(params.Keys) == 0 {
+				params.Keys = []string{lock.PublicKey}
+			}
+			err = validLockKeys(params.Keys)
+			if err == nil && params.Disablements < 1 {
+				err = fmt.Errorf("disablements must be at least 1")
+			}
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if params.SecretsFile != "" {
+				if _, err := output.Resolve(ctx, req.Session, params.SecretsFile); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			var result strings.Builder
+			if params.DryRun == nil || *params.DryRun {
+				result.WriteString("Dry run: tailnet lock would be enabled with these trusted keys:\n")
+				for _, key := range params.Keys {
+					line := "  " + key
+					if key == lock.PublicKey {
+						line += " [this node]"
+					}
+					result.WriteString(line + "\n")
+				}
+				result.WriteString(fmt.Sprintf("\n%d disablement secret(s) would be generated", params.Disablements))
+				if params.SupportDisablement {
+					result.WriteString(", plus one for Tailscale support")
+				}
+				result.WriteString(".\n")
+				if !containsString(params.Keys, lock.PublicKey) {
+					result.WriteString("⚠ This node's key isn't included, so it won't be able to sign nodes.\n")
+				}
+				if len(params.Keys) < 2 {
+					result.WriteString("⚠ Only one signing key: if that node is lost, new nodes can't be signed until lock is disabled.\n")
+				}
+				if params.SecretsFile == "" && revealSecrets {
+					result.WriteString("⚠ No secrets_file: the secrets will only be shown in the result.\n")
+				} else if params.SecretsFile == "" {
+					result.WriteString("⚠ No secrets_file: results have secrets redacted on this server, so enabling will be refused without one.\n")
+				}
+				result.WriteString("\nEvery existing node is signed when lock is enabled; nodes added later need lock_sign. Set dry_run=false to enable.\n")
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: result.String()},
+					},
+				}, nil
+			}
+
+			if params.SecretsFile == "" && !revealSecrets {
+				return refusedResult("Results have secrets redacted on this server, so the disablement secrets would be lost. Pass secrets_file to save them."), nil
+			}
+
+			out, err := cli.LockInit(ctx, params.Keys, params.Disablements, params.SupportDisablement)
+			if err != nil {
+				return errorResult("Failed to enable tailnet lock", err), nil
+			}
+			secrets := tailscale.ParseDisablementSecrets(out)
+
+			result.WriteString(fmt.Sprintf("✓ Tailnet lock enabled with %d trusted key(s)\n", len(params.Keys)))
+			content := []mcp.Content{}
+			if params.SecretsFile != "" && len(secrets) > 0 {
+				link, err := output.Write(ctx, req.Session, params.SecretsFile, []byte(strings.Join(secrets, "\n")+"\n"), "text/plain")
+				if err != nil {
+					result.WriteString(fmt.Sprintf("✗ Could not save the secrets: %v\n", err))
+				} else {
+					result.WriteString(fmt.Sprintf("Disablement secrets saved to %s (owner-only permissions)\n", link.URI))
+					content = append(content, link)
+				}
+			}
+			result.WriteString(fmt.Sprintf("\nDisablement secrets (%d) — shown only now, store them somewhere safe:\n", len(secrets)))
+			for _, secret := range secrets {
+				result.WriteString("  " + secret + "\n")
+			}
+			if len(secrets) == 0 {
+				result.WriteString(fmt.Sprintf("  ⚠ None found in the command output:\n%s\n", out))
+			}
+
+			return &mcp.CallToolResult{
+				Content: append([]mcp.Content{&mcp.TextContent{Text: result.String()}}, content...),
+			}, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "lock_add_keys",
+			Description: "Add trusted tailnet lock signing keys, letting those nodes sign other nodes. This node's key must already be trusted.",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"keys": lockKeySchema,
+				},
+				Required: []string{"keys"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Keys []string `json:"keys"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if err := validLockKeys(params.Keys); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			if err := cli.LockAdd(ctx, params.Keys); err != nil {
+				return errorResult("Failed to add keys", err), nil
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("✓ Added %d trusted key(s):\n  %s", len(params.Keys), strings.Join(params.Keys, "\n  "))},
+				},
+			}, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "lock_remove_keys",
+			Description: "Remove trusted tailnet lock signing keys. Nodes signed by a removed key are re-signed with this node's key unless re_sign=false, in which case they are locked out.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"keys": lockKeySchema,
+					"re_sign": {
+						Type:        "boolean",
+						Description: "Re-sign nodes signed by the removed keys so they keep access (default: true)",
+					},
+				},
+				Required: []string{"keys"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Keys   []string `json:"keys"`
+				ReSign *bool    `json:"re_sign"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if err := validLockKeys(params.Keys); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			reSign := params.ReSign == nil || *params.ReSign
+
+			lock, err := cli.LockStatus(ctx)
+			if err != nil {
+				return errorResult("Error getting tailnet lock status", err), nil
+			}
+			remaining := 0
+			for _, key := range lock.TrustedKeys {
+				if !containsString(params.Keys, key.Key) {
+					remaining++
+				}
+			}
+			if remaining == 0 {
+				return refusedResult("Refusing to remove every trusted key: no node could sign new nodes. Use lock_disable to turn tailnet lock off instead."), nil
+			}
+
+			if err := cli.LockRemove(ctx, params.Keys, reSign); err != nil {
+				return errorResult("Failed to remove keys", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("✓ Removed %d trusted key(s); %d remain\n", len(params.Keys), remaining))
+			if reSign {
+				result.WriteString("Nodes they had signed were re-signed with this node's key.\n")
+			} else {
+				result.WriteString("⚠ Nodes they had signed were not re-signed and are locked out until signed again.\n")
+			}
+			if containsString(params.Keys, lock.PublicKey) {
+				result.WriteString("This node's key was removed, so it can no longer sign nodes.\n")
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: result.String()},
+				},
+			}, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "lock_disable",
+			Description: "Permanently disable tailnet lock for the whole tailnet using one of the disablement secrets from lock_init. Re-enabling it means running lock_init again. Shows what would happen by default; set dry_run=false to disable.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"secret": {
+						Type:        "string",
+						Description: "A disablement secret (disablement-secret:...)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show what would happen without disabling tailnet lock (default: true)",
+					},
+				},
+				Required: []string{"secret"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Secret string `json:"secret"`
+				DryRun *bool  `json:"dry_run"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			secrets := tailscale.ParseDisablementSecrets(params.Secret)
+			if len(secrets) != 1 || secrets[0] != strings.TrimSpace(params.Secret) {
+				return invalidInputResult("Invalid parameters: secret must be a single disablement secret (disablement-secret:...)"), nil
+			}
+
+			if params.DryRun == nil || *params.DryRun {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Dry run: tailnet lock would be disabled for the whole tailnet with %s. Node keys would no longer need signing, and the trusted keys and signatures would be discarded. Set dry_run=false to disable.", maskSecret(secrets[0]))},
+					},
+				}, nil
+			}
+
+			if err := cli.LockDisable(ctx, secrets[0]); err != nil {
+				return errorResult("Failed to disable tailnet lock", err), nil
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "✓ Tailnet lock disabled. The used secret can't be used again."},
+				},
+			}, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "lock_list_disablement_secrets",
+			Description: "List the disablement secrets saved by lock_init's secrets_file. tailscaled only stores a derived value of each secret, so saved files are the only place they can be read back from. Secrets are masked unless reveal=true.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"file": {
+						Type:        "string",
+						Description: "The secrets_file given to lock_init (relative to the client's roots or the configured output directory)",
+					},
+					"reveal": {
+						Type:        "boolean",
+						Description: "Show the full secrets, if the server's reveal_secrets setting allows it (default: false)",
+					},
+				},
+				Required: []string{"file"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				File   string `json:"file"`
+				Reveal bool   `json:"reveal"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			path, err := output.Resolve(ctx, req.Session, params.File)
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return errorResult("Error reading secrets", err), nil
+			}
+
+			secrets := tailscale.ParseDisablementSecrets(string(data))
+			shown := make([]string, len(secrets))
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Disablement Secrets in %s (%d):\n", path, len(secrets)))
+			for i, secret := range secrets {
+				shown[i] = secret
+				if !params.Reveal {
+					shown[i] = maskSecret(secret)
+				}
+				result.WriteString("  " + shown[i] + "\n")
+			}
+			if len(secrets) == 0 {
+				result.WriteString("  No disablement secrets found in the file.\n")
+			}
+			return structuredResult(result.String(), map[string]interface{}{"file": path, "secrets": shown}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "lock_log",
+			Description: "Show recent updates to the tailnet key authority (tailnet lock's signed log of trusted key changes and checkpoints), newest first",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"limit": {
+						Type:        "integer",
+						Description: "Number of updates to show (default: 20)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Limit int `json:"limit"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Limit <= 0 {
+				params.Limit = 20
+			}
+
+			entries, err := cli.LockLog(ctx, params.Limit)
+			if err != nil {
+				return errorResult("Error getting tailnet lock log", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Tailnet Key Authority Log (%d updates, newest first):\n", len(entries)))
+			if len(entries) == 0 {
+				result.WriteString("\nNo updates. Tailnet lock may not be enabled.\n")
+			}
+			for _, entry := range entries {
+				result.WriteString(fmt.Sprintf("\n%s %s\n", entry.Kind, entry.Hash))
+				for _, detail := range entry.Details {
+					result.WriteString("  " + detail + "\n")
+				}
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"updates": entries}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "lock_list_pending",
+			Description: "List nodes locked out by tailnet lock because their node key isn't signed, and optionally sign all of them (or the given ones) in one call with this node's trusted key",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"sign": {
+						Type:        "boolean",
+						Description: "Sign the pending nodes (default: false, only list them)",
+					},
+					"nodes": {
+						Type:        "array",
+						Description: "Only sign these pending nodes, by name, Tailscale IP or node key (default: all)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Sign  bool     `json:"sign"`
+				Nodes []string `json:"nodes"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			lock, err := cli.LockStatus(ctx)
+			if err != nil {
+				return errorResult("Error getting tailnet lock status", err), nil
+			}
+			if !lock.Enabled {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: "Tailnet lock is not enabled, so no nodes are waiting for a signature."},
+					},
+				}, nil
+			}
+
+			pending := lock.FilteredPeers
+			var missing []string
+			if len(params.Nodes) > 0 {
+				pending = nil
+				for _, node := range params.Nodes {
+					found := false
+					for _, peer := range lock.FilteredPeers {
+						if peer.NodeKey == node || lockPeerMatches(peer, node) {
+							pending = append(pending, peer)
+							found = true
+							break
+						}
+					}
+					if !found {
+						missing = append(missing, node)
+					}
+				}
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Pending Nodes (%d):\n", len(pending)))
+			if len(pending) == 0 && len(missing) == 0 {
+				result.WriteString("  None: every node is signed.\n")
+			}
+
+			trusted := false
+			for _, key := range lock.TrustedKeys {
+				if key.Key == lock.PublicKey {
+					trusted = true
+				}
+			}
+
+			type signResult struct {
+				tailscale.LockPeer
+				Signed bool   `json:"signed"`
+				Error  string `json:"error,omitempty"`
+			}
+			results := []signResult{}
+			signed := 0
+			progress := newProgress(req, len(pending))
+			for _, peer := range pending {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				r := signResult{LockPeer: peer}
+				line := fmt.Sprintf("%s %s (%s)", peer.Name, strings.Join(peer.TailscaleIPs, ", "), peer.NodeKey)
+				switch {
+				case !params.Sign:
+					result.WriteString("  " + line + "\n")
+				case !trusted:
+					r.Error = "this node's key isn't trusted"
+					result.WriteString("  " + line + "\n")
+				default:
+					if err := cli.LockSign(ctx, peer.NodeKey); err != nil {
+						r.Error = err.Error()
+						result.WriteString(fmt.Sprintf("  ✗ %s: %v\n", line, err))
+					} else {
+						r.Signed = true
+						signed++
+						result.WriteString(fmt.Sprintf("  ✓ %s: signed\n", line))
+					}
+					progress.step(ctx, "signed "+peer.Name)
+				}
+				results = append(results, r)
+			}
+			for _, node := range missing {
+				result.WriteString(fmt.Sprintf("  ✗ %s: not a pending node\n", node))
+			}
+
+			switch {
+			case len(pending) == 0:
+			case !params.Sign:
+				result.WriteString("\nSet sign=true to sign them with this node's key.\n")
+			case !trusted:
+				result.WriteString(fmt.Sprintf("\n✗ Not signed: this node's lock key (%s) isn't trusted. Run this on a signing node, or add the key with lock_add_keys.\n", lock.PublicKey))
+			default:
+				result.WriteString(fmt.Sprintf("\nSigned %d of %d node(s).\n", signed, len(pending)))
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{
+				"pending": results,
+				"missing": missing,
+			}), nil
+		}),
+	)
+}
@@ -0,0 +1,377 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/diff"
+	"github.com/phildougherty/go-tailscale-mcp/scheduler"
+	"github.com/phildougherty/go-tailscale-mcp/store"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const (
+	maintenanceBucket      = "maintenance"
+	defaultMaintenanceTag  = "tag:maintenance"
+	defaultMaintenanceTime = time.Hour
+)
+
+// MaintenanceWindow records a device placed in maintenance mode
+type MaintenanceWindow struct {
+	DeviceID     string    `json:"device_id"`
+	DeviceName   string    `json:"device_name"`
+	Tag          string    `json:"tag"`
+	Reason       string    `json:"reason,omitempty"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Blocked      bool      `json:"blocked"`
+	OriginalTags []string  `json:"original_tags"`
+	Reminded     bool      `json:"reminded"`
+}
+
+// RegisterMaintenanceTools registers the device maintenance workflow tools
+func RegisterMaintenanceTools(server *mcp.Server, api *tailscale.APIClient, st *store.Store, sched *scheduler.Scheduler) {
+	// Remind clients when a maintenance window has ended but the device is still tagged
+	sched.Every("maintenance-reminders", time.Minute, func(ctx context.Context) {
+		var windows map[string]*MaintenanceWindow
+		var due []*MaintenanceWindow
+		err := st.Update(maintenanceBucket, &windows, func() error {
+			now := time.Now()
+			for _, w := range windows {
+				if !w.Reminded && now.After(w.End) {
+					w.Reminded = true
+					due = append(due, w)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return
+		}
+
+		for _, w := range due {
+			notifySessions(ctx, server, "warning", fmt.Sprintf(
+				"Maintenance window for %s (%s) ended at %s. Run set_maintenance_mode with device_id=%s and enabled=false to remove %s.",
+				w.DeviceName, w.DeviceID, w.End.Format(time.RFC3339), w.DeviceID, w.Tag))
+		}
+	})
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "set_maintenance_mode",
+			Description: "Put a device into (or take it out of) maintenance mode: applies a maintenance tag, optionally isolates it, and records the window locally with a reminder when it ends",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"device_id": {
+						Type:        "string",
+						Description: "Device ID to place in maintenance",
+					},
+					"enabled": {
+						Type:        "boolean",
+						Description: "true to start maintenance, false to end it and restore the device's tags (default: true)",
+					},
+					"duration": {
+						Type:        "string",
+						Description: "Length of the maintenance window (e.g., 30m, 2h) (default: 1h)",
+					},
+					"reason": {
+						Type:        "string",
+						Description: "Why the device is in maintenance (optional)",
+					},
+					"tag": {
+						Type:        "string",
+						Description: "Maintenance tag to apply (default: tag:maintenance, or the active window's tag when extending it; a different tag is refused)",
+					},
+					"block": {
+						Type:        "boolean",
+						Description: "Isolate the device by replacing all of its tags with the maintenance tag, so tag-based ACL rules no longer match it (default: false)",
+					},
+					"apply_acl": {
+						Type:        "boolean",
+						Description: "Apply the ACL draft if the maintenance tag needs to be added to tagOwners; otherwise the draft is only shown (default: false)",
+					},
+				},
+				Required: []string{"device_id"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				DeviceID string `json:"device_id"`
+				Enabled  *bool  `json:"enabled"`
+				Duration string `json:"duration"`
+				Reason   string `json:"reason"`
+				Tag      string `json:"tag"`
+				Block    bool   `json:"block"`
+				ApplyACL bool   `json:"apply_acl"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			var result *mcp.CallToolResult
+			if params.Enabled == nil || *params.Enabled {
+				result = startMaintenance(ctx, api, st, params.DeviceID, params.Duration, params.Reason, params.Tag, params.Block, params.ApplyACL)
+			} else {
+				result = endMaintenance(ctx, api, st, params.DeviceID)
+			}
+			if result.IsError {
+				return result, nil
+			}
+
+			// Surface any other windows that have already ended
+			if overdue := overdueMaintenance(st, params.DeviceID); overdue != "" {
+				text := result.Content[0].(*mcp.TextContent)
+				text.Text += "\n" + overdue
+			}
+			return result, nil
+		}),
+	)
+}
+
+func startMaintenance(ctx context.Context, api *tailscale.APIClient, st *store.Store, deviceID, duration, reason, tag string, block, applyACL bool) *mcp.CallToolResult {
+	var windows map[string]*MaintenanceWindow
+	if err := st.Load(maintenanceBucket, &windows); err != nil {
+		return errorResult("Error loading maintenance windows", err)
+	}
+
+	// Extending a window keeps its tag, since ending it only removes one
+	tag = tailscale.NormalizeTag(tag)
+	if existing, ok := windows[deviceID]; ok {
+		if tag != "" && tag != existing.Tag {
+			return refusedResult(fmt.Sprintf("%s is already in maintenance with %s. End it before starting one with %s, or extend it without a different tag.", existing.DeviceName, existing.Tag, tag))
+		}
+		tag = existing.Tag
+	}
+	if tag == "" {
+		tag = defaultMaintenanceTag
+	}
+
+	length := defaultMaintenanceTime
+	if duration != "" {
+		d, err := time.ParseDuration(duration)
+		if err != nil || d <= 0 {
+			return invalidInputResult(fmt.Sprintf("Invalid duration %q: use a positive Go duration such as 30m or 2h", duration))
+		}
+		length = d
+	}
+
+	device, err := api.GetDevice(ctx, deviceID)
+	if err != nil {
+		return errorResult("Error getting device", err)
+	}
+
+	var result strings.Builder
+
+	// The tag must be declared in tagOwners before it can be applied
+	acl, err := api.GetACL(ctx)
+	if err != nil {
+		return errorResult("Error getting ACL", err)
+	}
+	policy, err := tailscale.ParseACL(acl)
+	if err != nil {
+		return errorResult("Error parsing ACL", err)
+	}
+
+	if !policy.HasTagOwner(tag) {
+		draft := policy.Clone()
+		if err := draft.AddTagOwner(tag, []string{"autogroup:admin"}); err != nil {
+			return errorResult("Error preparing ACL draft", err)
+		}
+		draftDiff := diff.Unified("policy.hujson (current)", "policy.hujson (draft)", policy.String(), draft.String())
+
+		if !applyACL {
+			result.WriteString(fmt.Sprintf("%s is not declared in tagOwners, so it can't be applied yet.\n\n", tag))
+			result.WriteString("ACL draft:\n")
+			result.WriteString(draftDiff)
+			result.WriteString("\nRe-run with apply_acl=true to apply this draft and start maintenance.\n")
+			return textResult(result.String())
+		}
+
+		if err := api.ValidateACL(ctx, draft.ACL()); err != nil {
+			return errorResult("ACL draft validation failed", err)
+		}
+		if _, err := api.SetACL(ctx, draft.ACL()); err != nil {
+			return errorResult("Error applying ACL draft", err)
+		}
+		result.WriteString(fmt.Sprintf("✓ Added %s to tagOwners\n", tag))
+		policy = draft
+	}
+
+	tags := []string{tag}
+	if !block {
+		tags = append([]string{}, device.Tags...)
+		if !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	if err := api.SetDeviceTags(ctx, device.ID, tags); err != nil {
+		result.WriteString(fmt.Sprintf("✗ Error tagging device: %v\n", err))
+		return reportErrorResult(result.String(), err)
+	}
+
+	now := time.Now()
+	window := &MaintenanceWindow{
+		DeviceID:     device.ID,
+		DeviceName:   device.Name,
+		Tag:          tag,
+		Reason:       reason,
+		Start:        now,
+		End:          now.Add(length),
+		Blocked:      block,
+		OriginalTags: device.Tags,
+	}
+
+	storeErr := st.Update(maintenanceBucket, &windows, func() error {
+		if windows == nil {
+			windows = make(map[string]*MaintenanceWindow)
+		}
+		// Keep the tags from before the first window if maintenance is
+		// extended, and stay blocked if it already was, so ending it still
+		// restores them
+		if existing, ok := windows[device.ID]; ok {
+			window.OriginalTags = existing.OriginalTags
+			window.Start = existing.Start
+			window.Blocked = existing.Blocked || block
+		}
+		windows[device.ID] = window
+		return nil
+	})
+	if storeErr != nil {
+		result.WriteString(fmt.Sprintf("✗ Device was tagged but the maintenance window could not be recorded: %v\n", storeErr))
+		return reportErrorResult(result.String(), storeErr)
+	}
+
+	result.WriteString(fmt.Sprintf("✓ %s (%s) is in maintenance until %s\n", device.Name, device.ID, window.End.Format(time.RFC3339)))
+	result.WriteString(fmt.Sprintf("  Tags: %s\n", strings.Join(tags, ", ")))
+	if reason != "" {
+		result.WriteString(fmt.Sprintf("  Reason: %s\n", reason))
+	}
+
+	if window.Blocked {
+		result.WriteString("  Blocked: device tags were replaced, so tag-based ACL rules no longer apply to it\n")
+		if len(window.OriginalTags) == 0 {
+			result.WriteString("  ⚠ The device was user-owned; restoring it to user ownership may require re-authentication\n")
+		}
+		if wildcards := wildcardRules(policy); len(wildcards) > 0 {
+			result.WriteString("  ⚠ These ACL rules still match every device, including this one:\n")
+			for _, rule := range wildcards {
+				result.WriteString(fmt.Sprintf("    %s\n", rule))
+			}
+		}
+	}
+
+	result.WriteString("\nYou'll be reminded when the window ends. Run set_maintenance_mode with enabled=false to finish.\n")
+	return textResult(result.String())
+}
+
+func endMaintenance(ctx context.Context, api *tailscale.APIClient, st *store.Store, deviceID string) *mcp.CallToolResult {
+	var windows map[string]*MaintenanceWindow
+	if err := st.Load(maintenanceBucket, &windows); err != nil {
+		return errorResult("Error loading maintenance windows", err)
+	}
+
+	window, ok := windows[deviceID]
+	if !ok {
+		return notFoundResult(fmt.Sprintf("Device %s has no recorded maintenance window.", deviceID))
+	}
+
+	device, err := api.GetDevice(ctx, deviceID)
+	if err != nil {
+		return errorResult("Error getting device", err)
+	}
+
+	var tags []string
+	if window.Blocked {
+		tags = window.OriginalTags
+	} else {
+		for _, t := range device.Tags {
+			if t != window.Tag {
+				tags = append(tags, t)
+			}
+		}
+	}
+
+	if err := api.SetDeviceTags(ctx, deviceID, tags); err != nil {
+		return errorResult("Error restoring device tags", err)
+	}
+
+	err = st.Update(maintenanceBucket, &windows, func() error {
+		delete(windows, deviceID)
+		return nil
+	})
+	if err != nil {
+		return errorResult("Device tags were restored but the maintenance record could not be removed", err)
+	}
+
+	restored := strings.Join(tags, ", ")
+	if restored == "" {
+		restored = "(none)"
+	}
+	return textResult(fmt.Sprintf("✓ Maintenance ended for %s (%s)\n  Tags: %s\n", window.DeviceName, deviceID, restored))
+}
+
+// overdueMaintenance lists windows (other than skipID) that have ended
+func overdueMaintenance(st *store.Store, skipID string) string {
+	var windows map[string]*MaintenanceWindow
+	if err := st.Load(maintenanceBucket, &windows); err != nil {
+		return ""
+	}
+
+	var overdue []*MaintenanceWindow
+	now := time.Now()
+	for id, w := range windows {
+		if id != skipID && now.After(w.End) {
+			overdue = append(overdue, w)
+		}
+	}
+	if len(overdue) == 0 {
+		return ""
+	}
+	sort.Slice(overdue, func(i, j int) bool { return overdue[i].End.Before(overdue[j].End) })
+
+	var result strings.Builder
+	result.WriteString("⚠ Maintenance windows that have ended but are still active:\n")
+	for _, w := range overdue {
+		result.WriteString(fmt.Sprintf("  %s (%s) - %s, ended %s ago\n", w.DeviceName, w.DeviceID, w.Tag, now.Sub(w.End).Round(time.Minute)))
+	}
+	return result.String()
+}
+
+// wildcardRules returns ACL rules whose source and destination match any device
+func wildcardRules(policy *tailscale.Policy) []string {
+	var doc struct {
+		ACLs []struct {
+			Src []string `json:"src"`
+			Dst []string `json:"dst"`
+		} `json:"acls"`
+	}
+	if err := policy.Decode(&doc); err != nil {
+		return nil
+	}
+
+	var rules []string
+	for _, rule := range doc.ACLs {
+		if containsString(rule.Src, "*") {
+			for _, dst := range rule.Dst {
+				if strings.HasPrefix(dst, "*:") {
+					rules = append(rules, fmt.Sprintf("src %s → dst %s", strings.Join(rule.Src, ", "), strings.Join(rule.Dst, ", ")))
+					break
+				}
+			}
+		}
+	}
+	return rules
+}
@@ -0,0 +1,365 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/scheduler"
+	"github.com/phildougherty/go-tailscale-mcp/store"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const meteredBucket = "metered_nodes"
+
+// MeteredNode tracks traffic through a device on a metered connection (e.g.,
+// an exit node on LTE) against daily and monthly byte budgets
+type MeteredNode struct {
+	Host          string    `json:"host"`
+	DailyBudget   int64     `json:"daily_budget,omitempty"`
+	MonthlyBudget int64     `json:"monthly_budget,omitempty"`
+	Day           string    `json:"day"`
+	Month         string    `json:"month"`
+	DayBytes      int64     `json:"day_bytes"`
+	MonthBytes    int64     `json:"month_bytes"`
+	LastRx        int64     `json:"last_rx"`
+	LastTx        int64     `json:"last_tx"`
+	LastSample    time.Time `json:"last_sample,omitempty"`
+	DayAlerted    bool      `json:"day_alerted,omitempty"`
+	MonthAlerted  bool      `json:"month_alerted,omitempty"`
+}
+
+// RegisterMeteredTools registers metered node budget tools and the usage monitor
+func RegisterMeteredTools(server *mcp.Server, cli *tailscale.CLI, st *store.Store, sched *scheduler.Scheduler) {
+	// Sample peer counters and alert once per period when a budget is exceeded
+	sched.Every("metered-usage", time.Minute, func(ctx context.Context) {
+		alerts, err := sampleMeteredUsage(ctx, cli, st, time.Now())
+		if err != nil {
+			return
+		}
+		for _, alert := range alerts {
+			notifySessions(ctx, server, "warning", alert)
+		}
+	})
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "set_metered_node",
+			Description: "Mark a device as metered (e.g., an exit node on a cellular link) with daily and/or monthly byte budgets. Traffic to and from it is tracked in the local state file and connected clients are alerted when a budget is exceeded.",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"device": {
+						Type:        "string",
+						Description: "Device hostname",
+					},
+					"metered": {
+						Type:        "boolean",
+						Description: "true to track the device, false to stop tracking it (default: true)",
+					},
+					"daily_budget": {
+						Type:        "string",
+						Description: "Daily byte budget (e.g., 500MB, 2GiB)",
+					},
+					"monthly_budget": {
+						Type:        "string",
+						Description: "Monthly byte budget (e.g., 20GB)",
+					},
+				},
+				Required: []string{"device"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Device        string `json:"device"`
+				Metered       *bool  `json:"metered"`
+				DailyBudget   string `json:"daily_budget"`
+				MonthlyBudget string `json:"monthly_budget"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			key := strings.ToLower(params.Device)
+			if key == "" {
+				return invalidInputResult("device is required"), nil
+			}
+
+			if params.Metered != nil && !*params.Metered {
+				var nodes map[string]*MeteredNode
+				removed := false
+				err := st.Update(meteredBucket, &nodes, func() error {
+					_, removed = nodes[key]
+					delete(nodes, key)
+					return nil
+				})
+				if err != nil {
+					return errorResult("Error updating state", err), nil
+				}
+				text := fmt.Sprintf("✓ %s is no longer tracked as metered", params.Device)
+				if !removed {
+					text = fmt.Sprintf("%s was not marked as metered", params.Device)
+				}
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: text},
+					},
+				}, nil
+			}
+
+			daily, err := parseByteSize(params.DailyBudget)
+			if err != nil {
+				return errorResult("Invalid daily_budget", err), nil
+			}
+			monthly, err := parseByteSize(params.MonthlyBudget)
+			if err != nil {
+				return errorResult("Invalid monthly_budget", err), nil
+			}
+			if daily == 0 && monthly == 0 {
+				return invalidInputResult("At least one of daily_budget or monthly_budget is required"), nil
+			}
+
+			if err := saveMeteredNode(ctx, cli, st, key, params.Device, daily, monthly); err != nil {
+				return errorResult("Error updating state", err), nil
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("✓ %s marked as metered. Usage is sampled every minute; see list_metered_nodes.", params.Device)},
+				},
+			}, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "list_metered_nodes",
+			Description: "Show tracked metered devices with their traffic for the current day and month against their budgets",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			// Take a fresh sample so the numbers are current
+			alerts, err := sampleMeteredUsage(ctx, cli, st, time.Now())
+			if err != nil {
+				return errorResult("Error sampling usage", err), nil
+			}
+			for _, alert := range alerts {
+				notifySessions(ctx, server, "warning", alert)
+			}
+
+			var nodes map[string]*MeteredNode
+			if err := st.Load(meteredBucket, &nodes); err != nil {
+				return errorResult("Error loading state", err), nil
+			}
+			if len(nodes) == 0 {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: "No metered devices. Use set_metered_node to track one."},
+					},
+				}, nil
+			}
+
+			keys := make([]string, 0, len(nodes))
+			for k := range nodes {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			var result strings.Builder
+			result.WriteString("Metered devices\n\n")
+			list := make([]*MeteredNode, 0, len(keys))
+			for _, k := range keys {
+				n := nodes[k]
+				list = append(list, n)
+				result.WriteString(fmt.Sprintf("%s\n", n.Host))
+				result.WriteString(fmt.Sprintf("  Today (%s): %s\n", n.Day, describeUsage(n.DayBytes, n.DailyBudget)))
+				result.WriteString(fmt.Sprintf("  This month (%s): %s\n", n.Month, describeUsage(n.MonthBytes, n.MonthlyBudget)))
+				if n.LastSample.IsZero() {
+					result.WriteString("  ⚠ Not seen in status yet\n")
+				}
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"nodes": list}), nil
+		}),
+	)
+}
+
+// saveMeteredNode creates or updates a metered node, keeping its usage so far.
+// New nodes start from the current counters so past traffic isn't counted.
+func saveMeteredNode(ctx context.Context, cli *tailscale.CLI, st *store.Store, key, host string, daily, monthly int64) error {
+	var rx, tx int64
+	var seen bool
+	if status, err := cli.Status(ctx); err == nil {
+		if peer := findPeerByHost(status, host); peer != nil {
+			rx, tx, seen = peer.RxBytes, peer.TxBytes, true
+		}
+	}
+
+	var nodes map[string]*MeteredNode
+	return st.Update(meteredBucket, &nodes, func() error {
+		if nodes == nil {
+			nodes = map[string]*MeteredNode{}
+		}
+		n, ok := nodes[key]
+		if !ok {
+			now := time.Now()
+			n = &MeteredNode{Host: host, Day: now.Format("2006-01-02"), Month: now.Format("2006-01")}
+			if seen {
+				n.LastRx, n.LastTx, n.LastSample = rx, tx, now
+			}
+			nodes[key] = n
+		}
+		n.DailyBudget, n.MonthlyBudget = daily, monthly
+		// Re-arm alerts so a raised budget alerts again when it is exceeded
+		n.DayAlerted = n.DailyBudget > 0 && n.DayBytes > n.DailyBudget
+		n.MonthAlerted = n.MonthlyBudget > 0 && n.MonthBytes > n.MonthlyBudget
+		return nil
+	})
+}
+
+// sampleMeteredUsage adds the traffic since the last sample to each node's day
+// and month totals, rolling periods over, and returns alerts for budgets that
+// were exceeded for the first time in the period
+func sampleMeteredUsage(ctx context.Context, cli *tailscale.CLI, st *store.Store, now time.Time) ([]string, error) {
+	var nodes map[string]*MeteredNode
+	if err := st.Load(meteredBucket, &nodes); err != nil || len(nodes) == 0 {
+		return nil, err
+	}
+
+	status, err := cli.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []string
+	err = st.Update(meteredBucket, &nodes, func() error {
+		alerts = nil
+		day, month := now.Format("2006-01-02"), now.Format("2006-01")
+
+		for _, n := range nodes {
+			if n.Day != day {
+				n.Day, n.DayBytes, n.DayAlerted = day, 0, false
+			}
+			if n.Month != month {
+				n.Month, n.MonthBytes, n.MonthAlerted = month, 0, false
+			}
+
+			peer := findPeerByHost(status, n.Host)
+			if peer == nil {
+				continue
+			}
+
+			// Counters reset when either side restarts; count the new value then
+			if !n.LastSample.IsZero() {
+				delta := counterDelta(n.LastRx, peer.RxBytes) + counterDelta(n.LastTx, peer.TxBytes)
+				n.DayBytes += delta
+				n.MonthBytes += delta
+			}
+			n.LastRx, n.LastTx, n.LastSample = peer.RxBytes, peer.TxBytes, now
+
+			if n.DailyBudget > 0 && !n.DayAlerted && n.DayBytes > n.DailyBudget {
+				n.DayAlerted = true
+				alerts = append(alerts, fmt.Sprintf("Metered node %s exceeded its daily budget: %s of %s used today",
+					n.Host, formatByteSize(n.DayBytes), formatByteSize(n.DailyBudget)))
+			}
+			if n.MonthlyBudget > 0 && !n.MonthAlerted && n.MonthBytes > n.MonthlyBudget {
+				n.MonthAlerted = true
+				alerts = append(alerts, fmt.Sprintf("Metered node %s exceeded its monthly budget: %s of %s used this month",
+					n.Host, formatByteSize(n.MonthBytes), formatByteSize(n.MonthlyBudget)))
+			}
+		}
+		return nil
+	})
+	return alerts, err
+}
+
+func counterDelta(last, current int64) int64 {
+	if current < last {
+		return current
+	}
+	return current - last
+}
+
+// findPeerByHost finds a peer by hostname or MagicDNS short name
+func findPeerByHost(status *tailscale.Status, host string) *tailscale.PeerStatus {
+	for _, peer := range status.Peer {
+		if peer == nil {
+			continue
+		}
+		if strings.EqualFold(peer.HostName, host) || strings.EqualFold(peerHost(peer), host) {
+			return peer
+		}
+	}
+	return nil
+}
+
+func describeUsage(used, budget int64) string {
+	if budget == 0 {
+		return fmt.Sprintf("%s (no budget)", formatByteSize(used))
+	}
+	pct := float64(used) / float64(budget) * 100
+	mark := "✓"
+	if used > budget {
+		mark = "✗"
+	} else if pct >= 80 {
+		mark = "⚠"
+	}
+	return fmt.Sprintf("%s %s of %s (%.0f%%)", mark, formatByteSize(used), formatByteSize(budget), pct)
+}
+
+var byteUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// parseByteSize parses sizes such as 500MB or 2GiB. An empty string is zero.
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	value, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	unit, ok := byteUnits[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit in %q (use B, KB, MB, GB, TB or KiB, MiB, GiB, TiB)", s)
+	}
+	return int64(value * float64(unit)), nil
+}
+
+// formatByteSize renders a byte count with a decimal unit
+func formatByteSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,246 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/scheduler"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// keyExpiryWarning is how far ahead a key expiry counts as expiring soon
+const keyExpiryWarning = 7 * 24 * time.Hour
+
+// tailnetMetrics is a point-in-time summary of the tailnet for monitoring
+type tailnetMetrics struct {
+	Source       string
+	Devices      int
+	Online       int
+	ExitNodes    int
+	KeysExpiring int
+	KeysExpired  int
+	ActivePeers  int
+	RelayedPeers int
+	Generated    time.Time
+}
+
+// RegisterMetricsTools registers the Prometheus textfile export tool.
+// defaultPath is the configured textfile, used when no path is given.
+func RegisterMetricsTools(server *mcp.Server, cli *tailscale.CLI, api *tailscale.APIClient, output *OutputWriter, defaultPath string) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "export_metrics_textfile",
+			Description: "Write tailnet summary metrics (device and online counts, expiring keys, relayed connection ratio) in Prometheus text format for the node_exporter textfile collector. Set metrics_textfile in the config to export on a schedule.",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"path": {
+						Type:        "string",
+						Description: "File to write, ending in .prom (default: the configured metrics_textfile). Must be inside the allowed output directories.",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Path string `json:"path"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			path := defaultPath
+			if params.Path != "" {
+				resolved, err := output.Resolve(ctx, req.Session, params.Path)
+				if err != nil {
+					return errorResult("Error", err), nil
+				}
+				path = resolved
+			}
+			if path == "" {
+				return invalidInputResult("No path given and metrics_textfile is not configured. Pass a path or set metrics_textfile (or TAILSCALE_MCP_METRICS_TEXTFILE)."), nil
+			}
+
+			metrics, err := writeMetricsTextfile(ctx, cli, api, path)
+			if err != nil {
+				return errorResult("Error exporting metrics", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("✓ Metrics written to %s\n\n", path))
+			result.WriteString(fmt.Sprintf("Devices: %d (%d online, source: %s)\n", metrics.Devices, metrics.Online, metrics.Source))
+			result.WriteString(fmt.Sprintf("Keys expiring within 7 days: %d, expired: %d\n", metrics.KeysExpiring, metrics.KeysExpired))
+			result.WriteString(fmt.Sprintf("Relayed peers: %d of %d active (%.0f%%)\n", metrics.RelayedPeers, metrics.ActivePeers, metrics.relayRatio()*100))
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: result.String()},
+				},
+			}, nil
+		}),
+	)
+}
+
+// ScheduleMetricsExport rewrites the textfile at path every interval
+func ScheduleMetricsExport(cli *tailscale.CLI, api *tailscale.APIClient, sched *scheduler.Scheduler, path string, interval time.Duration) {
+	sched.Every("metrics-textfile", interval, func(ctx context.Context) {
+		if _, err := writeMetricsTextfile(ctx, cli, api, path); err != nil {
+			slog.Warn("Failed to export metrics", "path", path, "error", err)
+		}
+	})
+}
+
+// writeMetricsTextfile collects metrics and atomically replaces the file at
+// path, since the textfile collector may read it at any time
+func writeMetricsTextfile(ctx context.Context, cli *tailscale.CLI, api *tailscale.APIClient, path string) (*tailnetMetrics, error) {
+	if !strings.HasSuffix(path, ".prom") {
+		return nil, fmt.Errorf("textfile collector files must end in .prom: %s", path)
+	}
+
+	metrics, err := collectTailnetMetrics(ctx, cli, api)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tailscale-mcp-*.prom.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(metrics.textfile()); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write metrics: %w", err)
+	}
+	// node_exporter usually runs as a different user, so the file must be world-readable
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	return metrics, nil
+}
+
+// collectTailnetMetrics gathers device counts from the API when available (the
+// whole tailnet) or the local status otherwise (visible peers only). Relay
+// metrics always come from the local node's view of its peers.
+func collectTailnetMetrics(ctx context.Context, cli *tailscale.CLI, api *tailscale.APIClient) (*tailnetMetrics, error) {
+	status, err := cli.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	now := time.Now()
+	m := &tailnetMetrics{Generated: now}
+	countKey := func(expiry time.Time) {
+		if expiry.IsZero() {
+			return
+		}
+		if expiry.Before(now) {
+			m.KeysExpired++
+		} else if expiry.Before(now.Add(keyExpiryWarning)) {
+			m.KeysExpiring++
+		}
+	}
+
+	if api != nil && api.IsAvailable() {
+		devices, err := api.ListDevices(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list devices: %w", err)
+		}
+		m.Source = "api"
+		for _, d := range devices {
+			m.Devices++
+			if d.Online {
+				m.Online++
+			}
+			if d.ExitNode {
+				m.ExitNodes++
+			}
+			countKey(d.KeyExpiry)
+		}
+	} else {
+		m.Source = "status"
+		peers := make([]*tailscale.PeerStatus, 0, len(status.Peer)+1)
+		if status.Self != nil {
+			peers = append(peers, status.Self)
+		}
+		for _, peer := range status.Peer {
+			peers = append(peers, peer)
+		}
+		for _, p := range peers {
+			m.Devices++
+			if p.Online {
+				m.Online++
+			}
+			if p.ExitNodeOption {
+				m.ExitNodes++
+			}
+			countKey(p.KeyExpiry)
+		}
+	}
+
+	// A peer with recent traffic but no direct address is going through DERP
+	for _, peer := range status.Peer {
+		if peer == nil || !peer.Online || !peer.Active {
+			continue
+		}
+		m.ActivePeers++
+		if peer.CurAddr == "" {
+			m.RelayedPeers++
+		}
+	}
+
+	return m, nil
+}
+
+func (m *tailnetMetrics) relayRatio() float64 {
+	if m.ActivePeers == 0 {
+		return 0
+	}
+	return float64(m.RelayedPeers) / float64(m.ActivePeers)
+}
+
+// textfile renders the metrics in the Prometheus text exposition format
+func (m *tailnetMetrics) textfile() string {
+	var sb strings.Builder
+	gauge := func(name, help string, value float64) {
+		sb.WriteString(fmt.Sprintf("# HELP %s %s\n", name, help))
+		sb.WriteString(fmt.Sprintf("# TYPE %s gauge\n", name))
+		sb.WriteString(fmt.Sprintf("%s{source=%q} %s\n", name, m.Source, strconv.FormatFloat(value, 'f', -1, 64)))
+	}
+
+	gauge("tailscale_devices", "Number of devices in the tailnet.", float64(m.Devices))
+	gauge("tailscale_devices_online", "Number of devices currently online.", float64(m.Online))
+	gauge("tailscale_exit_nodes", "Number of devices offering an exit node.", float64(m.ExitNodes))
+	gauge("tailscale_device_keys_expiring", "Number of device keys expiring within 7 days.", float64(m.KeysExpiring))
+	gauge("tailscale_device_keys_expired", "Number of device keys that have expired.", float64(m.KeysExpired))
+	gauge("tailscale_peers_active", "Number of peers with recent traffic from this node.", float64(m.ActivePeers))
+	gauge("tailscale_peers_relayed", "Number of active peers reached through a DERP relay.", float64(m.RelayedPeers))
+	gauge("tailscale_peers_relay_ratio", "Fraction of active peers reached through a DERP relay.", m.relayRatio())
+	gauge("tailscale_metrics_generated_timestamp_seconds", "Unix time the metrics were collected.", float64(m.Generated.Unix()))
+
+	return sb.String()
+}
@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// mullvadDNSSuffix is the MagicDNS suffix of Mullvad exit nodes
+const mullvadDNSSuffix = ".mullvad.ts.net"
+
+// isMullvadPeer reports whether a peer is a Mullvad exit node. Only Mullvad
+// nodes carry a location, but the DNS name is checked too in case it's missing.
+func isMullvadPeer(peer *tailscale.PeerStatus) bool {
+	return peer.Location != nil || strings.HasSuffix(strings.TrimSuffix(peer.DNSName, "."), mullvadDNSSuffix)
+}
+
+// isMullvadHost reports whether an exit-node list hostname is a Mullvad node
+func isMullvadHost(hostname string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(hostname, "."), mullvadDNSSuffix)
+}
+
+// mullvadPeers returns the Mullvad exit nodes this device can see. They are
+// only in the netmap when the tailnet has the Mullvad add-on and the device
+// has the mullvad node attribute.
+func mullvadPeers(status *tailscale.Status) []*tailscale.PeerStatus {
+	var peers []*tailscale.PeerStatus
+	for _, peer := range status.Peer {
+		if peer != nil && isMullvadPeer(peer) {
+			peers = append(peers, peer)
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].HostName < peers[j].HostName
+	})
+	return peers
+}
+
+// matchesLocation reports whether a country and city match the filters.
+// Country matches the name or code; city matches a case-insensitive substring.
+func matchesLocation(country, countryCode, city, wantCountry, wantCity string) bool {
+	if wantCountry != "" && !strings.EqualFold(country, wantCountry) && !strings.EqualFold(countryCode, wantCountry) {
+		return false
+	}
+	if wantCity != "" && !strings.Contains(strings.ToLower(city), strings.ToLower(wantCity)) {
+		return false
+	}
+	return true
+}
+
+// bestMullvadPeer picks the online Mullvad node with the highest priority in
+// the given country and city, the way the client picks a location's node
+func bestMullvadPeer(peers []*tailscale.PeerStatus, country, city string) *tailscale.PeerStatus {
+	var best *tailscale.PeerStatus
+	for _, peer := range peers {
+		if peer.Location == nil || !peer.Online {
+			continue
+		}
+		loc := peer.Location
+		if !matchesLocation(loc.Country, loc.CountryCode, loc.City, country, city) {
+			continue
+		}
+		if best == nil || loc.Priority > best.Location.Priority {
+			best = peer
+		}
+	}
+	return best
+}
+
+// mullvadExitNodeList builds exit-node list rows from the netmap, for clients
+// too old to have the command. Like the command, it lists the best node per
+// city, or every node when filtering by country.
+func mullvadExitNodeList(peers []*tailscale.PeerStatus, country string) []tailscale.ExitNodeListEntry {
+	var entries []tailscale.ExitNodeListEntry
+	for _, peer := range peers {
+		loc := peer.Location
+		if loc == nil {
+			continue
+		}
+		if country != "" {
+			if !matchesLocation(loc.Country, loc.CountryCode, loc.City, country, "") {
+				continue
+			}
+		} else if bestMullvadPeer(peers, loc.CountryCode, loc.City) != peer {
+			continue
+		}
+		entry := tailscale.ExitNodeListEntry{
+			Hostname: strings.TrimSuffix(peer.DNSName, "."),
+			Country:  loc.Country,
+			City:     loc.City,
+		}
+		if len(peer.TailscaleIPs) > 0 {
+			entry.IP = peer.TailscaleIPs[0]
+		}
+		if !peer.Online {
+			entry.Status = "offline"
+		} else if peer.ExitNode {
+			entry.Status = "selected"
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// mullvadCountryName turns a country code into the country's name, which is
+// what `tailscale exit-node list --filter` matches
+func mullvadCountryName(peers []*tailscale.PeerStatus, country string) string {
+	for _, peer := range peers {
+		if peer.Location != nil && strings.EqualFold(peer.Location.CountryCode, country) {
+			return peer.Location.Country
+		}
+	}
+	return country
+}
+
+// mullvadCountries counts the distinct countries of the Mullvad nodes
+func mullvadCountries(peers []*tailscale.PeerStatus) int {
+	countries := map[string]bool{}
+	for _, peer := range peers {
+		if peer.Location != nil {
+			countries[peer.Location.CountryCode] = true
+		}
+	}
+	return len(countries)
+}
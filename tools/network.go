@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/google/jsonschema-go/jsonschema"
@@ -12,23 +14,104 @@ import (
 )
 
 // RegisterNetworkTools registers network operation tools
-func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI) {
+func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI, cache *ResponseCache) {
 	// Enhanced status tool
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "status",
-			Description: "Get comprehensive Tailscale network status",
-			InputSchema: &jsonschema.Schema{Type: "object"},
+			Description: "Get comprehensive Tailscale network status. Sections and peers can be filtered to keep output small on large tailnets.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"sections": {
+						Type:        "array",
+						Description: "Sections to include (default: all)",
+						Items:       &jsonschema.Schema{Type: "string", Enum: []any{"self", "peers", "health"}},
+					},
+					"online": {
+						Type:        "boolean",
+						Description: "Only list peers that are online (true) or offline (false)",
+					},
+					"exit_node": {
+						Type:        "boolean",
+						Description: "Only list peers that are (true) or aren't (false) available as exit nodes",
+					},
+					"tags": {
+						Type:        "array",
+						Description: "Only list peers with at least one of these tags",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"summary_only": {
+						Type:        "boolean",
+						Description: "Return a one-line summary instead of the full status",
+					},
+					"refresh": {
+						Type:        "boolean",
+						Description: "Bypass the response cache and fetch fresh data",
+					},
+				},
+			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			status, err := cli.Status()
+			var params struct {
+				Sections    []string `json:"sections"`
+				Online      *bool    `json:"online"`
+				ExitNode    *bool    `json:"exit_node"`
+				Tags        []string `json:"tags"`
+				SummaryOnly bool     `json:"summary_only"`
+				Refresh     bool     `json:"refresh"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			include := map[string]bool{"self": true, "peers": true, "health": true}
+			if len(params.Sections) > 0 {
+				include = map[string]bool{}
+				for _, section := range params.Sections {
+					section = strings.ToLower(section)
+					if section != "self" && section != "peers" && section != "health" {
+						return invalidInputResult(fmt.Sprintf("Unknown section %q (expected self, peers, or health)", section)), nil
+					}
+					include[section] = true
+				}
+			}
+			for i, tag := range params.Tags {
+				params.Tags[i] = tailscale.NormalizeTag(tag)
+			}
+
+			status, err := cached(ctx, cache, statusCacheKey, params.Refresh, cli.Status)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting status: %v", err)},
-					},
-				}, nil
+				return errorResult("Error getting status", err), nil
+			}
+
+			peerCount := len(status.Peer)
+			onlineCount := 0
+			exitNodeCount := 0
+			for _, peer := range status.Peer {
+				if peer.Online {
+					onlineCount++
+				}
+				if peer.ExitNodeOption {
+					exitNodeCount++
+				}
+			}
+
+			if params.SummaryOnly {
+				summary := status.BackendState
+				if status.CurrentTailnet != nil {
+					summary += fmt.Sprintf(" on %s", status.CurrentTailnet.Name)
+				}
+				if status.Self != nil && len(status.Self.TailscaleIPs) > 0 {
+					summary += fmt.Sprintf(" as %s (%s)", status.Self.HostName, status.Self.TailscaleIPs[0])
+				}
+				summary += fmt.Sprintf(", %d/%d peers online, %d exit nodes, %d health issues", onlineCount, peerCount, exitNodeCount, len(status.Health))
+				return structuredResult(summary, newStatusInfo(status)), nil
 			}
+			info := newStatusInfo(status)
 
 			var result strings.Builder
 			result.WriteString("=== Tailscale Network Status ===\n\n")
@@ -42,7 +125,7 @@ func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI) {
 				}
 			}
 
-			if status.Self != nil {
+			if include["self"] && status.Self != nil {
 				result.WriteString(fmt.Sprintf("\n=== Your Device ===\n"))
 				result.WriteString(fmt.Sprintf("Name: %s\n", status.Self.HostName))
 				result.WriteString(fmt.Sprintf("OS: %s\n", status.Self.OS))
@@ -59,37 +142,40 @@ func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI) {
 				}
 			}
 
-			peerCount := len(status.Peer)
-			result.WriteString(fmt.Sprintf("\n=== Network Peers ===\n"))
-			result.WriteString(fmt.Sprintf("Total peers: %d\n", peerCount))
-
-			if peerCount > 0 {
-				onlineCount := 0
-				exitNodeCount := 0
-				for _, peer := range status.Peer {
-					if peer.Online {
-						onlineCount++
-					}
-					if peer.ExitNodeOption {
-						exitNodeCount++
+			if include["peers"] {
+				result.WriteString(fmt.Sprintf("\n=== Network Peers ===\n"))
+				result.WriteString(fmt.Sprintf("Total peers: %d\n", peerCount))
+
+				if peerCount > 0 {
+					result.WriteString(fmt.Sprintf("Online peers: %d\n", onlineCount))
+					result.WriteString(fmt.Sprintf("Available exit nodes: %d\n", exitNodeCount))
+				}
+
+				// Peers are only listed when filtered, so the default output stays small
+				if params.Online != nil || params.ExitNode != nil || len(params.Tags) > 0 {
+					matching := filterPeers(status.Peer, params.Online, params.ExitNode, params.Tags)
+					result.WriteString(fmt.Sprintf("\nMatching peers: %d\n", len(matching)))
+					for _, peer := range matching {
+						result.WriteString(formatPeerLine(peer))
+						info.Peers = append(info.Peers, newDeviceInfo(peer, false))
 					}
 				}
-				result.WriteString(fmt.Sprintf("Online peers: %d\n", onlineCount))
-				result.WriteString(fmt.Sprintf("Available exit nodes: %d\n", exitNodeCount))
 			}
 
-			if len(status.Health) > 0 {
+			if include["health"] && len(status.Health) > 0 {
 				result.WriteString(fmt.Sprintf("\n=== Health Issues ===\n"))
 				for _, issue := range status.Health {
 					result.WriteString(fmt.Sprintf("• %s\n", issue))
 				}
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result.String()},
-				},
-			}, nil
+			if !include["self"] {
+				info.Self = nil
+			}
+			if !include["health"] {
+				info.Health = nil
+			}
+			return structuredResult(result.String(), info), nil
 		}),
 	)
 
@@ -98,6 +184,7 @@ func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "connect",
 			Description: "Connect to Tailscale with optional configuration",
+			Annotations: updateTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -106,6 +193,7 @@ func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI) {
 					"advertise_exit": {Type: "boolean", Description: "Advertise as exit node (optional)"},
 					"hostname":       {Type: "string", Description: "Set custom hostname (optional)"},
 					"ssh":           {Type: "boolean", Description: "Enable SSH server (optional)"},
+					"login_server":   {Type: "string", Description: "Control server URL, e.g. a Headscale server (optional, defaults to the configured login_server or Tailscale's)"},
 				},
 			},
 		},
@@ -116,13 +204,10 @@ func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI) {
 				AdvertiseExit  *bool  `json:"advertise_exit"`
 				Hostname       string `json:"hostname"`
 				SSH           *bool  `json:"ssh"`
+				LoginServer    string `json:"login_server"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			options := make(map[string]string)
@@ -151,13 +236,16 @@ func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI) {
 				}
 			}
 
-			err := cli.Login(params.AuthKey, options)
+			if params.LoginServer != "" {
+				if u, err := url.Parse(params.LoginServer); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+					return invalidInputResult(fmt.Sprintf("Invalid parameters: login_server %q must be an http(s) URL", params.LoginServer)), nil
+				}
+				options["login-server"] = params.LoginServer
+			}
+
+			err := cli.Login(ctx, params.AuthKey, options)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to connect: %v", err)},
-					},
-				}, nil
+				return errorResult("Failed to connect", err), nil
 			}
 
 			var result strings.Builder
@@ -187,16 +275,13 @@ func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "disconnect",
 			Description: "Disconnect from Tailscale network (stays logged in)",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{Type: "object"},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			err := cli.Down()
+			err := cli.Down(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to disconnect: %v", err)},
-					},
-				}, nil
+				return errorResult("Failed to disconnect", err), nil
 			}
 
 			return &mcp.CallToolResult{
@@ -212,16 +297,13 @@ func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "logout",
 			Description: "Logout from Tailscale completely",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{Type: "object"},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			err := cli.Logout()
+			err := cli.Logout(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to logout: %v", err)},
-					},
-				}, nil
+				return errorResult("Failed to logout", err), nil
 			}
 
 			return &mcp.CallToolResult{
@@ -237,23 +319,117 @@ func RegisterNetworkTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "version",
 			Description: "Get Tailscale version information",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{Type: "object"},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			version, err := cli.Version()
+			version, err := cli.Version(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting version: %v", err)},
-					},
-				}, nil
+				return errorResult("Error getting version", err), nil
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Tailscale Version Information:\n%s", version)},
-				},
-			}, nil
+			// The first line is the version; the rest are build details
+			short, _, _ := strings.Cut(version, "\n")
+			return structuredResult(fmt.Sprintf("Tailscale Version Information:\n%s", version), map[string]interface{}{
+				"version": strings.TrimSpace(short),
+				"details": version,
+			}), nil
 		}),
 	)
-}
\ No newline at end of file
+}
+
+// statusInfo is the structured form of the status tool's output
+type statusInfo struct {
+	BackendState   string       `json:"backend_state"`
+	Tailnet        string       `json:"tailnet,omitempty"`
+	MagicDNS       bool         `json:"magic_dns"`
+	MagicDNSSuffix string       `json:"magic_dns_suffix,omitempty"`
+	Self           *deviceInfo  `json:"self,omitempty"`
+	PeerCount      int          `json:"peer_count"`
+	OnlinePeers    int          `json:"online_peers"`
+	ExitNodes      int          `json:"exit_nodes"`
+	Peers          []deviceInfo `json:"peers,omitempty"`
+	Health         []string     `json:"health,omitempty"`
+}
+
+func newStatusInfo(status *tailscale.Status) *statusInfo {
+	info := &statusInfo{
+		BackendState: status.BackendState,
+		PeerCount:    len(status.Peer),
+		Health:       status.Health,
+	}
+	for _, peer := range status.Peer {
+		if peer.Online {
+			info.OnlinePeers++
+		}
+		if peer.ExitNodeOption {
+			info.ExitNodes++
+		}
+	}
+	if status.CurrentTailnet != nil {
+		info.Tailnet = status.CurrentTailnet.Name
+		info.MagicDNS = status.CurrentTailnet.MagicDNSEnabled
+		info.MagicDNSSuffix = status.CurrentTailnet.MagicDNSSuffix
+	}
+	if status.Self != nil {
+		self := newDeviceInfo(status.Self, true)
+		info.Self = &self
+	}
+	return info
+}
+
+// filterPeers returns the peers matching all given filters, sorted by hostname.
+// A nil filter matches everything; tags match if the peer has any of them.
+func filterPeers(peers map[string]*tailscale.PeerStatus, online, exitNode *bool, tags []string) []*tailscale.PeerStatus {
+	var matching []*tailscale.PeerStatus
+	for _, peer := range peers {
+		if peer == nil {
+			continue
+		}
+		if online != nil && peer.Online != *online {
+			continue
+		}
+		if exitNode != nil && peer.ExitNodeOption != *exitNode {
+			continue
+		}
+		if len(tags) > 0 {
+			found := false
+			for _, tag := range tags {
+				if containsString(peer.Tags, tag) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		matching = append(matching, peer)
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].HostName < matching[j].HostName
+	})
+	return matching
+}
+
+// formatPeerLine renders a peer as a single status line
+func formatPeerLine(peer *tailscale.PeerStatus) string {
+	state := "offline"
+	if peer.Online {
+		state = "online"
+	}
+	ip := ""
+	if len(peer.TailscaleIPs) > 0 {
+		ip = peer.TailscaleIPs[0]
+	}
+
+	line := fmt.Sprintf("• %s (%s) %s, %s", peer.HostName, ip, peer.OS, state)
+	if peer.ExitNodeOption {
+		line += ", exit node"
+	}
+	if len(peer.Tags) > 0 {
+		line += fmt.Sprintf(", tags: %s", strings.Join(peer.Tags, ", "))
+	}
+	return line + "\n"
+}
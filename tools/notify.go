@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// notifySessions sends a log notification to every connected client. Clients
+// only receive log messages after setting a log level, so the message is also
+// written to the server log, which MCP hosts usually capture from stderr.
+func notifySessions(ctx context.Context, server *mcp.Server, level mcp.LoggingLevel, message string) {
+	slog.Log(ctx, slogLevel(level), message)
+
+	for session := range server.Sessions() {
+		_ = session.Log(ctx, &mcp.LoggingMessageParams{
+			Level:  level,
+			Logger: "tailscale-mcp",
+			Data:   message,
+		})
+	}
+}
+
+// slogLevel maps an MCP (syslog-style) logging level to the nearest slog level
+func slogLevel(level mcp.LoggingLevel) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "info", "notice":
+		return slog.LevelInfo
+	case "warning":
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
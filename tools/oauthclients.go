@@ -0,0 +1,295 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// oauthScopes are the scopes an OAuth client can be granted. Each also has a
+// read-only ":read" form.
+var oauthScopes = []string{
+	"all",
+	"account_settings",
+	"auth_keys",
+	"devices:core",
+	"devices:posture_attributes",
+	"devices:routes",
+	"dns",
+	"feature_settings",
+	"logs:configuration",
+	"logs:network",
+	"oauth_keys",
+	"policy_file",
+	"services",
+	"users",
+	"webhooks",
+}
+
+// taggedOAuthScopes need the client to have tags, which are the tags it may
+// give devices and auth keys it creates
+var taggedOAuthScopes = []string{"all", "auth_keys", "devices:core"}
+
+// oauthPresets are scope and tag sets for common OAuth client uses
+var oauthPresets = map[string]struct {
+	scopes []string
+	tags   []string
+}{
+	// The Kubernetes operator creates proxies as devices joined with auth keys
+	"k8s-operator": {scopes: []string{"devices:core", "auth_keys", "services"}, tags: []string{"tag:k8s-operator"}},
+}
+
+// validOAuthScope reports whether scope is a known scope or its read-only form
+func validOAuthScope(scope string) bool {
+	return containsString(oauthScopes, strings.TrimSuffix(scope, ":read"))
+}
+
+// RegisterOAuthClientTools registers tools for managing the tailnet's OAuth clients
+func RegisterOAuthClientTools(server *mcp.Server, api *tailscale.APIClient, output *OutputWriter) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "list_oauth_clients",
+			Description: "List the tailnet's OAuth clients with their scopes and tags",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			clients, err := api.ListOAuthClients(ctx)
+			if err != nil {
+				return errorResult("Error listing OAuth clients", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("OAuth Clients (%d):\n\n", len(clients)))
+			if len(clients) == 0 {
+				result.WriteString("No OAuth clients found.\n")
+			}
+			for i, client := range clients {
+				result.WriteString(fmt.Sprintf("ID: %s\n", client.ID))
+				if client.ID == api.OAuthClientID() {
+					result.WriteString("(used by this server)\n")
+				}
+				writeAuthKey(&result, client)
+				result.WriteString("\n")
+				// The secret is only returned on creation, but don't pass it on if it is
+				clients[i].Key = ""
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"clients": clients}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "create_oauth_client",
+			Description: "Create an OAuth client with the given scopes and tags, or a preset such as k8s-operator. The client secret is only returned on creation; save it with secrets_file.",
+			Annotations: updateTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"description": {
+						Type:        "string",
+						Description: "What the client is for, up to 50 letters, digits, spaces or hyphens",
+					},
+					"scopes": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: fmt.Sprintf("Scopes to grant: %s, or any of these with :read for read-only access", strings.Join(oauthScopes, ", ")),
+					},
+					"tags": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "Tags the client may assign; required with the auth_keys, devices:core or all scopes",
+					},
+					"preset": {
+						Type:        "string",
+						Description: "Use the scopes and tags for a known use: k8s-operator (devices:core, auth_keys and services with tag:k8s-operator). Given scopes and tags are added to the preset's",
+						Enum:        []interface{}{"k8s-operator"},
+					},
+					"secrets_file": secretsFileProperty("client secret"),
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Description string   `json:"description"`
+				Scopes      []string `json:"scopes"`
+				Tags        []string `json:"tags"`
+				Preset      string   `json:"preset"`
+				SecretsFile string   `json:"secrets_file"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.SecretsFile != "" {
+				if _, err := output.Resolve(ctx, req.Session, params.SecretsFile); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			options := tailscale.OAuthClientOptions{Description: strings.TrimSpace(params.Description)}
+			preset, hasPreset := oauthPresets[params.Preset]
+			scopes := append(append([]string{}, preset.scopes...), params.Scopes...)
+			tags := append(append([]string{}, preset.tags...), params.Tags...)
+			if hasPreset && options.Description == "" {
+				options.Description = params.Preset
+			}
+			for _, scope := range scopes {
+				if scope = strings.TrimSpace(scope); scope != "" && !containsString(options.Scopes, scope) {
+					options.Scopes = append(options.Scopes, scope)
+				}
+			}
+			for _, tag := range tags {
+				if tag = tailscale.NormalizeTag(tag); tag != "" && !containsString(options.Tags, tag) {
+					options.Tags = append(options.Tags, tag)
+				}
+			}
+
+			var problem string
+			var invalid []string
+			for _, scope := range options.Scopes {
+				if !validOAuthScope(scope) {
+					invalid = append(invalid, scope)
+				}
+			}
+			needsTags := false
+			for _, scope := range options.Scopes {
+				if containsString(taggedOAuthScopes, scope) {
+					needsTags = true
+				}
+			}
+			switch {
+			case params.Preset != "" && !hasPreset:
+				problem = fmt.Sprintf("Unknown preset %q; the only preset is k8s-operator", params.Preset)
+			case len(options.Scopes) == 0:
+				problem = "No scopes given. Pass scopes or a preset."
+			case len(invalid) > 0:
+				problem = fmt.Sprintf("Unknown scopes: %s. Valid scopes are %s, each optionally with :read", strings.Join(invalid, ", "), strings.Join(oauthScopes, ", "))
+			case needsTags && len(options.Tags) == 0:
+				problem = "The auth_keys, devices:core and all scopes need tags; pass the tags the client may assign"
+			case options.Description != "" && !authKeyDescriptionPattern.MatchString(options.Description):
+				problem = "description must be at most 50 letters, digits, spaces or hyphens"
+			}
+			if problem != "" {
+				return invalidInputResult(problem), nil
+			}
+
+			if refused := checkTagOwners(ctx, api, options.Tags); refused != nil {
+				return refused, nil
+			}
+
+			client, err := api.CreateOAuthClient(ctx, options)
+			if err != nil {
+				return errorResult("Error creating OAuth client", err), nil
+			}
+			if len(client.Scopes) == 0 {
+				client.Scopes = options.Scopes
+			}
+			if len(client.Tags) == 0 {
+				client.Tags = options.Tags
+			}
+
+			var result strings.Builder
+			result.WriteString("OAuth Client Created:\n\n")
+			result.WriteString(fmt.Sprintf("Client ID: %s\n", client.ID))
+			result.WriteString(fmt.Sprintf("Client secret: %s\n", client.Key))
+			if client.Description != "" {
+				result.WriteString(fmt.Sprintf("Description: %s\n", client.Description))
+			}
+			result.WriteString(fmt.Sprintf("Scopes: %s\n", strings.Join(client.Scopes, ", ")))
+			if len(client.Tags) > 0 {
+				result.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(client.Tags, ", ")))
+			}
+			result.WriteString("\n⚠ Store the secret now; it can't be retrieved again.\n")
+			link := saveSecret(ctx, output, req.Session, params.SecretsFile, "client secret", client.Key, &result)
+			if params.Preset == "k8s-operator" {
+				result.WriteString("\nInstall the operator with these credentials, e.g.:\n")
+				result.WriteString(fmt.Sprintf("  helm upgrade --install tailscale-operator tailscale/tailscale-operator --namespace=tailscale --create-namespace --set-string oauth.clientId=%s --set-string oauth.clientSecret=<secret>\n", client.ID))
+				result.WriteString("tag:k8s-operator must own the tags the operator's proxies use (tag:k8s by default); see mcp__tailscale__k8s_prepare_acl.\n")
+			}
+
+			toolResult := structuredResult(result.String(), client)
+			if link != nil {
+				toolResult.Content = append(toolResult.Content, link)
+			}
+			return toolResult, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "revoke_oauth_client",
+			Description: "Revoke an OAuth client. Anything using its credentials stops working; access tokens it already issued stay valid until they expire.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"client_id": {
+						Type:        "string",
+						Description: "ID of the OAuth client to revoke",
+					},
+				},
+				Required: []string{"client_id"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				ClientID string `json:"client_id"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if params.ClientID == api.OAuthClientID() {
+				return refusedResult(fmt.Sprintf("%s is the OAuth client this server authenticates with; revoke it in the admin console if you really mean to", params.ClientID)), nil
+			}
+
+			// Only revoke OAuth clients, not auth or API keys with the same endpoint
+			clients, err := api.ListOAuthClients(ctx)
+			if err != nil {
+				return errorResult("Error listing OAuth clients", err), nil
+			}
+			var client *tailscale.AuthKey
+			for i := range clients {
+				if clients[i].ID == params.ClientID {
+					client = &clients[i]
+				}
+			}
+			if client == nil {
+				return notFoundResult(fmt.Sprintf("No OAuth client with ID %s", params.ClientID)), nil
+			}
+
+			if err := api.DeleteAuthKey(ctx, client.ID); err != nil {
+				return errorResult("Error revoking OAuth client", err), nil
+			}
+
+			name := client.ID
+			if client.Description != "" {
+				name = fmt.Sprintf("%s (%s)", client.ID, client.Description)
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("✓ Revoked OAuth client %s", name)},
+				},
+			}, nil
+		}),
+	)
+}
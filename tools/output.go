@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// OutputWriter writes tool output files (support bundles, exports, kubeconfigs)
+// into directories the client has approved. When the client exposes MCP roots,
+// files must land inside one of them; otherwise the configured output directory is used.
+type OutputWriter struct {
+	defaultDir string
+}
+
+// NewOutputWriter creates an output writer. An empty dir falls back to a
+// tailscale-mcp directory under the system temp dir.
+func NewOutputWriter(dir string) *OutputWriter {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "tailscale-mcp")
+	}
+	return &OutputWriter{defaultDir: dir}
+}
+
+// AllowedDirs returns the directories files may be written to for this session
+func (w *OutputWriter) AllowedDirs(ctx context.Context, session *mcp.ServerSession) []string {
+	if session != nil {
+		rootsCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		if result, err := session.ListRoots(rootsCtx, nil); err == nil && len(result.Roots) > 0 {
+			var dirs []string
+			for _, root := range result.Roots {
+				if dir, ok := rootToPath(root.URI); ok {
+					dirs = append(dirs, dir)
+				}
+			}
+			if len(dirs) > 0 {
+				return dirs
+			}
+		}
+	}
+
+	return []string{w.defaultDir}
+}
+
+// Resolve turns a requested file name into an absolute path inside an allowed
+// directory. Relative names are placed in the first allowed directory.
+func (w *OutputWriter) Resolve(ctx context.Context, session *mcp.ServerSession, requested string) (string, error) {
+	if strings.TrimSpace(requested) == "" {
+		return "", fmt.Errorf("output file name is required")
+	}
+	return w.resolve(ctx, session, requested, false)
+}
+
+// ResolveDir is Resolve for a directory to write files into. An empty name
+// or an allowed directory itself is accepted as well as its descendants.
+func (w *OutputWriter) ResolveDir(ctx context.Context, session *mcp.ServerSession, requested string) (string, error) {
+	if strings.TrimSpace(requested) == "" {
+		requested = "."
+	}
+	return w.resolve(ctx, session, requested, true)
+}
+
+// resolve places requested inside an allowed directory, accepting the
+// directory itself only when dirOK is set
+func (w *OutputWriter) resolve(ctx context.Context, session *mcp.ServerSession, requested string, dirOK bool) (string, error) {
+	allowed := w.AllowedDirs(ctx, session)
+
+	path := requested
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(allowed[0], path)
+	}
+	path = filepath.Clean(path)
+
+	// Resolve symlinks in the existing part of the path so a link can't point outside a root
+	resolved, err := resolveExisting(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, dir := range allowed {
+		resolvedDir, err := resolveExisting(filepath.Clean(dir))
+		if err != nil {
+			continue
+		}
+		if isWithin(resolvedDir, resolved) || (dirOK && resolved == resolvedDir) {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("path %s is outside the allowed output directories (%s)", requested, strings.Join(allowed, ", "))
+}
+
+// Write validates the requested path, writes data to it, and returns a
+// resource link pointing at the written file
+func (w *OutputWriter) Write(ctx context.Context, session *mcp.ServerSession, requested string, data []byte, mimeType string) (*mcp.ResourceLink, error) {
+	path, err := w.Resolve(ctx, session, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	size := int64(len(data))
+	return &mcp.ResourceLink{
+		URI:      (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String(),
+		Name:     filepath.Base(path),
+		MIMEType: mimeType,
+		Size:     &size,
+	}, nil
+}
+
+// rootToPath converts a file:// root URI into a local path
+func rootToPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+// resolveExisting evaluates symlinks for the longest existing prefix of path
+func resolveExisting(path string) (string, error) {
+	existing := path
+	var rest []string
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", existing, err)
+	}
+	return filepath.Join(append([]string{resolved}, rest...)...), nil
+}
+
+// isWithin reports whether path is a strict descendant of dir; dir itself
+// isn't a file that can be written
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
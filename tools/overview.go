@@ -0,0 +1,326 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const (
+	defaultAuditHours = 24
+	defaultAuditLimit = 10
+)
+
+// pendingRoutes are routes a device advertises that haven't been approved
+type pendingRoutes struct {
+	DeviceID string   `json:"device_id"`
+	Name     string   `json:"name"`
+	Routes   []string `json:"routes"`
+}
+
+// auditEvent is a configuration change in the overview
+type auditEvent struct {
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor"`
+	Action string    `json:"action"`
+	Target string    `json:"target"`
+}
+
+// tailnetOverview is the structured result of tailnet_overview. Source is
+// api when device data covers the whole tailnet, or status when it only
+// covers the peers this node can see.
+type tailnetOverview struct {
+	Tailnet        string               `json:"tailnet,omitempty"`
+	Self           string               `json:"self,omitempty"`
+	BackendState   string               `json:"backend_state"`
+	Source         string               `json:"source"`
+	Devices        int                  `json:"devices"`
+	Online         int                  `json:"online"`
+	ByOS           map[string]int       `json:"by_os"`
+	PendingDevices []string             `json:"pending_devices"`
+	PendingRoutes  []pendingRoutes      `json:"pending_routes"`
+	ExpiringKeys   []expiringKey        `json:"expiring_keys"`
+	Health         []string             `json:"health"`
+	DNS            *tailscale.DNSConfig `json:"dns,omitempty"`
+	AuditEvents    []auditEvent         `json:"audit_events"`
+	Errors         []string             `json:"errors,omitempty"`
+}
+
+// unapprovedRoutes returns the advertised routes that aren't enabled
+func unapprovedRoutes(d tailscale.Device) []string {
+	var routes []string
+	for _, route := range d.AdvertisedRoutes {
+		if !containsString(d.EnabledRoutes, route) {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// auditEvents summarizes the newest limit audit log entries, newest first
+func auditEvents(logs []tailscale.ConfigurationAuditLog, limit int) []auditEvent {
+	sort.Slice(logs, func(i, j int) bool { return logs[i].EventTime.After(logs[j].EventTime) })
+	if len(logs) > limit {
+		logs = logs[:limit]
+	}
+	events := make([]auditEvent, 0, len(logs))
+	for _, l := range logs {
+		actor := l.Actor.LoginName
+		if actor == "" {
+			actor = l.Actor.DisplayName
+		}
+		if actor == "" {
+			actor = strings.ToLower(l.Actor.Type)
+		}
+		target := strings.ToLower(l.Target.Type)
+		if l.Target.Name != "" {
+			target += " " + l.Target.Name
+		}
+		if l.Target.Property != "" {
+			target += " (" + strings.ToLower(l.Target.Property) + ")"
+		}
+		events = append(events, auditEvent{
+			Time:   l.EventTime,
+			Actor:  actor,
+			Action: strings.ToLower(l.Action),
+			Target: target,
+		})
+	}
+	return events
+}
+
+// collectOverview merges the local status with tailnet data from the API.
+// Without the API, device counts and key expiry fall back to the peers in
+// the local status and the API-only sections are left empty. A failed API
+// call is recorded in Errors rather than failing the whole overview.
+func collectOverview(ctx context.Context, cli *tailscale.CLI, api *tailscale.APIClient, auditHours, auditLimit int) (*tailnetOverview, error) {
+	status, err := cli.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	now := time.Now()
+	o := &tailnetOverview{
+		BackendState:   status.BackendState,
+		ByOS:           map[string]int{},
+		PendingDevices: []string{},
+		PendingRoutes:  []pendingRoutes{},
+		ExpiringKeys:   []expiringKey{},
+		Health:         status.Health,
+		AuditEvents:    []auditEvent{},
+	}
+	if o.Health == nil {
+		o.Health = []string{}
+	}
+	if status.Self != nil {
+		o.Self = peerHost(status.Self)
+	}
+	if status.CurrentTailnet != nil {
+		o.Tailnet = status.CurrentTailnet.Name
+		o.DNS = &tailscale.DNSConfig{MagicDNS: status.CurrentTailnet.MagicDNSEnabled}
+	}
+
+	var devices []tailscale.Device
+	fromAPI := false
+	if api != nil && api.IsAvailable() {
+		devices, err = api.ListAllDeviceFields(ctx)
+		if err != nil {
+			o.Errors = append(o.Errors, fmt.Sprintf("devices: %v", err))
+		} else {
+			fromAPI = true
+		}
+	}
+
+	if fromAPI {
+		o.Source = "api"
+		for _, d := range devices {
+			o.Devices++
+			if d.Online {
+				o.Online++
+			}
+			o.ByOS[d.OS]++
+			name := strings.SplitN(d.Name, ".", 2)[0]
+			if !d.Authorized {
+				o.PendingDevices = append(o.PendingDevices, name)
+			}
+			if routes := unapprovedRoutes(d); len(routes) > 0 {
+				o.PendingRoutes = append(o.PendingRoutes, pendingRoutes{DeviceID: d.ID, Name: name, Routes: routes})
+			}
+		}
+	} else {
+		o.Source = "status"
+		peers := make([]*tailscale.PeerStatus, 0, len(status.Peer)+1)
+		if status.Self != nil {
+			peers = append(peers, status.Self)
+		}
+		for _, peer := range status.Peer {
+			if peer != nil {
+				peers = append(peers, peer)
+			}
+		}
+		for _, p := range peers {
+			o.Devices++
+			if p.Online || p == status.Self {
+				o.Online++
+			}
+			o.ByOS[p.OS]++
+			devices = append(devices, tailscale.Device{ID: p.ID, Name: peerHost(p), Tags: p.Tags, KeyExpiry: p.KeyExpiry})
+		}
+	}
+	o.ExpiringKeys = append(o.ExpiringKeys, expiringKeys(devices, now, now.Add(keyExpiryWarning), true)...)
+	sort.Strings(o.PendingDevices)
+	sort.Slice(o.PendingRoutes, func(i, j int) bool { return o.PendingRoutes[i].Name < o.PendingRoutes[j].Name })
+
+	if api == nil || !api.IsAvailable() {
+		return o, nil
+	}
+	if dns, err := api.GetDNS(ctx); err != nil {
+		o.Errors = append(o.Errors, fmt.Sprintf("dns: %v", err))
+	} else {
+		o.DNS = dns
+	}
+	logs, err := api.GetConfigurationAuditLogs(ctx, now.Add(-time.Duration(auditHours)*time.Hour), now)
+	if err != nil {
+		o.Errors = append(o.Errors, fmt.Sprintf("audit log: %v", err))
+	} else {
+		o.AuditEvents = auditEvents(logs, auditLimit)
+	}
+	return o, nil
+}
+
+// RegisterOverviewTools registers the tailnet overview dashboard
+func RegisterOverviewTools(server *mcp.Server, cli *tailscale.CLI, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "tailnet_overview",
+			Description: "One-call summary of the tailnet: device counts by OS and online state, devices and subnet routes waiting for approval, node keys expired or expiring within 7 days, health warnings, DNS configuration and recent configuration audit events. Combines the local status with API data; without the API, counts cover only the peers this node can see.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"audit_hours": {
+						Type:        "integer",
+						Description: fmt.Sprintf("Show audit events from this many past hours (default: %d)", defaultAuditHours),
+					},
+					"audit_limit": {
+						Type:        "integer",
+						Description: fmt.Sprintf("Show at most this many audit events, newest first (default: %d)", defaultAuditLimit),
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				AuditHours int `json:"audit_hours"`
+				AuditLimit int `json:"audit_limit"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.AuditHours <= 0 {
+				params.AuditHours = defaultAuditHours
+			}
+			if params.AuditLimit <= 0 {
+				params.AuditLimit = defaultAuditLimit
+			}
+
+			o, err := collectOverview(ctx, cli, api, params.AuditHours, params.AuditLimit)
+			if err != nil {
+				return errorResult("Error", err), nil
+			}
+			hasAPI := api != nil && api.IsAvailable()
+
+			var result strings.Builder
+			result.WriteString("=== Tailnet Overview ===\n\n")
+			if o.Tailnet != "" {
+				result.WriteString(fmt.Sprintf("Tailnet: %s\n", o.Tailnet))
+			}
+			result.WriteString(fmt.Sprintf("This node: %s (%s)\n", o.Self, o.BackendState))
+
+			result.WriteString(fmt.Sprintf("\nDevices: %d total, %d online, %d offline", o.Devices, o.Online, o.Devices-o.Online))
+			if o.Source == "status" {
+				result.WriteString(" (peers visible to this node)")
+			}
+			result.WriteString("\n")
+			writeCounts(&result, o.ByOS)
+
+			if hasAPI {
+				result.WriteString("\nPending Approvals:\n")
+				if len(o.PendingDevices) == 0 && len(o.PendingRoutes) == 0 {
+					result.WriteString("  ✓ Nothing waiting for approval\n")
+				}
+				if len(o.PendingDevices) > 0 {
+					result.WriteString(fmt.Sprintf("  ⚠ %d device(s) awaiting authorization: %s\n", len(o.PendingDevices), strings.Join(o.PendingDevices, ", ")))
+				}
+				for _, p := range o.PendingRoutes {
+					result.WriteString(fmt.Sprintf("  ⚠ %s advertises unapproved routes: %s\n", p.Name, strings.Join(p.Routes, ", ")))
+				}
+			}
+
+			result.WriteString("\nKey Expiry (next 7 days):\n")
+			if len(o.ExpiringKeys) == 0 {
+				result.WriteString("  ✓ No keys expired or expiring soon\n")
+			}
+			for _, k := range o.ExpiringKeys {
+				mark := "⚠"
+				if k.Expired {
+					mark = "✗"
+				}
+				result.WriteString(fmt.Sprintf("  %s %s: %s\n", mark, k.Name, describeExpiry(k)))
+			}
+
+			result.WriteString("\nHealth:\n")
+			if len(o.Health) == 0 {
+				result.WriteString("  ✓ No health warnings\n")
+			}
+			for _, h := range o.Health {
+				result.WriteString(fmt.Sprintf("  ⚠ %s\n", h))
+			}
+
+			if o.DNS != nil {
+				result.WriteString("\nDNS:\n")
+				result.WriteString(fmt.Sprintf("  MagicDNS: %s\n", map[bool]string{true: "enabled", false: "disabled"}[o.DNS.MagicDNS]))
+				if hasAPI {
+					nameservers := "(none, using device defaults)"
+					if len(o.DNS.Nameservers) > 0 {
+						nameservers = strings.Join(o.DNS.Nameservers, ", ")
+					}
+					result.WriteString(fmt.Sprintf("  Nameservers: %s\n", nameservers))
+					if len(o.DNS.Domains) > 0 {
+						result.WriteString(fmt.Sprintf("  Search domains: %s\n", strings.Join(o.DNS.Domains, ", ")))
+					}
+				}
+			}
+
+			if hasAPI {
+				result.WriteString(fmt.Sprintf("\nRecent Audit Events (last %dh):\n", params.AuditHours))
+				if len(o.AuditEvents) == 0 {
+					result.WriteString("  (none)\n")
+				}
+				for _, e := range o.AuditEvents {
+					result.WriteString(fmt.Sprintf("  %s %s %s %s\n", e.Time.Local().Format("2006-01-02 15:04"), e.Actor, e.Action, e.Target))
+				}
+			} else {
+				result.WriteString("\nSet TAILSCALE_API_KEY for tailnet-wide counts, pending approvals, DNS settings and audit events.\n")
+			}
+
+			if len(o.Errors) > 0 {
+				result.WriteString("\n✗ Some data couldn't be fetched:\n")
+				for _, e := range o.Errors {
+					result.WriteString(fmt.Sprintf("  - %s\n", e))
+				}
+			}
+
+			return structuredResult(result.String(), o), nil
+		}),
+	)
+}
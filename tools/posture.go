@@ -0,0 +1,346 @@
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// customAttributePrefix is the namespace for attributes set through the API
+const customAttributePrefix = "custom:"
+
+// attributeChange is a single posture attribute update for a device
+type attributeChange struct {
+	Key    string
+	Old    interface{}
+	New    interface{}
+	Delete bool
+}
+
+// RegisterPostureTools registers posture attribute management tools
+func RegisterPostureTools(server *mcp.Server, api *tailscale.APIClient, output *OutputWriter) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "sync_posture_attributes",
+			Description: "Bulk-sync custom posture attributes from a CSV or JSON mapping of device to attribute values (e.g., exported from an asset management system). Shows a per-device diff by default; pass dry_run: false to apply.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"data": {
+						Type:        "string",
+						Description: "Mapping content. CSV: a header row of device,<attribute>,... and one row per device. JSON: {\"<device>\": {\"<attribute>\": value}}. Devices may be IDs, hostnames, or MagicDNS names.",
+					},
+					"file": {
+						Type:        "string",
+						Description: "Read the mapping from this file instead of data (must be inside the allowed directories)",
+					},
+					"format": {
+						Type:        "string",
+						Description: "Mapping format (default: detected from content)",
+						Enum:        []any{"csv", "json"},
+					},
+					"delete_missing": {
+						Type:        "boolean",
+						Description: "Remove custom attributes on the listed devices that aren't in the mapping (default: false)",
+					},
+					"comment": {
+						Type:        "string",
+						Description: "Comment recorded with each attribute change",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only show the diff (default: true)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Data          string `json:"data"`
+				File          string `json:"file"`
+				Format        string `json:"format"`
+				DeleteMissing bool   `json:"delete_missing"`
+				Comment       string `json:"comment"`
+				DryRun        *bool  `json:"dry_run"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			dryRun := params.DryRun == nil || *params.DryRun
+
+			data := params.Data
+			if params.File != "" {
+				path, err := output.Resolve(ctx, req.Session, params.File)
+				if err != nil {
+					return errorResult("Error", err), nil
+				}
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return errorResult(fmt.Sprintf("Error reading %s", path), err), nil
+				}
+				data = string(content)
+			}
+			if strings.TrimSpace(data) == "" {
+				return invalidInputResult("Either data or file is required"), nil
+			}
+
+			mapping, err := parseAttributeMapping(data, params.Format)
+			if err != nil {
+				return errorResult("Error parsing mapping", err), nil
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+
+			var result strings.Builder
+			if dryRun {
+				result.WriteString("Posture attribute sync (dry run)\n\n")
+			} else {
+				result.WriteString("Posture attribute sync\n\n")
+			}
+
+			names := make([]string, 0, len(mapping))
+			for name := range mapping {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			unmatched := []string{}
+			changed, unchanged, failed := 0, 0, 0
+			var firstErr error
+			progress := newProgress(req, len(names))
+			for _, name := range names {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				progress.step(ctx, "syncing "+name)
+				device := findDeviceByID(devices, name)
+				if device == nil {
+					device = findDeviceByHost(devices, name)
+				}
+				if device == nil {
+					unmatched = append(unmatched, name)
+					continue
+				}
+
+				current, err := api.GetPostureAttributes(ctx, device.ID)
+				if err != nil {
+					failed++
+					if firstErr == nil {
+						firstErr = err
+					}
+					result.WriteString(fmt.Sprintf("✗ %s: error getting attributes: %v\n", name, err))
+					continue
+				}
+
+				changes := diffAttributes(current, mapping[name], params.DeleteMissing)
+				if len(changes) == 0 {
+					unchanged++
+					continue
+				}
+				changed++
+
+				result.WriteString(fmt.Sprintf("%s (%s)\n", device.Hostname, device.ID))
+				for _, c := range changes {
+					line := describeAttributeChange(c)
+					if !dryRun {
+						var err error
+						if c.Delete {
+							err = api.DeletePostureAttribute(ctx, device.ID, c.Key)
+						} else {
+							err = api.SetPostureAttribute(ctx, device.ID, c.Key, c.New, params.Comment)
+						}
+						if err != nil {
+							failed++
+							if firstErr == nil {
+								firstErr = err
+							}
+							result.WriteString(fmt.Sprintf("  ✗ %s: %v\n", line, err))
+							continue
+						}
+					}
+					result.WriteString(fmt.Sprintf("  %s\n", line))
+				}
+			}
+
+			result.WriteString(fmt.Sprintf("\n%d device(s) with changes, %d already in sync", changed, unchanged))
+			if failed > 0 {
+				result.WriteString(fmt.Sprintf(", %d error(s)", failed))
+			}
+			result.WriteString("\n")
+
+			if len(unmatched) > 0 {
+				result.WriteString(fmt.Sprintf("\n⚠ No device found for: %s\n", strings.Join(unmatched, ", ")))
+			}
+			if dryRun && changed > 0 {
+				result.WriteString("\nRe-run with dry_run: false to apply these changes.\n")
+			}
+
+			summary := map[string]interface{}{
+				"dry_run":   dryRun,
+				"changed":   changed,
+				"unchanged": unchanged,
+				"failed":    failed,
+				"unmatched": unmatched,
+			}
+			// A partial apply is a failure, classified by its first error,
+			// with the counts alongside
+			if failed > 0 {
+				errResult := reportErrorResult(result.String(), fmt.Errorf("%d posture attribute operation(s) failed, first: %w", failed, firstErr))
+				for key, value := range summary {
+					errResult.StructuredContent.(map[string]interface{})[key] = value
+				}
+				return errResult, nil
+			}
+			return structuredResult(result.String(), summary), nil
+		}),
+	)
+}
+
+// parseAttributeMapping parses a device → attributes mapping from CSV or JSON.
+// Attribute keys are put in the custom: namespace if they aren't already.
+func parseAttributeMapping(data, format string) (map[string]map[string]interface{}, error) {
+	if format == "" {
+		format = "csv"
+		if strings.HasPrefix(strings.TrimSpace(data), "{") {
+			format = "json"
+		}
+	}
+
+	raw := map[string]map[string]interface{}{}
+	switch format {
+	case "json":
+		if err := json.Unmarshal([]byte(data), &raw); err != nil {
+			return nil, err
+		}
+	case "csv":
+		records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) < 2 || len(records[0]) < 2 {
+			return nil, fmt.Errorf("CSV needs a header row (device,<attribute>,...) and at least one device row")
+		}
+
+		header := records[0]
+		for _, row := range records[1:] {
+			device := strings.TrimSpace(row[0])
+			if device == "" {
+				continue
+			}
+			attrs := raw[device]
+			if attrs == nil {
+				attrs = map[string]interface{}{}
+				raw[device] = attrs
+			}
+			for i := 1; i < len(header) && i < len(row); i++ {
+				// Empty cells leave the attribute unset
+				if cell := strings.TrimSpace(row[i]); cell != "" {
+					attrs[strings.TrimSpace(header[i])] = parseAttributeValue(cell)
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected csv or json)", format)
+	}
+
+	mapping := make(map[string]map[string]interface{}, len(raw))
+	for device, attrs := range raw {
+		normalized := make(map[string]interface{}, len(attrs))
+		for key, value := range attrs {
+			if !strings.HasPrefix(key, customAttributePrefix) {
+				key = customAttributePrefix + key
+			}
+			switch value.(type) {
+			case string, bool, float64:
+			default:
+				return nil, fmt.Errorf("%s: attribute %s must be a string, number, or boolean", device, key)
+			}
+			normalized[key] = value
+		}
+		mapping[device] = normalized
+	}
+
+	return mapping, nil
+}
+
+// parseAttributeValue converts a CSV cell to a boolean or number where possible
+func parseAttributeValue(cell string) interface{} {
+	if cell == "true" || cell == "false" {
+		return cell == "true"
+	}
+	if n, err := strconv.ParseFloat(cell, 64); err == nil {
+		return n
+	}
+	return cell
+}
+
+// diffAttributes compares a device's current attributes with the desired custom
+// ones. Provider-set attributes (outside custom:) are never touched.
+func diffAttributes(current, desired map[string]interface{}, deleteMissing bool) []attributeChange {
+	var changes []attributeChange
+
+	for key, value := range desired {
+		old, ok := current[key]
+		if ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+		changes = append(changes, attributeChange{Key: key, Old: old, New: value})
+	}
+
+	if deleteMissing {
+		for key, value := range current {
+			if !strings.HasPrefix(key, customAttributePrefix) {
+				continue
+			}
+			if _, ok := desired[key]; !ok {
+				changes = append(changes, attributeChange{Key: key, Old: value, Delete: true})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+func describeAttributeChange(c attributeChange) string {
+	switch {
+	case c.Delete:
+		return fmt.Sprintf("- %s (was %v)", c.Key, c.Old)
+	case c.Old == nil:
+		return fmt.Sprintf("+ %s = %v", c.Key, c.New)
+	default:
+		return fmt.Sprintf("~ %s: %v → %v", c.Key, c.Old, c.New)
+	}
+}
+
+// findDeviceByID returns the device with the given ID, if any
+func findDeviceByID(devices []tailscale.Device, id string) *tailscale.Device {
+	for i := range devices {
+		if devices[i].ID == id {
+			return &devices[i]
+		}
+	}
+	return nil
+}
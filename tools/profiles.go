@@ -18,6 +18,7 @@ func RegisterProfileTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "switch_profile",
 			Description: "Switch to a different Tailscale profile",
+			Annotations: updateTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -31,21 +32,13 @@ func RegisterProfileTools(server *mcp.Server, cli *tailscale.CLI) {
 				Profile string `json:"profile"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			// Get list of profiles to find the right one
-			profiles, err := cli.ListProfiles()
+			profiles, err := cli.ListProfiles(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to list profiles: %v", err)},
-					},
-				}, nil
+				return errorResult("Failed to list profiles", err), nil
 			}
 
 			// Find matching profile by ID, account, or tailnet
@@ -76,11 +69,7 @@ func RegisterProfileTools(server *mcp.Server, cli *tailscale.CLI) {
 						targetProfile = &profile
 					} else {
 						// Multiple matches, need to be more specific
-						return &mcp.CallToolResult{
-							Content: []mcp.Content{
-								&mcp.TextContent{Text: fmt.Sprintf("Multiple profiles match '%s'. Please be more specific or use the profile ID.", params.Profile)},
-							},
-						}, nil
+						return invalidInputResult(fmt.Sprintf("Multiple profiles match '%s'. Please be more specific or use the profile ID.", params.Profile)), nil
 					}
 				}
 			}
@@ -109,13 +98,9 @@ func RegisterProfileTools(server *mcp.Server, cli *tailscale.CLI) {
 			}
 
 			// Switch using the profile ID
-			err = cli.SwitchProfile(targetProfile.ID)
+			err = cli.SwitchProfile(ctx, targetProfile.ID)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to switch to profile '%s': %v", targetProfile.Account, err)},
-					},
-				}, nil
+				return errorResult(fmt.Sprintf("Failed to switch to profile '%s'", targetProfile.Account), err), nil
 			}
 
 			return &mcp.CallToolResult{
@@ -132,16 +117,13 @@ func RegisterProfileTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "list_profiles",
 			Description: "List all available Tailscale profiles",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{Type: "object"},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			profiles, err := cli.ListProfiles()
+			profiles, err := cli.ListProfiles(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error listing profiles: %v", err)},
-					},
-				}, nil
+				return errorResult("Error listing profiles", err), nil
 			}
 
 			if len(profiles) == 0 {
@@ -164,11 +146,7 @@ func RegisterProfileTools(server *mcp.Server, cli *tailscale.CLI) {
 					profile.ID, profile.Tailnet, profile.Account, marker))
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result.String()},
-				},
-			}, nil
+			return structuredResult(result.String(), map[string]interface{}{"profiles": profiles}), nil
 		}),
 	)
 
@@ -177,26 +155,20 @@ func RegisterProfileTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "get_current_profile",
 			Description: "Get the currently active Tailscale profile",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{Type: "object"},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			profiles, err := cli.ListProfiles()
+			profiles, err := cli.ListProfiles(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting current profile: %v", err)},
-					},
-				}, nil
+				return errorResult("Error getting current profile", err), nil
 			}
 
 			for _, profile := range profiles {
 				if profile.Active {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: fmt.Sprintf("Current active profile:\n  ID: %s\n  Tailnet: %s\n  Account: %s",
-								profile.ID, profile.Tailnet, profile.Account)},
-						},
-					}, nil
+					text := fmt.Sprintf("Current active profile:\n  ID: %s\n  Tailnet: %s\n  Account: %s",
+						profile.ID, profile.Tailnet, profile.Account)
+					return structuredResult(text, profile), nil
 				}
 			}
 
@@ -213,11 +185,12 @@ func RegisterProfileTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "add_profile",
 			Description: "Add a new Tailscale profile by logging in to a different account",
+			Annotations: updateTool(false),
 			InputSchema: &jsonschema.Schema{Type: "object"},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			// Start the login process for a new profile
-			output, err := cli.LoginNewProfile()
+			output, err := cli.LoginNewProfile(ctx)
 			if err != nil {
 				// Check if it's because we need to specify a different account
 				if strings.Contains(err.Error(), "already logged in") || strings.Contains(output, "already logged in") {
@@ -231,11 +204,7 @@ func RegisterProfileTools(server *mcp.Server, cli *tailscale.CLI) {
 						},
 					}, nil
 				}
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to start login process: %v", err)},
-					},
-				}, nil
+				return errorResult("Failed to start login process", err), nil
 			}
 
 			// Extract auth URL if present
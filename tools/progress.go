@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressReporter sends MCP progress notifications for a tool call. It is
+// nil when the client didn't ask for progress (no progress token), and its
+// methods do nothing on a nil reporter, so handlers can report
+// unconditionally. It is safe for concurrent use by worker goroutines.
+type progressReporter struct {
+	session *mcp.ServerSession
+	token   any
+	total   float64
+
+	mu   sync.Mutex
+	done float64
+}
+
+// newProgress returns a reporter for a call that works through total steps,
+// or nil if the request has no progress token
+func newProgress(req *mcp.CallToolRequest, total int) *progressReporter {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+	return &progressReporter{session: req.Session, token: token, total: float64(total)}
+}
+
+// step marks one step as done and tells the client what finished
+func (p *progressReporter) step(ctx context.Context, message string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	// Holding the lock keeps notifications in order, so progress only grows;
+	// a client that went away just stops getting updates
+	_ = p.session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Progress:      p.done,
+		Total:         p.total,
+		Message:       message,
+	})
+}
@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// RegisterPrompts registers prompt templates for common workflows. Each
+// prompt expands into instructions that chain the server's tools in the
+// right order; tools that need API access or the Kubernetes operator are
+// called out so the agent can skip them when they aren't registered.
+func RegisterPrompts(server *mcp.Server) {
+	server.AddPrompt(
+		&mcp.Prompt{
+			Name:        "diagnose_connectivity",
+			Title:       "Diagnose connectivity between two devices",
+			Description: "Walk through finding why one device can't reach another: resolve both, check the policy, the path and DERP, then test the port",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "destination", Description: "Device that can't be reached (hostname, MagicDNS name or Tailscale IP)", Required: true},
+				{Name: "source", Description: "Device the traffic comes from (defaults to this node)"},
+				{Name: "port", Description: "Port that should be reachable on the destination (e.g., 22 or 443)"},
+			},
+		},
+		mcp.PromptHandler(diagnoseConnectivityPrompt),
+	)
+
+	server.AddPrompt(
+		&mcp.Prompt{
+			Name:        "prepare_k8s_operator_acl",
+			Title:       "Prepare the ACL for the Kubernetes operator",
+			Description: "Declare the operator's tags, check the policy and create its OAuth client before installing the Tailscale Kubernetes operator",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "proxy_tag", Description: "Tag the operator gives the proxies it creates (default tag:k8s)"},
+				{Name: "owners", Description: "Comma-separated owners of the operator tag (default autogroup:admin)"},
+			},
+		},
+		mcp.PromptHandler(prepareK8sOperatorACLPrompt),
+	)
+
+	server.AddPrompt(
+		&mcp.Prompt{
+			Name:        "onboard_server",
+			Title:       "Onboard a new server",
+			Description: "Bring a new server into the tailnet: tag ownership, access rules, an auth key, authorization, routes and a final health check",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "hostname", Description: "Hostname the server will join with", Required: true},
+				{Name: "tags", Description: "Comma-separated tags for the server (default tag:server)"},
+				{Name: "routes", Description: "Comma-separated subnet routes the server will advertise (e.g., 10.0.0.0/24)"},
+			},
+		},
+		mcp.PromptHandler(onboardServerPrompt),
+	)
+}
+
+// promptResult wraps prompt instructions as a single user message
+func promptResult(description, text string) *mcp.GetPromptResult {
+	return &mcp.GetPromptResult{
+		Description: description,
+		Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: text}},
+		},
+	}
+}
+
+// promptArg returns a trimmed prompt argument, or def when it is empty
+func promptArg(req *mcp.GetPromptRequest, name, def string) string {
+	if v := strings.TrimSpace(req.Params.Arguments[name]); v != "" {
+		return v
+	}
+	return def
+}
+
+// splitPromptList splits a comma-separated prompt argument
+func splitPromptList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func diagnoseConnectivityPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	destination := promptArg(req, "destination", "")
+	if destination == "" {
+		return nil, fmt.Errorf("missing required argument %q", "destination")
+	}
+	source := promptArg(req, "source", "")
+	port := promptArg(req, "port", "")
+
+	var b strings.Builder
+	if source == "" {
+		b.WriteString(fmt.Sprintf("Diagnose why this node can't reach %s", destination))
+	} else {
+		b.WriteString(fmt.Sprintf("Diagnose why %s can't reach %s", source, destination))
+	}
+	if port != "" {
+		b.WriteString(fmt.Sprintf(" on port %s", port))
+	}
+	b.WriteString(". Work through these steps in order and stop at the first one that explains the problem.\n\n")
+
+	b.WriteString(fmt.Sprintf("1. Resolve the devices: call get_device with device=%q", destination))
+	if source != "" {
+		b.WriteString(fmt.Sprintf(" and again with device=%q", source))
+	}
+	b.WriteString(". If a name matches several devices, ask which one is meant. Note whether each is online, when it was last seen, its tags and, with API access, whether it is authorized and its key has expired.\n")
+
+	b.WriteString("2. Check the policy (needs API access): ")
+	switch {
+	case port != "":
+		b.WriteString(fmt.Sprintf("call acl_preview with device=%q and port=%s to list the rules that allow traffic to it, and check that one of them covers the source's user or tags. ", destination, port))
+	default:
+		b.WriteString(fmt.Sprintf("call acl_preview with the source device's user to list what it may reach, and check that %s is among it. ", destination))
+	}
+	b.WriteString("If SSH is the problem, call check_ssh_access for the destination as well.\n")
+
+	if source == "" {
+		b.WriteString(fmt.Sprintf("3. Find the path: call connection_path with device=%q. It tells you whether the link is direct or relayed through DERP and, if relayed, why.\n", destination))
+	} else {
+		b.WriteString(fmt.Sprintf("3. Find the path: connection_path and ping_device measure from this node, not from %s. If this node is one of the two devices, call connection_path with the other one; otherwise check this node's path to both, and if ssh_exec is available run \"tailscale ping %s\" on %s.\n", source, destination, source))
+	}
+
+	b.WriteString("4. If the path is relayed or fails, call netcheck and derp_report to look for blocked UDP, hard NAT or a slow preferred DERP region, and health_check for warnings from tailscaled.\n")
+
+	if port != "" {
+		b.WriteString(fmt.Sprintf("5. Test the service: call nc with host=%q and port=%s. A reachable peer with a closed port means the service isn't listening, not a Tailscale problem.\n", destination, port))
+	} else {
+		b.WriteString(fmt.Sprintf("5. Test reachability: call ping_device with device=%q. A peer that answers pings but not the application points at the service or a host firewall.\n", destination))
+	}
+
+	b.WriteString("\nFinish with the likely cause, the evidence from the tool output, and the change that would fix it. Don't change the policy or device settings without asking first.")
+	return promptResult("Connectivity diagnosis for "+destination, b.String()), nil
+}
+
+func prepareK8sOperatorACLPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	proxyTag := tailscale.NormalizeTag(promptArg(req, "proxy_tag", "tag:k8s"))
+	owners := splitPromptList(promptArg(req, "owners", "autogroup:admin"))
+	const operatorTag = "tag:k8s-operator"
+
+	var b strings.Builder
+	b.WriteString("Prepare this tailnet's ACL policy for the Tailscale Kubernetes operator. The policy steps need API access (TAILSCALE_API_KEY or OAuth client credentials).\n\n")
+	b.WriteString("1. Call mcp__tailscale__k8s_prepare_acl for the reference configuration, if the Kubernetes tools are enabled. Otherwise follow the steps below.\n")
+	b.WriteString("2. Call acl_list_tag_owners and check whether the operator's tags are already declared.\n")
+	b.WriteString(fmt.Sprintf("3. Declare the operator tag: call acl_add_tag_owner with tag=%q and owners=[%s]. Review the diff, then repeat with dry_run=false.\n", operatorTag, quoteList(owners)))
+	b.WriteString(fmt.Sprintf("4. Let the operator tag its proxies: call acl_add_tag_owner with tag=%q and owners=[%q], reviewing the diff as before.\n", proxyTag, operatorTag))
+	b.WriteString(fmt.Sprintf("5. Make sure clients can reach the proxies: call acl_preview for a typical user and check that %s devices are reachable. If not, propose an acl_add_rule or acl_add_grant with dst %q and ask before applying it.\n", proxyTag, proxyTag+":*"))
+	b.WriteString("6. Call validate_acl on the final policy and acl_run_tests if the policy has tests.\n")
+	b.WriteString(fmt.Sprintf("7. Create the operator's OAuth client: call create_oauth_client with preset \"k8s-operator\" and tags [%q]. Show the client ID and secret once and tell the user to store them as the operator's Helm values or secret; they can't be retrieved again.\n", operatorTag))
+	b.WriteString("8. Once the operator is installed, call mcp__tailscale__k8s_operator_status to confirm it is running and has joined the tailnet.\n")
+	b.WriteString("\nSummarize the policy changes that were applied and anything left for the user to do.")
+	return promptResult("Kubernetes operator ACL preparation", b.String()), nil
+}
+
+func onboardServerPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	hostname := promptArg(req, "hostname", "")
+	if hostname == "" {
+		return nil, fmt.Errorf("missing required argument %q", "hostname")
+	}
+	var tags []string
+	for _, tag := range splitPromptList(promptArg(req, "tags", "tag:server")) {
+		tags = append(tags, tailscale.NormalizeTag(tag))
+	}
+	routes := splitPromptList(promptArg(req, "routes", ""))
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Onboard the new server %s into this tailnet with tags %s. Most steps need API access (TAILSCALE_API_KEY or OAuth client credentials).\n\n", hostname, strings.Join(tags, ", ")))
+	b.WriteString(fmt.Sprintf("1. Call get_device with device=%q to make sure no device already uses the name. If one does, ask whether it should be replaced with delete_device or the new server given another name.\n", hostname))
+	b.WriteString(fmt.Sprintf("2. Call acl_list_tag_owners. For any of %s that isn't declared, call acl_add_tag_owner, review the diff and apply it with dry_run=false.\n", strings.Join(tags, ", ")))
+	b.WriteString("3. Ask who should reach the server, then call acl_preview with one of those users to see whether a rule already covers its tags. Propose any missing acl_add_rule or acl_add_grant and ask before applying it.\n")
+	b.WriteString(fmt.Sprintf("4. Call create_auth_key with tags [%s], preauthorized=true, reusable=false and a short expiry_seconds (e.g., 3600). Give the user the command to run on the server:\n   tailscale up --auth-key=<key> --hostname=%s", quoteList(tags), hostname))
+	if len(routes) > 0 {
+		b.WriteString(" --advertise-routes=" + strings.Join(routes, ","))
+	}
+	b.WriteString("\n   Show the key once; it can't be retrieved again.\n")
+	b.WriteString(fmt.Sprintf("5. After the user confirms the server has joined, call get_device with device=%q. If it isn't authorized, call list_pending_devices and authorize_device with its device ID. If its tags differ, fix them with set_device_tags.\n", hostname))
+	if len(routes) > 0 {
+		b.WriteString(fmt.Sprintf("6. Approve its subnet routes: call approve_routes with the device ID and routes [%s], then get_device_routes to confirm they are advertised and enabled.\n", quoteList(routes)))
+	} else {
+		b.WriteString("6. If the server should act as a subnet router or exit node, have it advertise the routes and approve them with approve_routes; otherwise skip this step.\n")
+	}
+	b.WriteString(fmt.Sprintf("7. Verify: call ping_device and connection_path with device=%q and check that the path is direct, then key_expiry_report to confirm the tagged key doesn't expire.\n", hostname))
+	b.WriteString("\nSummarize what was created and changed, including the device ID and IPs.")
+	return promptResult("Onboarding "+hostname, b.String()), nil
+}
+
+// quoteList renders strings as a quoted, comma-separated list
+func quoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = fmt.Sprintf("%q", item)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// maxDeviceCandidates caps how many candidates an ambiguous match lists
+const maxDeviceCandidates = 10
+
+// deviceNotFoundError is returned when no device matches a query
+type deviceNotFoundError struct {
+	Query string
+}
+
+func (e *deviceNotFoundError) Error() string {
+	return fmt.Sprintf("no device matches %q; use list_devices to see device names", e.Query)
+}
+
+// ambiguousDeviceError is returned when a query matches several devices
+// equally well. Its message lists them so the caller can pick one.
+type ambiguousDeviceError struct {
+	Query      string
+	Candidates []*tailscale.PeerStatus
+}
+
+func (e *ambiguousDeviceError) Error() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%q matches %d devices; use a full name or IP:\n", e.Query, len(e.Candidates)))
+	for i, node := range e.Candidates {
+		if i == maxDeviceCandidates {
+			b.WriteString(fmt.Sprintf("  … and %d more\n", len(e.Candidates)-i))
+			break
+		}
+		b.WriteString("  " + describeCandidate(node) + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// describeCandidate renders a device in an ambiguous match
+func describeCandidate(node *tailscale.PeerStatus) string {
+	state := "offline"
+	if node.Online {
+		state = "online"
+	}
+	line := "• " + peerHost(node)
+	if !strings.EqualFold(node.HostName, peerHost(node)) {
+		line += fmt.Sprintf(" [hostname %s]", node.HostName)
+	}
+	if len(node.TailscaleIPs) > 0 {
+		line += fmt.Sprintf(" (%s)", node.TailscaleIPs[0])
+	}
+	return line + fmt.Sprintf(" %s, %s", node.OS, state)
+}
+
+// resolveDevice finds the node a user-supplied name refers to, trying in
+// order: a Tailscale IP, stable node ID or MagicDNS name (full or short);
+// the OS hostname; then a prefix and finally any part of the MagicDNS name
+// or hostname. The first step with exactly one match wins. A step with
+// several matches returns an ambiguousDeviceError instead of guessing, and
+// no match at all a deviceNotFoundError. The local node is only considered
+// when includeSelf is set.
+func resolveDevice(status *tailscale.Status, query string, includeSelf bool) (*tailscale.PeerStatus, error) {
+	q := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(query)), ".")
+	if q == "" {
+		return nil, &deviceNotFoundError{Query: query}
+	}
+
+	var nodes []*tailscale.PeerStatus
+	for _, node := range statusNodes(status) {
+		if node != status.Self || includeSelf {
+			nodes = append(nodes, node)
+		}
+	}
+
+	steps := []func(node *tailscale.PeerStatus, dnsName, short, host string) bool{
+		func(node *tailscale.PeerStatus, dnsName, short, host string) bool {
+			return containsString(node.TailscaleIPs, q) || (node.ID != "" && strings.EqualFold(node.ID, q)) || dnsName == q || short == q
+		},
+		func(node *tailscale.PeerStatus, dnsName, short, host string) bool {
+			return host == q
+		},
+		func(node *tailscale.PeerStatus, dnsName, short, host string) bool {
+			return strings.HasPrefix(short, q) || strings.HasPrefix(host, q)
+		},
+		func(node *tailscale.PeerStatus, dnsName, short, host string) bool {
+			return strings.Contains(short, q) || strings.Contains(host, q)
+		},
+	}
+	for _, matches := range steps {
+		var found []*tailscale.PeerStatus
+		for _, node := range nodes {
+			dnsName := strings.TrimSuffix(strings.ToLower(node.DNSName), ".")
+			short := strings.SplitN(dnsName, ".", 2)[0]
+			if matches(node, dnsName, short, strings.ToLower(node.HostName)) {
+				found = append(found, node)
+			}
+		}
+		switch {
+		case len(found) == 1:
+			return found[0], nil
+		case len(found) > 1:
+			sort.Slice(found, func(i, j int) bool {
+				if found[i].Online != found[j].Online {
+					return found[i].Online
+				}
+				return peerHost(found[i]) < peerHost(found[j])
+			})
+			return nil, &ambiguousDeviceError{Query: query, Candidates: found}
+		}
+	}
+	return nil, &deviceNotFoundError{Query: query}
+}
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// routeRouter is one device advertising a route
+type routeRouter struct {
+	Device   string `json:"device"`
+	DeviceID string `json:"device_id"`
+	Online   bool   `json:"online"`
+	Enabled  bool   `json:"enabled"`
+	Primary  bool   `json:"primary"`
+}
+
+// routeTableEntry is a route and every device advertising it
+type routeTableEntry struct {
+	Route   string        `json:"route"`
+	Routers []routeRouter `json:"routers"`
+}
+
+// routeOverlap is a pair of different routes covering some of the same addresses
+type routeOverlap struct {
+	Route   string `json:"route"`
+	Overlap string `json:"overlaps"`
+}
+
+// routeTable is the tailnet's subnet routes collated across devices
+type routeTable struct {
+	Routes      []routeTableEntry `json:"routes"`
+	ExitNodes   []routeRouter     `json:"exit_nodes"`
+	Unapproved  int               `json:"unapproved"`
+	Overlaps    []routeOverlap    `json:"overlaps"`
+	Unreachable []bulkResult      `json:"errors,omitempty"`
+}
+
+// RegisterRouteTableTools registers the tailnet-wide route overview
+func RegisterRouteTableTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "tailnet_routes",
+			Description: "Show one routing table for the whole tailnet: every advertised subnet route, the devices advertising it and whether each is approved and primary. Flags unapproved advertisements and overlapping CIDRs.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+
+			var mu sync.Mutex
+			routes := map[string]*tailscale.DeviceRoutes{}
+			results := runBulk(ctx, devices, defaultBulkConcurrency, newProgress(req, len(devices)), func(ctx context.Context, d tailscale.Device) error {
+				r, err := api.GetRoutes(ctx, d.ID)
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				routes[d.ID] = r
+				mu.Unlock()
+				return nil
+			})
+
+			table := buildRouteTable(devices, routes)
+			for _, r := range results {
+				if r.Error != "" {
+					table.Unreachable = append(table.Unreachable, r)
+				}
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Tailnet Routes (%d subnet route(s) across %d device(s))\n\n", len(table.Routes), len(devices)))
+			if len(table.Routes) == 0 {
+				result.WriteString("No subnet routes are advertised.\n")
+			}
+			for _, entry := range table.Routes {
+				result.WriteString(fmt.Sprintf("%s\n", entry.Route))
+				for _, router := range entry.Routers {
+					result.WriteString(fmt.Sprintf("  %s\n", routerLine(router)))
+				}
+			}
+
+			if len(table.ExitNodes) > 0 {
+				result.WriteString("\nExit nodes:\n")
+				for _, router := range table.ExitNodes {
+					result.WriteString(fmt.Sprintf("  %s\n", routerLine(router)))
+				}
+			}
+
+			if table.Unapproved > 0 {
+				result.WriteString(fmt.Sprintf("\n⚠ %d advertisement(s) awaiting approval; use enable_routes to approve them\n", table.Unapproved))
+			}
+			if len(table.Overlaps) > 0 {
+				result.WriteString("\n⚠ Overlapping routes (the more specific route wins for its addresses):\n")
+				for _, o := range table.Overlaps {
+					result.WriteString(fmt.Sprintf("  %s overlaps %s\n", o.Route, o.Overlap))
+				}
+			}
+			if len(table.Unreachable) > 0 {
+				result.WriteString("\nCould not get routes for:\n")
+				writeBulkResults(&result, table.Unreachable)
+			}
+
+			return structuredResult(result.String(), table), nil
+		}),
+	)
+}
+
+// buildRouteTable collates each device's routes by route. Exit node routes
+// are listed per device rather than as routes. Identical routes on several
+// devices are failover, not overlap, so only differing CIDRs are compared.
+func buildRouteTable(devices []tailscale.Device, routes map[string]*tailscale.DeviceRoutes) *routeTable {
+	table := &routeTable{Routes: []routeTableEntry{}, ExitNodes: []routeRouter{}, Overlaps: []routeOverlap{}}
+	entries := map[string]*routeTableEntry{}
+	var prefixes []netip.Prefix
+
+	for _, d := range devices {
+		r := routes[d.ID]
+		if r == nil {
+			continue
+		}
+		exit := false
+		for _, route := range r.AdvertisedRoutes {
+			router := routeRouter{
+				Device:   d.Hostname,
+				DeviceID: d.ID,
+				Online:   d.Online,
+				Enabled:  containsString(r.EnabledRoutes, route),
+				Primary:  containsString(d.PrimaryRoutes, route),
+			}
+			if !router.Enabled {
+				table.Unapproved++
+			}
+
+			if route == "0.0.0.0/0" || route == "::/0" {
+				// The IPv4 and IPv6 default routes are advertised together
+				if !exit {
+					table.ExitNodes = append(table.ExitNodes, router)
+					exit = true
+				} else if !router.Enabled {
+					table.ExitNodes[len(table.ExitNodes)-1].Enabled = false
+				}
+				continue
+			}
+
+			entry := entries[route]
+			if entry == nil {
+				entry = &routeTableEntry{Route: route}
+				entries[route] = entry
+				if prefix, err := netip.ParsePrefix(route); err == nil {
+					prefixes = append(prefixes, prefix)
+				}
+			}
+			entry.Routers = append(entry.Routers, router)
+		}
+	}
+
+	sort.Slice(prefixes, func(i, j int) bool {
+		a, b := prefixes[i], prefixes[j]
+		if a.Addr().Is4() != b.Addr().Is4() {
+			return a.Addr().Is4()
+		}
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c < 0
+		}
+		return a.Bits() < b.Bits()
+	})
+	for i, prefix := range prefixes {
+		entry := entries[prefix.String()]
+		sort.Slice(entry.Routers, func(a, b int) bool {
+			return entry.Routers[a].Device < entry.Routers[b].Device
+		})
+		table.Routes = append(table.Routes, *entry)
+		for _, other := range prefixes[i+1:] {
+			if prefix.Overlaps(other) {
+				table.Overlaps = append(table.Overlaps, routeOverlap{Route: other.String(), Overlap: prefix.String()})
+			}
+		}
+	}
+	// Routes that failed to parse still belong in the table
+	for route, entry := range entries {
+		if _, err := netip.ParsePrefix(route); err != nil {
+			table.Routes = append(table.Routes, *entry)
+		}
+	}
+	sort.Slice(table.ExitNodes, func(i, j int) bool {
+		return table.ExitNodes[i].Device < table.ExitNodes[j].Device
+	})
+
+	return table
+}
+
+// routerLine describes one device's state for a route
+func routerLine(router routeRouter) string {
+	var notes []string
+	if router.Primary {
+		notes = append(notes, "primary")
+	}
+	if !router.Online {
+		notes = append(notes, "offline")
+	}
+	mark, state := "✓", "approved"
+	if !router.Enabled {
+		mark, state = "✗", "awaiting approval"
+	}
+	line := fmt.Sprintf("%s %s (%s): %s", mark, router.Device, router.DeviceID, state)
+	if len(notes) > 0 {
+		line += " [" + strings.Join(notes, ", ") + "]"
+	}
+	return line
+}
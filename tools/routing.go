@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/netip"
 	"strings"
 
 	"github.com/google/jsonschema-go/jsonschema"
@@ -17,39 +18,72 @@ func RegisterRoutingTools(server *mcp.Server, cli *tailscale.CLI) {
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "set_exit_node",
-			Description: "Set a specific exit node for routing internet traffic",
+			Description: "Set a specific exit node for routing internet traffic. Give a node, or a country (and optionally city) to use the best Mullvad exit node there.",
+			Annotations: updateTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
-					"node": {Type: "string", Description: "Exit node hostname, device name, or Tailscale IP"},
+					"node":    {Type: "string", Description: "Exit node hostname, device name, Tailscale IP, or a unique part of a name"},
+					"country": {Type: "string", Description: "Pick a Mullvad exit node in this country, by name or code (e.g., Sweden or SE)"},
+					"city":    {Type: "string", Description: "Narrow the Mullvad pick to a city (e.g., Stockholm)"},
 				},
-				Required: []string{"node"},
 			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			var params struct {
-				Node string `json:"node"`
+				Node    string `json:"node"`
+				Country string `json:"country"`
+				City    string `json:"city"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
+			}
+
+			location := ""
+			if params.Node == "" {
+				if params.Country == "" && params.City == "" {
+					return invalidInputResult("Provide a node, or a country (and optionally city) to pick a Mullvad exit node."), nil
+				}
+				status, err := cli.Status(ctx)
+				if err != nil {
+					return errorResult("Error getting status", err), nil
+				}
+				peers := mullvadPeers(status)
+				if len(peers) == 0 {
+					return refusedResult("No Mullvad exit nodes are available. The tailnet needs the Mullvad add-on, and this device the mullvad node attribute (see acl_add_node_attr)."), nil
+				}
+				best := bestMullvadPeer(peers, params.Country, params.City)
+				if best == nil {
+					return notFoundResult(fmt.Sprintf("No online Mullvad exit node matches country %q, city %q. Use list_exit_nodes to see the locations.", params.Country, params.City)), nil
+				}
+				params.Node = strings.TrimSuffix(best.DNSName, ".")
+				location = fmt.Sprintf(" in %s, %s", best.Location.City, best.Location.Country)
+			} else {
+				status, err := cli.Status(ctx)
+				if err != nil {
+					return errorResult("Error getting status", err), nil
+				}
+				peer, err := resolveDevice(status, params.Node, false)
+				if err != nil {
+					return errorResult("", err), nil
+				}
+				if !peer.ExitNodeOption {
+					return refusedResult(fmt.Sprintf("%s isn't offering to be an exit node. Use list_exit_nodes to see the available ones.", peerHost(peer))), nil
+				}
+				params.Node = strings.TrimSuffix(peer.DNSName, ".")
+				if params.Node == "" && len(peer.TailscaleIPs) > 0 {
+					params.Node = peer.TailscaleIPs[0]
+				}
 			}
 
-			err := cli.SetExitNode(params.Node)
+			err := cli.SetExitNode(ctx, params.Node)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to set exit node '%s': %v", params.Node, err)},
-					},
-				}, nil
+				return errorResult(fmt.Sprintf("Failed to set exit node '%s'", params.Node), err), nil
 			}
 
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Successfully set exit node to '%s'. Internet traffic will now route through this device.", params.Node)},
+					&mcp.TextContent{Text: fmt.Sprintf("Successfully set exit node to '%s'%s. Internet traffic will now route through this device.", params.Node, location)},
 				},
 			}, nil
 		}),
@@ -60,16 +94,13 @@ func RegisterRoutingTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "clear_exit_node",
 			Description: "Clear the current exit node and route traffic directly",
+			Annotations: updateTool(true),
 			InputSchema: &jsonschema.Schema{Type: "object"},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			err := cli.ClearExitNode()
+			err := cli.ClearExitNode(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to clear exit node: %v", err)},
-					},
-				}, nil
+				return errorResult("Failed to clear exit node", err), nil
 			}
 
 			return &mcp.CallToolResult{
@@ -84,38 +115,61 @@ func RegisterRoutingTools(server *mcp.Server, cli *tailscale.CLI) {
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "list_exit_nodes",
-			Description: "List all available exit nodes in the network",
-			InputSchema: &jsonschema.Schema{Type: "object"},
+			Description: "List all available exit nodes in the network, and Mullvad exit nodes when the tailnet has the Mullvad add-on. Filter Mullvad nodes by country or city.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"country": {Type: "string", Description: "Only list Mullvad exit nodes in this country, by name or code (e.g., Sweden or SE). Lists every node there rather than the best per city"},
+					"city":    {Type: "string", Description: "Only list Mullvad exit nodes in a matching city"},
+					"mullvad": {Type: "boolean", Description: "Only list Mullvad exit nodes (default: false)"},
+				},
+			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			status, err := cli.Status()
+			var params struct {
+				Country string `json:"country"`
+				City    string `json:"city"`
+				Mullvad bool   `json:"mullvad"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			// A location filter only applies to Mullvad nodes
+			mullvadOnly := params.Mullvad || params.Country != "" || params.City != ""
+
+			status, err := cli.Status(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting exit node list: %v", err)},
-					},
-				}, nil
+				return errorResult("Error getting exit node list", err), nil
 			}
 
 			var result strings.Builder
 			result.WriteString("Available Exit Nodes:\n\n")
+			exitNodeOption := true
 
-			exitNodesFound := false
+			exitNodes := []deviceInfo{}
+			if !mullvadOnly {
+				exitNodesFound := false
 
-			// Check self device
-			if status.Self != nil && status.Self.ExitNodeOption {
-				result.WriteString("Your Device:\n")
-				result.WriteString(fmt.Sprintf("  %s (%s) - %s\n", status.Self.HostName, strings.Join(status.Self.TailscaleIPs, ", "), status.Self.OS))
-				if status.Self.ExitNode {
-					result.WriteString("    Currently active as your exit node\n")
+				// Check self device
+				if status.Self != nil && status.Self.ExitNodeOption {
+					result.WriteString("Your Device:\n")
+					result.WriteString(fmt.Sprintf("  %s (%s) - %s\n", status.Self.HostName, strings.Join(status.Self.TailscaleIPs, ", "), status.Self.OS))
+					if status.Self.ExitNode {
+						result.WriteString("    Currently active as your exit node\n")
+					}
+					result.WriteString("\n")
+					exitNodesFound = true
+					exitNodes = append(exitNodes, newDeviceInfo(status.Self, true))
 				}
-				result.WriteString("\n")
-				exitNodesFound = true
-			}
 
-			// Check peer devices
-			for _, peer := range status.Peer {
-				if peer.ExitNodeOption {
+				// Check peer devices; Mullvad nodes are listed separately
+				for _, peer := range filterPeers(status.Peer, nil, &exitNodeOption, nil) {
+					if isMullvadPeer(peer) {
+						continue
+					}
 					if !exitNodesFound {
 						// First peer exit node, add header if no self device was an exit node
 						result.WriteString("Network Exit Nodes:\n")
@@ -131,19 +185,51 @@ func RegisterRoutingTools(server *mcp.Server, cli *tailscale.CLI) {
 						result.WriteString("    Currently active as your exit node\n")
 					}
 					exitNodesFound = true
+					exitNodes = append(exitNodes, newDeviceInfo(peer, false))
+				}
+
+				if !exitNodesFound {
+					result.WriteString("No exit nodes available in the network.\n")
+					result.WriteString("Exit nodes must be explicitly enabled on devices to appear here.\n")
 				}
+				result.WriteString("\n")
 			}
 
-			if !exitNodesFound {
-				result.WriteString("No exit nodes available in the network.\n")
-				result.WriteString("Exit nodes must be explicitly enabled on devices to appear here.\n")
+			peers := mullvadPeers(status)
+			mullvad := map[string]interface{}{"available": len(peers) > 0}
+			if len(peers) == 0 {
+				result.WriteString("Mullvad add-on: not available to this device. The tailnet needs the Mullvad add-on, and this device the mullvad node attribute (see acl_add_node_attr).\n")
+			} else {
+				result.WriteString(fmt.Sprintf("Mullvad add-on: active (%d exit nodes in %d countries)\n", len(peers), mullvadCountries(peers)))
+
+				mullvadNodes := []tailscale.ExitNodeListEntry{}
+				entries, err := cli.ExitNodeList(ctx, mullvadCountryName(peers, params.Country))
+				if err != nil {
+					// Older clients have no exit-node list; use the netmap's locations
+					entries = mullvadExitNodeList(peers, params.Country)
+				}
+				for _, entry := range entries {
+					if isMullvadHost(entry.Hostname) && matchesLocation(entry.Country, "", entry.City, "", params.City) {
+						mullvadNodes = append(mullvadNodes, entry)
+					}
+				}
+
+				if len(mullvadNodes) == 0 {
+					result.WriteString("No Mullvad exit nodes match the filter.\n")
+				} else if params.Country == "" {
+					result.WriteString("Best node per city (filter by country to see every node):\n")
+				}
+				for _, entry := range mullvadNodes {
+					line := fmt.Sprintf("  %s (%s) - %s, %s", entry.Hostname, entry.IP, entry.City, entry.Country)
+					if entry.Status != "" {
+						line += fmt.Sprintf(" [%s]", entry.Status)
+					}
+					result.WriteString(line + "\n")
+				}
+				mullvad["nodes"] = mullvadNodes
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result.String()},
-				},
-			}, nil
+			return structuredResult(result.String(), map[string]interface{}{"exit_nodes": exitNodes, "mullvad": mullvad}), nil
 		}),
 	)
 
@@ -152,6 +238,7 @@ func RegisterRoutingTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "advertise_routes",
 			Description: "Advertise subnet routes to other devices in the network",
+			Annotations: destructiveTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -169,33 +256,99 @@ func RegisterRoutingTools(server *mcp.Server, cli *tailscale.CLI) {
 				Routes []string `json:"routes"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
 			if len(params.Routes) == 0 {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "No routes specified. Please provide at least one route to advertise."},
+				return invalidInputResult("No routes specified. Please provide at least one route to advertise."), nil
+			}
+
+			if _, err := tailscale.ValidateRoutes(params.Routes); err != nil {
+				return errorResult("Refusing to advertise routes", err), nil
+			}
+
+			err := cli.AdvertiseRoutes(ctx, params.Routes)
+			if err != nil {
+				return errorResult("Failed to advertise routes", err), nil
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Successfully advertising routes: %s\n\nNote: Routes may need approval in the Tailscale admin console.", strings.Join(params.Routes, ", "))},
+				},
+			}, nil
+		}),
+	)
+
+	// Summarize routes tool
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "summarize_routes",
+			Description: "Aggregate IP addresses and CIDRs (e.g., many /32s) into the minimal set of CIDRs covering exactly the same addresses, optionally advertising the result",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"routes": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "IP addresses or CIDRs to summarize (e.g., ['10.0.0.4', '10.0.0.5', '10.0.1.0/24'])",
 					},
-				}, nil
+					"advertise": {
+						Type:        "boolean",
+						Description: "Advertise the summarized routes from this device (default: false)",
+					},
+				},
+				Required: []string{"routes"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Routes    []string `json:"routes"`
+				Advertise bool     `json:"advertise"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
 			}
 
-			err := cli.AdvertiseRoutes(params.Routes)
+			summarized, err := tailscale.SummarizeRoutes(params.Routes)
 			if err != nil {
+				return errorResult("Error summarizing routes", err), nil
+			}
+			if len(summarized) == 0 {
+				return invalidInputResult("No routes specified. Please provide at least one IP address or CIDR."), nil
+			}
+			routes := tailscale.PrefixStrings(summarized)
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Summarized %d input(s) into %d route(s):\n", len(params.Routes), len(routes)))
+			for _, route := range routes {
+				result.WriteString(fmt.Sprintf("  %s\n", route))
+			}
+
+			if _, err := tailscale.ValidateRoutes(routes); err != nil {
+				result.WriteString(fmt.Sprintf("\n⚠ %v\n", err))
+				if params.Advertise {
+					result.WriteString("\nRoutes were not advertised.\n")
+				}
 				return &mcp.CallToolResult{
 					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to advertise routes: %v", err)},
+						&mcp.TextContent{Text: result.String()},
 					},
 				}, nil
 			}
 
+			if params.Advertise {
+				if err := cli.AdvertiseRoutes(ctx, routes); err != nil {
+					result.WriteString(fmt.Sprintf("\nFailed to advertise routes: %v\n", err))
+				} else {
+					result.WriteString("\nSuccessfully advertising these routes.\nNote: Routes may need approval in the Tailscale admin console.\n")
+				}
+			}
+
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Successfully advertising routes: %s\n\nNote: Routes may need approval in the Tailscale admin console.", strings.Join(params.Routes, ", "))},
+					&mcp.TextContent{Text: result.String()},
 				},
 			}, nil
 		}),
@@ -206,6 +359,7 @@ func RegisterRoutingTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "accept_routes",
 			Description: "Enable or disable accepting subnet routes from peers",
+			Annotations: updateTool(true),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -219,20 +373,12 @@ func RegisterRoutingTools(server *mcp.Server, cli *tailscale.CLI) {
 				Accept bool `json:"accept"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
-			err := cli.AcceptRoutes(params.Accept)
+			err := cli.AcceptRoutes(ctx, params.Accept)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Failed to update route acceptance: %v", err)},
-					},
-				}, nil
+				return errorResult("Failed to update route acceptance", err), nil
 			}
 
 			status := "disabled"
@@ -256,69 +402,226 @@ func RegisterRoutingToolsWithAPI(server *mcp.Server, cli *tailscale.CLI, api *ta
 	// Register all existing CLI-based tools first
 	RegisterRoutingTools(server, cli)
 
-	// Approve routes tool (API-only)
+	// Get device routes tool (API-only)
 	server.AddTool(
 		&mcp.Tool{
-			Name:        "approve_routes",
-			Description: "Approve advertised routes for a device",
+			Name:        "get_device_routes",
+			Description: "Show a device's advertised subnet routes and which of them are enabled (approved)",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
 					"device_id": {
 						Type:        "string",
-						Description: "Device ID to approve routes for",
-					},
-					"routes": {
-						Type: "array",
-						Items: &jsonschema.Schema{Type: "string"},
-						Description: "List of routes to approve (e.g., ['192.168.1.0/24', '10.0.0.0/8'])",
+						Description: "Device ID to show routes for",
 					},
 				},
-				Required: []string{"device_id", "routes"},
+				Required: []string{"device_id"},
 			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			if api == nil || !api.IsAvailable() {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "API client not configured. Route approval requires API access. Please set TAILSCALE_API_KEY environment variable."},
-					},
-				}, nil
+				return apiNotConfiguredResult(), nil
 			}
 
 			var params struct {
-				DeviceID string   `json:"device_id"`
-				Routes   []string `json:"routes"`
+				DeviceID string `json:"device_id"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
-			if len(params.Routes) == 0 {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: "No routes specified. Please provide at least one route to approve."},
-					},
-				}, nil
+			routes, err := api.GetRoutes(ctx, params.DeviceID)
+			if err != nil {
+				return errorResult("Error getting routes", err), nil
 			}
 
-			if err := api.ApproveRoutes(params.DeviceID, params.Routes); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error approving routes: %v", err)},
-					},
-				}, nil
-			}
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Routes for device %s:\n\n", params.DeviceID))
+			writeDeviceRoutes(&result, routes)
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Routes approved successfully for device %s: %s", params.DeviceID, strings.Join(params.Routes, ", "))},
-				},
-			}, nil
+			return structuredResult(result.String(), routes), nil
 		}),
 	)
-}
\ No newline at end of file
+
+	enableRoutes := routeChangeHandler(api, true)
+
+	// Enable routes tool (API-only)
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "enable_routes",
+			Description: "Enable (approve) some of a device's subnet routes, leaving its other enabled routes as they are",
+			Annotations: updateTool(true),
+			InputSchema: routeChangeSchema("enable"),
+		},
+		enableRoutes,
+	)
+
+	// Disable routes tool (API-only)
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "disable_routes",
+			Description: "Disable some of a device's enabled subnet routes, leaving the rest enabled. The device keeps advertising them.",
+			Annotations: destructiveTool(true),
+			InputSchema: routeChangeSchema("disable"),
+		},
+		routeChangeHandler(api, false),
+	)
+
+	// Approve routes tool (API-only), kept for existing clients
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "approve_routes",
+			Description: "Approve advertised routes for a device. Same as enable_routes.",
+			Annotations: updateTool(true),
+			InputSchema: routeChangeSchema("approve"),
+		},
+		enableRoutes,
+	)
+}
+
+// routeChangeSchema is the input schema shared by the route enable/disable tools
+func routeChangeSchema(verb string) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"device_id": {
+				Type:        "string",
+				Description: fmt.Sprintf("Device ID to %s routes for", verb),
+			},
+			"routes": {
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: fmt.Sprintf("Routes to %s (e.g., ['192.168.1.0/24', '10.0.0.0/8'])", verb),
+			},
+		},
+		Required: []string{"device_id", "routes"},
+	}
+}
+
+// routeChangeHandler enables or disables a subset of a device's routes. The
+// API only takes the complete enabled set, so the change is merged into the
+// device's current routes before it is sent.
+func routeChangeHandler(api *tailscale.APIClient, enable bool) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if api == nil || !api.IsAvailable() {
+			return apiNotConfiguredResult(), nil
+		}
+
+		var params struct {
+			DeviceID string   `json:"device_id"`
+			Routes   []string `json:"routes"`
+		}
+		if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+			return invalidParamsResult(err), nil
+		}
+
+		if len(params.Routes) == 0 {
+			return invalidInputResult("No routes specified. Please provide at least one route."), nil
+		}
+		requested, err := canonicalRoutes(params.Routes)
+		if err != nil {
+			return invalidParamsResult(err), nil
+		}
+
+		current, err := api.GetRoutes(ctx, params.DeviceID)
+		if err != nil {
+			return errorResult("Error getting routes", err), nil
+		}
+
+		var enabled, changed, unchanged []string
+		if enable {
+			enabled = append(enabled, current.EnabledRoutes...)
+			for _, route := range requested {
+				if containsString(enabled, route) {
+					unchanged = append(unchanged, route)
+					continue
+				}
+				enabled = append(enabled, route)
+				changed = append(changed, route)
+			}
+		} else {
+			enabled = []string{}
+			for _, route := range current.EnabledRoutes {
+				if containsString(requested, route) {
+					changed = append(changed, route)
+					continue
+				}
+				enabled = append(enabled, route)
+			}
+			for _, route := range requested {
+				if !containsString(changed, route) {
+					unchanged = append(unchanged, route)
+				}
+			}
+		}
+
+		action, state := "Disabled", "not enabled"
+		if enable {
+			action, state = "Enabled", "already enabled"
+		}
+
+		var result strings.Builder
+		routes := current
+		if len(changed) > 0 {
+			routes, err = api.SetRoutes(ctx, params.DeviceID, enabled)
+			if err != nil {
+				return errorResult("Error updating routes", err), nil
+			}
+			result.WriteString(fmt.Sprintf("✓ %s routes for device %s: %s\n", action, params.DeviceID, strings.Join(changed, ", ")))
+		}
+		if len(unchanged) > 0 {
+			result.WriteString(fmt.Sprintf("Routes %s: %s\n", state, strings.Join(unchanged, ", ")))
+		}
+		if enable {
+			for _, route := range changed {
+				if !containsString(current.AdvertisedRoutes, route) {
+					result.WriteString(fmt.Sprintf("⚠ %s is not advertised by the device; it takes effect once the device advertises it\n", route))
+				}
+			}
+		}
+		result.WriteString("\n")
+		writeDeviceRoutes(&result, routes)
+
+		return structuredResult(result.String(), routes), nil
+	}
+}
+
+// canonicalRoutes parses routes as CIDRs and returns them in the form the
+// API reports them in, so they can be compared with a device's routes
+func canonicalRoutes(routes []string) ([]string, error) {
+	var result []string
+	for _, route := range routes {
+		route = strings.TrimSpace(route)
+		prefix, err := netip.ParsePrefix(route)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid CIDR (e.g., 192.168.1.0/24)", route)
+		}
+		if masked := prefix.Masked(); masked != prefix {
+			return nil, fmt.Errorf("%s has host bits set; did you mean %s?", route, masked)
+		}
+		if !containsString(result, prefix.String()) {
+			result = append(result, prefix.String())
+		}
+	}
+	return result, nil
+}
+
+// writeDeviceRoutes lists each advertised route with whether it is enabled
+func writeDeviceRoutes(result *strings.Builder, routes *tailscale.DeviceRoutes) {
+	if len(routes.AdvertisedRoutes) == 0 {
+		result.WriteString("No routes advertised\n")
+	}
+	for _, route := range routes.AdvertisedRoutes {
+		if containsString(routes.EnabledRoutes, route) {
+			result.WriteString(fmt.Sprintf("  ✓ %s (enabled)\n", route))
+		} else {
+			result.WriteString(fmt.Sprintf("  ✗ %s (awaiting approval)\n", route))
+		}
+	}
+	for _, route := range routes.EnabledRoutes {
+		if !containsString(routes.AdvertisedRoutes, route) {
+			result.WriteString(fmt.Sprintf("  ⚠ %s (enabled but not advertised)\n", route))
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// secretsFileProperty is the secrets_file argument of tools that create a
+// secret. Results have secrets redacted unless the server is configured to
+// reveal them, so the file is how the secret is kept.
+func secretsFileProperty(what string) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Description: fmt.Sprintf("Save the %s to this file (relative to the client's roots or the configured output directory), since results have secrets redacted by default (optional)", what),
+	}
+}
+
+// saveSecret writes secret to the secrets_file path, noting the outcome in
+// result, and returns the link to the file (nil if nothing was written)
+func saveSecret(ctx context.Context, output *OutputWriter, session *mcp.ServerSession, path, what, secret string, result *strings.Builder) *mcp.ResourceLink {
+	if path == "" || secret == "" {
+		return nil
+	}
+	link, err := output.Write(ctx, session, path, []byte(secret+"\n"), "text/plain")
+	if err != nil {
+		result.WriteString(fmt.Sprintf("✗ Could not save the %s: %v\n", what, err))
+		return nil
+	}
+	result.WriteString(fmt.Sprintf("✓ The %s was saved to %s (owner-only permissions)\n", what, link.URI))
+	return link
+}
@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// tcpServeTarget turns a forwarding target into the tcp://host:port form
+// serve expects. A bare port forwards to localhost.
+func tcpServeTarget(target string) (string, error) {
+	target = strings.TrimPrefix(strings.TrimSpace(target), "tcp://")
+	if _, err := strconv.Atoi(target); err == nil {
+		target = net.JoinHostPort("127.0.0.1", target)
+	}
+	host, port, err := net.SplitHostPort(target)
+	if err != nil || host == "" {
+		return "", fmt.Errorf("target %q must be a port, host:port or tcp://host:port", target)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("target %q has an invalid port", target)
+	}
+	return "tcp://" + net.JoinHostPort(host, port), nil
+}
+
+// RegisterServeTools registers tools that expose local services with tailscale serve
+func RegisterServeTools(server *mcp.Server, cli *tailscale.CLI) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "serve_tcp",
+			Description: "Expose a raw TCP service (e.g., a database or SSH gateway) on this device's tailnet address with tailscale serve, optionally terminating TLS first. Only reachable from the tailnet, subject to the ACL policy. Set off=true to stop forwarding a port.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"port": {
+						Type:        "integer",
+						Description: "Port to listen on at the device's tailnet address",
+					},
+					"target": {
+						Type:        "string",
+						Description: "Where to forward connections: a local port (e.g., 5432), host:port or tcp://host:port. Not needed with off=true",
+					},
+					"tls_terminated": {
+						Type:        "boolean",
+						Description: "Terminate TLS with the device's certificate and forward plain TCP (default: false)",
+					},
+					"off": {
+						Type:        "boolean",
+						Description: "Stop forwarding the port instead (default: false)",
+					},
+				},
+				Required: []string{"port"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Port          int    `json:"port"`
+				Target        string `json:"target"`
+				TLSTerminated bool   `json:"tls_terminated"`
+				Off           bool   `json:"off"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			kind := "TCP"
+			if params.TLSTerminated {
+				kind = "TLS-terminated TCP"
+			}
+
+			var target string
+			var err error
+			switch {
+			case params.Port < 1 || params.Port > 65535:
+				err = fmt.Errorf("port must be between 1 and 65535")
+			case !params.Off && params.Target == "":
+				err = fmt.Errorf("target is required unless off=true")
+			case !params.Off:
+				target, err = tcpServeTarget(params.Target)
+			}
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			if params.Off {
+				if err := cli.ServeTCPOff(ctx, params.Port, params.TLSTerminated); err != nil {
+					return errorResult(fmt.Sprintf("Failed to stop %s forwarding on port %d", kind, params.Port), err), nil
+				}
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: fmt.Sprintf("Stopped %s forwarding on port %d.", kind, params.Port)},
+					},
+				}, nil
+			}
+
+			if err := cli.ServeTCP(ctx, params.Port, target, params.TLSTerminated); err != nil {
+				return errorResult(fmt.Sprintf("Failed to serve %s on port %d", kind, params.Port), err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("✓ Forwarding %s on port %d to %s\n", kind, params.Port, target))
+			if status, err := cli.Status(ctx); err == nil && status.Self != nil {
+				if name := strings.TrimSuffix(status.Self.DNSName, "."); name != "" {
+					result.WriteString(fmt.Sprintf("Reach it at %s:%d from the tailnet.\n", name, params.Port))
+				}
+			}
+			if params.TLSTerminated {
+				result.WriteString("Clients connect with TLS; the certificate is the device's MagicDNS name, so HTTPS certificates must be enabled for the tailnet.\n")
+			}
+			result.WriteString("The forwarder runs in the background and persists across restarts; remove it with off=true.\n")
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: result.String()},
+				},
+			}, nil
+		}),
+	)
+}
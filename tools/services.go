@@ -0,0 +1,373 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// normalizeServiceName adds the "svc:" prefix if it is missing and checks
+// that the rest is a valid DNS label, since it becomes the service's MagicDNS name
+func normalizeServiceName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	label := strings.TrimPrefix(name, "svc:")
+	if err := validateMachineName(label); err != nil {
+		return "", fmt.Errorf("invalid service name: %w", err)
+	}
+	return "svc:" + label, nil
+}
+
+// validateServicePort checks a service port: "tcp:443", "udp:53", a range
+// such as "tcp:8000-8010", or "do-not-validate" to allow any port
+func validateServicePort(port string) error {
+	if port == "do-not-validate" {
+		return nil
+	}
+	proto, ports, ok := strings.Cut(port, ":")
+	if !ok || (proto != "tcp" && proto != "udp") {
+		return fmt.Errorf("port %q must be tcp:<port> or udp:<port> (e.g., tcp:443)", port)
+	}
+	low, high, isRange := strings.Cut(ports, "-")
+	if !isRange {
+		high = low
+	}
+	lo, errLo := strconv.Atoi(low)
+	hi, errHi := strconv.Atoi(high)
+	if errLo != nil || errHi != nil || lo < 1 || hi > 65535 || lo > hi {
+		return fmt.Errorf("port %q has an invalid port number or range", port)
+	}
+	return nil
+}
+
+// RegisterServiceTools registers tools for Tailscale Services
+func RegisterServiceTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "list_services",
+			Description: "List the tailnet's Tailscale Services with their addresses, ports and tags",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			services, err := api.ListServices(ctx)
+			if err != nil {
+				return errorResult("Error listing services", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Tailscale Services (%d):\n\n", len(services)))
+			if len(services) == 0 {
+				result.WriteString("No services defined. Create one with create_service.\n")
+			}
+			for _, service := range services {
+				writeService(&result, service)
+				result.WriteString("\n")
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"services": services}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "get_service",
+			Description: "Show a Tailscale Service and the devices hosting it, with whether each host is approved",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {
+						Type:        "string",
+						Description: "Service name (e.g., svc:web or web)",
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			name, err := normalizeServiceName(params.Name)
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			service, err := api.GetService(ctx, name)
+			if err != nil {
+				return errorResult("Error getting service", err), nil
+			}
+
+			var result strings.Builder
+			writeService(&result, *service)
+
+			hosts, err := api.ListServiceHosts(ctx, name)
+			if err != nil {
+				result.WriteString(fmt.Sprintf("\n⚠ Could not list hosts: %v\n", err))
+				return structuredResult(result.String(), map[string]interface{}{"service": service}), nil
+			}
+			// Name the hosts; the endpoint may only return device IDs
+			if devices, err := api.ListDevices(ctx); err == nil {
+				for i := range hosts {
+					for _, d := range devices {
+						if hosts[i].Hostname == "" && (d.ID == hosts[i].DeviceID || d.NodeID == hosts[i].DeviceID) {
+							hosts[i].Hostname = d.Hostname
+						}
+					}
+				}
+			}
+
+			result.WriteString(fmt.Sprintf("\nHosts (%d):\n", len(hosts)))
+			if len(hosts) == 0 {
+				result.WriteString("  No devices advertise this service yet. Hosts advertise it with `tailscale serve --service=" + name + "`.\n")
+			}
+			pending := 0
+			for _, host := range hosts {
+				label := host.DeviceID
+				if host.Hostname != "" {
+					label = fmt.Sprintf("%s (%s)", host.Hostname, host.DeviceID)
+				}
+				if host.Approved {
+					result.WriteString(fmt.Sprintf("  ✓ %s: approved\n", label))
+				} else {
+					result.WriteString(fmt.Sprintf("  ✗ %s: awaiting approval\n", label))
+					pending++
+				}
+			}
+			if pending > 0 {
+				result.WriteString(fmt.Sprintf("\n%d host(s) need approval; use approve_service_host.\n", pending))
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"service": service, "hosts": hosts}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "create_service",
+			Description: "Create a Tailscale Service that clients reach by name, served by the devices that advertise it. Fails if a service with the name already exists.",
+			Annotations: updateTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {
+						Type:        "string",
+						Description: "Service name; the svc: prefix is added if missing (e.g., web becomes svc:web)",
+					},
+					"ports": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "Ports the service accepts, e.g. ['tcp:443', 'udp:53', 'tcp:8000-8010'], or ['do-not-validate'] for any",
+					},
+					"tags": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "Tags for referring to the service in the policy (optional)",
+					},
+					"comment": {
+						Type:        "string",
+						Description: "What the service is for (optional)",
+					},
+				},
+				Required: []string{"name", "ports"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Name    string   `json:"name"`
+				Ports   []string `json:"ports"`
+				Tags    []string `json:"tags"`
+				Comment string   `json:"comment"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			service := tailscale.Service{Comment: strings.TrimSpace(params.Comment)}
+			name, err := normalizeServiceName(params.Name)
+			if err == nil && len(params.Ports) == 0 {
+				err = fmt.Errorf("at least one port is required (e.g., tcp:443)")
+			}
+			for _, port := range params.Ports {
+				port = strings.ToLower(strings.TrimSpace(port))
+				if err == nil {
+					err = validateServicePort(port)
+				}
+				if !containsString(service.Ports, port) {
+					service.Ports = append(service.Ports, port)
+				}
+			}
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+			service.Name = name
+			for _, tag := range params.Tags {
+				if tag = tailscale.NormalizeTag(tag); tag != "" && !containsString(service.Tags, tag) {
+					service.Tags = append(service.Tags, tag)
+				}
+			}
+
+			// PUT replaces an existing definition, so check first
+			if _, err := api.GetService(ctx, name); err == nil {
+				return refusedResult(fmt.Sprintf("Service %s already exists. Delete it first to redefine it.", name)), nil
+			}
+
+			if refused := checkTagOwners(ctx, api, service.Tags); refused != nil {
+				return refused, nil
+			}
+
+			if err := api.PutService(ctx, service); err != nil {
+				return errorResult("Error creating service", err), nil
+			}
+
+			// The API assigns the addresses
+			if created, err := api.GetService(ctx, name); err == nil {
+				service = *created
+			}
+
+			var result strings.Builder
+			result.WriteString("✓ Service created\n\n")
+			writeService(&result, service)
+			result.WriteString(fmt.Sprintf("\nNext: advertise it from host devices with `tailscale serve --service=%s`, then approve them with approve_service_host. Clients also need a grant to the service in the policy.\n", name))
+
+			return structuredResult(result.String(), service), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "delete_service",
+			Description: "Delete a Tailscale Service. Clients can no longer reach it, and its addresses are released.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {
+						Type:        "string",
+						Description: "Service name (e.g., svc:web or web)",
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			name, err := normalizeServiceName(params.Name)
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			if err := api.DeleteService(ctx, name); err != nil {
+				return errorResult("Error deleting service", err), nil
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Service %s deleted successfully.", name)},
+				},
+			}, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "approve_service_host",
+			Description: "Approve a device advertising a Tailscale Service so it receives the service's traffic, or revoke the approval",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {
+						Type:        "string",
+						Description: "Service name (e.g., svc:web or web)",
+					},
+					"device_id": {
+						Type:        "string",
+						Description: "ID of the host device",
+					},
+					"approved": {
+						Type:        "boolean",
+						Description: "Approve the host (default: true); false revokes the approval",
+					},
+				},
+				Required: []string{"name", "device_id"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Name     string `json:"name"`
+				DeviceID string `json:"device_id"`
+				Approved *bool  `json:"approved"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			name, err := normalizeServiceName(params.Name)
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+			approved := params.Approved == nil || *params.Approved
+
+			if err := api.SetServiceHostApproval(ctx, name, params.DeviceID, approved); err != nil {
+				return errorResult("Error updating host approval", err), nil
+			}
+
+			text := fmt.Sprintf("✓ Approved device %s as a host of %s", params.DeviceID, name)
+			if !approved {
+				text = fmt.Sprintf("✓ Revoked approval of device %s as a host of %s; it no longer receives the service's traffic", params.DeviceID, name)
+			}
+			return structuredResult(text, map[string]interface{}{"service": name, "device_id": params.DeviceID, "approved": approved}), nil
+		}),
+	)
+}
+
+// writeService writes a service's definition
+func writeService(result *strings.Builder, service tailscale.Service) {
+	result.WriteString(fmt.Sprintf("%s\n", service.Name))
+	if service.Comment != "" {
+		result.WriteString(fmt.Sprintf("  Comment: %s\n", service.Comment))
+	}
+	if len(service.Addrs) > 0 {
+		result.WriteString(fmt.Sprintf("  Addresses: %s\n", strings.Join(service.Addrs, ", ")))
+	}
+	if len(service.Ports) > 0 {
+		result.WriteString(fmt.Sprintf("  Ports: %s\n", strings.Join(service.Ports, ", ")))
+	}
+	if len(service.Tags) > 0 {
+		result.WriteString(fmt.Sprintf("  Tags: %s\n", strings.Join(service.Tags, ", ")))
+	}
+}
@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// settingRow is a tailnet setting as displayed by the settings tools
+type settingRow struct {
+	label string
+	value string
+}
+
+// settingRows lists the settings in display order
+func settingRows(s *tailscale.TailnetSettings) []settingRow {
+	return []settingRow{
+		{"Device approval required", fmt.Sprintf("%t", s.DevicesApprovalOn)},
+		{"Device auto-updates", fmt.Sprintf("%t", s.DevicesAutoUpdatesOn)},
+		{"Key expiry (days)", fmt.Sprintf("%d", s.DevicesKeyDurationDays)},
+		{"User approval required", fmt.Sprintf("%t", s.UsersApprovalOn)},
+		{"Roles allowed to join external tailnets", s.UsersRoleAllowedToJoinExternalTailnets},
+		{"Network flow logging", fmt.Sprintf("%t", s.NetworkFlowLoggingOn)},
+		{"Regional routing", fmt.Sprintf("%t", s.RegionalRoutingOn)},
+		{"Posture identity collection", fmt.Sprintf("%t", s.PostureIdentityCollectionOn)},
+	}
+}
+
+// RegisterTailnetSettingsTools registers tools for viewing and changing tailnet settings
+func RegisterTailnetSettingsTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "get_tailnet_settings",
+			Description: "Get the tailnet-wide settings: device and user approval, key expiry, auto-updates, network flow logging, regional routing and posture collection",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			settings, err := api.GetTailnetSettings(ctx)
+			if err != nil {
+				return errorResult("Error getting tailnet settings", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString("Tailnet Settings:\n\n")
+			for _, row := range settingRows(settings) {
+				result.WriteString(fmt.Sprintf("%s: %s\n", row.label, row.value))
+			}
+
+			return structuredResult(result.String(), settings), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "update_tailnet_settings",
+			Description: "Change tailnet-wide settings. Only the settings given are changed; the result lists each change as old → new.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"device_approval": {
+						Type:        "boolean",
+						Description: "Require new devices to be approved by an admin before they can connect",
+					},
+					"device_auto_updates": {
+						Type:        "boolean",
+						Description: "Turn on auto-updates by default for new devices",
+					},
+					"key_expiry_days": {
+						Type:        "integer",
+						Description: "Days until node keys expire and devices must re-authenticate (1-180)",
+					},
+					"user_approval": {
+						Type:        "boolean",
+						Description: "Require new users to be approved by an admin before they can join",
+					},
+					"external_tailnet_role": {
+						Type:        "string",
+						Description: "Which users may join other tailnets: none, admin or member",
+						Enum:        []interface{}{"none", "admin", "member"},
+					},
+					"flow_logging": {
+						Type:        "boolean",
+						Description: "Record network flow logs",
+					},
+					"regional_routing": {
+						Type:        "boolean",
+						Description: "Route to the closest of several subnet routers advertising the same route",
+					},
+					"posture_collection": {
+						Type:        "boolean",
+						Description: "Collect device identity (serial numbers, etc.) for posture checks",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				DeviceApproval      *bool   `json:"device_approval"`
+				DeviceAutoUpdates   *bool   `json:"device_auto_updates"`
+				KeyExpiryDays       *int    `json:"key_expiry_days"`
+				UserApproval        *bool   `json:"user_approval"`
+				ExternalTailnetRole *string `json:"external_tailnet_role"`
+				FlowLogging         *bool   `json:"flow_logging"`
+				RegionalRouting     *bool   `json:"regional_routing"`
+				PostureCollection   *bool   `json:"posture_collection"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			update := tailscale.TailnetSettingsUpdate{
+				DevicesApprovalOn:                      params.DeviceApproval,
+				DevicesAutoUpdatesOn:                   params.DeviceAutoUpdates,
+				DevicesKeyDurationDays:                 params.KeyExpiryDays,
+				UsersApprovalOn:                        params.UserApproval,
+				UsersRoleAllowedToJoinExternalTailnets: params.ExternalTailnetRole,
+				NetworkFlowLoggingOn:                   params.FlowLogging,
+				RegionalRoutingOn:                      params.RegionalRouting,
+				PostureIdentityCollectionOn:            params.PostureCollection,
+			}
+			var problem string
+			switch {
+			case update == tailscale.TailnetSettingsUpdate{}:
+				problem = "No settings given to change"
+			case params.KeyExpiryDays != nil && (*params.KeyExpiryDays < 1 || *params.KeyExpiryDays > 180):
+				problem = "key_expiry_days must be between 1 and 180"
+			case params.ExternalTailnetRole != nil && !containsString([]string{"none", "admin", "member"}, *params.ExternalTailnetRole):
+				problem = "external_tailnet_role must be none, admin or member"
+			}
+			if problem != "" {
+				return invalidInputResult(problem), nil
+			}
+
+			before, err := api.GetTailnetSettings(ctx)
+			if err != nil {
+				return errorResult("Error getting tailnet settings", err), nil
+			}
+			after, err := api.UpdateTailnetSettings(ctx, update)
+			if err != nil {
+				return errorResult("Error updating tailnet settings", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString("Tailnet Settings Updated:\n\n")
+			changed := 0
+			oldRows := settingRows(before)
+			for i, row := range settingRows(after) {
+				if row.value != oldRows[i].value {
+					result.WriteString(fmt.Sprintf("✓ %s: %s → %s\n", row.label, oldRows[i].value, row.value))
+					changed++
+				}
+			}
+			if changed == 0 {
+				result.WriteString("The settings already had these values; nothing changed.\n")
+			}
+
+			return structuredResult(result.String(), after), nil
+		}),
+	)
+}
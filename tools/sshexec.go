@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const (
+	defaultSSHExecTimeout = 60 * time.Second
+	maxSSHExecTimeout     = 10 * time.Minute
+	// maxSSHExecOutput caps each of stdout and stderr in the result, so a
+	// chatty command doesn't flood the conversation
+	maxSSHExecOutput = 64 * 1024
+)
+
+// sshExecResult is the structured result of ssh_exec
+type sshExecResult struct {
+	Host            string  `json:"host"`
+	User            string  `json:"user,omitempty"`
+	Command         string  `json:"command"`
+	ExitCode        int     `json:"exit_code"`
+	Stdout          string  `json:"stdout"`
+	Stderr          string  `json:"stderr"`
+	StdoutTruncated bool    `json:"stdout_truncated,omitempty"`
+	StderrTruncated bool    `json:"stderr_truncated,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// truncateOutput cuts s to maxSSHExecOutput bytes, reporting whether it did
+func truncateOutput(s string) (string, bool) {
+	if len(s) <= maxSSHExecOutput {
+		return s, false
+	}
+	return strings.ToValidUTF8(s[:maxSSHExecOutput], ""), true
+}
+
+// RegisterSSHExecTools registers a tool that runs arbitrary commands on tailnet
+// hosts over Tailscale SSH. Only registered when SSH execution is enabled.
+func RegisterSSHExecTools(server *mcp.Server, cli *tailscale.CLI) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "ssh_exec",
+			Description: "Run a command on a tailnet device over Tailscale SSH (tailscale ssh user@host -- command) and return its exit code, stdout and stderr. The command runs non-interactively through the remote user's shell, so hosts that require SSH check mode can't be reached. Access is governed by the tailnet's SSH policy.",
+			Annotations: destructiveTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"host": {
+						Type:        "string",
+						Description: "Device hostname, MagicDNS name or Tailscale IP",
+					},
+					"command": {
+						Type:        "string",
+						Description: "Command to run; it is interpreted by the remote user's shell",
+					},
+					"ssh_user": {
+						Type:        "string",
+						Description: "User to SSH as (default: root)",
+					},
+					"timeout_seconds": {
+						Type:        "integer",
+						Description: "Kill the command after this many seconds (default: 60, max: 600)",
+					},
+				},
+				Required: []string{"host", "command"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Host           string `json:"host"`
+				Command        string `json:"command"`
+				SSHUser        string `json:"ssh_user"`
+				TimeoutSeconds int    `json:"timeout_seconds"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			sshUser := params.SSHUser
+			if sshUser == "" {
+				sshUser = "root"
+			}
+			timeout := defaultSSHExecTimeout
+			if params.TimeoutSeconds > 0 {
+				timeout = time.Duration(params.TimeoutSeconds) * time.Second
+			}
+
+			err := tailscale.ValidateSSHTarget(sshUser, params.Host)
+			switch {
+			case err != nil:
+			case strings.TrimSpace(params.Command) == "":
+				err = fmt.Errorf("command is required")
+			case timeout > maxSSHExecTimeout:
+				err = fmt.Errorf("timeout_seconds can be at most %d", int(maxSSHExecTimeout.Seconds()))
+			}
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			start := time.Now()
+			res, err := cli.SSHExec(ctx, sshUser, params.Host, params.Command, timeout)
+			if err != nil {
+				var result strings.Builder
+				result.WriteString(fmt.Sprintf("✗ %v\n", err))
+				if res != nil && res.Stdout != "" {
+					stdout, _ := truncateOutput(res.Stdout)
+					result.WriteString(fmt.Sprintf("\nOutput before failure:\n%s\n", stdout))
+				}
+				return reportErrorResult(result.String(), err), nil
+			}
+
+			out := sshExecResult{
+				Host:            params.Host,
+				User:            sshUser,
+				Command:         params.Command,
+				ExitCode:        res.ExitCode,
+				DurationSeconds: time.Since(start).Round(time.Millisecond).Seconds(),
+			}
+			out.Stdout, out.StdoutTruncated = truncateOutput(res.Stdout)
+			out.Stderr, out.StderrTruncated = truncateOutput(res.Stderr)
+
+			var result strings.Builder
+			marker := "✓"
+			if out.ExitCode != 0 {
+				marker = "✗"
+			}
+			result.WriteString(fmt.Sprintf("%s %s@%s exited with code %d (%.1fs)\n", marker, sshUser, params.Host, out.ExitCode, out.DurationSeconds))
+			if out.Stdout != "" {
+				result.WriteString(fmt.Sprintf("\nStdout:\n%s\n", out.Stdout))
+				if out.StdoutTruncated {
+					result.WriteString(fmt.Sprintf("[stdout truncated to %d KiB]\n", maxSSHExecOutput/1024))
+				}
+			}
+			if out.Stderr != "" {
+				result.WriteString(fmt.Sprintf("\nStderr:\n%s\n", out.Stderr))
+				if out.StderrTruncated {
+					result.WriteString(fmt.Sprintf("[stderr truncated to %d KiB]\n", maxSSHExecOutput/1024))
+				}
+			}
+
+			return structuredResult(result.String(), out), nil
+		}),
+	)
+}
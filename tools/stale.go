@@ -0,0 +1,271 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const defaultStaleDays = 60
+
+// staleDevice is a device that hasn't been seen for the stale period
+type staleDevice struct {
+	DeviceID string    `json:"device_id"`
+	Name     string    `json:"name"`
+	User     string    `json:"user"`
+	OS       string    `json:"os"`
+	Tags     []string  `json:"tags,omitempty"`
+	LastSeen time.Time `json:"last_seen"`
+	DaysIdle int       `json:"days_idle"`
+}
+
+// staleCriteria selects stale devices. Devices carrying any of ExcludeTags
+// are never stale, so long-lived infrastructure can be protected.
+type staleCriteria struct {
+	Days        int      `json:"days"`
+	Tag         string   `json:"tag"`
+	ExcludeTags []string `json:"exclude_tags"`
+}
+
+// staleProperties are the input schema properties of staleCriteria
+var staleProperties = map[string]*jsonschema.Schema{
+	"days": {
+		Type:        "integer",
+		Description: fmt.Sprintf("Devices not seen for this many days are stale (default: %d)", defaultStaleDays),
+	},
+	"tag": {
+		Type:        "string",
+		Description: "Only consider devices carrying this tag (e.g., tag:ci)",
+	},
+	"exclude_tags": {
+		Type:        "array",
+		Description: "Never treat devices carrying any of these tags as stale",
+		Items:       &jsonschema.Schema{Type: "string"},
+	},
+}
+
+// findStale returns the offline devices last seen before the cutoff, idle
+// the longest first. Devices that were never seen count from their creation.
+func findStale(devices []tailscale.Device, c staleCriteria, now time.Time) []staleDevice {
+	days := c.Days
+	if days <= 0 {
+		days = defaultStaleDays
+	}
+	cutoff := now.AddDate(0, 0, -days)
+	tag := ""
+	if c.Tag != "" {
+		tag = tailscale.NormalizeTag(c.Tag)
+	}
+	excluded := make([]string, len(c.ExcludeTags))
+	for i, t := range c.ExcludeTags {
+		excluded[i] = tailscale.NormalizeTag(t)
+	}
+
+	var stale []staleDevice
+	for _, d := range devices {
+		if d.Online || (tag != "" && !containsString(d.Tags, tag)) {
+			continue
+		}
+		protected := false
+		for _, t := range excluded {
+			if containsString(d.Tags, t) {
+				protected = true
+			}
+		}
+		seen := d.LastSeen
+		if seen.IsZero() {
+			seen = d.Created
+		}
+		if protected || seen.IsZero() || seen.After(cutoff) {
+			continue
+		}
+		stale = append(stale, staleDevice{
+			DeviceID: d.ID,
+			Name:     strings.SplitN(d.Name, ".", 2)[0],
+			User:     d.User,
+			OS:       d.OS,
+			Tags:     d.Tags,
+			LastSeen: seen,
+			DaysIdle: int(now.Sub(seen).Hours() / 24),
+		})
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].LastSeen.Before(stale[j].LastSeen) })
+	return stale
+}
+
+// writeStaleDevices lists stale devices one per line
+func writeStaleDevices(result *strings.Builder, stale []staleDevice) {
+	for _, d := range stale {
+		owner := d.User
+		if len(d.Tags) > 0 {
+			owner = strings.Join(d.Tags, ",")
+		}
+		result.WriteString(fmt.Sprintf("  %s (%s) %s, %s: last seen %s (%d days ago)\n", d.Name, d.DeviceID, d.OS, owner, d.LastSeen.Local().Format("2006-01-02"), d.DaysIdle))
+	}
+}
+
+// RegisterStaleDeviceTools registers tools that find and remove devices that
+// haven't connected for a long time
+func RegisterStaleDeviceTools(server *mcp.Server, api *tailscale.APIClient) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "find_stale_devices",
+			Description: "List offline devices that haven't been seen for a number of days, idle the longest first, to keep the tailnet tidy. Remove them with cleanup_stale_devices.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type:       "object",
+				Properties: staleProperties,
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params staleCriteria
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Days <= 0 {
+				params.Days = defaultStaleDays
+			}
+
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+
+			stale := findStale(devices, params, time.Now())
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Stale Devices: %d of %d not seen for %d+ days\n\n", len(stale), len(devices), params.Days))
+			if len(stale) == 0 {
+				result.WriteString("✓ No stale devices.\n")
+			} else {
+				writeStaleDevices(&result, stale)
+				result.WriteString("\nRemove them with cleanup_stale_devices.\n")
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"devices": stale}), nil
+		}),
+	)
+
+	cleanupProperties := map[string]*jsonschema.Schema{
+		"device_ids": {
+			Type:        "array",
+			Description: "The stale devices to remove, as listed by a preview (required with confirm=true; default for a preview: every stale device)",
+			Items:       &jsonschema.Schema{Type: "string"},
+		},
+		"confirm": {
+			Type:        "boolean",
+			Description: "Delete the devices (default: false, only lists what would be deleted)",
+		},
+	}
+	for name, schema := range staleProperties {
+		cleanupProperties[name] = schema
+	}
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "cleanup_stale_devices",
+			Description: "Delete offline devices that haven't been seen for a number of days. Only lists what would be deleted unless confirm=true, which also requires the device_ids from that preview; device_ids that aren't stale are never deleted. Deleted devices must be re-authenticated to rejoin.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type:       "object",
+				Properties: cleanupProperties,
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				staleCriteria
+				DeviceIDs []string `json:"device_ids"`
+				Confirm   bool     `json:"confirm"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Days <= 0 {
+				params.Days = defaultStaleDays
+			}
+
+			// Deleting requires the exact devices from a preview, so a device
+			// that went stale after the preview is never deleted unseen
+			if params.Confirm && len(params.DeviceIDs) == 0 {
+				return invalidInputResult("confirm=true requires device_ids: run cleanup_stale_devices without confirm first and pass the device_ids it lists"), nil
+			}
+
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			devices, err := api.ListDevices(ctx)
+			if err != nil {
+				return errorResult("Error listing devices", err), nil
+			}
+
+			// Staleness is re-checked here rather than trusting an earlier
+			// listing, so a device that came back since is never deleted
+			stale := findStale(devices, params.staleCriteria, time.Now())
+			var selected []staleDevice
+			var skipped []string
+			if len(params.DeviceIDs) > 0 {
+				for _, id := range params.DeviceIDs {
+					found := false
+					for _, d := range stale {
+						if d.DeviceID == id {
+							selected = append(selected, d)
+							found = true
+						}
+					}
+					if !found {
+						skipped = append(skipped, id)
+					}
+				}
+			} else {
+				selected = stale
+			}
+
+			var result strings.Builder
+			if len(skipped) > 0 {
+				result.WriteString(fmt.Sprintf("⚠ Not stale (or not found), skipped: %s\n\n", strings.Join(skipped, ", ")))
+			}
+			if len(selected) == 0 {
+				result.WriteString(fmt.Sprintf("No devices not seen for %d+ days to delete.\n", params.Days))
+				return structuredResult(result.String(), map[string]interface{}{"devices": selected, "skipped": skipped}), nil
+			}
+
+			if !params.Confirm {
+				result.WriteString(fmt.Sprintf("Would delete %d device(s) not seen for %d+ days:\n", len(selected), params.Days))
+				writeStaleDevices(&result, selected)
+				ids := make([]string, len(selected))
+				for i, d := range selected {
+					ids[i] = d.DeviceID
+				}
+				result.WriteString(fmt.Sprintf("\nRe-run with confirm=true and device_ids=[%s] to delete them.\n", strings.Join(ids, ", ")))
+				return structuredResult(result.String(), map[string]interface{}{"devices": selected, "skipped": skipped}), nil
+			}
+
+			targets := make([]tailscale.Device, len(selected))
+			for i, d := range selected {
+				targets[i] = tailscale.Device{ID: d.DeviceID, Hostname: d.Name}
+			}
+			results := runBulk(ctx, targets, defaultBulkConcurrency, newProgress(req, len(targets)), func(ctx context.Context, d tailscale.Device) error {
+				return api.DeleteDevice(ctx, d.ID)
+			})
+			failed := writeBulkResults(&result, results)
+			result.WriteString(fmt.Sprintf("\nDeleted %d of %d stale device(s)\n", len(results)-failed, len(results)))
+
+			return structuredResult(result.String(), map[string]interface{}{"deleted": results, "skipped": skipped}), nil
+		}),
+	)
+}
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// structuredResult returns text for display along with data as structured
+// content, so agents can read the same result without parsing the text.
+// MCP requires structured content to be a JSON object, so data should be a
+// struct or map.
+func structuredResult(text string, data interface{}) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+		StructuredContent: data,
+	}
+}
+
+// textResult returns a successful result with only text content
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+}
+
+// deviceInfo is the structured form of a device in the local status
+type deviceInfo struct {
+	Name           string     `json:"name"`
+	DNSName        string     `json:"dns_name,omitempty"`
+	OS             string     `json:"os,omitempty"`
+	User           string     `json:"user,omitempty"`
+	Online         bool       `json:"online"`
+	Active         bool       `json:"active,omitempty"`
+	IPs            []string   `json:"ips,omitempty"`
+	AllowedIPs     []string   `json:"allowed_ips,omitempty"`
+	Tags           []string   `json:"tags,omitempty"`
+	ExitNode       bool       `json:"exit_node,omitempty"`
+	ExitNodeOption bool       `json:"exit_node_option,omitempty"`
+	Self           bool       `json:"self,omitempty"`
+	PublicKey      string     `json:"public_key,omitempty"`
+	LastSeen       *time.Time `json:"last_seen,omitempty"`
+	RxBytes        int64      `json:"rx_bytes,omitempty"`
+	TxBytes        int64      `json:"tx_bytes,omitempty"`
+}
+
+// newDeviceInfo summarizes a peer (or the local node, when self is set)
+func newDeviceInfo(peer *tailscale.PeerStatus, self bool) deviceInfo {
+	info := deviceInfo{
+		Name:           peer.HostName,
+		DNSName:        peer.DNSName,
+		OS:             peer.OS,
+		Online:         peer.Online,
+		Active:         peer.Active,
+		IPs:            peer.TailscaleIPs,
+		AllowedIPs:     peer.AllowedIPs,
+		Tags:           peer.Tags,
+		ExitNode:       peer.ExitNode,
+		ExitNodeOption: peer.ExitNodeOption,
+		Self:           self,
+		PublicKey:      peer.PublicKey,
+		RxBytes:        peer.RxBytes,
+		TxBytes:        peer.TxBytes,
+	}
+	if !peer.LastSeen.IsZero() {
+		lastSeen := peer.LastSeen
+		info.LastSeen = &lastSeen
+	}
+	return info
+}
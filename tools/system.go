@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/google/jsonschema-go/jsonschema"
@@ -18,10 +20,11 @@ func RegisterSystemTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "get_ip",
 			Description: "Get Tailscale IP addresses for this device or a specific device",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
-					"device": {Type: "string", Description: "Device name to get IP for (optional, defaults to this device)"},
+					"device": {Type: "string", Description: "Device name, or a unique part of one, to get IP for (optional, defaults to this device)"},
 				},
 			},
 		},
@@ -30,42 +33,34 @@ func RegisterSystemTools(server *mcp.Server, cli *tailscale.CLI) {
 				Device string `json:"device"`
 			}
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Invalid parameters: %v", err)},
-					},
-				}, nil
+				return invalidParamsResult(err), nil
 			}
 
-			ip, err := cli.IP(params.Device)
-			if err != nil {
-				if params.Device != "" {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: fmt.Sprintf("Failed to get IP for device '%s': %v", params.Device, err)},
-						},
-					}, nil
-				} else {
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: fmt.Sprintf("Failed to get IP: %v", err)},
-						},
-					}, nil
+			if params.Device != "" {
+				status, err := cli.Status(ctx)
+				if err != nil {
+					return errorResult(fmt.Sprintf("Failed to get IP for device '%s'", params.Device), err), nil
+				}
+				peer, err := resolveDevice(status, params.Device, true)
+				if err != nil {
+					return errorResult(fmt.Sprintf("Failed to get IP for device '%s'", params.Device), err), nil
 				}
+				name := peerHost(peer)
+				return structuredResult(fmt.Sprintf("Tailscale IP for device '%s':\n%s", name, strings.Join(peer.TailscaleIPs, "\n")), map[string]interface{}{
+					"device": name,
+					"ips":    peer.TailscaleIPs,
+				}), nil
 			}
 
-			var result string
-			if params.Device != "" {
-				result = fmt.Sprintf("Tailscale IP for device '%s':\n%s", params.Device, ip)
-			} else {
-				result = fmt.Sprintf("Your Tailscale IP addresses:\n%s", ip)
+			ip, err := cli.IP(ctx, "")
+			if err != nil {
+				return errorResult("Failed to get IP", err), nil
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("Your Tailscale IP addresses:\n%s", ip), map[string]interface{}{
+				"device": params.Device,
+				"ips":    strings.Fields(ip),
+			}), nil
 		}),
 	)
 
@@ -74,16 +69,13 @@ func RegisterSystemTools(server *mcp.Server, cli *tailscale.CLI) {
 		&mcp.Tool{
 			Name:        "get_preferences",
 			Description: "Get current Tailscale preferences and settings",
+			Annotations: readOnlyTool(),
 			InputSchema: &jsonschema.Schema{Type: "object"},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			status, err := cli.Status()
+			status, err := cli.Status(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error getting preferences: %v", err)},
-					},
-				}, nil
+				return errorResult("Error getting preferences", err), nil
 			}
 
 			var result strings.Builder
@@ -184,11 +176,166 @@ func RegisterSystemTools(server *mcp.Server, cli *tailscale.CLI) {
 				result.WriteString("No health issues detected\n")
 			}
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: result.String()},
+			prefs := map[string]interface{}{
+				"status": newStatusInfo(status),
+				"routes": advertisedRoutes(status),
+			}
+			if status.Self != nil {
+				prefs["key_expiry"] = status.Self.KeyExpiry
+				prefs["key_expired"] = status.Self.Expired
+			}
+			for _, peer := range status.Peer {
+				if peer.ExitNode {
+					prefs["exit_node"] = peer.HostName
+					break
+				}
+			}
+
+			return structuredResult(result.String(), prefs), nil
+		}),
+	)
+
+	// Set preferences tool
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "set_preferences",
+			Description: "Change this device's Tailscale preferences (tailscale set): shields-up, accept-dns, hostname, operator, auto-update, netfilter mode and advertised tags. Only the given preferences change. Exit nodes and routes have their own tools.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"shields_up": {
+						Type:        "boolean",
+						Description: "Block all incoming connections from the tailnet (this also blocks MCP clients reaching this server over the tailnet)",
+					},
+					"accept_dns": {
+						Type:        "boolean",
+						Description: "Use the tailnet's DNS settings (MagicDNS, nameservers, search domains)",
+					},
+					"hostname": {
+						Type:        "string",
+						Description: "Hostname to register with; empty to use the OS hostname",
+					},
+					"operator": {
+						Type:        "string",
+						Description: "Local Unix user allowed to operate tailscaled without sudo; empty to clear",
+					},
+					"auto_update": {
+						Type:        "boolean",
+						Description: "Install client updates automatically",
+					},
+					"netfilter_mode": {
+						Type:        "string",
+						Description: "How tailscaled manages netfilter rules (Linux only): on, nodivert (create chains but don't hook them) or off",
+						Enum:        []interface{}{"on", "nodivert", "off"},
+					},
+					"advertise_tags": {
+						Type:        "array",
+						Description: "Tags to advertise (e.g. tag:server); [] to advertise none. Needs the LocalAPI socket, and the owner must be allowed to apply them by tagOwners. To retag another device, use set_device_tags.",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
 				},
-			}, nil
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				ShieldsUp     *bool     `json:"shields_up"`
+				AcceptDNS     *bool     `json:"accept_dns"`
+				Hostname      *string   `json:"hostname"`
+				Operator      *string   `json:"operator"`
+				AutoUpdate    *bool     `json:"auto_update"`
+				NetfilterMode string    `json:"netfilter_mode"`
+				AdvertiseTags *[]string `json:"advertise_tags"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			flags := map[string]string{}
+			if params.ShieldsUp != nil {
+				flags["shields-up"] = fmt.Sprint(*params.ShieldsUp)
+			}
+			if params.AcceptDNS != nil {
+				flags["accept-dns"] = fmt.Sprint(*params.AcceptDNS)
+			}
+			if params.AutoUpdate != nil {
+				flags["auto-update"] = fmt.Sprint(*params.AutoUpdate)
+			}
+			if params.NetfilterMode != "" {
+				flags["netfilter-mode"] = params.NetfilterMode
+			}
+			var err error
+			if params.Hostname != nil {
+				if *params.Hostname != "" {
+					err = validateMachineName(strings.ToLower(*params.Hostname))
+				}
+				flags["hostname"] = *params.Hostname
+			}
+			if params.Operator != nil {
+				if strings.ContainsAny(*params.Operator, " \t\n=") || strings.HasPrefix(*params.Operator, "-") {
+					err = fmt.Errorf("operator %q is not a valid user name", *params.Operator)
+				}
+				flags["operator"] = *params.Operator
+			}
+			var tags []string
+			if params.AdvertiseTags != nil {
+				tags = []string{}
+				for _, tag := range *params.AdvertiseTags {
+					tag = tailscale.NormalizeTag(tag)
+					if tag == "tag:" || strings.ContainsAny(tag, " ,") {
+						err = fmt.Errorf("%q is not a valid tag", tag)
+					}
+					if tag != "" && !containsString(tags, tag) {
+						tags = append(tags, tag)
+					}
+				}
+			}
+			switch {
+			case err != nil:
+			case params.NetfilterMode != "" && !containsString([]string{"on", "nodivert", "off"}, params.NetfilterMode):
+				err = fmt.Errorf("netfilter_mode must be on, nodivert or off")
+			case len(flags) == 0 && tags == nil:
+				err = fmt.Errorf("no preferences given")
+			}
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			before, _ := cli.Prefs(ctx)
+
+			if len(flags) > 0 {
+				if err := cli.Set(ctx, flags); err != nil {
+					return errorResult("Failed to set preferences", err), nil
+				}
+			}
+			var tagErr error
+			if tags != nil {
+				tagErr = cli.SetAdvertiseTags(ctx, tags)
+			}
+
+			after, _ := cli.Prefs(ctx)
+
+			var result strings.Builder
+			result.WriteString("Preferences updated:\n")
+			changes := preferenceChanges(before, after, flags, tags, tagErr == nil)
+			for _, change := range changes {
+				result.WriteString(fmt.Sprintf("  ✓ %s\n", change))
+			}
+			if tagErr != nil {
+				result.WriteString(fmt.Sprintf("  ✗ advertise-tags: %v\n", tagErr))
+			} else if tags != nil {
+				result.WriteString("\nNew tags are applied once the control server accepts them; if they don't show up, the device may need to re-authenticate.\n")
+			}
+			if params.ShieldsUp != nil && *params.ShieldsUp {
+				result.WriteString("\n⚠ Shields are up: peers can no longer connect to this device.\n")
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{
+				"changed": changes,
+				"prefs":   after,
+			}), nil
 		}),
 	)
 
@@ -196,17 +343,39 @@ func RegisterSystemTools(server *mcp.Server, cli *tailscale.CLI) {
 	server.AddTool(
 		&mcp.Tool{
 			Name:        "health_check",
-			Description: "Check Tailscale network health and connectivity",
-			InputSchema: &jsonschema.Schema{Type: "object"},
+			Description: "Check Tailscale network health and connectivity. With deep=true, also runs netcheck, checks DERP connectivity, key expiry and MagicDNS resolution, and pings a sample of peers, producing a scored report.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"deep": {
+						Type:        "boolean",
+						Description: "Run the slower network checks and score the result (default: false)",
+					},
+					"sample_size": {
+						Type:        "integer",
+						Description: fmt.Sprintf("Online peers to ping in deep mode (default: %d)", defaultHealthPeerSample),
+					},
+				},
+			},
 		},
 		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			status, err := cli.Status()
+			var params struct {
+				Deep       bool `json:"deep"`
+				SampleSize int  `json:"sample_size"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.SampleSize <= 0 {
+				params.SampleSize = defaultHealthPeerSample
+			}
+
+			status, err := cli.Status(ctx)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{
-						&mcp.TextContent{Text: fmt.Sprintf("Error performing health check: %v", err)},
-					},
-				}, nil
+				return errorResult("Error performing health check", err), nil
 			}
 
 			var result strings.Builder
@@ -281,6 +450,29 @@ func RegisterSystemTools(server *mcp.Server, cli *tailscale.CLI) {
 				result.WriteString("✗ ISSUES DETECTED: Tailscale needs attention\n")
 			}
 
+			if params.Deep {
+				checks := runDeepHealthChecks(ctx, cli, status, params.SampleSize, newProgress(req, 3))
+				score := healthScore(checks)
+				result.WriteString("\n=== Deep Checks ===\n")
+				for _, check := range checks {
+					result.WriteString(fmt.Sprintf("%s %s: %s\n", healthMark(check.Status), check.Name, check.Detail))
+				}
+				grade := "HEALTHY"
+				switch {
+				case score < 60:
+					grade = "UNHEALTHY"
+				case score < 90:
+					grade = "DEGRADED"
+				}
+				result.WriteString(fmt.Sprintf("\nScore: %d/100 (%s)\n", score, grade))
+
+				return structuredResult(result.String(), map[string]interface{}{
+					"score":  score,
+					"grade":  strings.ToLower(grade),
+					"checks": checks,
+				}), nil
+			}
+
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
 					&mcp.TextContent{Text: result.String()},
@@ -288,4 +480,162 @@ func RegisterSystemTools(server *mcp.Server, cli *tailscale.CLI) {
 			}, nil
 		}),
 	)
-}
\ No newline at end of file
+
+	// Client update tool
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "update_tailscale",
+			Description: "Check for and install Tailscale client updates on this device (tailscale update). Only reports the available update by default; set dry_run=false to install it. Installing restarts tailscaled, briefly dropping tailnet connections, and usually needs the server to run as root.",
+			Annotations: updateTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"track": {
+						Type:        "string",
+						Description: "Release track to update from (default: the track of the installed version)",
+						Enum:        []interface{}{"stable", "unstable"},
+					},
+					"version": {
+						Type:        "string",
+						Description: "Specific version to install, e.g. 1.80.2 (default: latest on the track)",
+					},
+					"dry_run": {
+						Type:        "boolean",
+						Description: "Only check for an update without installing it (default: true)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Track   string `json:"track"`
+				Version string `json:"version"`
+				DryRun  *bool  `json:"dry_run"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			var err error
+			switch {
+			case params.Track != "" && params.Track != "stable" && params.Track != "unstable":
+				err = fmt.Errorf("track must be stable or unstable")
+			case params.Version != "" && !clientVersionPattern.MatchString(params.Version):
+				err = fmt.Errorf("version %q must look like 1.80.2", params.Version)
+			}
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+			dryRun := params.DryRun == nil || *params.DryRun
+
+			before := ""
+			if version, err := cli.Version(ctx); err == nil {
+				before = strings.SplitN(version, "\n", 2)[0]
+			}
+
+			output, err := cli.Update(ctx, dryRun, params.Track, params.Version)
+			if err != nil {
+				return errorResult("✗ Update failed", err), nil
+			}
+
+			var result strings.Builder
+			if dryRun {
+				result.WriteString("Update check (dry run)\n")
+			} else {
+				result.WriteString("✓ Update finished\n")
+			}
+			if before != "" {
+				result.WriteString(fmt.Sprintf("Installed version: %s\n", before))
+			}
+			if output != "" {
+				result.WriteString(fmt.Sprintf("\n%s\n", output))
+			}
+
+			after := ""
+			if !dryRun {
+				if version, err := cli.Version(ctx); err == nil {
+					after = strings.SplitN(version, "\n", 2)[0]
+					result.WriteString(fmt.Sprintf("\nNow running: %s\n", after))
+				}
+			} else {
+				result.WriteString("\nSet dry_run=false to install the update.\n")
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{
+				"dry_run":          dryRun,
+				"previous_version": before,
+				"current_version":  after,
+				"output":           output,
+			}), nil
+		}),
+	)
+}
+
+// preferenceChanges describes the preferences set_preferences changed as
+// "name: old → new", or just "name: new" when the old value isn't known
+func preferenceChanges(before, after *tailscale.Prefs, flags map[string]string, tags []string, tagsSet bool) []string {
+	netfilterModes := map[int]string{0: "off", 1: "nodivert", 2: "on"}
+	value := func(prefs *tailscale.Prefs, name string) string {
+		if prefs == nil {
+			return ""
+		}
+		switch name {
+		case "shields-up":
+			return fmt.Sprint(prefs.ShieldsUp)
+		case "accept-dns":
+			return fmt.Sprint(prefs.CorpDNS)
+		case "hostname":
+			return fmt.Sprintf("%q", prefs.Hostname)
+		case "operator":
+			return fmt.Sprintf("%q", prefs.OperatorUser)
+		case "auto-update":
+			return fmt.Sprint(prefs.AutoUpdate.Apply != nil && *prefs.AutoUpdate.Apply)
+		case "netfilter-mode":
+			return netfilterModes[prefs.NetfilterMode]
+		case "advertise-tags":
+			return "[" + strings.Join(prefs.AdvertiseTags, ", ") + "]"
+		}
+		return ""
+	}
+
+	names := make([]string, 0, len(flags)+1)
+	for name := range flags {
+		names = append(names, name)
+	}
+	if tags != nil && tagsSet {
+		names = append(names, "advertise-tags")
+	}
+	sort.Strings(names)
+
+	var changes []string
+	for _, name := range names {
+		old, current := value(before, name), value(after, name)
+		if current == "" {
+			current = flags[name]
+			if name == "advertise-tags" {
+				current = "[" + strings.Join(tags, ", ") + "]"
+			}
+		}
+		if old != "" && old != current {
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", name, old, current))
+		} else {
+			changes = append(changes, fmt.Sprintf("%s: %s", name, current))
+		}
+	}
+	return changes
+}
+
+// clientVersionPattern matches a Tailscale client version such as 1.80.2
+var clientVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// advertisedRoutes maps each peer with primary subnet routes to those routes
+func advertisedRoutes(status *tailscale.Status) map[string][]string {
+	routes := map[string][]string{}
+	for _, peer := range status.Peer {
+		if len(peer.PrimaryRoutes) > 0 {
+			routes[peer.HostName] = peer.PrimaryRoutes
+		}
+	}
+	return routes
+}
@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// savedFile is a file written to the target directory by get_received_file
+type savedFile struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// taildropDir resolves dir inside the allowed output directories, creating
+// it if needed, so received files can't be dropped anywhere on disk
+func taildropDir(ctx context.Context, output *OutputWriter, session *mcp.ServerSession, dir string) (string, error) {
+	path, err := output.ResolveDir(ctx, session, dir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("dir %q: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%q is not a directory", dir)
+	}
+	return path, nil
+}
+
+// dirSnapshot records the modification time of each file in dir
+func dirSnapshot(dir string) map[string]time.Time {
+	snapshot := map[string]time.Time{}
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && !entry.IsDir() {
+			snapshot[entry.Name()] = info.ModTime()
+		}
+	}
+	return snapshot
+}
+
+// RegisterTaildropTools registers tools for files received with Taildrop
+func RegisterTaildropTools(server *mcp.Server, cli *tailscale.CLI, output *OutputWriter) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "list_received_files",
+			Description: "List files sent to this device with Taildrop that are waiting to be saved",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			files, err := cli.WaitingFiles(ctx)
+			if err != nil {
+				return errorResult("Error listing received files", err), nil
+			}
+			sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Received Files (%d):\n\n", len(files)))
+			if len(files) == 0 {
+				result.WriteString("No files waiting.\n")
+			}
+			var total int64
+			for _, f := range files {
+				result.WriteString(fmt.Sprintf("  %s (%s)\n", f.Name, formatByteSize(f.Size)))
+				total += f.Size
+			}
+			if len(files) > 0 {
+				result.WriteString(fmt.Sprintf("\nTotal: %s. Save them with get_received_file.\n", formatByteSize(total)))
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"files": files}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "get_received_file",
+			Description: "Save files received with Taildrop to a directory (tailscale file get), removing them from the inbox. Saves every waiting file, or just the one given by name.",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"dir": {
+						Type:        "string",
+						Description: "Directory to save the files in, inside the client's roots or the server's output directory; relative paths are placed in the first of them (default: that directory itself)",
+					},
+					"name": {
+						Type:        "string",
+						Description: "Only save this file (optional; needs the tailscaled LocalAPI socket)",
+					},
+					"conflict": {
+						Type:        "string",
+						Description: "What to do when a file with the same name exists: rename (default), skip or overwrite",
+						Enum:        []interface{}{"rename", "skip", "overwrite"},
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Dir      string `json:"dir"`
+				Name     string `json:"name"`
+				Conflict string `json:"conflict"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Conflict == "" {
+				params.Conflict = "rename"
+			}
+			var err error
+			switch {
+			case !containsString([]string{"rename", "skip", "overwrite"}, params.Conflict):
+				err = fmt.Errorf("conflict must be rename, skip or overwrite")
+			case params.Name != "" && (params.Name != filepath.Base(params.Name) || params.Name == "." || params.Name == ".."):
+				err = fmt.Errorf("name %q must be a file name, not a path", params.Name)
+			default:
+				params.Dir, err = taildropDir(ctx, output, req.Session, params.Dir)
+			}
+			if err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			var saved []savedFile
+			var result strings.Builder
+			if params.Name != "" {
+				file, err := saveReceivedFile(ctx, cli, params.Dir, params.Name, params.Conflict)
+				if err != nil {
+					return errorResult(fmt.Sprintf("Error saving %s", params.Name), err), nil
+				}
+				if file != nil {
+					saved = append(saved, *file)
+				} else {
+					result.WriteString(fmt.Sprintf("Skipped %s: a file with that name already exists in %s\n", params.Name, params.Dir))
+				}
+			} else {
+				before := dirSnapshot(params.Dir)
+				if _, err := cli.FileGet(ctx, params.Dir, params.Conflict); err != nil {
+					if strings.Contains(err.Error(), "no files") {
+						return &mcp.CallToolResult{
+							Content: []mcp.Content{
+								&mcp.TextContent{Text: "No received files are waiting."},
+							},
+						}, nil
+					}
+					return errorResult("Error saving received files", err), nil
+				}
+				// The CLI doesn't say what it wrote, so compare the directory
+				for name, modified := range dirSnapshot(params.Dir) {
+					if old, ok := before[name]; !ok || !old.Equal(modified) {
+						file := savedFile{Name: name, Path: filepath.Join(params.Dir, name)}
+						if info, err := os.Stat(file.Path); err == nil {
+							file.Size = info.Size()
+						}
+						saved = append(saved, file)
+					}
+				}
+				sort.Slice(saved, func(i, j int) bool { return saved[i].Name < saved[j].Name })
+			}
+
+			if len(saved) > 0 {
+				result.WriteString(fmt.Sprintf("✓ Saved %d file(s) to %s:\n", len(saved), params.Dir))
+				for _, f := range saved {
+					result.WriteString(fmt.Sprintf("  %s (%s)\n", f.Name, formatByteSize(f.Size)))
+				}
+			} else if params.Name == "" {
+				result.WriteString("No new files were written")
+				if params.Conflict == "skip" {
+					result.WriteString("; files whose names already exist were skipped")
+				}
+				result.WriteString(".\n")
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"saved": saved}), nil
+		}),
+	)
+}
+
+// saveReceivedFile saves one waiting file into dir, resolving a name clash
+// the way tailscale file get does. It returns nil when the file was skipped.
+// The file is written to a temporary name first so a failed transfer never
+// leaves a partial file or clobbers the one being overwritten.
+func saveReceivedFile(ctx context.Context, cli *tailscale.CLI, dir, name, conflict string) (*savedFile, error) {
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		switch conflict {
+		case "skip":
+			return nil, nil
+		case "rename":
+			ext := filepath.Ext(name)
+			base := strings.TrimSuffix(name, ext)
+			for i := 1; ; i++ {
+				path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+				if _, err := os.Stat(path); os.IsNotExist(err) {
+					break
+				}
+			}
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, ".taildrop-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if err := cli.SaveWaitingFile(ctx, name, tmp); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+
+	file := &savedFile{Name: filepath.Base(path), Path: path}
+	if info, err := os.Stat(path); err == nil {
+		file.Size = info.Size()
+	}
+	return file, nil
+}
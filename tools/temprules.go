@@ -0,0 +1,349 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/scheduler"
+	"github.com/phildougherty/go-tailscale-mcp/store"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+const temporaryRulesBucket = "temporary_rules"
+
+// TemporaryRule is an ACL rule that is removed from the policy when it expires
+type TemporaryRule struct {
+	ID      string               `json:"id"`
+	Rule    tailscale.PolicyRule `json:"rule"`
+	Reason  string               `json:"reason,omitempty"`
+	Source  string               `json:"source,omitempty"` // e.g., "access_request:1a2b3c4d"
+	Created time.Time            `json:"created"`
+	Expires time.Time            `json:"expires"`
+}
+
+// RegisterTemporaryRuleTools registers tools for time-bound ACL rules
+func RegisterTemporaryRuleTools(server *mcp.Server, api *tailscale.APIClient, st *store.Store) {
+	// Add temporary rule tool
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_add_temporary_rule",
+			Description: "Add an ACL accept rule that is removed automatically when it expires. The policy is validated before the rule is added and again when it is removed; clients are notified on expiry.",
+			Annotations: updateTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"src": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "Sources (e.g., ['group:eng', 'alice@example.com'])",
+					},
+					"dst": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "Destinations in host:port form (e.g., ['tag:db:5432'])",
+					},
+					"duration": {
+						Type:        "string",
+						Description: "How long the rule should exist (e.g., 30m, 4h) (default: 1h, max: 168h)",
+					},
+					"reason": {
+						Type:        "string",
+						Description: "Why the rule is needed (optional)",
+					},
+				},
+				Required: []string{"src", "dst"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				Src      []string `json:"src"`
+				Dst      []string `json:"dst"`
+				Duration string   `json:"duration"`
+				Reason   string   `json:"reason"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			if len(params.Src) == 0 || len(params.Dst) == 0 {
+				return invalidInputResult("At least one src and one dst are required"), nil
+			}
+			for _, src := range params.Src {
+				for _, dst := range params.Dst {
+					if err := validateAccessTarget(src, dst); err != nil {
+						return errorResult("Invalid rule", err), nil
+					}
+				}
+			}
+
+			duration, err := parseAccessDuration(params.Duration)
+			if err != nil {
+				return errorResult("Invalid rule", err), nil
+			}
+
+			rule := tailscale.PolicyRule{Action: "accept", Src: params.Src, Dst: params.Dst}
+			temp, policyDiff, err := addTemporaryRule(ctx, api, st, rule, duration, params.Reason, "acl_add_temporary_rule")
+			if temp == nil {
+				return errorResult("Error adding temporary rule", err), nil
+			}
+
+			var result strings.Builder
+			if err != nil {
+				result.WriteString(fmt.Sprintf("⚠ %v\n\n", err))
+			}
+			result.WriteString(fmt.Sprintf("✓ Temporary rule %s added: %s\n", temp.ID, describeRule(rule)))
+			result.WriteString(fmt.Sprintf("  Expires: %s (in %s)\n", temp.Expires.Format(time.RFC3339), duration))
+			if policyDiff != "" {
+				result.WriteString("\nApplied ACL change:\n")
+				result.WriteString(policyDiff)
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: result.String()},
+				},
+			}, nil
+		}),
+	)
+
+	// List temporary rules tool
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_list_temporary_rules",
+			Description: "List temporary ACL rules and when they expire",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var rules map[string]*TemporaryRule
+			if err := st.Load(temporaryRulesBucket, &rules); err != nil {
+				return errorResult("Error loading temporary rules", err), nil
+			}
+
+			if len(rules) == 0 {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: "No temporary ACL rules."},
+					},
+				}, nil
+			}
+
+			list := make([]*TemporaryRule, 0, len(rules))
+			for _, temp := range rules {
+				list = append(list, temp)
+			}
+			sort.Slice(list, func(i, j int) bool { return list[i].Expires.Before(list[j].Expires) })
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Temporary ACL Rules (%d):\n\n", len(list)))
+			now := time.Now()
+			for _, temp := range list {
+				result.WriteString(fmt.Sprintf("%s: %s\n", temp.ID, describeRule(temp.Rule)))
+				if now.After(temp.Expires) {
+					result.WriteString(fmt.Sprintf("  Expired: %s (pending removal)\n", temp.Expires.Format(time.RFC3339)))
+				} else {
+					result.WriteString(fmt.Sprintf("  Expires: %s (in %s)\n", temp.Expires.Format(time.RFC3339), temp.Expires.Sub(now).Round(time.Minute)))
+				}
+				if temp.Reason != "" {
+					result.WriteString(fmt.Sprintf("  Reason: %s\n", temp.Reason))
+				}
+				if temp.Source != "" {
+					result.WriteString(fmt.Sprintf("  Source: %s\n", temp.Source))
+				}
+				result.WriteString("\n")
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"rules": list}), nil
+		}),
+	)
+
+	// Revoke temporary rule tool
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "acl_remove_temporary_rule",
+			Description: "Remove a temporary ACL rule before it expires",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"rule_id": {
+						Type:        "string",
+						Description: "ID of the temporary rule",
+					},
+				},
+				Required: []string{"rule_id"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				RuleID string `json:"rule_id"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			found, policyDiff, err := removeTemporaryRule(ctx, api, st, params.RuleID)
+			if err != nil {
+				return errorResult("Error removing temporary rule", err), nil
+			}
+
+			text := fmt.Sprintf("✓ Temporary rule %s removed", params.RuleID)
+			if !found {
+				text = fmt.Sprintf("Temporary rule %s was no longer in the policy; its record was removed", params.RuleID)
+			}
+			if policyDiff != "" {
+				text += "\n\nApplied ACL change:\n" + policyDiff
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: text},
+				},
+			}, nil
+		}),
+	)
+}
+
+// ScheduleTemporaryRuleExpiry registers the background job that removes
+// expired temporary rules from the policy and notifies connected clients
+func ScheduleTemporaryRuleExpiry(server *mcp.Server, api *tailscale.APIClient, st *store.Store, sched *scheduler.Scheduler) {
+	sched.Every("temporary-rule-expiry", time.Minute, func(ctx context.Context) {
+		expireTemporaryRules(ctx, server, api, st)
+	})
+}
+
+// addTemporaryRule applies rule to the policy and records it for automatic
+// removal after duration. It returns the recorded rule and the policy diff.
+func addTemporaryRule(ctx context.Context, api *tailscale.APIClient, st *store.Store, rule tailscale.PolicyRule, duration time.Duration, reason, source string) (*TemporaryRule, string, error) {
+	now := time.Now()
+	temp := &TemporaryRule{
+		ID:      newRecordID(),
+		Rule:    rule,
+		Reason:  reason,
+		Source:  source,
+		Created: now,
+		Expires: now.Add(duration),
+	}
+
+	comment := fmt.Sprintf("%s expires %s (managed by tailscale-mcp)", temporaryRuleMarker(temp.ID), temp.Expires.UTC().Format(time.RFC3339))
+	policyDiff, err := updatePolicy(ctx, api, func(policy *tailscale.Policy) error {
+		return policy.AddACLRule(rule, comment)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rules map[string]*TemporaryRule
+	err = st.Update(temporaryRulesBucket, &rules, func() error {
+		if rules == nil {
+			rules = make(map[string]*TemporaryRule)
+		}
+		rules[temp.ID] = temp
+		return nil
+	})
+	if err != nil {
+		return temp, policyDiff, fmt.Errorf("rule was applied but its expiry could not be recorded, remove it manually: %w", err)
+	}
+
+	return temp, policyDiff, nil
+}
+
+// expireTemporaryRules removes every temporary rule past its expiry. Rules that
+// fail to be removed are kept and retried on the next run.
+func expireTemporaryRules(ctx context.Context, server *mcp.Server, api *tailscale.APIClient, st *store.Store) {
+	var rules map[string]*TemporaryRule
+	if err := st.Load(temporaryRulesBucket, &rules); err != nil {
+		notifySessions(ctx, server, "error", fmt.Sprintf("Failed to load temporary ACL rules: %v", err))
+		return
+	}
+
+	now := time.Now()
+	for id, temp := range rules {
+		if now.Before(temp.Expires) {
+			continue
+		}
+
+		found, _, err := removeTemporaryRule(ctx, api, st, id)
+		if err != nil {
+			notifySessions(ctx, server, "error", fmt.Sprintf("Failed to remove expired temporary ACL rule %s (will retry): %v", id, err))
+			continue
+		}
+
+		if found {
+			notifySessions(ctx, server, "notice", fmt.Sprintf("Temporary ACL rule %s expired and was removed: %s", id, describeRule(temp.Rule)))
+		} else {
+			notifySessions(ctx, server, "notice", fmt.Sprintf("Temporary ACL rule %s expired but was no longer in the policy: %s", id, describeRule(temp.Rule)))
+		}
+	}
+}
+
+// removeTemporaryRule removes a temporary rule from the policy and the store.
+// Only the rule carrying its marker comment is removed, never an identical
+// permanent rule. It reports whether the rule was still present in the
+// policy.
+func removeTemporaryRule(ctx context.Context, api *tailscale.APIClient, st *store.Store, id string) (bool, string, error) {
+	var rules map[string]*TemporaryRule
+	if err := st.Load(temporaryRulesBucket, &rules); err != nil {
+		return false, "", err
+	}
+	temp, ok := rules[id]
+	if !ok {
+		return false, "", fmt.Errorf("temporary rule %s not found", id)
+	}
+
+	found := false
+	policyDiff, err := updatePolicy(ctx, api, func(policy *tailscale.Policy) error {
+		var err error
+		found, err = policy.RemoveCommentedACLRule(temp.Rule, temporaryRuleMarker(id))
+		return err
+	})
+	if err != nil {
+		return false, policyDiff, err
+	}
+
+	err = st.Update(temporaryRulesBucket, &rules, func() error {
+		delete(rules, id)
+		return nil
+	})
+	if err != nil {
+		return found, policyDiff, fmt.Errorf("rule was removed from the policy but the state file could not be updated: %w", err)
+	}
+	return found, policyDiff, nil
+}
+
+// temporaryRuleMarker is the start of the comment that marks a temporary
+// rule in the policy file. The trailing comma keeps one ID from matching as
+// a prefix of another.
+func temporaryRuleMarker(id string) string {
+	return "Temporary rule " + id + ","
+}
+
+// describeRule formats a rule as "src → dst"
+func describeRule(rule tailscale.PolicyRule) string {
+	return fmt.Sprintf("%s %v → %v", rule.Action, rule.Src, rule.Dst)
+}
+
+// newRecordID returns a short random identifier for stored records
+func newRecordID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
+	return hex.EncodeToString(b)
+}
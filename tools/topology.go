@@ -0,0 +1,377 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// k8sTagPrefix marks devices created by the Kubernetes operator, which tags
+// itself tag:k8s-operator and its proxies tag:k8s (or a tag:k8s-* variant)
+const k8sTagPrefix = "tag:k8s"
+
+// topologyNode is a device in the topology with the roles it plays
+type topologyNode struct {
+	Name   string   `json:"name"`
+	IPs    []string `json:"ips,omitempty"`
+	OS     string   `json:"os,omitempty"`
+	Online bool     `json:"online"`
+	Self   bool     `json:"self,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	// Roles are subnet_router, exit_node, k8s_operator and k8s_proxy
+	Roles []string `json:"roles,omitempty"`
+	// Routes are the subnet routes the node is the primary router for
+	Routes []string `json:"routes,omitempty"`
+	// ExitNodeInUse is set on the exit node this node routes through
+	ExitNodeInUse bool `json:"exit_node_in_use,omitempty"`
+}
+
+// topologyLink is this node's connection to an online peer. Path is direct,
+// relayed, or idle when there's no recent traffic and no direct path yet.
+type topologyLink struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Path       string `json:"path"`
+	Endpoint   string `json:"endpoint,omitempty"`
+	DERPRegion string `json:"derp_region,omitempty"`
+}
+
+// topology is the structured result of network_topology
+type topology struct {
+	Self          string         `json:"self"`
+	Nodes         []topologyNode `json:"nodes"`
+	SubnetRouters []string       `json:"subnet_routers"`
+	ExitNodes     []string       `json:"exit_nodes"`
+	K8sDevices    []string       `json:"k8s_devices"`
+	Links         []topologyLink `json:"links"`
+	Diagram       string         `json:"diagram,omitempty"`
+}
+
+// isExitRoute reports whether a route is a default route advertised by an
+// exit node rather than a subnet
+func isExitRoute(route string) bool {
+	return route == "0.0.0.0/0" || route == "::/0"
+}
+
+// topologyNodeFor describes a device in the local status
+func topologyNodeFor(peer *tailscale.PeerStatus, self bool) topologyNode {
+	node := topologyNode{
+		Name:          peerHost(peer),
+		IPs:           peer.TailscaleIPs,
+		OS:            peer.OS,
+		Online:        peer.Online || self,
+		Self:          self,
+		Tags:          peer.Tags,
+		ExitNodeInUse: peer.ExitNode,
+	}
+	for _, route := range peer.PrimaryRoutes {
+		if !isExitRoute(route) {
+			node.Routes = append(node.Routes, route)
+		}
+	}
+	if len(node.Routes) > 0 {
+		node.Roles = append(node.Roles, "subnet_router")
+	}
+	if peer.ExitNodeOption {
+		node.Roles = append(node.Roles, "exit_node")
+	}
+	if containsString(peer.Tags, "tag:k8s-operator") {
+		node.Roles = append(node.Roles, "k8s_operator")
+	} else {
+		for _, tag := range peer.Tags {
+			if strings.HasPrefix(tag, k8sTagPrefix) {
+				node.Roles = append(node.Roles, "k8s_proxy")
+				break
+			}
+		}
+	}
+	return node
+}
+
+// buildTopology collects the devices, their roles and this node's links to
+// online peers from the local status. Only links from this node are known:
+// how two other peers reach each other isn't visible here.
+func buildTopology(status *tailscale.Status, includeOffline bool) topology {
+	top := topology{
+		SubnetRouters: []string{},
+		ExitNodes:     []string{},
+		K8sDevices:    []string{},
+		Links:         []topologyLink{},
+	}
+	if status.Self != nil {
+		self := topologyNodeFor(status.Self, true)
+		top.Self = self.Name
+		top.Nodes = append(top.Nodes, self)
+	}
+
+	var peers []topologyNode
+	for _, peer := range status.Peer {
+		if peer == nil || (!peer.Online && !includeOffline) {
+			continue
+		}
+		node := topologyNodeFor(peer, false)
+		peers = append(peers, node)
+		if !peer.Online {
+			continue
+		}
+		link := topologyLink{From: top.Self, To: node.Name}
+		switch {
+		case peer.CurAddr != "":
+			link.Path, link.Endpoint = "direct", peer.CurAddr
+		case peer.Active && peer.Relay != "":
+			link.Path, link.DERPRegion = "relayed", peer.Relay
+		default:
+			link.Path, link.DERPRegion = "idle", peer.Relay
+		}
+		top.Links = append(top.Links, link)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Name < peers[j].Name })
+	sort.Slice(top.Links, func(i, j int) bool { return top.Links[i].To < top.Links[j].To })
+	top.Nodes = append(top.Nodes, peers...)
+
+	for _, node := range top.Nodes {
+		for _, role := range node.Roles {
+			switch role {
+			case "subnet_router":
+				top.SubnetRouters = append(top.SubnetRouters, node.Name)
+			case "exit_node":
+				top.ExitNodes = append(top.ExitNodes, node.Name)
+			case "k8s_operator", "k8s_proxy":
+				top.K8sDevices = append(top.K8sDevices, node.Name)
+			}
+		}
+	}
+	return top
+}
+
+// mermaidLabel quotes a Mermaid node label
+func mermaidLabel(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// nodeLabel is the diagram label of a node: its name and roles
+func nodeLabel(node topologyNode) string {
+	label := node.Name
+	if node.Self {
+		label += " (this node)"
+	}
+	if len(node.Roles) > 0 {
+		label += "\n" + strings.Join(node.Roles, ", ")
+	}
+	return label
+}
+
+// topologyMermaid renders the topology as a Mermaid flowchart. Direct links
+// are solid, relayed ones dotted and idle ones left out; Kubernetes devices
+// are grouped in a subgraph.
+func topologyMermaid(top topology) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	ids := map[string]string{}
+	var k8s []topologyNode
+	for i, node := range top.Nodes {
+		ids[node.Name] = fmt.Sprintf("n%d", i)
+		if containsString(top.K8sDevices, node.Name) {
+			k8s = append(k8s, node)
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %s[%s]\n", ids[node.Name], mermaidLabel(strings.ReplaceAll(nodeLabel(node), "\n", "<br/>"))))
+	}
+	if len(k8s) > 0 {
+		b.WriteString("  subgraph k8s [Kubernetes]\n")
+		for _, node := range k8s {
+			b.WriteString(fmt.Sprintf("    %s[%s]\n", ids[node.Name], mermaidLabel(strings.ReplaceAll(nodeLabel(node), "\n", "<br/>"))))
+		}
+		b.WriteString("  end\n")
+	}
+
+	for _, link := range top.Links {
+		switch link.Path {
+		case "direct":
+			b.WriteString(fmt.Sprintf("  %s ---|direct| %s\n", ids[link.From], ids[link.To]))
+		case "relayed":
+			b.WriteString(fmt.Sprintf("  %s -.-|%s| %s\n", ids[link.From], mermaidLabel("DERP "+link.DERPRegion), ids[link.To]))
+		}
+	}
+
+	route := 0
+	internet := false
+	for _, node := range top.Nodes {
+		for _, r := range node.Routes {
+			b.WriteString(fmt.Sprintf("  %s --> r%d[(%s)]\n", ids[node.Name], route, mermaidLabel(r)))
+			route++
+		}
+		if containsString(node.Roles, "exit_node") {
+			if !internet {
+				b.WriteString("  internet((Internet))\n")
+				internet = true
+			}
+			arrow := "-.->"
+			if node.ExitNodeInUse {
+				arrow = "==>"
+			}
+			b.WriteString(fmt.Sprintf("  %s %s internet\n", ids[node.Name], arrow))
+		}
+	}
+	return b.String()
+}
+
+// topologyDOT renders the topology as a Graphviz DOT graph, styled like the
+// Mermaid diagram
+func topologyDOT(top topology) string {
+	var b strings.Builder
+	b.WriteString("graph tailnet {\n  rankdir=LR;\n  node [shape=box];\n")
+	var k8s []topologyNode
+	for _, node := range top.Nodes {
+		if containsString(top.K8sDevices, node.Name) {
+			k8s = append(k8s, node)
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %s [label=%s];\n", strconv.Quote(node.Name), strconv.Quote(nodeLabel(node))))
+	}
+	if len(k8s) > 0 {
+		b.WriteString("  subgraph cluster_k8s {\n    label=\"Kubernetes\";\n")
+		for _, node := range k8s {
+			b.WriteString(fmt.Sprintf("    %s [label=%s];\n", strconv.Quote(node.Name), strconv.Quote(nodeLabel(node))))
+		}
+		b.WriteString("  }\n")
+	}
+
+	for _, link := range top.Links {
+		switch link.Path {
+		case "direct":
+			b.WriteString(fmt.Sprintf("  %s -- %s [label=\"direct\"];\n", strconv.Quote(link.From), strconv.Quote(link.To)))
+		case "relayed":
+			b.WriteString(fmt.Sprintf("  %s -- %s [label=%s, style=dashed];\n", strconv.Quote(link.From), strconv.Quote(link.To), strconv.Quote("DERP "+link.DERPRegion)))
+		}
+	}
+
+	internet := false
+	for _, node := range top.Nodes {
+		for _, r := range node.Routes {
+			b.WriteString(fmt.Sprintf("  %s [shape=cylinder];\n  %s -- %s;\n", strconv.Quote(r), strconv.Quote(node.Name), strconv.Quote(r)))
+		}
+		if containsString(node.Roles, "exit_node") {
+			if !internet {
+				b.WriteString("  \"Internet\" [shape=ellipse];\n")
+				internet = true
+			}
+			style := "dotted"
+			if node.ExitNodeInUse {
+				style = "bold"
+			}
+			b.WriteString(fmt.Sprintf("  %s -- \"Internet\" [style=%s];\n", strconv.Quote(node.Name), style))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// RegisterTopologyTools registers the tailnet topology map
+func RegisterTopologyTools(server *mcp.Server, cli *tailscale.CLI) {
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "network_topology",
+			Description: "Map the tailnet as seen from this node: subnet routers and the routes they serve, exit nodes, devices created by the Kubernetes operator, and whether this node reaches each online peer directly or relayed through DERP. Optionally renders a Mermaid or Graphviz DOT diagram.",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"diagram": {
+						Type:        "string",
+						Description: "Also render the topology as a diagram (default: none)",
+						Enum:        []interface{}{"none", "mermaid", "dot"},
+					},
+					"include_offline": {
+						Type:        "boolean",
+						Description: "Include offline devices (default: false)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Diagram        string `json:"diagram"`
+				IncludeOffline bool   `json:"include_offline"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Diagram != "" && params.Diagram != "none" && params.Diagram != "mermaid" && params.Diagram != "dot" {
+				return invalidInputResult("Invalid parameters: diagram must be none, mermaid or dot"), nil
+			}
+
+			status, err := cli.Status(ctx)
+			if err != nil {
+				return errorResult("Error getting status", err), nil
+			}
+
+			top := buildTopology(status, params.IncludeOffline)
+			switch params.Diagram {
+			case "mermaid":
+				top.Diagram = topologyMermaid(top)
+			case "dot":
+				top.Diagram = topologyDOT(top)
+			}
+
+			nodes := map[string]topologyNode{}
+			for _, node := range top.Nodes {
+				nodes[node.Name] = node
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("=== Tailnet Topology from %s ===\n", top.Self))
+
+			result.WriteString(fmt.Sprintf("\nSubnet Routers (%d):\n", len(top.SubnetRouters)))
+			for _, name := range top.SubnetRouters {
+				result.WriteString(fmt.Sprintf("  %s %s: %s\n", checkMark(nodes[name].Online), name, strings.Join(nodes[name].Routes, ", ")))
+			}
+			result.WriteString(fmt.Sprintf("\nExit Nodes (%d):\n", len(top.ExitNodes)))
+			for _, name := range top.ExitNodes {
+				inUse := ""
+				if nodes[name].ExitNodeInUse {
+					inUse = " (in use)"
+				}
+				result.WriteString(fmt.Sprintf("  %s %s%s\n", checkMark(nodes[name].Online), name, inUse))
+			}
+			if len(top.K8sDevices) > 0 {
+				result.WriteString(fmt.Sprintf("\nKubernetes Operator Devices (%d):\n", len(top.K8sDevices)))
+				for _, name := range top.K8sDevices {
+					result.WriteString(fmt.Sprintf("  %s %s (%s)\n", checkMark(nodes[name].Online), name, strings.Join(nodes[name].Tags, ", ")))
+				}
+			}
+
+			counts := map[string]int{}
+			for _, link := range top.Links {
+				counts[link.Path]++
+			}
+			result.WriteString(fmt.Sprintf("\nLinks to Online Peers: %d direct, %d relayed, %d idle\n", counts["direct"], counts["relayed"], counts["idle"]))
+			for _, link := range top.Links {
+				switch link.Path {
+				case "direct":
+					result.WriteString(fmt.Sprintf("  ✓ %s: direct via %s\n", link.To, link.Endpoint))
+				case "relayed":
+					result.WriteString(fmt.Sprintf("  ⚠ %s: relayed through DERP %s\n", link.To, link.DERPRegion))
+				}
+			}
+			if counts["idle"] > 0 {
+				result.WriteString("  (idle peers have no recent traffic; use connection_path to test one)\n")
+			}
+
+			if top.Diagram != "" {
+				result.WriteString(fmt.Sprintf("\n```%s\n%s```\n", params.Diagram, top.Diagram))
+			}
+
+			return structuredResult(result.String(), top), nil
+		}),
+	)
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// userRoles are the roles that can be assigned through the API. Ownership
+// can only be transferred in the admin console.
+var userRoles = []string{"member", "admin", "it-admin", "network-admin", "billing-admin", "auditor"}
+
+// RegisterUserTools registers tailnet user management tools
+func RegisterUserTools(server *mcp.Server, api *tailscale.APIClient) {
+	roles := make([]interface{}, len(userRoles))
+	for i, role := range userRoles {
+		roles[i] = role
+	}
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "set_user_role",
+			Description: "Change a tailnet user's role, e.g. to admin, network-admin or member",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"user": {
+						Type:        "string",
+						Description: "Login name (e.g., alice@example.com) or user ID",
+					},
+					"role": {
+						Type:        "string",
+						Description: "New role: member, admin, it-admin, network-admin, billing-admin or auditor",
+						Enum:        roles,
+					},
+				},
+				Required: []string{"user", "role"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				User string `json:"user"`
+				Role string `json:"role"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if !containsString(userRoles, params.Role) {
+				text := fmt.Sprintf("role must be one of %s", strings.Join(userRoles, ", "))
+				if params.Role == "owner" {
+					text = "Ownership can't be assigned through the API; transfer it in the admin console"
+				}
+				return invalidInputResult(text), nil
+			}
+
+			users, err := api.ListUsers(ctx)
+			if err != nil {
+				return errorResult("Error listing users", err), nil
+			}
+			var user *tailscale.TailnetUser
+			for i := range users {
+				if users[i].ID == params.User || strings.EqualFold(users[i].LoginName, params.User) {
+					user = &users[i]
+					break
+				}
+			}
+
+			var problem string
+			switch {
+			case user == nil:
+				problem = fmt.Sprintf("No user %s in the tailnet", params.User)
+			case user.Type == "shared":
+				problem = fmt.Sprintf("%s is shared in from another tailnet and has no role here", user.LoginName)
+			case user.Role == "owner":
+				problem = fmt.Sprintf("%s is the tailnet owner; transfer ownership in the admin console before changing their role", user.LoginName)
+			case user.Role == params.Role:
+				problem = fmt.Sprintf("%s already has the %s role", user.LoginName, params.Role)
+			}
+			if problem != "" {
+				return refusedResult(problem), nil
+			}
+
+			if err := api.SetUserRole(ctx, user.ID, params.Role); err != nil {
+				return errorResult("Error setting user role", err), nil
+			}
+
+			return structuredResult(
+				fmt.Sprintf("✓ %s: %s → %s", user.LoginName, user.Role, params.Role),
+				map[string]interface{}{"user_id": user.ID, "login_name": user.LoginName, "old_role": user.Role, "role": params.Role},
+			), nil
+		}),
+	)
+}
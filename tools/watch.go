@@ -0,0 +1,256 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/scheduler"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// Resources kept up to date by the status watcher
+const (
+	StatusResourceURI = "tailscale://status"
+	PeersResourceURI  = "tailscale://peers"
+	HealthResourceURI = "tailscale://health"
+)
+
+// RegisterStatusResources exposes the local status as MCP resources and
+// schedules a watcher that polls it every interval. When peers come online or
+// go offline, their routes or tags change, their keys expire, the exit node in
+// use changes, or health warnings appear, subscribed clients get
+// notifications/resources/updated for the affected resources and every client
+// gets a log message describing the change.
+func RegisterStatusResources(server *mcp.Server, cli *tailscale.CLI, sched *scheduler.Scheduler, interval time.Duration) {
+	server.AddResource(&mcp.Resource{
+		URI:         StatusResourceURI,
+		Name:        "status",
+		Title:       "Tailscale status",
+		Description: "Backend state, tailnet, peer counts and health of the local node",
+		MIMEType:    "application/json",
+	}, statusResourceHandler(cli, func(status *tailscale.Status) interface{} {
+		return newStatusInfo(status)
+	}))
+
+	server.AddResource(&mcp.Resource{
+		URI:         PeersResourceURI,
+		Name:        "peers",
+		Title:       "Tailscale peers",
+		Description: "Peers in the tailnet with their online state, IPs and routes",
+		MIMEType:    "application/json",
+	}, statusResourceHandler(cli, func(status *tailscale.Status) interface{} {
+		var peers []deviceInfo
+		for _, peer := range filterPeers(status.Peer, nil, nil, nil) {
+			peers = append(peers, newDeviceInfo(peer, false))
+		}
+		return map[string]interface{}{"peers": peers}
+	}))
+
+	server.AddResource(&mcp.Resource{
+		URI:         HealthResourceURI,
+		Name:        "health",
+		Title:       "Tailscale health",
+		Description: "Health warnings reported by tailscaled",
+		MIMEType:    "application/json",
+	}, statusResourceHandler(cli, func(status *tailscale.Status) interface{} {
+		return map[string]interface{}{"backend_state": status.BackendState, "warnings": status.Health}
+	}))
+
+	w := &statusWatcher{server: server, cli: cli}
+	sched.Every("status-watch", interval, w.poll)
+}
+
+// statusResourceHandler serves the JSON form of the current status produced by render
+func statusResourceHandler(cli *tailscale.CLI, render func(*tailscale.Status) interface{}) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		status, err := cli.Status(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status: %w", err)
+		}
+		data, err := json.MarshalIndent(render(status), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{
+				URI:      req.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(data),
+			}},
+		}, nil
+	}
+}
+
+// statusWatcher remembers the last polled status so it can report what changed
+type statusWatcher struct {
+	server *mcp.Server
+	cli    *tailscale.CLI
+
+	mu   sync.Mutex
+	last *statusSnapshot
+}
+
+// statusSnapshot is the part of the status whose changes are worth reporting
+type statusSnapshot struct {
+	backendState string
+	peers        map[string]peerSnapshot
+	health       []string
+}
+
+type peerSnapshot struct {
+	name    string
+	online  bool
+	routes  []string
+	tags    []string
+	expired bool
+	// exitNode is set on the peer this node uses as its exit node
+	exitNode bool
+}
+
+func newStatusSnapshot(status *tailscale.Status) *statusSnapshot {
+	snap := &statusSnapshot{
+		backendState: status.BackendState,
+		peers:        map[string]peerSnapshot{},
+		health:       slices.Sorted(slices.Values(status.Health)),
+	}
+	for key, peer := range status.Peer {
+		if peer == nil {
+			continue
+		}
+		name := peer.HostName
+		if name == "" {
+			name = strings.TrimSuffix(peer.DNSName, ".")
+		}
+		snap.peers[key] = peerSnapshot{
+			name:     name,
+			online:   peer.Online,
+			routes:   slices.Sorted(slices.Values(peer.PrimaryRoutes)),
+			tags:     slices.Sorted(slices.Values(peer.Tags)),
+			expired:  peer.Expired,
+			exitNode: peer.ExitNode,
+		}
+	}
+	return snap
+}
+
+// poll fetches the status and notifies clients about changes since the last poll
+func (w *statusWatcher) poll(ctx context.Context) {
+	status, err := w.cli.Status(ctx)
+	if err != nil {
+		return
+	}
+	snap := newStatusSnapshot(status)
+
+	w.mu.Lock()
+	prev := w.last
+	w.last = snap
+	w.mu.Unlock()
+
+	// The first poll only establishes the baseline
+	if prev == nil {
+		return
+	}
+
+	changes, updated := diffSnapshots(prev, snap)
+	if len(changes) == 0 {
+		return
+	}
+
+	for _, uri := range updated {
+		_ = w.server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+	}
+	notifySessions(ctx, w.server, "info", "Tailscale network changed: "+strings.Join(changes, "; "))
+}
+
+// diffSnapshots describes the changes between two snapshots and returns the
+// URIs of the resources they affect
+func diffSnapshots(prev, cur *statusSnapshot) ([]string, []string) {
+	var changes []string
+	peersChanged, healthChanged := false, false
+
+	if prev.backendState != cur.backendState {
+		changes = append(changes, fmt.Sprintf("backend state %s → %s", prev.backendState, cur.backendState))
+	}
+
+	keys := make([]string, 0, len(cur.peers))
+	for key := range cur.peers {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return cur.peers[keys[i]].name < cur.peers[keys[j]].name })
+
+	for _, key := range keys {
+		peer := cur.peers[key]
+		old, ok := prev.peers[key]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("%s joined the tailnet", peer.name))
+			peersChanged = true
+			continue
+		case old.online != peer.online:
+			state := "offline"
+			if peer.online {
+				state = "online"
+			}
+			changes = append(changes, fmt.Sprintf("%s went %s", peer.name, state))
+			peersChanged = true
+		}
+		if !slices.Equal(old.routes, peer.routes) {
+			changes = append(changes, fmt.Sprintf("%s routes changed: [%s] → [%s]", peer.name, strings.Join(old.routes, ", "), strings.Join(peer.routes, ", ")))
+			peersChanged = true
+		}
+		if !slices.Equal(old.tags, peer.tags) {
+			changes = append(changes, fmt.Sprintf("%s tags changed: [%s] → [%s]", peer.name, strings.Join(old.tags, ", "), strings.Join(peer.tags, ", ")))
+			peersChanged = true
+		}
+		if !old.expired && peer.expired {
+			changes = append(changes, fmt.Sprintf("%s node key expired", peer.name))
+			peersChanged = true
+		}
+		if old.exitNode != peer.exitNode {
+			if peer.exitNode {
+				changes = append(changes, fmt.Sprintf("now using %s as exit node", peer.name))
+			} else {
+				changes = append(changes, fmt.Sprintf("stopped using %s as exit node", peer.name))
+			}
+			peersChanged = true
+		}
+	}
+	for key, old := range prev.peers {
+		if _, ok := cur.peers[key]; !ok {
+			changes = append(changes, fmt.Sprintf("%s left the tailnet", old.name))
+			peersChanged = true
+		}
+	}
+
+	for _, warning := range cur.health {
+		if !slices.Contains(prev.health, warning) {
+			changes = append(changes, "new health warning: "+warning)
+			healthChanged = true
+		}
+	}
+	for _, warning := range prev.health {
+		if !slices.Contains(cur.health, warning) {
+			changes = append(changes, "health warning cleared: "+warning)
+			healthChanged = true
+		}
+	}
+
+	var updated []string
+	if len(changes) > 0 {
+		updated = append(updated, StatusResourceURI)
+	}
+	if peersChanged {
+		updated = append(updated, PeersResourceURI)
+	}
+	if healthChanged {
+		updated = append(updated, HealthResourceURI)
+	}
+	return changes, updated
+}
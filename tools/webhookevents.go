@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// EventsResourceURI is the resource listing received webhook events
+const EventsResourceURI = "tailscale://events"
+
+// maxWebhookEvents bounds how many received events are kept in memory
+const maxWebhookEvents = 500
+
+// maxWebhookBody bounds the size of a webhook delivery
+const maxWebhookBody = 1 << 20
+
+// actionableEvents are the event types that need someone to act, so clients
+// are notified about them at warning level
+var actionableEvents = []string{
+	"nodeNeedsApproval",
+	"nodeNeedsSignature",
+	"nodeKeyExpiringInOneDay",
+	"nodeKeyExpired",
+	"userNeedsApproval",
+	"subnetIPForwardingNotEnabled",
+	"exitNodeIPForwardingNotEnabled",
+}
+
+// WebhookReceiver accepts webhook deliveries from the control plane, keeps
+// the most recent events and forwards them to connected clients
+type WebhookReceiver struct {
+	server *mcp.Server
+	secret string
+
+	mu     sync.Mutex
+	events []tailscale.WebhookEvent
+}
+
+// NewWebhookReceiver creates a receiver that verifies deliveries with the
+// webhook endpoint's secret
+func NewWebhookReceiver(server *mcp.Server, secret string) *WebhookReceiver {
+	return &WebhookReceiver{server: server, secret: secret}
+}
+
+// ServeHTTP handles a delivery: the signature is verified before the body is
+// parsed, then each event is recorded and sent to clients as a log message.
+// Subscribers to tailscale://events also get a resource update.
+func (r *WebhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxWebhookBody+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxWebhookBody {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err := tailscale.VerifyWebhookSignature(req.Header.Get(tailscale.WebhookSignatureHeader), body, r.secret, time.Now()); err != nil {
+		http.Error(w, "invalid signature: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var events []tailscale.WebhookEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		http.Error(w, "invalid event payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.record(events)
+	for _, event := range events {
+		level := mcp.LoggingLevel("info")
+		if slices.Contains(actionableEvents, event.Type) {
+			level = "warning"
+		}
+		notifySessions(req.Context(), r.server, level, fmt.Sprintf("Tailscale event %s: %s", event.Type, event.Message))
+	}
+	if len(events) > 0 {
+		_ = r.server.ResourceUpdated(req.Context(), &mcp.ResourceUpdatedNotificationParams{URI: EventsResourceURI})
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *WebhookReceiver) record(events []tailscale.WebhookEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, events...)
+	if len(r.events) > maxWebhookEvents {
+		r.events = slices.Clone(r.events[len(r.events)-maxWebhookEvents:])
+	}
+}
+
+// Events returns the received events, newest first
+func (r *WebhookReceiver) Events() []tailscale.WebhookEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := slices.Clone(r.events)
+	slices.Reverse(events)
+	return events
+}
+
+// RegisterWebhookEventTools exposes the events received by the webhook
+// receiver as the tailscale://events resource and the recent_events tool
+func RegisterWebhookEventTools(server *mcp.Server, receiver *WebhookReceiver) {
+	server.AddResource(&mcp.Resource{
+		URI:         EventsResourceURI,
+		Name:        "events",
+		Title:       "Tailscale webhook events",
+		Description: "Tailnet events received from the control plane's webhooks, newest first",
+		MIMEType:    "application/json",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		data, err := json.MarshalIndent(map[string]interface{}{"events": receiver.Events()}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{
+				URI:      req.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(data),
+			}},
+		}, nil
+	})
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "recent_events",
+			Description: fmt.Sprintf("Show tailnet events (device added or awaiting approval, key expiry, policy updates, user changes, ...) received by the webhook receiver, newest first. Only the last %d are kept, and only since the server started.", maxWebhookEvents),
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"type": {
+						Type:        "string",
+						Description: "Only show events of this type, e.g. nodeNeedsApproval, or a prefix such as node or user (optional)",
+					},
+					"since": {
+						Type:        "string",
+						Description: "Only show events from this long ago, e.g. 30m or 24h (optional)",
+					},
+					"limit": {
+						Type:        "integer",
+						Description: "Maximum number of events to show (default: 50)",
+					},
+				},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params struct {
+				Type  string `json:"type"`
+				Since string `json:"since"`
+				Limit int    `json:"limit"`
+			}
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+			if params.Limit <= 0 {
+				params.Limit = 50
+			}
+			var cutoff time.Time
+			if params.Since != "" {
+				since, err := time.ParseDuration(params.Since)
+				if err != nil || since <= 0 {
+					return invalidInputResult(fmt.Sprintf("Invalid parameters: since %q is not a positive duration (e.g., 30m, 24h)", params.Since)), nil
+				}
+				cutoff = time.Now().Add(-since)
+			}
+
+			events := []tailscale.WebhookEvent{}
+			for _, event := range receiver.Events() {
+				if params.Type != "" && !strings.HasPrefix(event.Type, params.Type) {
+					continue
+				}
+				if !cutoff.IsZero() && event.Timestamp.Before(cutoff) {
+					continue
+				}
+				events = append(events, event)
+				if len(events) == params.Limit {
+					break
+				}
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Recent Events (%d)\n\n", len(events)))
+			if len(events) == 0 {
+				result.WriteString("No matching events received yet.\n")
+			}
+			for _, event := range events {
+				marker := "•"
+				if slices.Contains(actionableEvents, event.Type) {
+					marker = "⚠"
+				}
+				result.WriteString(fmt.Sprintf("%s %s %s: %s\n", marker, event.Timestamp.Local().Format("2006-01-02 15:04:05"), event.Type, event.Message))
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"events": events}), nil
+		}),
+	)
+}
@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phildougherty/go-tailscale-mcp/tailscale"
+)
+
+// webhookProviders are the providerType values the API accepts. The empty
+// string is a generic endpoint that receives signed JSON deliveries.
+var webhookProviders = []string{"", "slack", "mattermost", "googlechat", "discord"}
+
+// RegisterWebhookTools registers tools for managing tailnet webhook endpoints
+func RegisterWebhookTools(server *mcp.Server, api *tailscale.APIClient, output *OutputWriter) {
+	events := make([]interface{}, len(tailscale.WebhookEvents))
+	for i, event := range tailscale.WebhookEvents {
+		events[i] = event
+	}
+	providers := make([]interface{}, len(webhookProviders))
+	for i, provider := range webhookProviders {
+		providers[i] = provider
+	}
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "list_webhooks",
+			Description: "List the tailnet's webhook endpoints with the events each is subscribed to",
+			Annotations: readOnlyTool(),
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			webhooks, err := api.ListWebhooks(ctx)
+			if err != nil {
+				return errorResult("Error listing webhooks", err), nil
+			}
+			if webhooks == nil {
+				webhooks = []tailscale.Webhook{}
+			}
+
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Webhooks (%d)\n\n", len(webhooks)))
+			if len(webhooks) == 0 {
+				result.WriteString("No webhook endpoints are configured.\n")
+			}
+			for _, webhook := range webhooks {
+				result.WriteString(fmt.Sprintf("%s → %s\n", webhook.EndpointID, webhook.EndpointURL))
+				result.WriteString(fmt.Sprintf("  Provider: %s\n", webhookProviderName(webhook.ProviderType)))
+				if webhook.CreatorLoginName != "" {
+					result.WriteString(fmt.Sprintf("  Created: %s by %s\n", webhook.Created.Format("2006-01-02 15:04:05"), webhook.CreatorLoginName))
+				}
+				result.WriteString(fmt.Sprintf("  Events: %s\n\n", strings.Join(webhook.Subscriptions, ", ")))
+			}
+
+			return structuredResult(result.String(), map[string]interface{}{"webhooks": webhooks}), nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "create_webhook",
+			Description: "Create a webhook endpoint that receives the selected tailnet events. The signing secret is only returned on creation; save it with secrets_file to verify deliveries.",
+			Annotations: updateTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"endpoint_url": {
+						Type:        "string",
+						Description: "URL the events are sent to",
+					},
+					"provider_type": {
+						Type:        "string",
+						Description: "Format the events for slack, mattermost, googlechat or discord; leave empty for a generic signed JSON endpoint",
+						Enum:        providers,
+					},
+					"subscriptions": {
+						Type:        "array",
+						Description: "Events to send (e.g., nodeCreated, nodeNeedsApproval, policyUpdate)",
+						Items:       &jsonschema.Schema{Type: "string", Enum: events},
+					},
+					"secrets_file": secretsFileProperty("signing secret"),
+				},
+				Required: []string{"endpoint_url", "subscriptions"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				EndpointURL   string   `json:"endpoint_url"`
+				ProviderType  string   `json:"provider_type"`
+				Subscriptions []string `json:"subscriptions"`
+				SecretsFile   string   `json:"secrets_file"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+			if params.SecretsFile != "" {
+				if _, err := output.Resolve(ctx, req.Session, params.SecretsFile); err != nil {
+					return invalidParamsResult(err), nil
+				}
+			}
+
+			var problem string
+			endpoint, err := url.Parse(params.EndpointURL)
+			switch {
+			case err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "":
+				problem = fmt.Sprintf("endpoint_url %q is not an http(s) URL", params.EndpointURL)
+			case !containsString(webhookProviders, params.ProviderType):
+				problem = fmt.Sprintf("provider_type must be one of slack, mattermost, googlechat or discord, or empty; got %q", params.ProviderType)
+			case len(params.Subscriptions) == 0:
+				problem = "subscriptions needs at least one event"
+			}
+			for _, event := range params.Subscriptions {
+				if problem == "" && !containsString(tailscale.WebhookEvents, event) {
+					problem = fmt.Sprintf("unknown event %q; valid events: %s", event, strings.Join(tailscale.WebhookEvents, ", "))
+				}
+			}
+			if problem != "" {
+				return invalidInputResult(problem), nil
+			}
+
+			webhook, err := api.CreateWebhook(ctx, params.EndpointURL, params.ProviderType, params.Subscriptions)
+			if err != nil {
+				return errorResult("Error creating webhook", err), nil
+			}
+
+			var result strings.Builder
+			result.WriteString("Webhook Created:\n\n")
+			result.WriteString(fmt.Sprintf("ID: %s\n", webhook.EndpointID))
+			result.WriteString(fmt.Sprintf("URL: %s\n", webhook.EndpointURL))
+			result.WriteString(fmt.Sprintf("Provider: %s\n", webhookProviderName(webhook.ProviderType)))
+			result.WriteString(fmt.Sprintf("Events: %s\n", strings.Join(webhook.Subscriptions, ", ")))
+			if webhook.Secret != "" {
+				result.WriteString(fmt.Sprintf("Secret: %s\n", webhook.Secret))
+				result.WriteString("\n⚠ The secret is not shown again. Store it to verify the Tailscale-Webhook-Signature header on deliveries.\n")
+			}
+			link := saveSecret(ctx, output, req.Session, params.SecretsFile, "signing secret", webhook.Secret, &result)
+
+			toolResult := structuredResult(result.String(), webhook)
+			if link != nil {
+				toolResult.Content = append(toolResult.Content, link)
+			}
+			return toolResult, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "test_webhook",
+			Description: "Send a test event to a webhook endpoint to check that it is reachable",
+			Annotations: updateTool(false),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"endpoint_id": {
+						Type:        "string",
+						Description: "ID of the webhook endpoint, as shown by list_webhooks",
+					},
+				},
+				Required: []string{"endpoint_id"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				EndpointID string `json:"endpoint_id"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			if err := api.TestWebhook(ctx, params.EndpointID); err != nil {
+				return errorResult("Error testing webhook", err), nil
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Test event queued for webhook %s. Delivery happens asynchronously; check the receiving end to confirm it arrived.", params.EndpointID)},
+				},
+			}, nil
+		}),
+	)
+
+	server.AddTool(
+		&mcp.Tool{
+			Name:        "delete_webhook",
+			Description: "Delete a webhook endpoint",
+			Annotations: destructiveTool(true),
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"endpoint_id": {
+						Type:        "string",
+						Description: "ID of the webhook endpoint to delete",
+					},
+				},
+				Required: []string{"endpoint_id"},
+			},
+		},
+		mcp.ToolHandler(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if api == nil || !api.IsAvailable() {
+				return apiNotConfiguredResult(), nil
+			}
+
+			var params struct {
+				EndpointID string `json:"endpoint_id"`
+			}
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return invalidParamsResult(err), nil
+			}
+
+			if err := api.DeleteWebhook(ctx, params.EndpointID); err != nil {
+				return errorResult("Error deleting webhook", err), nil
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Webhook %s deleted successfully.", params.EndpointID)},
+				},
+			}, nil
+		}),
+	)
+}
+
+func webhookProviderName(providerType string) string {
+	if providerType == "" {
+		return "generic"
+	}
+	return providerType
+}